@@ -39,6 +39,7 @@ func (p *MiniMaxProvider) Models(ctx context.Context) ([]common.Model, error) {
 			Name:          modelName,
 			Provider:      common.ProviderName(p.connectContext.Name),
 			ProviderAlias: p.connectContext.Alias,
+			ProviderId:    p.connectContext.Id,
 		})
 	}
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 	"unicode"
 	"wox/common"
 	"wox/setting"
@@ -79,6 +80,13 @@ func NewOpenAIBaseProviderWithOptions(connectContext setting.AIProvider, options
 
 // ChatStream starts a chat stream with the OpenAI compatible provider
 func (o *OpenAIBaseProvider) ChatStream(ctx context.Context, model common.Model, conversations []common.Conversation, options common.ChatOptions) (ChatStream, error) {
+	if model.Name == "" {
+		model.Name = o.connectContext.DefaultModel
+	}
+	if model.Name == "" {
+		return nil, fmt.Errorf("no model specified and provider %s has no default model", o.connectContext.Name)
+	}
+
 	client := o.getClient(ctx)
 	requestOptions := o.getChatRequestOptions(ctx, model, conversations, options)
 
@@ -139,6 +147,7 @@ func (o *OpenAIBaseProvider) Models(ctx context.Context) ([]common.Model, error)
 			Name:          model.ID,
 			Provider:      common.ProviderName(o.connectContext.Name),
 			ProviderAlias: o.connectContext.Alias,
+			ProviderId:    o.connectContext.Id,
 		})
 	}
 
@@ -651,6 +660,9 @@ func (o *OpenAIBaseProvider) getClient(ctx context.Context) openai.Client {
 		option.WithAPIKey(o.connectContext.ApiKey),
 		option.WithHTTPClient(util.GetHTTPClient(ctx)),
 	}
+	if o.connectContext.TimeoutSeconds > 0 {
+		requestOption = append(requestOption, option.WithRequestTimeout(time.Duration(o.connectContext.TimeoutSeconds)*time.Second))
+	}
 
 	// with custom headers
 	if o.options.Headers != nil {
@@ -44,7 +44,7 @@ func initCloudSync(ctx context.Context) {
 		Crypto:            cloudsync.NewAesGcmCrypto(keyManager),
 		DeviceProvider:    deviceProvider,
 		Applier:           settingadapter.NewLocalSettingApplier(),
-		OplogStore:        cloudsync.NewDefaultOplogStore(),
+		OplogStore:        settingadapter.NewSecretOpeningOplogStore(),
 		Snapshotter:       settingadapter.NewLocalSnapshotter(),
 		ProgressNotifier:  cloudSyncUIProgressNotifier{},
 		ExclusionProvider: settingadapter.NewCloudSyncPluginExclusionProvider(),
@@ -18,7 +18,7 @@ func ResolveOplogSyncPolicy(entityType string, entityID string, key string, op s
 
 	if entityType == EntityWoxSetting {
 		switch key {
-		case "ActionedResults", "QueryHistories":
+		case "ActionedResults":
 			return OplogSyncPolicy{Delay: 10 * time.Minute}
 		default:
 			return OplogSyncPolicy{}
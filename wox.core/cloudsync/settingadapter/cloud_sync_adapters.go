@@ -2,6 +2,8 @@ package settingadapter
 
 import (
 	"context"
+	"wox/cloudsync"
+	"wox/database"
 	"wox/setting"
 )
 
@@ -18,3 +20,33 @@ func (p *CloudSyncPluginExclusionProvider) DisabledPluginIDs(ctx context.Context
 	}
 	return woxSetting.CloudSyncDisabledPlugins.Get()
 }
+
+// SecretOpeningOplogStore opens the sealed secrets of wox setting oplogs
+// before they are pushed. Secrets are sealed with a device-local key, so like
+// the snapshotter the cloud payload must carry them opened; the cloud sync
+// crypto layer encrypts them end to end.
+type SecretOpeningOplogStore struct {
+	*cloudsync.DefaultOplogStore
+}
+
+func NewSecretOpeningOplogStore() *SecretOpeningOplogStore {
+	return &SecretOpeningOplogStore{DefaultOplogStore: cloudsync.NewDefaultOplogStore()}
+}
+
+func (s *SecretOpeningOplogStore) LoadPending(ctx context.Context, limit int) ([]database.Oplog, error) {
+	oplogs, err := s.DefaultOplogStore.LoadPending(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range oplogs {
+		if oplogs[i].EntityType != cloudsync.EntityWoxSetting || oplogs[i].Operation != cloudsync.OpUpsert {
+			continue
+		}
+		value, openErr := setting.OpenStoredSettingValue(oplogs[i].Key, oplogs[i].Value)
+		if openErr != nil {
+			return nil, openErr
+		}
+		oplogs[i].Value = value
+	}
+	return oplogs, nil
+}
@@ -22,6 +22,13 @@ func NewLocalSettingApplier() *LocalSettingApplier {
 }
 
 func (a *LocalSettingApplier) ApplyWoxSetting(ctx context.Context, key string, op string, rawValue string) error {
+	if key == setting.LegacyQueryHistoriesKey {
+		// Query history is local only, applying the retired row would only
+		// bring it back next to the query_history table.
+		util.GetLogger().Debug(ctx, "skip remote change of the retired query history setting")
+		return nil
+	}
+
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
 	if woxSetting == nil {
 		return fmt.Errorf("wox setting not initialized")
@@ -75,7 +82,7 @@ func (a *LocalSettingApplier) ApplyPluginSetting(ctx context.Context, pluginID s
 			return err
 		}
 		if shouldNotifySettingChange(op, hadPrevious, previousValue, rawValue) {
-			notifyPluginSettingChanged(ctx, pluginID, normalizePluginSettingKey(key), rawValue)
+			notifyPluginSettingChanged(ctx, pluginID, normalizePluginSettingKey(key), previousValue, rawValue)
 		}
 		return nil
 	default:
@@ -279,7 +286,7 @@ func normalizePluginSettingKey(key string) string {
 	return key
 }
 
-func notifyPluginSettingChanged(ctx context.Context, pluginID string, key string, value string) {
+func notifyPluginSettingChanged(ctx context.Context, pluginID string, key string, previousValue string, value string) {
 	instances := plugin.GetPluginManager().GetPluginInstances()
 	for _, instance := range instances {
 		if instance.Metadata.Id != pluginID {
@@ -288,6 +295,7 @@ func notifyPluginSettingChanged(ctx context.Context, pluginID string, key string
 		for _, callback := range instance.SettingChangeCallbacks {
 			callback(ctx, key, value)
 		}
+		instance.NotifyPluginSettingChanged(ctx, key, previousValue, value)
 		return
 	}
 }
@@ -70,18 +70,24 @@ func (s *LocalSnapshotter) collectLocalSnapshotOplogs(ctx context.Context) ([]da
 	oplogs := make([]database.Oplog, 0, len(woxSettings)+len(pluginSettings))
 
 	for _, item := range woxSettings {
-		if !isCurrentPlatformSettingKey(item.Key) {
+		if !isCurrentPlatformSettingKey(item.Key) || item.Key == setting.LastModifiedKey {
 			continue
 		}
 		if syncable, ok := syncableWoxSettings[item.Key]; ok && !syncable {
 			continue
 		}
+		// Secret fields are sealed with a device-local key, so they must be
+		// opened before they enter the cloud payload.
+		value, err := setting.OpenStoredSettingValue(item.Key, item.Value)
+		if err != nil {
+			return nil, err
+		}
 		oplogs = append(oplogs, database.Oplog{
 			EntityType: cloudsync.EntityWoxSetting,
 			EntityID:   item.Key,
 			Operation:  cloudsync.OpUpsert,
 			Key:        item.Key,
-			Value:      item.Value,
+			Value:      value,
 			Timestamp:  timestamp,
 		})
 	}
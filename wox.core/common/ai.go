@@ -133,6 +133,7 @@ type Model struct {
 	Name          string
 	Provider      ProviderName
 	ProviderAlias string // optional, used to choose the correct provider config when there are multiple
+	ProviderId    string // id of the provider config, preferred over Provider and ProviderAlias when set
 }
 
 func (m *Model) ProviderName() string {
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"wox/analytics"
 	"wox/util"
@@ -116,6 +120,24 @@ type MRURecord struct {
 	UpdatedAt   time.Time
 }
 
+// QueryHistoryRecord stores one query the user submitted. Query holds the
+// display text for searching and de-duplication; PlainQuery is the full
+// serialized common.PlainQuery so the query can be restored as it was typed.
+// PluginID is empty for the global history and holds the plugin id for the
+// history a plugin keeps of its own queries. Rows are local only and never
+// synced, see setting.LegacyQueryHistoriesKey.
+type QueryHistoryRecord struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	PluginID   string `gorm:"index;not null;default:''"`
+	Query      string `gorm:"index;not null"`
+	PlainQuery string `gorm:"not null"`
+	Timestamp  int64  `gorm:"index;not null"`
+}
+
+func (QueryHistoryRecord) TableName() string {
+	return "query_history"
+}
+
 // AttentionItem stores persistent plugin-sourced user attention items.
 type AttentionItem struct {
 	IdentityKey        string `gorm:"primaryKey"`
@@ -141,9 +163,15 @@ type MigrationRecord struct {
 }
 
 func Init(ctx context.Context) error {
+	return initDatabase(ctx, true)
+}
+
+func initDatabase(ctx context.Context, allowBackupRestore bool) error {
 	util.GetLogger().Info(ctx, "initializing database")
 
-	dbPath := filepath.Join(util.GetLocation().GetUserDataDirectory(), "wox.db")
+	dbPath := util.GetLocation().GetDatabasePath()
+	dbInfo, statErr := os.Stat(dbPath)
+	dbExisted := statErr == nil
 
 	// Configure SQLite with proper concurrency settings
 	dsn := dbPath + "?" +
@@ -158,6 +186,10 @@ func Init(ctx context.Context) error {
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
+		// A badly truncated file fails while the connection applies its pragmas.
+		if allowBackupRestore && dbExisted {
+			return recoverUnusableDatabase(ctx, dbPath, nil, err.Error())
+		}
 		return err
 	}
 
@@ -189,8 +221,65 @@ func Init(ctx context.Context) error {
 	}
 
 	runIntegrityChecks(ctx, sqlDB)
+	if allowBackupRestore && dbExisted {
+		if reason := checkExistingDatabase(sqlDB, dbInfo.Size()); reason != "" {
+			return recoverUnusableDatabase(ctx, dbPath, sqlDB, reason)
+		}
+	}
+
+	if err := MigrateSchema(db); err != nil {
+		return fmt.Errorf("failed to migrate database schema: %w", err)
+	}
 
-	err = db.AutoMigrate(
+	return nil
+}
+
+// InitReadOnly opens wox.db without ever writing to it, for a user data
+// directory that cannot be written, e.g. a read-only mount. The schema is not
+// migrated and no backup is restored. Without an existing wox.db an empty
+// in-memory database is used, so Wox still starts with default settings.
+func InitReadOnly(ctx context.Context) error {
+	util.GetLogger().Info(ctx, "initializing database read-only")
+
+	dbPath := util.GetLocation().GetDatabasePath()
+	if _, statErr := os.Stat(dbPath); statErr != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("no readable database at %s, using an empty in-memory database: %s", dbPath, statErr.Error()))
+		return openInMemoryDatabase()
+	}
+
+	// SQLite only reads the mode parameter from URI filenames.
+	var err error
+	db, err = gorm.Open(sqlite.Open("file:"+filepath.ToSlash(dbPath)+"?mode=ro&_busy_timeout=5000"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open database read-only: %w", err)
+	}
+	return nil
+}
+
+// openInMemoryDatabase opens an empty database that lives for this session.
+// One connection keeps every query on the same in-memory database.
+func openInMemoryDatabase() error {
+	var err error
+	db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return MigrateSchema(db)
+}
+
+// MigrateSchema creates or updates every Wox table in db. It is exported for
+// databases opened outside Init, e.g. a backup copy migrated before restore.
+func MigrateSchema(db *gorm.DB) error {
+	return db.AutoMigrate(
 		&analytics.Event{},
 		&WoxSetting{},
 		&PluginSetting{},
@@ -199,20 +288,58 @@ func Init(ctx context.Context) error {
 		&CloudSyncHistory{},
 		&AccountState{},
 		&MRURecord{},
+		&QueryHistoryRecord{},
 		&AttentionItem{},
 		&MigrationRecord{},
 	)
-	if err != nil {
-		return fmt.Errorf("failed to migrate database schema: %w", err)
-	}
-
-	return nil
 }
 
 func GetDB() *gorm.DB {
 	return db
 }
 
+// recoverUnusableDatabase handles a wox.db that is empty, cannot be read at
+// all (e.g. a truncated file after power loss) or lost its settings table,
+// which would otherwise start Wox with empty settings. Files that open but
+// fail quick_check are left to the doctor, see RecoverDatabase. It falls back
+// to the latest auto backup, or archives the file and starts over as a fresh
+// install so the migrations run again. sqlDB may be nil when the file could
+// not be opened.
+func recoverUnusableDatabase(ctx context.Context, dbPath string, sqlDB *sql.DB, reason string) error {
+	util.GetLogger().Error(ctx, fmt.Sprintf("existing database is unusable: %s", reason))
+
+	backupPath, restoreErr := restoreDatabaseFromLatestBackup(ctx, dbPath, sqlDB)
+	if restoreErr == nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("database was unusable, restored from backup: %s", backupPath))
+		return initDatabase(ctx, false)
+	}
+	util.GetLogger().Error(ctx, fmt.Sprintf("failed to restore database from backup: %v", restoreErr))
+
+	corruptPath, moveErr := moveAsideDatabase(ctx, dbPath, sqlDB)
+	if moveErr != nil {
+		return fmt.Errorf("failed to archive unusable database: %w", moveErr)
+	}
+	util.GetLogger().Warn(ctx, fmt.Sprintf("database was unusable and no backup could be restored, archived it to %s and starting fresh", corruptPath))
+	return initDatabase(ctx, false)
+}
+
+// checkExistingDatabase returns why an existing wox.db cannot be used, or an
+// empty string when it looks healthy. A file that exists but is empty or has
+// no settings table is usually left behind by a failed first run or a crash.
+func checkExistingDatabase(sqlDB *sql.DB, size int64) string {
+	if size == 0 {
+		return "database file is empty"
+	}
+	var tableCount int
+	if err := sqlDB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'wox_settings'").Scan(&tableCount); err != nil {
+		return fmt.Sprintf("failed to read schema: %v", err)
+	}
+	if tableCount == 0 {
+		return "wox_settings table is missing"
+	}
+	return ""
+}
+
 // runIntegrityChecks runs a lightweight PRAGMA quick_check only to detect corruption.
 func runIntegrityChecks(ctx context.Context, sqlDB *sql.DB) {
 	logger := util.GetLogger()
@@ -265,7 +392,7 @@ func RecoverDatabase(ctx context.Context) (RecoveryResult, error) {
 		return result, fmt.Errorf("sqlite3 not found in PATH: %w", err)
 	}
 
-	dbPath := filepath.Join(util.GetLocation().GetUserDataDirectory(), "wox.db")
+	dbPath := util.GetLocation().GetDatabasePath()
 	if _, err := os.Stat(dbPath); err != nil {
 		return result, fmt.Errorf("failed to stat database: %w", err)
 	}
@@ -361,6 +488,137 @@ func RecoverDatabase(ctx context.Context) (RecoveryResult, error) {
 	return result, nil
 }
 
+var backupFileReaderMu sync.RWMutex
+var backupFileReader func(backupDir string, name string) ([]byte, error)
+
+// SetBackupFileReader registers the reader that returns the plaintext of a file
+// in an encrypted backup. The setting package owns the backup key, so it is
+// registered at startup, see setting.ReadBackupFile.
+func SetBackupFileReader(reader func(backupDir string, name string) ([]byte, error)) {
+	backupFileReaderMu.Lock()
+	defer backupFileReaderMu.Unlock()
+	backupFileReader = reader
+}
+
+// restoreDatabaseFromLatestBackup replaces a corrupt wox.db with the copy from the newest backup
+// that can be read. Backups that cannot be read, e.g. ones encrypted on another machine, are
+// skipped before anything is moved. The corrupt file and its journal are kept next to it so
+// doctor recovery can still be attempted.
+func restoreDatabaseFromLatestBackup(ctx context.Context, dbPath string, sqlDB *sql.DB) (string, error) {
+	backupDir := util.GetLocation().GetBackupDirectory()
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	// Backup folders are named by their creation timestamp, see setting.Manager.Backup.
+	type candidate struct {
+		ts   int64
+		path string
+	}
+	var candidates []candidate
+	for _, entry := range entries {
+		ts, parseErr := strconv.ParseInt(entry.Name(), 10, 64)
+		if !entry.IsDir() || parseErr != nil {
+			continue
+		}
+		candidatePath := filepath.Join(backupDir, entry.Name())
+		if util.IsFileExists(filepath.Join(candidatePath, "wox.db")) {
+			candidates = append(candidates, candidate{ts: ts, path: candidatePath})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no backup with wox.db found in %s", backupDir)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ts > candidates[j].ts
+	})
+
+	for _, c := range candidates {
+		content, readErr := readBackupDatabase(c.path)
+		if readErr != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("skip backup %s: %v", c.path, readErr))
+			continue
+		}
+
+		if _, err := moveAsideDatabase(ctx, dbPath, sqlDB); err != nil {
+			return "", err
+		}
+		if err := util.WriteFileAtomic(dbPath, content, 0644); err != nil {
+			return "", fmt.Errorf("failed to write restored database: %w", err)
+		}
+		return filepath.Join(c.path, "wox.db"), nil
+	}
+
+	return "", fmt.Errorf("no readable backup found in %s", backupDir)
+}
+
+// readBackupDatabase returns the plaintext wox.db of a backup. backup.json
+// tells whether its files are encrypted, see setting.Backup.
+func readBackupDatabase(backupPath string) ([]byte, error) {
+	var info struct {
+		Encrypted bool
+	}
+	if infoContent, err := os.ReadFile(filepath.Join(backupPath, "backup.json")); err == nil {
+		if err := json.Unmarshal(infoContent, &info); err != nil {
+			return nil, fmt.Errorf("failed to parse backup info: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read backup info: %w", err)
+	}
+
+	var content []byte
+	if info.Encrypted {
+		backupFileReaderMu.RLock()
+		reader := backupFileReader
+		backupFileReaderMu.RUnlock()
+		if reader == nil {
+			return nil, fmt.Errorf("backup is encrypted and no backup reader is registered")
+		}
+		decrypted, err := reader(backupPath, "wox.db")
+		if err != nil {
+			return nil, err
+		}
+		content = decrypted
+	} else {
+		plaintext, err := os.ReadFile(filepath.Join(backupPath, "wox.db"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup database: %w", err)
+		}
+		content = plaintext
+	}
+
+	if !bytes.HasPrefix(content, sqliteHeader) {
+		return nil, fmt.Errorf("backup database is not a sqlite file")
+	}
+	return content, nil
+}
+
+// sqliteHeader starts every sqlite database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// moveAsideDatabase closes the database and renames wox.db and its journal
+// files to wox.db.corrupt_<ts>, keeping them for doctor recovery.
+func moveAsideDatabase(ctx context.Context, dbPath string, sqlDB *sql.DB) (string, error) {
+	if sqlDB != nil {
+		if err := sqlDB.Close(); err != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to close corrupt database: %v", err))
+		}
+	}
+
+	corruptPath := fmt.Sprintf("%s.corrupt_%d", dbPath, util.GetSystemTimestamp())
+	// A leftover rollback journal belongs to the corrupt file and must not be
+	// replayed onto the restored one.
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		if _, statErr := os.Stat(dbPath + suffix); statErr == nil {
+			if renameErr := os.Rename(dbPath+suffix, corruptPath+suffix); renameErr != nil {
+				return "", fmt.Errorf("failed to move aside %s: %w", dbPath+suffix, renameErr)
+			}
+		}
+	}
+	return corruptPath, nil
+}
+
 func copyFile(src, dst string) error {
 	srcFile, err := os.Open(src)
 	if err != nil {
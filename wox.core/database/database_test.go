@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wox/util"
+)
+
+func initTestLocation(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv(util.TestWoxDataDirEnv, filepath.Join(root, "wox"))
+	t.Setenv(util.TestUserDataDirEnv, filepath.Join(root, "user"))
+	if err := util.GetLocation().Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+	return filepath.Join(util.GetLocation().GetUserDataDirectory(), "wox.db")
+}
+
+func closeTestDB(t *testing.T) {
+	t.Helper()
+	sqlDB, err := GetDB().DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	_ = sqlDB.Close()
+}
+
+func findCorruptArchives(t *testing.T, dbPath string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(dbPath + ".corrupt_*")
+	if err != nil {
+		t.Fatalf("failed to glob corrupt archives: %v", err)
+	}
+	return matches
+}
+
+func TestInitRecoversFromTruncatedDatabase(t *testing.T) {
+	ctx := context.Background()
+	dbPath := initTestLocation(t)
+
+	if err := Init(ctx); err != nil {
+		t.Fatalf("failed to init database: %v", err)
+	}
+	if err := GetDB().Create(&WoxSetting{Key: "LangCode", Value: "en_US"}).Error; err != nil {
+		t.Fatalf("failed to write setting: %v", err)
+	}
+	closeTestDB(t)
+
+	// Keep only part of the SQLite header, as a crash during a rewrite would.
+	if err := os.Truncate(dbPath, 50); err != nil {
+		t.Fatalf("failed to truncate database: %v", err)
+	}
+
+	if err := Init(ctx); err != nil {
+		t.Fatalf("expected init to recover from truncated database, got: %v", err)
+	}
+	defer closeTestDB(t)
+
+	if archives := findCorruptArchives(t, dbPath); len(archives) != 1 {
+		t.Fatalf("expected the truncated database to be archived once, got %v", archives)
+	}
+
+	var count int64
+	if err := GetDB().Model(&WoxSetting{}).Count(&count).Error; err != nil {
+		t.Fatalf("expected a usable database after recovery: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected a fresh database without backups, got %d settings", count)
+	}
+	var migrationCount int64
+	if err := GetDB().Model(&MigrationRecord{}).Count(&migrationCount).Error; err != nil {
+		t.Fatalf("expected migration_records table: %v", err)
+	}
+	if migrationCount != 0 {
+		t.Fatalf("expected migrations to run again on the fresh database, got %d records", migrationCount)
+	}
+}
+
+func TestInitRestoresEmptyDatabaseFromBackup(t *testing.T) {
+	ctx := context.Background()
+	dbPath := initTestLocation(t)
+
+	if err := Init(ctx); err != nil {
+		t.Fatalf("failed to init database: %v", err)
+	}
+	if err := GetDB().Create(&WoxSetting{Key: "LangCode", Value: "zh_CN"}).Error; err != nil {
+		t.Fatalf("failed to write setting: %v", err)
+	}
+	closeTestDB(t)
+
+	backupDir := filepath.Join(util.GetLocation().GetBackupDirectory(), "1700000000000")
+	if err := os.MkdirAll(backupDir, os.ModePerm); err != nil {
+		t.Fatalf("failed to create backup dir: %v", err)
+	}
+	content, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read database: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(backupDir, "wox.db"), content, 0644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	if err := os.WriteFile(dbPath, nil, 0644); err != nil {
+		t.Fatalf("failed to empty database: %v", err)
+	}
+
+	if err := Init(ctx); err != nil {
+		t.Fatalf("expected init to recover from empty database, got: %v", err)
+	}
+	defer closeTestDB(t)
+
+	var setting WoxSetting
+	if err := GetDB().Where("key = ?", "LangCode").First(&setting).Error; err != nil {
+		t.Fatalf("expected setting restored from backup: %v", err)
+	}
+	if setting.Value != "zh_CN" {
+		t.Fatalf("expected restored value zh_CN, got %s", setting.Value)
+	}
+	if archives := findCorruptArchives(t, dbPath); len(archives) != 1 || !strings.Contains(archives[0], ".corrupt_") {
+		t.Fatalf("expected the empty database to be archived, got %v", archives)
+	}
+}
+
+func TestInitReadOnlyReadsExistingDatabaseWithoutWriting(t *testing.T) {
+	ctx := context.Background()
+	initTestLocation(t)
+
+	if err := Init(ctx); err != nil {
+		t.Fatalf("failed to init database: %v", err)
+	}
+	if err := GetDB().Create(&WoxSetting{Key: "LangCode", Value: "en_US"}).Error; err != nil {
+		t.Fatalf("failed to write setting: %v", err)
+	}
+	closeTestDB(t)
+
+	if err := InitReadOnly(ctx); err != nil {
+		t.Fatalf("failed to open database read-only: %v", err)
+	}
+	defer closeTestDB(t)
+
+	var row WoxSetting
+	if err := GetDB().Where("key = ?", "LangCode").First(&row).Error; err != nil || row.Value != "en_US" {
+		t.Fatalf("expected to read the stored setting, got %q (%v)", row.Value, err)
+	}
+	if err := GetDB().Create(&WoxSetting{Key: "ShowTray", Value: "false"}).Error; err == nil {
+		t.Fatalf("expected writes to a database opened read-only to fail")
+	}
+}
+
+func TestInitReadOnlyWithoutDatabaseUsesEmptyInMemoryDatabase(t *testing.T) {
+	ctx := context.Background()
+	dbPath := initTestLocation(t)
+
+	if err := InitReadOnly(ctx); err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	defer closeTestDB(t)
+
+	if err := GetDB().Create(&WoxSetting{Key: "ShowTray", Value: "false"}).Error; err != nil {
+		t.Fatalf("expected the in-memory database to have the schema, got %v", err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no database file to be created, got %v", err)
+	}
+}
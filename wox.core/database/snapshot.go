@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot writes a consistent copy of the open database to dstPath with
+// VACUUM INTO. The copy is read inside a single transaction, so writes that
+// happen during the snapshot can neither tear it nor be half included, unlike
+// copying wox.db and its journal from disk. dstPath must not exist yet.
+func Snapshot(ctx context.Context, dstPath string) error {
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := db.WithContext(ctx).Exec("VACUUM INTO ?", dstPath).Error; err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
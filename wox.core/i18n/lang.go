@@ -1,5 +1,10 @@
 package i18n
 
+import (
+	"fmt"
+	"strings"
+)
+
 type LangCode string
 
 type Lang struct {
@@ -35,11 +40,58 @@ func GetSupportedLanguages() []Lang {
 	}
 }
 
-func IsSupportedLangCode(langCode string) bool {
-	for _, lang := range GetSupportedLanguages() {
-		if string(lang.Code) == langCode {
+// SupportedLangs returns the codes of every language Wox ships a translation
+// for, in the order of GetSupportedLanguages, e.g. for a language dropdown.
+func SupportedLangs() []LangCode {
+	languages := GetSupportedLanguages()
+	codes := make([]LangCode, 0, len(languages))
+	for _, lang := range languages {
+		codes = append(codes, lang.Code)
+	}
+	return codes
+}
+
+// IsSupported reports whether Wox ships a translation for code.
+func IsSupported(code LangCode) bool {
+	for _, supported := range SupportedLangs() {
+		if supported == code {
 			return true
 		}
 	}
 	return false
 }
+
+func IsSupportedLangCode(langCode string) bool {
+	return IsSupported(LangCode(langCode))
+}
+
+// UnsupportedLangError describes a language code without a translation and
+// lists the supported ones, so callers can show a useful message.
+func UnsupportedLangError(code LangCode) error {
+	supported := make([]string, 0, len(SupportedLangs()))
+	for _, lang := range SupportedLangs() {
+		supported = append(supported, string(lang))
+	}
+	return fmt.Errorf("unsupported lang code: %s, supported: %s", code, strings.Join(supported, ", "))
+}
+
+// LangCodeFromLocale maps an OS language and region, e.g. "zh" and "CN", to
+// the closest supported language. It returns false when Wox has no
+// translation for the language.
+func LangCodeFromLocale(lang string, region string) (LangCode, bool) {
+	switch strings.ToLower(lang) {
+	case "en":
+		return LangCodeEnUs, true
+	case "zh":
+		// Only simplified Chinese is translated; zh_TW users would be better
+		// served by their own choice than by an automatic suggestion.
+		if strings.EqualFold(region, "CN") || strings.EqualFold(region, "SG") {
+			return LangCodeZhCn, true
+		}
+	case "ru":
+		return LangCodeRuRu, true
+	case "pt":
+		return LangCodePtBr, true
+	}
+	return "", false
+}
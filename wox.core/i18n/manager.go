@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"wox/resource"
 	"wox/util"
 
+	"github.com/samber/lo"
 	"github.com/tidwall/gjson"
 )
 
@@ -15,43 +17,140 @@ var managerInstance *Manager
 var managerOnce sync.Once
 
 type Manager struct {
-	currentLangCode  LangCode
-	enUsLangJson     string
-	currentLangJson  string
-	currentLangCache *util.HashMap[string, string]
-	enUsCache        *util.HashMap[string, string]
+	// mu guards the language tables, which are swapped while other goroutines
+	// translate. switchMu serializes SwitchLang so two switches cannot
+	// interleave their save and swap steps.
+	mu       sync.RWMutex
+	switchMu sync.Mutex
+
+	currentLangCode LangCode
+	currentLang     *langTable
+	// fallbackLang is consulted after the current language and before en_US.
+	// It is nil when no extra fallback is configured.
+	fallbackLang *langTable
+	enUsLang     *langTable
+	// chain is the lookup order built from the tables above whenever one of
+	// them changes, so translating does not allocate, see langChain.
+	chain atomic.Pointer[[]*langTable]
+}
+
+// langTable is a parsed language file with a lookup cache.
+type langTable struct {
+	code  LangCode
+	json  string
+	cache *util.HashMap[string, string]
+}
+
+func newLangTable(code LangCode, json string) *langTable {
+	return &langTable{
+		code:  code,
+		json:  json,
+		cache: util.NewHashMap[string, string](),
+	}
+}
+
+func (t *langTable) lookup(key string) (string, bool) {
+	if value, ok := t.cache.Load(key); ok {
+		return value, true
+	}
+	result := gjson.Get(t.json, key)
+	if !result.Exists() {
+		return "", false
+	}
+	value := result.String()
+	t.cache.Store(key, value)
+	return value, true
+}
+
+func newManager(enUsLangJson string) *Manager {
+	enUsLang := newLangTable(LangCodeEnUs, enUsLangJson)
+	m := &Manager{
+		currentLangCode: LangCodeEnUs,
+		currentLang:     enUsLang,
+		enUsLang:        enUsLang,
+	}
+	m.rebuildLangChain()
+	return m
 }
 
 func GetI18nManager() *Manager {
 	managerOnce.Do(func() {
-		managerInstance = &Manager{
-			currentLangCode:  LangCodeEnUs,
-			currentLangCache: util.NewHashMap[string, string](),
-			enUsCache:        util.NewHashMap[string, string](),
-		}
 		json, _ := resource.GetLangJson(util.NewTraceContext(), string(LangCodeEnUs))
-		managerInstance.enUsLangJson = string(json)
+		managerInstance = newManager(string(json))
 	})
 	return managerInstance
 }
 
+// SetFallbackLang sets the language used when a key is missing in the current
+// language. en_US is always the last fallback, so passing en_US or an empty code
+// clears the extra fallback.
+func SetFallbackLang(code LangCode) error {
+	return GetI18nManager().SetFallbackLang(util.NewTraceContext(), code)
+}
+
+func (m *Manager) SetFallbackLang(ctx context.Context, langCode LangCode) error {
+	if langCode == "" || langCode == LangCodeEnUs {
+		m.mu.Lock()
+		m.fallbackLang = nil
+		m.rebuildLangChain()
+		m.mu.Unlock()
+		return nil
+	}
+	if !IsSupported(langCode) {
+		return UnsupportedLangError(langCode)
+	}
+
+	json, err := m.GetLangJson(ctx, langCode)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.fallbackLang = newLangTable(langCode, json)
+	m.rebuildLangChain()
+	m.mu.Unlock()
+	return nil
+}
+
 func (m *Manager) UpdateLang(ctx context.Context, langCode LangCode) error {
-	if !IsSupportedLangCode(string(langCode)) {
-		return fmt.Errorf("unsupported lang code: %s", langCode)
+	return m.SwitchLang(ctx, langCode, nil)
+}
+
+// SwitchLang changes the current language together with its persisted
+// setting. The language file is loaded first and save runs before anything
+// in memory changes, so a failed save leaves the previous language active and
+// the in-memory and stored language never diverge. A nil save only switches
+// the in-memory language.
+func (m *Manager) SwitchLang(ctx context.Context, langCode LangCode, save func() error) error {
+	if !IsSupported(langCode) {
+		return UnsupportedLangError(langCode)
 	}
 
+	m.switchMu.Lock()
+	defer m.switchMu.Unlock()
+
 	json, err := m.GetLangJson(ctx, langCode)
 	if err != nil {
 		return err
 	}
 
+	if save != nil {
+		if saveErr := save(); saveErr != nil {
+			return fmt.Errorf("failed to save lang code %s: %w", langCode, saveErr)
+		}
+	}
+
+	m.mu.Lock()
 	m.currentLangCode = langCode
-	m.currentLangJson = json
-	m.currentLangCache.Clear()
+	m.currentLang = newLangTable(langCode, json)
+	m.rebuildLangChain()
+	m.mu.Unlock()
 	return nil
 }
 
 func (m *Manager) GetCurrentLangCode() LangCode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.currentLangCode
 }
 
@@ -64,31 +163,36 @@ func (m *Manager) GetLangJson(ctx context.Context, langCode LangCode) (string, e
 	return string(json), nil
 }
 
-// TranslateWox translates a key using the current language json file.
+// rebuildLangChain stores the languages to search in order: current,
+// fallback, en_US. Callers hold mu for writing.
+func (m *Manager) rebuildLangChain() {
+	chain := []*langTable{m.currentLang}
+	for _, lang := range []*langTable{m.fallbackLang, m.enUsLang} {
+		if lang == nil || lo.ContainsBy(chain, func(item *langTable) bool { return item.code == lang.code }) {
+			continue
+		}
+		chain = append(chain, lang)
+	}
+	m.chain.Store(&chain)
+}
+
+// langChain returns the lookup order built by rebuildLangChain. The slice is
+// shared and must not be modified.
+func (m *Manager) langChain() []*langTable {
+	return *m.chain.Load()
+}
+
+// TranslateWox translates a key using the current language json file, walking
+// the fallback chain when the key is missing so users never see a raw key.
 // Because this function is hot path, we use cache to improve performance
 func (m *Manager) TranslateWox(ctx context.Context, key string) string {
 	originKey := key
 
 	key = strings.TrimPrefix(key, "i18n:")
-	if value, ok := m.currentLangCache.Load(key); ok {
-		return value
-	}
-	result := gjson.Get(m.currentLangJson, key)
-	if result.Exists() {
-		value := result.String()
-		m.currentLangCache.Store(key, value)
-		return value
-	}
-
-	// fallback to en_US
-	if value, ok := m.enUsCache.Load(key); ok {
-		return value
-	}
-	enUsResult := gjson.Get(m.enUsLangJson, key)
-	if enUsResult.Exists() {
-		value := enUsResult.String()
-		m.enUsCache.Store(key, value)
-		return value
+	for _, lang := range m.langChain() {
+		if value, ok := lang.lookup(key); ok {
+			return value
+		}
 	}
 
 	return originKey
@@ -98,13 +202,7 @@ func (m *Manager) TranslateWoxEnUs(ctx context.Context, key string) string {
 	originKey := key
 
 	key = strings.TrimPrefix(key, "i18n:")
-	if value, ok := m.enUsCache.Load(key); ok {
-		return value
-	}
-	enUsResult := gjson.Get(m.enUsLangJson, key)
-	if enUsResult.Exists() {
-		value := enUsResult.String()
-		m.enUsCache.Store(key, value)
+	if value, ok := m.enUsLang.lookup(key); ok {
 		return value
 	}
 
@@ -114,21 +212,15 @@ func (m *Manager) TranslateWoxEnUs(ctx context.Context, key string) string {
 // TranslateI18nMap translates a key using metadata i18n map that may include both inline and lang file values.
 // Priority:
 // 1. I18n map for current language
-// 2. I18n map for en_US fallback
-// 3. Return original key
+// 2. I18n map for the configured fallback language
+// 3. I18n map for en_US fallback
+// 4. Return original key
 func (m *Manager) TranslateI18nMap(_ context.Context, key string, pluginI18n map[string]map[string]string) string {
 	originKey := key
 
 	key = strings.TrimPrefix(key, "i18n:")
-
-	// 1. Try current language
-	if translated := m.translateFromInlineI18n(key, string(m.currentLangCode), pluginI18n); translated != "" {
-		return translated
-	}
-
-	// 2. Try en_US fallback
-	if m.currentLangCode != LangCodeEnUs {
-		if translated := m.translateFromInlineI18n(key, string(LangCodeEnUs), pluginI18n); translated != "" {
+	for _, lang := range m.langChain() {
+		if translated := m.translateFromInlineI18n(key, string(lang.code), pluginI18n); translated != "" {
 			return translated
 		}
 	}
@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testEnUsJson = `{"greeting":"Hello","farewell":"Goodbye","only_en":"English only"}`
+
+// testZhCnJson is deliberately incomplete: "farewell" and "only_en" are missing.
+const testZhCnJson = `{"greeting":"你好"}`
+
+const testPtBrJson = `{"farewell":"Adeus"}`
+
+func TestTranslateWoxFallsBackToEnUs(t *testing.T) {
+	m := newManager(testEnUsJson)
+	m.currentLangCode = LangCodeZhCn
+	m.currentLang = newLangTable(LangCodeZhCn, testZhCnJson)
+	m.rebuildLangChain()
+
+	ctx := context.Background()
+	assert.Equal(t, "你好", m.TranslateWox(ctx, "i18n:greeting"))
+	assert.Equal(t, "Goodbye", m.TranslateWox(ctx, "i18n:farewell"))
+	assert.Equal(t, "i18n:missing", m.TranslateWox(ctx, "i18n:missing"))
+}
+
+func TestTranslateWoxWalksFallbackChain(t *testing.T) {
+	m := newManager(testEnUsJson)
+	m.currentLangCode = LangCodeZhCn
+	m.currentLang = newLangTable(LangCodeZhCn, testZhCnJson)
+	m.fallbackLang = newLangTable(LangCodePtBr, testPtBrJson)
+	m.rebuildLangChain()
+
+	ctx := context.Background()
+	assert.Equal(t, "你好", m.TranslateWox(ctx, "greeting"))
+	assert.Equal(t, "Adeus", m.TranslateWox(ctx, "farewell"))
+	assert.Equal(t, "English only", m.TranslateWox(ctx, "only_en"))
+}
+
+func TestTranslateWoxDoesNotAllocate(t *testing.T) {
+	m := newManager(testEnUsJson)
+	ctx := context.Background()
+	assert.NoError(t, m.SetFallbackLang(ctx, LangCodeZhCn))
+	m.TranslateWox(ctx, "i18n:greeting")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.TranslateWox(ctx, "i18n:greeting")
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestTranslateI18nMapWalksFallbackChain(t *testing.T) {
+	m := newManager(testEnUsJson)
+	m.currentLangCode = LangCodeZhCn
+	m.currentLang = newLangTable(LangCodeZhCn, testZhCnJson)
+	m.fallbackLang = newLangTable(LangCodePtBr, testPtBrJson)
+	m.rebuildLangChain()
+
+	pluginI18n := map[string]map[string]string{
+		"pt_BR": {"title": "Título"},
+		"en_US": {"title": "Title", "desc": "Description"},
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, "Título", m.TranslateI18nMap(ctx, "i18n:title", pluginI18n))
+	assert.Equal(t, "Description", m.TranslateI18nMap(ctx, "i18n:desc", pluginI18n))
+	assert.Equal(t, "i18n:unknown", m.TranslateI18nMap(ctx, "i18n:unknown", pluginI18n))
+}
+
+func TestSetFallbackLang(t *testing.T) {
+	m := newManager(testEnUsJson)
+	ctx := context.Background()
+
+	assert.Error(t, m.SetFallbackLang(ctx, "xx_XX"))
+	assert.Nil(t, m.fallbackLang)
+
+	assert.NoError(t, m.SetFallbackLang(ctx, LangCodeZhCn))
+	if assert.NotNil(t, m.fallbackLang) {
+		assert.Equal(t, LangCodeZhCn, m.fallbackLang.code)
+	}
+
+	assert.NoError(t, m.SetFallbackLang(ctx, LangCodeEnUs))
+	assert.Nil(t, m.fallbackLang)
+}
+
+func TestSwitchLangKeepsCurrentLangWhenSaveFails(t *testing.T) {
+	m := newManager(testEnUsJson)
+	ctx := context.Background()
+
+	err := m.SwitchLang(ctx, LangCodeZhCn, func() error {
+		return errors.New("database is locked")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, LangCodeEnUs, m.GetCurrentLangCode())
+	assert.Equal(t, "Hello", m.TranslateWox(ctx, "greeting"))
+}
+
+func TestSwitchLangSavesBeforeSwitching(t *testing.T) {
+	m := newManager(testEnUsJson)
+	ctx := context.Background()
+
+	saved := false
+	err := m.SwitchLang(ctx, LangCodeZhCn, func() error {
+		// the previous language must still be active while saving
+		assert.Equal(t, LangCodeEnUs, m.GetCurrentLangCode())
+		saved = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, saved)
+	assert.Equal(t, LangCodeZhCn, m.GetCurrentLangCode())
+}
+
+func TestSwitchLangRejectsUnsupportedLangWithoutSaving(t *testing.T) {
+	m := newManager(testEnUsJson)
+
+	err := m.SwitchLang(context.Background(), LangCode("xx_XX"), func() error {
+		t.Fatal("save must not run for an unsupported language")
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, LangCodeEnUs, m.GetCurrentLangCode())
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"syscall"
 	"wox/ai"
 	"wox/analytics"
 	"wox/database"
@@ -26,8 +29,11 @@ import (
 	"wox/util/clipboard"
 	"wox/util/imagecache"
 	"wox/util/mainthread"
+	"wox/util/notifier"
 	"wox/util/selection"
 
+	"gorm.io/gorm"
+
 	_ "wox/plugin/host"
 
 	// import all hosts
@@ -187,10 +193,23 @@ func run() {
 	}
 
 	diagnostic.GetManager().RecordRunStart(ctx, diagnostic.GetManager().IsChildArg(os.Args))
+	watchShutdownSignals(ctx)
 
 	util.GetLogger().Info(ctx, "no existing instance found, proceeding with full startup")
 
-	if err := database.Init(ctx); err != nil {
+	// A read-only user data directory would fail every write below, so wox.db
+	// is opened read-only and the setting manager keeps changes in memory.
+	dataReadOnly := false
+	if probeErr := setting.ProbeDataDirectoryWritable(); probeErr != nil {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("user data directory is not writable, starting read-only: %s", probeErr.Error()))
+		dataReadOnly = true
+	}
+	database.SetBackupFileReader(setting.ReadBackupFile)
+	initDatabase := database.Init
+	if dataReadOnly {
+		initDatabase = database.InitReadOnly
+	}
+	if err := initDatabase(ctx); err != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to initialize database: %s", err.Error()))
 		return
 	}
@@ -199,11 +218,21 @@ func run() {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to initialize analytics: %s", err.Error()))
 	}
 
-	if err := migration.Run(ctx); err != nil {
-		util.GetLogger().Error(ctx, fmt.Sprintf("failed to run migration: %s", err.Error()))
-		// In some cases, we might want to exit if migration fails, but for now we just log it.
+	if dataReadOnly {
+		util.GetLogger().Info(ctx, "user data directory is read-only, skip migrations")
+	} else if !runStartupMigrations(ctx) {
+		// ExitApp is quitting, stop here instead of starting on a partly migrated database.
+		util.GetLogger().Info(ctx, "migration interrupted by shutdown, skip the rest of startup")
+		return
+	} else {
+		runLegacySettingsImport(ctx)
 	}
 
+	setting.GetSettingManager().SetBackupMigrator(func(ctx context.Context, db *gorm.DB) error {
+		_, err := migration.RunWithDB(ctx, db)
+		return err
+	})
+
 	serverPort, serverPortErr := resolveServerPort(ctx)
 	if serverPortErr != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to get server port: %s", serverPortErr.Error()))
@@ -221,7 +250,10 @@ func run() {
 	extractErr := resource.Extract(ctx)
 	if extractErr != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to extract embed file: %s", extractErr.Error()))
-		return
+		// A read-only install keeps using the files extracted by an earlier start.
+		if !dataReadOnly {
+			return
+		}
 	}
 
 	settingErr := setting.GetSettingManager().Init(ctx)
@@ -262,6 +294,21 @@ func run() {
 		}
 	}
 
+	if _, mismatch := setting.GetSettingManager().TakeAutostartMismatch(); mismatch {
+		ui.GetUIManager().SetStartupNotify(common.NotifyMsg{
+			Text:           i18n.GetI18nManager().TranslateWox(ctx, "ui_autostart_mismatch_notify"),
+			DisplaySeconds: 8,
+		})
+	}
+
+	// Set last so it wins over the other startup notifications.
+	if setting.GetSettingManager().IsReadOnly() {
+		ui.GetUIManager().SetStartupNotify(common.NotifyMsg{
+			Text:           i18n.GetI18nManager().TranslateWox(ctx, "ui_setting_read_only_notify"),
+			DisplaySeconds: 10,
+		})
+	}
+
 	themeErr := ui.GetUIManager().Start(ctx)
 	if themeErr != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to initialize themes: %s", themeErr.Error()))
@@ -301,9 +348,9 @@ func run() {
 	}
 
 	// Platform-specific keyboard implementations handle their own main-thread dispatch.
-	registerMainHotkeyErr := ui.GetUIManager().RegisterMainHotkey(ctx, woxSetting.MainHotkey.Get())
+	registerMainHotkeyErr := ui.GetUIManager().RegisterMainHotkeys(ctx, woxSetting.GetMainHotkeys())
 	if registerMainHotkeyErr != nil {
-		util.GetLogger().Error(ctx, fmt.Sprintf("failed to register main hotkey: %s", registerMainHotkeyErr.Error()))
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to register main hotkeys: %s", registerMainHotkeyErr.Error()))
 	}
 	registerSelectionHotkeyErr := ui.GetUIManager().RegisterSelectionHotkey(ctx, woxSetting.SelectionHotkey.Get())
 	if registerSelectionHotkeyErr != nil {
@@ -330,6 +377,102 @@ func run() {
 	ui.GetUIManager().StartWebsocketAndWait(ctx)
 }
 
+// runStartupMigrations applies pending migrations and schedules the cleanup
+// of old migration backups. It returns false when the run was interrupted
+// by a shutdown.
+func runStartupMigrations(ctx context.Context) bool {
+	migrationCtx, migrationDone := util.WithShutdown(ctx)
+	reportMigrationProgress, finishMigrationProgress := newMigrationProgressReporter()
+	migrationResult, migrationErr := migration.Run(migration.WithProgress(migrationCtx, reportMigrationProgress))
+	finishMigrationProgress()
+	migrationDone()
+	if migrationCtx.Err() != nil {
+		return false
+	}
+	if migrationErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to run migration: %s", migrationErr.Error()))
+		// In some cases, we might want to exit if migration fails, but for now we just log it.
+	} else {
+		if len(migrationResult.Applied) > 0 || len(migrationResult.Warnings) > 0 {
+			util.GetLogger().Info(ctx, fmt.Sprintf("migration finished: applied=%d, skipped=%d, warnings=%d", len(migrationResult.Applied), len(migrationResult.Skipped), len(migrationResult.Warnings)))
+		}
+		util.Go(ctx, "cleanup migration backups", func() {
+			cleanupCtx, cleanupDone := util.WithShutdown(ctx)
+			defer cleanupDone()
+			if _, cleanupErr := migration.CleanupBackups(cleanupCtx, migration.DefaultBackupRetentionDays); cleanupErr != nil {
+				util.GetLogger().Warn(ctx, fmt.Sprintf("failed to clean up migration backups: %s", cleanupErr.Error()))
+			}
+		})
+	}
+	return true
+}
+
+// runLegacySettingsImport imports the legacy settings directory named by
+// migration.LegacyImportDirEnv. It runs after the migrations and before the
+// setting manager loads, see migration.RunFrom.
+func runLegacySettingsImport(ctx context.Context) {
+	legacyDir := strings.TrimSpace(os.Getenv(migration.LegacyImportDirEnv))
+	if legacyDir == "" {
+		return
+	}
+
+	importCtx, importDone := util.WithShutdown(ctx)
+	defer importDone()
+	result, err := migration.RunFrom(importCtx, filepath.Join(legacyDir, "wox.json"), filepath.Join(legacyDir, "wox.data.json"), legacyDir)
+	if err != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to import legacy settings from %s: %s", legacyDir, err.Error()))
+		return
+	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("imported legacy settings from %s: settings=%d, query histories=%d, favorites=%d, plugins=%d, warnings=%d", legacyDir, result.WoxSettings, result.QueryHistories, result.Favorites, len(result.PluginSettings), len(result.Warnings)))
+}
+
+// migrationProgressDelay keeps the progress notification of fast startup
+// migrations, the common case, from flashing on screen.
+const migrationProgressDelay = time.Second
+
+// newMigrationProgressReporter returns a migration.ProgressFunc that shows
+// startup migrations taking longer than migrationProgressDelay as a progress
+// notification, and a func that completes the notification once Run returned.
+// Translations are not extracted before migrations run, so the text is English.
+func newMigrationProgressReporter() (migration.ProgressFunc, func()) {
+	const progressId = "startup_migration"
+	start := time.Now()
+	shown := false
+	report := func(phase string, done, total int) {
+		if total == 0 || time.Since(start) < migrationProgressDelay {
+			return
+		}
+		shown = true
+		// A long migration reports its own items, so the notification moves
+		// while it runs instead of only between migrations.
+		title := "Upgrading Wox data"
+		if phase != migration.PhaseMigrations {
+			title = fmt.Sprintf("Upgrading Wox data: %s", phase)
+		}
+		notifier.ShowProgress(progressId, title, done*100/total)
+	}
+	finish := func() {
+		if shown {
+			notifier.CompleteProgress(progressId, "Wox data upgraded")
+		}
+	}
+	return report, finish
+}
+
+// watchShutdownSignals quits Wox through ExitApp on an interrupt or
+// termination signal, so running migrations stop at a safe point and app data
+// is flushed. A second signal kills the process as before.
+func watchShutdownSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	util.Go(ctx, "watch shutdown signals", func() {
+		received := <-signals
+		signal.Stop(signals)
+		util.GetLogger().Info(ctx, fmt.Sprintf("received %s, quitting", received.String()))
+		ui.GetUIManager().ExitApp(ctx)
+	})
+}
+
 func resolveServerPort(ctx context.Context) (int, error) {
 	if util.IsProd() {
 		return util.GetAvailableTcpPort(ctx)
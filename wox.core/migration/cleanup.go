@@ -0,0 +1,129 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// PhaseCleanupBackups is the progress phase that counts removed backup files.
+const PhaseCleanupBackups = "cleanup_backups"
+
+// DefaultBackupRetentionDays is how long migration backups are kept before
+// the startup cleanup removes them.
+const DefaultBackupRetentionDays = 30
+
+// ErrMigrationIncomplete is returned by CleanupBackups while a registered
+// migration has not been applied or skipped, since its backups may still be
+// the only copy of the data it failed to migrate.
+var ErrMigrationIncomplete = errors.New("migration is not complete")
+
+// CleanupBackups removes the legacy .json.bak copies older than olderThanDays
+// that the archive migration could not move. The pre-migration-backup-*.zip
+// archives are never removed, since they hold the only copy of the legacy
+// files. Every removal is logged and reported as PhaseCleanupBackups progress;
+// the number of removed files is returned.
+func CleanupBackups(ctx context.Context, olderThanDays int) (int, error) {
+	db := database.GetDB()
+	if db == nil {
+		return 0, fmt.Errorf("migration: database not initialized")
+	}
+	return cleanupBackupsWithDB(ctx, db, DefaultLegacyPaths(), olderThanDays, time.Now())
+}
+
+func cleanupBackupsWithDB(ctx context.Context, db *gorm.DB, paths LegacyPaths, olderThanDays int, now time.Time) (int, error) {
+	if olderThanDays < 0 {
+		return 0, fmt.Errorf("migration: invalid backup age %d days", olderThanDays)
+	}
+	if err := checkMigrationComplete(db); err != nil {
+		return 0, err
+	}
+
+	files, err := findMigrationBackupFiles(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := now.Add(-time.Duration(olderThanDays) * 24 * time.Hour)
+	var expired []string
+	for _, file := range files {
+		info, statErr := os.Stat(file)
+		if statErr != nil || !info.Mode().IsRegular() || info.ModTime().After(cutoff) {
+			continue
+		}
+		expired = append(expired, file)
+	}
+
+	removed := 0
+	ReportProgress(ctx, PhaseCleanupBackups, 0, len(expired))
+	for index, file := range expired {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		if removeErr := os.Remove(file); removeErr != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to remove migration backup %s: %s", file, removeErr.Error()))
+		} else {
+			removed++
+			util.GetLogger().Info(ctx, fmt.Sprintf("removed migration backup %s", file))
+		}
+		ReportProgress(ctx, PhaseCleanupBackups, index+1, len(expired))
+	}
+
+	if removed > 0 {
+		util.GetLogger().Info(ctx, fmt.Sprintf("removed %d migration backups older than %d days", removed, olderThanDays))
+	}
+	return removed, nil
+}
+
+// checkMigrationComplete returns ErrMigrationIncomplete unless every
+// registered migration has a record. Records are only written after Up and
+// Verify succeeded in the same transaction.
+func checkMigrationComplete(db *gorm.DB) error {
+	var records []database.MigrationRecord
+	if err := db.Find(&records).Error; err != nil {
+		return fmt.Errorf("migration: failed to load migration records: %w", err)
+	}
+	recorded := map[string]bool{}
+	for _, record := range records {
+		recorded[record.ID] = true
+	}
+
+	for _, m := range registeredMigrations {
+		if !recorded[m.ID()] {
+			return fmt.Errorf("%w: %s has not run yet", ErrMigrationIncomplete, m.ID())
+		}
+	}
+	return nil
+}
+
+// findMigrationBackupFiles lists the files CleanupBackups may remove: .bak
+// copies of legacy JSON setting files. Live setting files, plugin databases and
+// migration archives never match.
+func findMigrationBackupFiles(paths LegacyPaths) ([]string, error) {
+	var files []string
+
+	entries, err := os.ReadDir(paths.PluginDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read setting directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".json.bak") {
+			files = append(files, filepath.Join(paths.PluginDir, entry.Name()))
+		}
+	}
+
+	for _, file := range []string{paths.SettingPath, paths.AppDataPath} {
+		if file != "" && filepath.Dir(file) != filepath.Clean(paths.PluginDir) {
+			files = append(files, file+".bak")
+		}
+	}
+	return files, nil
+}
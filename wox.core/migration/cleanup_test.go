@@ -0,0 +1,82 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wox/database"
+)
+
+func TestCleanupBackupsRemovesOnlyExpiredBakFiles(t *testing.T) {
+	db := newMigrationTestDB(t, &database.MigrationRecord{})
+	originalMigrations := registeredMigrations
+	registeredMigrations = nil
+	t.Cleanup(func() { registeredMigrations = originalMigrations })
+
+	pluginDir := t.TempDir()
+	written := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, modTime := range map[string]time.Time{
+		"expired-plugin.json.bak":       written,
+		"recent-plugin.json.bak":        written.Add(20 * 24 * time.Hour),
+		"expired-plugin.json":           written,
+		"pre-migration-backup-1.zip":    written,
+		"expired-plugin_clipboard.db":   written,
+		"expired-plugin.json.bak.other": written,
+	} {
+		path := filepath.Join(pluginDir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("failed to set time of %s: %v", name, err)
+		}
+	}
+
+	now := written.Add(DefaultBackupRetentionDays*24*time.Hour + time.Hour)
+	removed, err := cleanupBackupsWithDB(context.Background(), db, LegacyPaths{PluginDir: pluginDir}, DefaultBackupRetentionDays, now)
+	if err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 removed backup, got %d", removed)
+	}
+
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		t.Fatalf("failed to read plugin dir: %v", err)
+	}
+	kept := map[string]bool{}
+	for _, entry := range entries {
+		kept[entry.Name()] = true
+	}
+	if kept["expired-plugin.json.bak"] || len(kept) != 5 {
+		t.Fatalf("expected only the expired .bak file to be removed, kept %v", kept)
+	}
+	if !kept["pre-migration-backup-1.zip"] {
+		t.Fatalf("expected the migration archive to be kept")
+	}
+}
+
+func TestCleanupBackupsRefusesIncompleteMigration(t *testing.T) {
+	db := newMigrationTestDB(t, &database.MigrationRecord{})
+	originalMigrations := registeredMigrations
+	registeredMigrations = []Migration{&failingAfterCommitMigration{}}
+	t.Cleanup(func() { registeredMigrations = originalMigrations })
+
+	pluginDir := t.TempDir()
+	backup := filepath.Join(pluginDir, "plugin.json.bak")
+	if err := os.WriteFile(backup, []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	now := time.Now().Add(365 * 24 * time.Hour)
+	if _, err := cleanupBackupsWithDB(context.Background(), db, LegacyPaths{PluginDir: pluginDir}, 0, now); !errors.Is(err, ErrMigrationIncomplete) {
+		t.Fatalf("expected ErrMigrationIncomplete, got %v", err)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Fatalf("expected the backup to be kept, got %v", err)
+	}
+}
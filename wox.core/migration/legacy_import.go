@@ -0,0 +1,432 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"wox/common"
+	"wox/database"
+	"wox/setting"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// legacyImportID names the legacy import in verification errors and progress.
+const legacyImportID = "legacy_import"
+
+// LegacyImportDirEnv names a legacy settings directory, the one holding
+// wox.json, wox.data.json and the <pluginId>.json files, that is imported with
+// RunFrom at startup, e.g. a config directory copied from another machine.
+// The files are imported on every start while it is set.
+const LegacyImportDirEnv = "WOX_IMPORT_LEGACY_SETTINGS_DIR"
+
+// LegacyImportResult counts what RunFrom imported from legacy setting files.
+type LegacyImportResult struct {
+	WoxSettings    int            // values from wox.json and wox.data.json stored in wox_settings
+	QueryHistories int            // queries added to query_history
+	Favorites      int            // favorite results from wox.data.json
+	PluginSettings map[string]int // stored settings keyed by plugin id
+	Warnings       []string       // files or values that were skipped
+}
+
+// legacyPluginSettingFile is the shape of a legacy <pluginId>.json file.
+// Settings is required, it tells plugin settings apart from other JSON data
+// a plugin may keep in the same directory.
+type legacyPluginSettingFile struct {
+	Settings        map[string]json.RawMessage
+	Disabled        json.RawMessage
+	TriggerKeywords json.RawMessage
+}
+
+// legacyPlatformValueKeys are the fields of the legacy per-platform value
+// shape, e.g. {"WinValue": "alt+space", "MacValue": "cmd+space"}.
+var legacyPlatformValueKeys = map[string]util.Platform{
+	"WinValue":   util.PlatformWindows,
+	"MacValue":   util.PlatformMacOS,
+	"LinuxValue": util.PlatformLinux,
+}
+
+// legacySettingSource holds everything read from the legacy files, in the
+// form it is stored in wox.db.
+type legacySettingSource struct {
+	woxValues      map[string]string
+	queryHistories []legacyQueryHistory
+	favorites      int
+	pluginValues   map[string]map[string]string
+}
+
+// RunFrom imports the legacy JSON setting files at the given locations into
+// wox.db, e.g. a config directory copied from another machine or an old
+// install that used a non-standard path. Empty arguments fall back to the
+// default location. Values in the files replace the stored ones, query
+// histories are added, and the files are left untouched.
+//
+// Unlike Run, RunFrom does not use migration records: every call imports the
+// files again. It must be called after Run and before settings are loaded,
+// because loaded settings keep their cached values. Startup calls it when
+// LegacyImportDirEnv is set.
+func RunFrom(ctx context.Context, oldSettingPath, oldAppDataPath, oldPluginDir string) (LegacyImportResult, error) {
+	db := database.GetDB()
+	if db == nil {
+		return LegacyImportResult{}, fmt.Errorf("migration: database not initialized")
+	}
+
+	paths := DefaultLegacyPaths()
+	if oldSettingPath != "" {
+		paths.SettingPath = oldSettingPath
+	}
+	if oldAppDataPath != "" {
+		paths.AppDataPath = oldAppDataPath
+	}
+	if oldPluginDir != "" {
+		paths.PluginDir = oldPluginDir
+	}
+	return importLegacySettings(ctx, db, paths)
+}
+
+// importLegacySettings reads the files at paths and writes them in one
+// transaction, which is rolled back when the written data does not read back
+// like the source.
+func importLegacySettings(ctx context.Context, db *gorm.DB, paths LegacyPaths) (LegacyImportResult, error) {
+	result := LegacyImportResult{PluginSettings: map[string]int{}}
+	ctx = context.WithValue(ctx, warningsContextKey{}, &result.Warnings)
+
+	source, err := readLegacySettingFiles(ctx, paths)
+	if err != nil {
+		return result, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		woxStore := setting.NewWoxSettingStore(tx)
+		for _, key := range sortedKeys(source.woxValues) {
+			if err := woxStore.Set(key, source.woxValues[key]); err != nil {
+				return fmt.Errorf("failed to import setting %s: %w", key, err)
+			}
+		}
+
+		added, err := importLegacyQueryHistories(ctx, tx, source.queryHistories)
+		if err != nil {
+			return err
+		}
+
+		for _, pluginId := range sortedKeys(source.pluginValues) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			pluginStore := setting.NewPluginSettingStore(tx, pluginId)
+			for _, key := range sortedKeys(source.pluginValues[pluginId]) {
+				if err := pluginStore.Set(key, source.pluginValues[pluginId][key]); err != nil {
+					return fmt.Errorf("failed to import setting %s of plugin %s: %w", key, pluginId, err)
+				}
+			}
+		}
+
+		if err := verifyLegacyImport(tx, source); err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("!!! imported legacy settings do not match their source, rolling back: %s", err.Error()))
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result.WoxSettings = len(source.woxValues)
+		result.QueryHistories = added
+		result.Favorites = source.favorites
+		for pluginId, values := range source.pluginValues {
+			result.PluginSettings[pluginId] = len(values)
+		}
+		return nil
+	})
+	if err != nil {
+		return LegacyImportResult{Warnings: result.Warnings}, fmt.Errorf("migration: legacy import failed: %w", err)
+	}
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("imported legacy settings: %d settings, %d query histories, %d favorites, %d plugins", result.WoxSettings, result.QueryHistories, result.Favorites, len(result.PluginSettings)))
+	return result, nil
+}
+
+// readLegacySettingFiles reads wox.json, wox.data.json and the plugin setting
+// files at paths. Missing files are skipped, unreadable ones are reported
+// with Warn and skipped as well.
+func readLegacySettingFiles(ctx context.Context, paths LegacyPaths) (legacySettingSource, error) {
+	source := legacySettingSource{
+		woxValues:    map[string]string{},
+		pluginValues: map[string]map[string]string{},
+	}
+
+	if fields, ok := readLegacyJSONObject(ctx, paths.SettingPath); ok {
+		for name, raw := range fields {
+			if name == setting.LastModifiedKey {
+				continue
+			}
+			addLegacyWoxValue(source.woxValues, name, raw)
+		}
+	}
+
+	if fields, ok := readLegacyJSONObject(ctx, paths.AppDataPath); ok {
+		for name, raw := range fields {
+			switch name {
+			case setting.LegacyQueryHistoriesKey:
+				if err := json.Unmarshal(raw, &source.queryHistories); err != nil {
+					Warn(ctx, fmt.Sprintf("failed to decode legacy query histories in %s, skipping them: %v", paths.AppDataPath, err))
+				}
+			case "FavoriteResults", "PinedResults":
+				// Older versions called the favorites FavoriteResults.
+				var favorites map[string]bool
+				if err := json.Unmarshal(raw, &favorites); err != nil {
+					Warn(ctx, fmt.Sprintf("failed to decode legacy favorites in %s, skipping them: %v", paths.AppDataPath, err))
+					continue
+				}
+				source.favorites = countFavorites(favorites)
+				addLegacyWoxValue(source.woxValues, "PinedResults", raw)
+			default:
+				addLegacyWoxValue(source.woxValues, name, raw)
+			}
+		}
+	}
+
+	if paths.PluginDir == "" {
+		return source, nil
+	}
+	entries, err := os.ReadDir(paths.PluginDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return source, nil
+		}
+		return source, fmt.Errorf("failed to read legacy plugin setting directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || filepath.Ext(name) != ".json" || name == "wox.json" || name == "wox.data.json" {
+			continue
+		}
+		values, ok := readLegacyPluginSettingFile(ctx, filepath.Join(paths.PluginDir, name))
+		if ok && len(values) > 0 {
+			source.pluginValues[strings.TrimSuffix(name, ".json")] = values
+		}
+	}
+	return source, nil
+}
+
+// readLegacyJSONObject decodes the JSON object in file. ok is false when the
+// file does not exist or cannot be decoded.
+func readLegacyJSONObject(ctx context.Context, file string) (map[string]json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if !readLegacyJSONFile(ctx, file, &fields) {
+		return nil, false
+	}
+	return fields, true
+}
+
+// readLegacyJSONFile decodes file into target. Missing files are skipped
+// silently, unreadable ones with a warning.
+func readLegacyJSONFile(ctx context.Context, file string, target any) bool {
+	if file == "" {
+		return false
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			Warn(ctx, fmt.Sprintf("failed to read legacy setting file %s: %v", file, err))
+		}
+		return false
+	}
+	if err := json.Unmarshal(content, target); err != nil {
+		Warn(ctx, fmt.Sprintf("failed to decode legacy setting file %s, skipping it: %v", file, err))
+		return false
+	}
+	return true
+}
+
+// readLegacyPluginSettingFile returns the stored values of a legacy plugin
+// setting file. ok is false for files that are not plugin settings.
+func readLegacyPluginSettingFile(ctx context.Context, file string) (map[string]string, bool) {
+	var legacy legacyPluginSettingFile
+	if !readLegacyJSONFile(ctx, file, &legacy) || legacy.Settings == nil {
+		return nil, false
+	}
+
+	values := map[string]string{}
+	for key, raw := range legacy.Settings {
+		if value, ok := legacyStoredValue(raw); ok {
+			values[key] = value
+		}
+	}
+	if value, ok := legacyStoredValue(legacy.Disabled); ok {
+		values["Disabled"] = value
+	}
+	if value, ok := legacyStoredValue(legacy.TriggerKeywords); ok {
+		values["TriggerKeywords"] = value
+	}
+	return values, true
+}
+
+// addLegacyWoxValue adds a wox.json field to values. Per-platform values are
+// split into one key per platform, like m20260617_split_platform_wox_settings does.
+func addLegacyWoxValue(values map[string]string, name string, raw json.RawMessage) {
+	var platformFields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &platformFields); err == nil && isLegacyPlatformValue(platformFields) {
+		for field, fieldRaw := range platformFields {
+			if value, ok := legacyStoredValue(fieldRaw); ok {
+				values[setting.PlatformSettingKey(name, string(legacyPlatformValueKeys[field]))] = value
+			}
+		}
+		return
+	}
+
+	if value, ok := legacyStoredValue(raw); ok {
+		values[name] = value
+	}
+}
+
+func isLegacyPlatformValue(fields map[string]json.RawMessage) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	for field := range fields {
+		if _, ok := legacyPlatformValueKeys[field]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// legacyStoredValue converts a JSON value to its stored form, see
+// setting.SerializeValue: strings are stored without quotes, everything else
+// as compact JSON. ok is false for missing and null values.
+func legacyStoredValue(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", false
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, true
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, raw); err != nil {
+		return string(raw), true
+	}
+	return compacted.String(), true
+}
+
+func countFavorites(favorites map[string]bool) int {
+	count := 0
+	for _, isFavorite := range favorites {
+		if isFavorite {
+			count++
+		}
+	}
+	return count
+}
+
+// importLegacyQueryHistories adds the histories whose query is not in
+// query_history yet and returns how many were added. Existing rows are newer
+// than the legacy entries and win, like in m20261016_query_history_table.
+func importLegacyQueryHistories(ctx context.Context, tx *gorm.DB, histories []legacyQueryHistory) (int, error) {
+	added := 0
+	for index, history := range histories {
+		if err := ctx.Err(); err != nil {
+			return added, err
+		}
+		if index%100 == 0 {
+			ReportProgress(ctx, legacyImportID, index, len(histories))
+		}
+
+		var query common.PlainQuery
+		if err := json.Unmarshal(history.Query, &query); err != nil || query.IsEmpty() {
+			continue
+		}
+		var existing int64
+		if err := tx.Model(&database.QueryHistoryRecord{}).Where("plugin_id = ? AND query = ?", "", query.String()).Count(&existing).Error; err != nil {
+			return added, err
+		}
+		if existing > 0 {
+			continue
+		}
+		if err := tx.Create(&database.QueryHistoryRecord{
+			Query:      query.String(),
+			PlainQuery: string(history.Query),
+			Timestamp:  history.Timestamp,
+		}).Error; err != nil {
+			return added, err
+		}
+		added++
+	}
+	ReportProgress(ctx, legacyImportID, len(histories), len(histories))
+	return added, nil
+}
+
+// verifyLegacyImport reads back what importLegacySettings wrote and compares
+// it with the source: every setting of each plugin, the number of favorites
+// and the presence of every query history.
+func verifyLegacyImport(tx *gorm.DB, source legacySettingSource) error {
+	for _, pluginId := range sortedKeys(source.pluginValues) {
+		expected := source.pluginValues[pluginId]
+		store := setting.NewPluginSettingStore(tx, pluginId)
+		matched := 0
+		for key, value := range expected {
+			var stored string
+			if err := store.Get(key, &stored); err == nil && stored == value {
+				matched++
+			}
+		}
+		if matched != len(expected) {
+			return &MigrationVerificationError{MigrationID: legacyImportID, Item: fmt.Sprintf("settings of plugin %s", pluginId), Expected: len(expected), Actual: matched}
+		}
+	}
+
+	if _, hasFavorites := source.woxValues["PinedResults"]; hasFavorites {
+		favorites := util.NewHashMap[setting.ResultHash, bool]()
+		if err := setting.NewWoxSettingStore(tx).Get("PinedResults", favorites); err != nil {
+			return fmt.Errorf("failed to verify imported favorites: %w", err)
+		}
+		stored := 0
+		favorites.Range(func(_ setting.ResultHash, isFavorite bool) bool {
+			if isFavorite {
+				stored++
+			}
+			return true
+		})
+		if stored != source.favorites {
+			return &MigrationVerificationError{MigrationID: legacyImportID, Item: "favorites", Expected: source.favorites, Actual: stored}
+		}
+	}
+
+	expectedQueries := map[string]bool{}
+	for _, history := range source.queryHistories {
+		var query common.PlainQuery
+		if err := json.Unmarshal(history.Query, &query); err == nil && !query.IsEmpty() {
+			expectedQueries[query.String()] = true
+		}
+	}
+	if len(expectedQueries) > 0 {
+		queries := make([]string, 0, len(expectedQueries))
+		for query := range expectedQueries {
+			queries = append(queries, query)
+		}
+		var stored int64
+		if err := tx.Model(&database.QueryHistoryRecord{}).Where("plugin_id = ? AND query IN ?", "", queries).Count(&stored).Error; err != nil {
+			return fmt.Errorf("failed to verify imported query histories: %w", err)
+		}
+		if int(stored) != len(expectedQueries) {
+			return &MigrationVerificationError{MigrationID: legacyImportID, Item: "query histories", Expected: len(expectedQueries), Actual: int(stored)}
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,166 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/database"
+	"wox/setting"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// openLegacyImportTestDB returns a database with the tables the legacy import writes.
+func openLegacyImportTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return newMigrationTestDB(t, &database.WoxSetting{}, &database.PluginSetting{}, &database.Oplog{}, &database.QueryHistoryRecord{}, &database.MigrationRecord{})
+}
+
+// legacyFixturePaths points at the legacy config directory in testdata.
+func legacyFixturePaths() LegacyPaths {
+	dir := filepath.Join("testdata", "legacy")
+	return LegacyPaths{
+		SettingPath: filepath.Join(dir, "wox.json"),
+		AppDataPath: filepath.Join(dir, "wox.data.json"),
+		PluginDir:   filepath.Join(dir, "plugins"),
+	}
+}
+
+// readFixtureFiles returns the content of every file under dir, keyed by path.
+func readFixtureFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		files[path] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read fixture files: %v", err)
+	}
+	return files
+}
+
+func TestImportLegacySettingsFromFixtureDirectory(t *testing.T) {
+	db := openLegacyImportTestDB(t)
+	paths := legacyFixturePaths()
+	filesBefore := readFixtureFiles(t, filepath.Join("testdata", "legacy"))
+
+	result, err := importLegacySettings(context.Background(), db, paths)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if result.QueryHistories != 2 || result.Favorites != 2 || result.PluginSettings["calculator"] != 4 || len(result.PluginSettings) != 1 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+
+	woxStore := setting.NewWoxSettingStore(db)
+	expectedSettings := map[string]string{
+		"MainHotkey@windows": "alt+space",
+		"MainHotkey@darwin":  "cmd+space",
+		"MainHotkey@linux":   "ctrl+shift+space",
+		"LangCode":           "en_US",
+		"MaxResultCount":     "10",
+		"HideOnLostFocus":    "true",
+	}
+	for key, expected := range expectedSettings {
+		var stored string
+		if err := woxStore.Get(key, &stored); err != nil || stored != expected {
+			t.Fatalf("expected %s to be %q, got %q (%v)", key, expected, stored, err)
+		}
+	}
+	var lastModified string
+	if err := woxStore.Get(setting.LastModifiedKey, &lastModified); err == nil && lastModified == "123" {
+		t.Fatalf("expected LastModified not to be imported from wox.json")
+	}
+
+	favorites := util.NewHashMap[setting.ResultHash, bool]()
+	if err := woxStore.Get("PinedResults", favorites); err != nil {
+		t.Fatalf("failed to read imported favorites: %v", err)
+	}
+	if pinned, _ := favorites.Load("hash-a"); !pinned {
+		t.Fatalf("expected hash-a to be imported as a favorite")
+	}
+
+	pluginStore := setting.NewPluginSettingStore(db, "calculator")
+	expectedPluginSettings := map[string]string{
+		"Precision":       "4",
+		"ShowHistory":     "true",
+		"Disabled":        "false",
+		"TriggerKeywords": `["calc"]`,
+	}
+	for key, expected := range expectedPluginSettings {
+		var stored string
+		if err := pluginStore.Get(key, &stored); err != nil || stored != expected {
+			t.Fatalf("expected plugin setting %s to be %q, got %q (%v)", key, expected, stored, err)
+		}
+	}
+	var dataRows int64
+	if err := db.Model(&database.PluginSetting{}).Where("plugin_id = ?", "notes_data").Count(&dataRows).Error; err != nil || dataRows != 0 {
+		t.Fatalf("expected plugin data JSON without Settings to be skipped, got %d rows (%v)", dataRows, err)
+	}
+
+	var histories int64
+	if err := db.Model(&database.QueryHistoryRecord{}).Count(&histories).Error; err != nil || histories != 2 {
+		t.Fatalf("expected 2 imported query histories, got %d (%v)", histories, err)
+	}
+
+	var records int64
+	if err := db.Model(&database.MigrationRecord{}).Count(&records).Error; err != nil || records != 0 {
+		t.Fatalf("expected the import not to touch migration records, got %d (%v)", records, err)
+	}
+
+	filesAfter := readFixtureFiles(t, filepath.Join("testdata", "legacy"))
+	if len(filesAfter) != len(filesBefore) {
+		t.Fatalf("expected %d fixture files to be kept, found %d", len(filesBefore), len(filesAfter))
+	}
+	for path, content := range filesBefore {
+		if filesAfter[path] != content {
+			t.Fatalf("expected %s to be left untouched", path)
+		}
+	}
+
+	// Importing again rewrites the same values and adds no duplicate histories.
+	again, err := importLegacySettings(context.Background(), db, paths)
+	if err != nil {
+		t.Fatalf("second import failed: %v", err)
+	}
+	if again.QueryHistories != 0 {
+		t.Fatalf("expected no new query histories on the second import, got %d", again.QueryHistories)
+	}
+}
+
+func TestVerifyLegacyImportDetectsMismatches(t *testing.T) {
+	db := openLegacyImportTestDB(t)
+	ctx := context.Background()
+	if _, err := importLegacySettings(ctx, db, legacyFixturePaths()); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	source, err := readLegacySettingFiles(ctx, legacyFixturePaths())
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if err := verifyLegacyImport(db, source); err != nil {
+		t.Fatalf("expected the imported data to verify, got %v", err)
+	}
+
+	var verificationErr *MigrationVerificationError
+
+	source.favorites = 3
+	if err := verifyLegacyImport(db, source); !errors.As(err, &verificationErr) || verificationErr.Item != "favorites" {
+		t.Fatalf("expected a favorites verification error, got %v", err)
+	}
+	source.favorites = 2
+
+	source.pluginValues["calculator"]["Precision"] = "6"
+	if err := verifyLegacyImport(db, source); !errors.As(err, &verificationErr) || verificationErr.Expected != 4 || verificationErr.Actual != 3 {
+		t.Fatalf("expected a plugin settings verification error for 3 of 4 settings, got %v", err)
+	}
+}
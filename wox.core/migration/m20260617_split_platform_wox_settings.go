@@ -33,42 +33,42 @@ func (m *splitPlatformWoxSettingsMigration) Description() string {
 }
 
 func (m *splitPlatformWoxSettingsMigration) Up(ctx context.Context, tx *gorm.DB) error {
-	if err := migratePlatformSetting[string](tx, "MainHotkey"); err != nil {
+	if err := migratePlatformSetting[string](ctx, tx, "MainHotkey"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[string](tx, "SelectionHotkey"); err != nil {
+	if err := migratePlatformSetting[string](ctx, tx, "SelectionHotkey"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[[]setting.IgnoredHotkeyApp](tx, "IgnoredHotkeyApps"); err != nil {
+	if err := migratePlatformSetting[[]setting.IgnoredHotkeyApp](ctx, tx, "IgnoredHotkeyApps"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[[]setting.QueryHotkey](tx, "QueryHotkeys"); err != nil {
+	if err := migratePlatformSetting[[]setting.QueryHotkey](ctx, tx, "QueryHotkeys"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[bool](tx, "EnableAutostart"); err != nil {
+	if err := migratePlatformSetting[bool](ctx, tx, "EnableAutostart"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[bool](tx, "HttpProxyEnabled"); err != nil {
+	if err := migratePlatformSetting[bool](ctx, tx, "HttpProxyEnabled"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[string](tx, "HttpProxyUrl"); err != nil {
+	if err := migratePlatformSetting[string](ctx, tx, "HttpProxyUrl"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[string](tx, "CustomPythonPath"); err != nil {
+	if err := migratePlatformSetting[string](ctx, tx, "CustomPythonPath"); err != nil {
 		return err
 	}
-	if err := migratePlatformSetting[string](tx, "CustomNodejsPath"); err != nil {
+	if err := migratePlatformSetting[string](ctx, tx, "CustomNodejsPath"); err != nil {
 		return err
 	}
-	return migratePlatformSetting[string](tx, "AppFontFamily")
+	return migratePlatformSetting[string](ctx, tx, "AppFontFamily")
 }
 
 // migratePlatformSetting converts one legacy PlatformValue setting and its pending cloud oplogs.
-func migratePlatformSetting[T any](tx *gorm.DB, key string) error {
+func migratePlatformSetting[T any](ctx context.Context, tx *gorm.DB, key string) error {
 	if err := splitLegacyPlatformSettingRow[T](tx, key); err != nil {
 		return err
 	}
-	return convertLegacyPlatformSettingOplogs[T](tx, key)
+	return convertLegacyPlatformSettingOplogs[T](ctx, tx, key)
 }
 
 // splitLegacyPlatformSettingRow expands a base-key JSON row into physical per-platform rows.
@@ -115,7 +115,7 @@ func splitLegacyPlatformSettingRow[T any](tx *gorm.DB, key string) error {
 }
 
 // convertLegacyPlatformSettingOplogs prevents pending base-key oplogs from uploading legacy JSON.
-func convertLegacyPlatformSettingOplogs[T any](tx *gorm.DB, key string) error {
+func convertLegacyPlatformSettingOplogs[T any](ctx context.Context, tx *gorm.DB, key string) error {
 	var rows []database.Oplog
 	if err := tx.Where("entity_type = ? AND key = ? AND synced_to_cloud = ?", cloudsync.EntityWoxSetting, key, false).Find(&rows).Error; err != nil {
 		return err
@@ -127,6 +127,9 @@ func convertLegacyPlatformSettingOplogs[T any](tx *gorm.DB, key string) error {
 	currentPlatform := util.GetCurrentPlatform()
 	targetKey := setting.PlatformSettingKey(key, currentPlatform)
 	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		switch row.Operation {
 		case cloudsync.OpUpsert:
 			var values legacyPlatformValues[T]
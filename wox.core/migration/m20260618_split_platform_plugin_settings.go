@@ -42,7 +42,7 @@ func (m *splitPlatformPluginSettingsMigration) Description() string {
 }
 
 func (m *splitPlatformPluginSettingsMigration) Up(ctx context.Context, tx *gorm.DB) error {
-	for _, target := range []pluginSettingTarget{
+	platformTargets := []pluginSettingTarget{
 		{pluginID: appPluginID, keys: []string{"AppDirectories", "IgnoreRules"}},
 		{pluginID: fileSearchPluginID, keys: []string{"roots", "ignorePatterns"}},
 		{pluginID: explorerPluginID, keys: []string{"enableTypeToSearch", "quickJumpPaths"}},
@@ -50,19 +50,34 @@ func (m *splitPlatformPluginSettingsMigration) Up(ctx context.Context, tx *gorm.
 		{pluginID: folderPluginID, keys: []string{"favorites"}},
 		{pluginID: shellPluginID, keys: []string{"shellCommands"}},
 		{pluginID: browserBookmarkPluginID, keys: []string{"indexBrowsers"}},
-	} {
+	}
+	globalTargets := []pluginSettingTarget{
+		{pluginID: webSearchPluginID, keys: []string{"defaultBrowser", "webSearches"}},
+		{pluginID: webViewPluginID, keys: []string{"sites"}},
+	}
+	total := len(platformTargets) + len(globalTargets)
+	done := 0
+
+	for _, target := range platformTargets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := migratePluginSettingsToCurrentPlatform(tx, target.pluginID, target.keys); err != nil {
 			return err
 		}
+		done++
+		ReportProgress(ctx, m.ID(), done, total)
 	}
 
-	for _, target := range []pluginSettingTarget{
-		{pluginID: webSearchPluginID, keys: []string{"defaultBrowser", "webSearches"}},
-		{pluginID: webViewPluginID, keys: []string{"sites"}},
-	} {
+	for _, target := range globalTargets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := migratePluginSettingsToGlobal(tx, target.pluginID, target.keys); err != nil {
 			return err
 		}
+		done++
+		ReportProgress(ctx, m.ID(), done, total)
 	}
 
 	return nil
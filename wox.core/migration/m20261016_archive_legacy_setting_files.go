@@ -0,0 +1,227 @@
+package migration
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&archiveLegacySettingFilesMigration{})
+}
+
+// archiveLegacySettingFilesMigration collects the JSON setting files left
+// behind by versions that stored settings outside wox.db (wox.json,
+// wox.data.json, one <pluginId>.json per plugin and their .bak copies) into a
+// single zip, so the settings directory only holds live plugin data.
+//
+// Only these known names are archived, and <pluginId>.json only for plugins
+// whose settings are in wox.db, i.e. were imported from it. Any other JSON
+// file, e.g. data a plugin keeps next to its settings, and plugin databases
+// such as <pluginId>_clipboard.db are still in use and are never archived.
+type archiveLegacySettingFilesMigration struct {
+	// files is the list found by Up, archived by AfterCommit.
+	files []string
+}
+
+func (m *archiveLegacySettingFilesMigration) ID() string {
+	return "20261016_archive_legacy_setting_files"
+}
+
+func (m *archiveLegacySettingFilesMigration) Description() string {
+	return "Move legacy JSON setting files and their .bak copies into one pre-migration-backup zip."
+}
+
+func (m *archiveLegacySettingFilesMigration) IsNeeded(ctx context.Context, db *gorm.DB) (bool, error) {
+	files, err := findLegacySettingFilesInDB(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return len(files) > 0, nil
+}
+
+func (m *archiveLegacySettingFilesMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	// The legacy files were imported into wox.db long ago; only the filesystem
+	// cleanup in AfterCommit is left to do. The files are listed here, where
+	// the plugin settings that tell which ones are known can be read.
+	files, err := findLegacySettingFilesInDB(ctx, tx)
+	if err != nil {
+		return err
+	}
+	m.files = files
+	return nil
+}
+
+func (m *archiveLegacySettingFilesMigration) AfterCommit(ctx context.Context) error {
+	files := m.files
+	m.files = nil
+	if len(files) == 0 {
+		return nil
+	}
+
+	archivePath := filepath.Join(util.GetLocation().GetUserDataDirectory(), fmt.Sprintf("pre-migration-backup-%d.zip", util.GetSystemTimestamp()))
+	if err := writeLegacySettingArchive(ctx, archivePath, files); err != nil {
+		_ = os.Remove(archivePath)
+		return err
+	}
+	// The archive becomes the only copy of the files, so read it back before
+	// removing anything.
+	if err := m.verifyArchive(archivePath, files); err != nil {
+		_ = os.Remove(archivePath)
+		return err
+	}
+
+	// Originals are only removed once the archive is complete, so a failed
+	// or interrupted run leaves everything in place.
+	if err := ctx.Err(); err != nil {
+		_ = os.Remove(archivePath)
+		return err
+	}
+	for index, file := range files {
+		if removeErr := os.Remove(file); removeErr != nil {
+			Warn(ctx, fmt.Sprintf("failed to remove archived legacy setting file %s: %s", file, removeErr.Error()))
+		}
+		ReportProgress(ctx, m.ID(), index+1, len(files))
+	}
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("archived %d legacy setting files to %s", len(files), archivePath))
+	return nil
+}
+
+// findLegacySettingFilesInDB is findLegacySettingFiles for the plugins that
+// have settings in db.
+func findLegacySettingFilesInDB(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var pluginIds []string
+	if err := db.Model(&database.PluginSetting{}).Distinct().Pluck("plugin_id", &pluginIds).Error; err != nil {
+		return nil, fmt.Errorf("failed to list plugins with settings: %w", err)
+	}
+	knownPluginIds := make(map[string]bool, len(pluginIds))
+	for _, pluginId := range pluginIds {
+		knownPluginIds[pluginId] = true
+	}
+	return findLegacySettingFiles(DefaultLegacyPaths(), knownPluginIds)
+}
+
+// findLegacySettingFiles lists the legacy files at paths: wox.json,
+// wox.data.json and <pluginId>.json for every id in pluginIds, each with its
+// .bak copy. wox.json and wox.data.json normally live in the plugin
+// directory, but paths may point them somewhere else, so they are checked on
+// their own as well.
+func findLegacySettingFiles(paths LegacyPaths, pluginIds map[string]bool) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	addFile := func(file string) {
+		if cleaned := filepath.Clean(file); !seen[cleaned] {
+			seen[cleaned] = true
+			files = append(files, cleaned)
+		}
+	}
+
+	legacyNames := map[string]bool{"wox.json": true, "wox.data.json": true}
+	for pluginId := range pluginIds {
+		legacyNames[pluginId+".json"] = true
+	}
+
+	entries, err := os.ReadDir(paths.PluginDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read setting directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		name := entry.Name()
+		if legacyNames[name] || legacyNames[strings.TrimSuffix(name, ".bak")] {
+			addFile(filepath.Join(paths.PluginDir, name))
+		}
+	}
+
+	for _, file := range []string{paths.SettingPath, paths.AppDataPath} {
+		if file == "" {
+			continue
+		}
+		for _, candidate := range []string{file, file + ".bak"} {
+			if info, statErr := os.Stat(candidate); statErr == nil && info.Mode().IsRegular() {
+				addFile(candidate)
+			}
+		}
+	}
+	return files, nil
+}
+
+func writeLegacySettingArchive(ctx context.Context, archivePath string, files []string) error {
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer archiveFile.Close()
+
+	zipWriter := zip.NewWriter(archiveFile)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := addFileToZip(zipWriter, file); err != nil {
+			return err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return archiveFile.Sync()
+}
+
+func addFileToZip(zipWriter *zip.Writer, file string) error {
+	source, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer source.Close()
+
+	entry, err := zipWriter.Create(filepath.Base(file))
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", file, err)
+	}
+	if _, err := io.Copy(entry, source); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", file, err)
+	}
+	return nil
+}
+
+// verifyArchive checks that the archive holds every file with its full size.
+func (m *archiveLegacySettingFilesMigration) verifyArchive(archivePath string, files []string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to reopen archive for verification: %w", err)
+	}
+	defer reader.Close()
+
+	archived := map[string]uint64{}
+	for _, entry := range reader.File {
+		archived[entry.Name] = entry.UncompressedSize64
+	}
+
+	matched := 0
+	for _, file := range files {
+		info, statErr := os.Stat(file)
+		if statErr != nil {
+			return fmt.Errorf("failed to stat %s for archive verification: %w", file, statErr)
+		}
+		if size, ok := archived[filepath.Base(file)]; ok && size == uint64(info.Size()) {
+			matched++
+		}
+	}
+	if matched != len(files) {
+		return &MigrationVerificationError{MigrationID: m.ID(), Item: "archived legacy setting files", Expected: len(files), Actual: matched}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindLegacySettingFilesOnlyListsKnownNames(t *testing.T) {
+	pluginDir := t.TempDir()
+	for _, name := range []string{
+		"wox.json",
+		"wox.data.json.bak",
+		"imported-plugin.json",
+		"imported-plugin.json.bak",
+		"unknown-plugin.json",
+		"imported-plugin_cache.json",
+		"imported-plugin_clipboard.db",
+	} {
+		if err := os.WriteFile(filepath.Join(pluginDir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	files, err := findLegacySettingFiles(LegacyPaths{PluginDir: pluginDir}, map[string]bool{"imported-plugin": true})
+	if err != nil {
+		t.Fatalf("findLegacySettingFiles failed: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	slices.Sort(names)
+	expected := []string{"imported-plugin.json", "imported-plugin.json.bak", "wox.data.json.bak", "wox.json"}
+	if !slices.Equal(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,35 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"wox/setting"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&assignAIProviderIdsMigration{})
+}
+
+type assignAIProviderIdsMigration struct{}
+
+func (m *assignAIProviderIdsMigration) ID() string { return "20261016_assign_ai_provider_ids" }
+
+func (m *assignAIProviderIdsMigration) Description() string {
+	return "Assign a stable Id to every configured AI provider so several configs of the same provider can coexist."
+}
+
+func (m *assignAIProviderIdsMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	store := setting.NewWoxSettingStore(tx)
+
+	var providers []setting.AIProvider
+	if err := store.Get("AIProviders", &providers); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return store.Set("AIProviders", setting.AssignAIProviderIds(nil, providers))
+}
@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"wox/database"
+	"wox/setting"
+	"wox/util/fuzzymatch"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&pinYinMatchModeMigration{})
+}
+
+type pinYinMatchModeMigration struct{}
+
+func (m *pinYinMatchModeMigration) ID() string { return "20261016_pinyin_match_mode" }
+
+func (m *pinYinMatchModeMigration) Description() string {
+	return "Replace the UsePinYin switch with PinYinMatchMode (true becomes both, false becomes off)."
+}
+
+func (m *pinYinMatchModeMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	var legacy database.WoxSetting
+	if err := tx.Where("key = ?", "UsePinYin").First(&legacy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// A mode saved by a newer version on this device wins over the legacy switch.
+	var existing database.WoxSetting
+	err := tx.Where("key = ?", "PinYinMatchMode").First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Unparseable values were ignored by the old setting loader, which then
+		// used the locale default; leaving the mode unset keeps that behavior.
+		if usePinYin, parseErr := strconv.ParseBool(legacy.Value); parseErr == nil {
+			store := setting.NewWoxSettingStore(tx)
+			if setErr := store.Set("PinYinMatchMode", fuzzymatch.PinYinMatchModeFromBool(usePinYin)); setErr != nil {
+				return setErr
+			}
+		}
+	} else if err != nil {
+		return err
+	}
+
+	return tx.Delete(&legacy).Error
+}
@@ -0,0 +1,173 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"wox/cloudsync"
+	"wox/common"
+	"wox/database"
+	"wox/setting"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&queryHistoryTableMigration{})
+}
+
+type queryHistoryTableMigration struct {
+	// sourceValue is the legacy QueryHistories JSON read by Up. Verify decodes
+	// it again on its own and compares it with what query_history holds.
+	sourceValue string
+	// keptQueries were in query_history before Up ran. Their rows are newer
+	// than the legacy entries and are only checked for presence.
+	keptQueries map[string]bool
+}
+
+// legacyQueryHistory is the element shape of the QueryHistories setting row.
+// Query is kept raw so the stored PlainQuery is copied as it was written.
+type legacyQueryHistory struct {
+	Query     json.RawMessage
+	Timestamp int64
+}
+
+func (m *queryHistoryTableMigration) ID() string { return "20261016_query_history_table" }
+
+func (m *queryHistoryTableMigration) Description() string {
+	return "Move query histories from the QueryHistories setting into the query_history table."
+}
+
+func (m *queryHistoryTableMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	m.sourceValue = ""
+	m.keptQueries = map[string]bool{}
+	if err := tx.AutoMigrate(&database.QueryHistoryRecord{}); err != nil {
+		return fmt.Errorf("failed to create query_history table: %w", err)
+	}
+
+	var legacy database.WoxSetting
+	if err := tx.Where("key = ?", setting.LegacyQueryHistoriesKey).First(&legacy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	m.sourceValue = legacy.Value
+	var histories []legacyQueryHistory
+	if legacy.Value != "" {
+		if err := json.Unmarshal([]byte(legacy.Value), &histories); err != nil {
+			// The old loader fell back to an empty history for an unreadable
+			// value, so dropping it loses nothing the user could still see.
+			Warn(ctx, fmt.Sprintf("failed to decode legacy query histories, dropping them: %v", err))
+			histories = nil
+		}
+	}
+
+	for index, history := range histories {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Histories are small rows, reporting each one would only flood the callback.
+		if index%100 == 0 {
+			ReportProgress(ctx, m.ID(), index, len(histories))
+		}
+
+		var query common.PlainQuery
+		if err := json.Unmarshal(history.Query, &query); err != nil || query.IsEmpty() {
+			continue
+		}
+		// A query recorded after the upgrade (e.g. restored from another
+		// device) is newer than its legacy entry and wins.
+		var existing int64
+		if err := tx.Model(&database.QueryHistoryRecord{}).Where("query = ?", query.String()).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			m.keptQueries[query.String()] = true
+			continue
+		}
+
+		if err := tx.Create(&database.QueryHistoryRecord{
+			Query:      query.String(),
+			PlainQuery: string(history.Query),
+			Timestamp:  history.Timestamp,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	ReportProgress(ctx, m.ID(), len(histories), len(histories))
+
+	// Query history is local only from now on, see setting.LegacyQueryHistoriesKey.
+	// Pending uploads of the old row would push the retired key to other
+	// devices, so they are dropped instead of being kept for a later push.
+	dropped := tx.
+		Where("entity_type = ? AND key = ? AND synced_to_cloud = ?", cloudsync.EntityWoxSetting, setting.LegacyQueryHistoriesKey, false).
+		Delete(&database.Oplog{})
+	if dropped.Error != nil {
+		return fmt.Errorf("failed to drop pending query history oplogs: %w", dropped.Error)
+	}
+	if dropped.RowsAffected > 0 {
+		util.GetLogger().Info(ctx, fmt.Sprintf("dropped %d pending query history oplogs, query history is no longer synced", dropped.RowsAffected))
+	}
+	return tx.Delete(&legacy).Error
+}
+
+// Verify decodes the legacy JSON again and checks that query_history holds
+// every distinct query of it with the same content, and that the legacy row
+// is gone. Rows are read back from the table, so a query that was dropped or
+// stored with a different shape is caught before the legacy row is deleted.
+func (m *queryHistoryTableMigration) Verify(ctx context.Context, tx *gorm.DB) error {
+	var sources []struct{ Query json.RawMessage }
+	if m.sourceValue != "" {
+		if err := json.Unmarshal([]byte(m.sourceValue), &sources); err != nil {
+			// Up dropped the unreadable value, there is nothing to compare.
+			sources = nil
+		}
+	}
+	expected := map[string]common.PlainQuery{}
+	for _, source := range sources {
+		var query common.PlainQuery
+		if err := json.Unmarshal(source.Query, &query); err != nil || query.IsEmpty() {
+			continue
+		}
+		expected[query.String()] = query
+	}
+
+	var rows []database.QueryHistoryRecord
+	if err := tx.Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to verify migrated query histories: %w", err)
+	}
+	matched := 0
+	for _, row := range rows {
+		source, ok := expected[row.Query]
+		if !ok {
+			continue
+		}
+		if m.keptQueries[row.Query] {
+			matched++
+			continue
+		}
+		var stored common.PlainQuery
+		if err := json.Unmarshal([]byte(row.PlainQuery), &stored); err != nil || !reflect.DeepEqual(stored, source) {
+			continue
+		}
+		matched++
+	}
+	if matched != len(expected) {
+		return &MigrationVerificationError{MigrationID: m.ID(), Item: "query histories", Expected: len(expected), Actual: matched}
+	}
+
+	var legacyCount int64
+	if err := tx.Model(&database.WoxSetting{}).Where("key = ?", setting.LegacyQueryHistoriesKey).Count(&legacyCount).Error; err != nil {
+		return fmt.Errorf("failed to verify legacy query histories removal: %w", err)
+	}
+	if legacyCount != 0 {
+		return &MigrationVerificationError{MigrationID: m.ID(), Item: "legacy QueryHistories rows", Expected: 0, Actual: int(legacyCount)}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"wox/common"
+	"wox/database"
+
+	"gorm.io/gorm"
+)
+
+func TestQueryHistoryTableMigrationPreservesRows(t *testing.T) {
+	db := newMigrationTestDB(t, &database.WoxSetting{}, &database.Oplog{})
+
+	type sourceHistory struct {
+		Query     common.PlainQuery
+		Timestamp int64
+	}
+	var source []sourceHistory
+	for i := 0; i < 25; i++ {
+		source = append(source, sourceHistory{
+			Query:     common.PlainQuery{QueryType: "input", QueryText: fmt.Sprintf("query %d", i)},
+			Timestamp: int64(1700000000000 + i),
+		})
+	}
+	sourceJson, err := json.Marshal(source)
+	if err != nil {
+		t.Fatalf("failed to encode source histories: %v", err)
+	}
+	if err := db.Create(&database.WoxSetting{Key: "QueryHistories", Value: string(sourceJson)}).Error; err != nil {
+		t.Fatalf("failed to insert legacy histories: %v", err)
+	}
+	if err := db.Create(&database.Oplog{EntityType: "wox_setting", Key: "QueryHistories", Operation: "upsert", Value: string(sourceJson)}).Error; err != nil {
+		t.Fatalf("failed to insert pending oplog: %v", err)
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return (&queryHistoryTableMigration{}).Up(context.Background(), tx)
+	}); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	var records []database.QueryHistoryRecord
+	if err := db.Order("timestamp ASC").Find(&records).Error; err != nil {
+		t.Fatalf("failed to load migrated histories: %v", err)
+	}
+	if len(records) != len(source) {
+		t.Fatalf("expected %d migrated rows, got %d", len(source), len(records))
+	}
+	for i, record := range records {
+		if record.Query != source[i].Query.QueryText || record.Timestamp != source[i].Timestamp {
+			t.Fatalf("row %d: expected %q at %d, got %q at %d", i, source[i].Query.QueryText, source[i].Timestamp, record.Query, record.Timestamp)
+		}
+		var query common.PlainQuery
+		if err := json.Unmarshal([]byte(record.PlainQuery), &query); err != nil || query.QueryType != "input" {
+			t.Fatalf("row %d: plain query not preserved: %s", i, record.PlainQuery)
+		}
+	}
+
+	var legacyCount int64
+	if err := db.Model(&database.WoxSetting{}).Where("key = ?", "QueryHistories").Count(&legacyCount).Error; err != nil {
+		t.Fatalf("failed to count legacy rows: %v", err)
+	}
+	if legacyCount != 0 {
+		t.Fatalf("expected legacy QueryHistories row to be removed")
+	}
+
+	var oplogCount int64
+	if err := db.Model(&database.Oplog{}).Where("key = ?", "QueryHistories").Count(&oplogCount).Error; err != nil {
+		t.Fatalf("failed to count oplogs: %v", err)
+	}
+	if oplogCount != 0 {
+		t.Fatalf("expected pending QueryHistories oplogs to be dropped, got %d", oplogCount)
+	}
+}
+
+func TestQueryHistoryTableMigrationWithoutLegacyRow(t *testing.T) {
+	db := newMigrationTestDB(t, &database.WoxSetting{}, &database.Oplog{})
+
+	if err := (&queryHistoryTableMigration{}).Up(context.Background(), db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	if !db.Migrator().HasTable(&database.QueryHistoryRecord{}) {
+		t.Fatalf("expected query_history table to be created")
+	}
+}
+
+func TestQueryHistoryTableMigrationVerifyComparesWithSourceJSON(t *testing.T) {
+	db := newMigrationTestDB(t, &database.WoxSetting{}, &database.Oplog{})
+	source := `[{"Query":{"QueryType":"input","QueryText":"first"},"Timestamp":1},{"Query":{"QueryType":"input","QueryText":"second"},"Timestamp":2}]`
+	if err := db.Create(&database.WoxSetting{Key: "QueryHistories", Value: source}).Error; err != nil {
+		t.Fatalf("failed to insert legacy histories: %v", err)
+	}
+
+	m := &queryHistoryTableMigration{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Up(context.Background(), tx); err != nil {
+			return err
+		}
+		if err := m.Verify(context.Background(), tx); err != nil {
+			t.Fatalf("expected a faithful migration to verify, got %v", err)
+		}
+
+		// a row stored with a different shape than its source does not count
+		if err := tx.Model(&database.QueryHistoryRecord{}).Where("query = ?", "second").Update("plain_query", `{"QueryType":"selection"}`).Error; err != nil {
+			return err
+		}
+		return m.Verify(context.Background(), tx)
+	})
+
+	var verificationErr *MigrationVerificationError
+	if !errors.As(err, &verificationErr) || verificationErr.Expected != 2 || verificationErr.Actual != 1 {
+		t.Fatalf("expected a verification error for 1 of 2 histories, got %v", err)
+	}
+}
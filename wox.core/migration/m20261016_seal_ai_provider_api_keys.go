@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"wox/setting"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&sealAIProviderApiKeysMigration{})
+}
+
+type sealAIProviderApiKeysMigration struct{}
+
+func (m *sealAIProviderApiKeysMigration) ID() string { return "20261016_seal_ai_provider_api_keys" }
+
+func (m *sealAIProviderApiKeysMigration) Description() string {
+	return "Re-save AIProviders so API keys stored in plaintext by older versions are encrypted at rest."
+}
+
+func (m *sealAIProviderApiKeysMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	store := setting.NewWoxSettingStore(tx)
+
+	// Get accepts legacy plaintext keys and Set seals them, so one round trip is
+	// enough. Users without AI providers have nothing to migrate.
+	var providers []setting.AIProvider
+	if err := store.Get("AIProviders", &providers); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return store.Set("AIProviders", providers)
+}
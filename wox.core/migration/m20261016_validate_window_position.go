@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"wox/setting"
+	"wox/util"
+	"wox/util/screen"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&validateWindowPositionMigration{})
+}
+
+// listWindowPositionScreens returns the work areas of the connected screens.
+// Tests replace it to simulate a monitor layout.
+var listWindowPositionScreens = func() ([]screen.Rect, error) {
+	displays, err := screen.ListDisplays()
+	if err != nil {
+		return nil, err
+	}
+	rects := make([]screen.Rect, 0, len(displays))
+	for _, display := range displays {
+		if display.WorkArea.IsEmpty() {
+			rects = append(rects, display.Bounds)
+		} else {
+			rects = append(rects, display.WorkArea)
+		}
+	}
+	return rects, nil
+}
+
+// validateWindowPositionMigration resets a migrated launcher position that
+// lies on a monitor which is no longer connected. Old configs carried
+// LastWindowX/LastWindowY over as-is, so a user whose setup changed since
+// would otherwise start with an invisible window. The -1 sentinel lets the
+// launcher pick a position on the current screens.
+type validateWindowPositionMigration struct{}
+
+func (m *validateWindowPositionMigration) ID() string { return "20261016_validate_window_position" }
+
+func (m *validateWindowPositionMigration) Description() string {
+	return "Reset a saved window position that is off all connected screens."
+}
+
+func (m *validateWindowPositionMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	store := setting.NewWoxSettingStore(tx)
+
+	var x, y int
+	if err := store.Get("LastWindowX", &x); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := store.Get("LastWindowY", &y); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if x == -1 && y == -1 {
+		return nil
+	}
+
+	screens, err := listWindowPositionScreens()
+	if err != nil {
+		// Keep the position, the launcher still clamps it when showing.
+		Warn(ctx, fmt.Sprintf("failed to list screens, saved window position %d,%d was not validated: %s", x, y, err.Error()))
+		return nil
+	}
+	if setting.IsWindowPositionVisible(x, y, screens) {
+		return nil
+	}
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("saved window position %d,%d is off all connected screens, resetting it", x, y))
+	if err := store.Set("LastWindowX", -1); err != nil {
+		return err
+	}
+	return store.Set("LastWindowY", -1)
+}
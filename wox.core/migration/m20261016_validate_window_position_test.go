@@ -0,0 +1,66 @@
+package migration
+
+import (
+	"context"
+	"testing"
+	"wox/database"
+	"wox/setting"
+	"wox/util/screen"
+
+	"gorm.io/gorm"
+)
+
+func runWindowPositionMigration(t *testing.T, x, y int, screens []screen.Rect) (int, int) {
+	t.Helper()
+
+	db := newMigrationTestDB(t, &database.WoxSetting{}, &database.Oplog{})
+
+	originalList := listWindowPositionScreens
+	listWindowPositionScreens = func() ([]screen.Rect, error) { return screens, nil }
+	t.Cleanup(func() { listWindowPositionScreens = originalList })
+
+	store := setting.NewWoxSettingStore(db)
+	if err := store.Set("LastWindowX", x); err != nil {
+		t.Fatalf("failed to save LastWindowX: %v", err)
+	}
+	if err := store.Set("LastWindowY", y); err != nil {
+		t.Fatalf("failed to save LastWindowY: %v", err)
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return (&validateWindowPositionMigration{}).Up(context.Background(), tx)
+	}); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	var migratedX, migratedY int
+	if err := store.Get("LastWindowX", &migratedX); err != nil {
+		t.Fatalf("failed to load LastWindowX: %v", err)
+	}
+	if err := store.Get("LastWindowY", &migratedY); err != nil {
+		t.Fatalf("failed to load LastWindowY: %v", err)
+	}
+	return migratedX, migratedY
+}
+
+func TestValidateWindowPositionResetsPositionOnDisconnectedMonitor(t *testing.T) {
+	// Saved on a second monitor right of the primary one, which is now unplugged.
+	primaryOnly := []screen.Rect{{X: 0, Y: 0, Width: 1920, Height: 1080}}
+
+	x, y := runWindowPositionMigration(t, 2400, 300, primaryOnly)
+	if x != -1 || y != -1 {
+		t.Fatalf("expected the position to be reset to -1,-1, got %d,%d", x, y)
+	}
+}
+
+func TestValidateWindowPositionKeepsVisiblePosition(t *testing.T) {
+	dualMonitors := []screen.Rect{
+		{X: 0, Y: 0, Width: 1920, Height: 1080},
+		{X: 1920, Y: 0, Width: 2560, Height: 1440},
+	}
+
+	x, y := runWindowPositionMigration(t, 2400, 300, dualMonitors)
+	if x != 2400 || y != 300 {
+		t.Fatalf("expected the position to be kept, got %d,%d", x, y)
+	}
+}
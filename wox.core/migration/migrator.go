@@ -2,9 +2,11 @@ package migration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"wox/database"
 	"wox/util"
@@ -28,8 +30,86 @@ type ConditionalMigration interface {
 	IsNeeded(ctx context.Context, db *gorm.DB) (bool, error)
 }
 
+// VerifiableMigration checks that the data written by Up matches the source
+// it was read from. Verify runs in the same transaction right after Up and
+// re-reads what Up wrote, so a mismatch, e.g. from a marshaling bug, rolls
+// the migration back while the source data is still intact.
+type VerifiableMigration interface {
+	Migration
+	Verify(ctx context.Context, tx *gorm.DB) error
+}
+
+// MigrationVerificationError reports migrated data that does not match its
+// source. Run returns it wrapped, use errors.As to detect it.
+type MigrationVerificationError struct {
+	MigrationID string
+	Item        string // what was compared, e.g. "query histories"
+	Expected    int
+	Actual      int
+}
+
+func (e *MigrationVerificationError) Error() string {
+	return fmt.Sprintf("migration %s verification failed: expected %d %s, found %d", e.MigrationID, e.Expected, e.Item, e.Actual)
+}
+
 var registeredMigrations []Migration
 
+// MigrationResult summarizes one run so the UI can tell users what changed and
+// what did not migrate cleanly.
+type MigrationResult struct {
+	Applied  []string // IDs of migrations applied in this run
+	Skipped  []string // IDs of conditional migrations that were not needed
+	Warnings []string // non-fatal problems, see Warn
+}
+
+var lastResult MigrationResult
+var lastResultMu sync.Mutex
+
+type warningsContextKey struct{}
+
+// Warn logs a non-fatal migration problem and adds it to the running
+// MigrationResult. Migrations should use it instead of logging warnings
+// directly so the problem is visible to users, not only in the log file.
+func Warn(ctx context.Context, message string) {
+	util.GetLogger().Warn(ctx, message)
+	if warnings, ok := ctx.Value(warningsContextKey{}).(*[]string); ok {
+		*warnings = append(*warnings, message)
+	}
+}
+
+// ProgressFunc receives migration progress, e.g. to drive a splash screen
+// progress bar. phase is PhaseMigrations for the run as a whole, or the ID of
+// the running migration for its own items such as plugins or history entries.
+type ProgressFunc func(phase string, done, total int)
+
+// PhaseMigrations is the progress phase that counts pending migrations.
+const PhaseMigrations = "migrations"
+
+type progressContextKey struct{}
+
+// WithProgress returns a context that makes Run report progress to fn. A nil
+// fn disables reporting.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, fn)
+}
+
+// ReportProgress tells the ProgressFunc of ctx, if any, that done of total
+// items of phase are finished. Migrations that loop over many items should
+// call it with their ID as phase.
+func ReportProgress(ctx context.Context, phase string, done, total int) {
+	if fn, ok := ctx.Value(progressContextKey{}).(ProgressFunc); ok && fn != nil {
+		fn(phase, done, total)
+	}
+}
+
+// LastResult returns the result of the most recent Run.
+func LastResult() MigrationResult {
+	lastResultMu.Lock()
+	defer lastResultMu.Unlock()
+
+	return lastResult
+}
+
 func Register(m Migration) {
 	if m == nil {
 		panic("migration: Register(nil)")
@@ -46,40 +126,77 @@ func Register(m Migration) {
 	registeredMigrations = append(registeredMigrations, m)
 }
 
-func Run(ctx context.Context) error {
+// LegacyPaths are the locations of the JSON setting files written by versions
+// that kept settings outside wox.db: wox.json, wox.data.json and the directory
+// with one <pluginId>.json per plugin.
+type LegacyPaths struct {
+	SettingPath string
+	AppDataPath string
+	PluginDir   string
+}
+
+// DefaultLegacyPaths returns the legacy locations inside the current user data directory.
+func DefaultLegacyPaths() LegacyPaths {
+	return LegacyPaths{
+		SettingPath: util.GetLocation().GetWoxSettingPath(),
+		AppDataPath: util.GetLocation().GetWoxAppDataPath(),
+		PluginDir:   util.GetLocation().GetPluginSettingDirectory(),
+	}
+}
+
+func Run(ctx context.Context) (MigrationResult, error) {
 	db := database.GetDB()
 	if db == nil {
-		return fmt.Errorf("migration: database not initialized")
+		return MigrationResult{}, fmt.Errorf("migration: database not initialized")
 	}
 	return RunWithDB(ctx, db)
 }
 
-func RunWithDB(ctx context.Context, db *gorm.DB) error {
+func RunWithDB(ctx context.Context, db *gorm.DB) (MigrationResult, error) {
 	logger := util.GetLogger()
 
+	var result MigrationResult
+	ctx = context.WithValue(ctx, warningsContextKey{}, &result.Warnings)
+	defer func() {
+		lastResultMu.Lock()
+		lastResult = result
+		lastResultMu.Unlock()
+	}()
+
 	migrations := make([]Migration, 0, len(registeredMigrations))
 	migrations = append(migrations, registeredMigrations...)
 	sort.Slice(migrations, func(i, j int) bool { return migrations[i].ID() < migrations[j].ID() })
 
 	var applied []database.MigrationRecord
 	if err := db.Find(&applied).Error; err != nil {
-		return fmt.Errorf("migration: failed to load migration records: %w", err)
+		return result, fmt.Errorf("migration: failed to load migration records: %w", err)
 	}
 	appliedSet := map[string]database.MigrationRecord{}
 	for _, rec := range applied {
 		appliedSet[rec.ID] = rec
 	}
 
+	pending := make([]Migration, 0, len(migrations))
 	for _, m := range migrations {
+		if _, ok := appliedSet[m.ID()]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	ReportProgress(ctx, PhaseMigrations, 0, len(pending))
+
+	for index, m := range pending {
 		id := m.ID()
-		if _, ok := appliedSet[id]; ok {
-			continue
+
+		// Stop between migrations on shutdown. Every applied migration is
+		// committed on its own, so the next run continues from here.
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("migration: cancelled before %s: %w", id, err)
 		}
 
 		if conditional, ok := m.(ConditionalMigration); ok {
 			needed, err := conditional.IsNeeded(ctx, db)
 			if err != nil {
-				return fmt.Errorf("migration: %s IsNeeded failed: %w", id, err)
+				return result, fmt.Errorf("migration: %s IsNeeded failed: %w", id, err)
 			}
 			if !needed {
 				if err := db.Create(&database.MigrationRecord{
@@ -87,9 +204,11 @@ func RunWithDB(ctx context.Context, db *gorm.DB) error {
 					AppliedAt: time.Now().Unix(),
 					Status:    "skipped",
 				}).Error; err != nil {
-					return fmt.Errorf("migration: %s failed to record skipped: %w", id, err)
+					return result, fmt.Errorf("migration: %s failed to record skipped: %w", id, err)
 				}
+				result.Skipped = append(result.Skipped, id)
 				logger.Info(ctx, fmt.Sprintf("migration skipped: %s", id))
+				ReportProgress(ctx, PhaseMigrations, index+1, len(pending))
 				continue
 			}
 		}
@@ -100,23 +219,46 @@ func RunWithDB(ctx context.Context, db *gorm.DB) error {
 			if err := m.Up(ctx, tx); err != nil {
 				return err
 			}
+			if verifiable, ok := m.(VerifiableMigration); ok {
+				if err := verifiable.Verify(ctx, tx); err != nil {
+					logger.Error(ctx, fmt.Sprintf("!!! migration %s wrote data that does not match its source, rolling back: %s", id, err.Error()))
+					return err
+				}
+			}
+			// A migration cancelled halfway may have returned early without
+			// an error; returning one here rolls back whatever it wrote.
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			return tx.Create(&database.MigrationRecord{
 				ID:        id,
 				AppliedAt: time.Now().Unix(),
 				Status:    "applied",
 			}).Error
 		}); err != nil {
-			return fmt.Errorf("migration: %s failed: %w", id, err)
+			return result, fmt.Errorf("migration: %s failed: %w", id, err)
 		}
 
 		if postCommit, ok := m.(PostCommitMigration); ok {
 			if err := postCommit.AfterCommit(ctx); err != nil {
-				logger.Warn(ctx, fmt.Sprintf("migration after-commit failed: %s: %v", id, err))
+				var verificationErr *MigrationVerificationError
+				if errors.As(err, &verificationErr) {
+					logger.Error(ctx, fmt.Sprintf("!!! migration %s after-commit verification failed: %s", id, err.Error()))
+					// The migration did not finish, so it must run again on
+					// the next start instead of being reported as applied.
+					if deleteErr := db.Delete(&database.MigrationRecord{}, "id = ?", id).Error; deleteErr != nil {
+						logger.Error(ctx, fmt.Sprintf("failed to clear the record of migration %s: %s", id, deleteErr.Error()))
+					}
+					return result, fmt.Errorf("migration: %s failed: %w", id, err)
+				}
+				Warn(ctx, fmt.Sprintf("migration after-commit failed: %s: %v", id, err))
 			}
 		}
 
+		result.Applied = append(result.Applied, id)
 		logger.Info(ctx, fmt.Sprintf("migration applied: %s", id))
+		ReportProgress(ctx, PhaseMigrations, index+1, len(pending))
 	}
 
-	return nil
+	return result, nil
 }
@@ -0,0 +1,108 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"wox/database"
+
+	"gorm.io/gorm"
+)
+
+// cancellingMigration writes a setting row and then cancels the run, like a
+// shutdown arriving while a long migration is halfway through.
+type cancellingMigration struct {
+	id     string
+	cancel context.CancelFunc
+}
+
+func (m *cancellingMigration) ID() string          { return m.id }
+func (m *cancellingMigration) Description() string { return "test migration that cancels the run" }
+
+func (m *cancellingMigration) Up(ctx context.Context, tx *gorm.DB) error {
+	if err := tx.Create(&database.WoxSetting{Key: m.id, Value: "written"}).Error; err != nil {
+		return err
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return nil
+}
+
+func TestRunWithDBRollsBackWhenCancelled(t *testing.T) {
+	db := newMigrationTestDB(t, &database.WoxSetting{}, &database.MigrationRecord{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	originalMigrations := registeredMigrations
+	registeredMigrations = []Migration{
+		&cancellingMigration{id: "test_1_cancel", cancel: cancel},
+		&cancellingMigration{id: "test_2_after_cancel"},
+	}
+	t.Cleanup(func() { registeredMigrations = originalMigrations })
+
+	result, err := RunWithDB(ctx, db)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Fatalf("expected no applied migrations, got %v", result.Applied)
+	}
+
+	var settingCount int64
+	if err := db.Model(&database.WoxSetting{}).Count(&settingCount).Error; err != nil {
+		t.Fatalf("failed to count settings: %v", err)
+	}
+	if settingCount != 0 {
+		t.Fatalf("expected the cancelled migration to be rolled back, found %d setting rows", settingCount)
+	}
+
+	var recordCount int64
+	if err := db.Model(&database.MigrationRecord{}).Count(&recordCount).Error; err != nil {
+		t.Fatalf("failed to count migration records: %v", err)
+	}
+	if recordCount != 0 {
+		t.Fatalf("expected no migration records, got %d", recordCount)
+	}
+}
+
+// failingAfterCommitMigration commits its transaction and then fails the
+// verification in AfterCommit.
+type failingAfterCommitMigration struct{}
+
+func (m *failingAfterCommitMigration) ID() string { return "test_after_commit_verification" }
+func (m *failingAfterCommitMigration) Description() string {
+	return "test migration failing after commit"
+}
+
+func (m *failingAfterCommitMigration) Up(ctx context.Context, tx *gorm.DB) error { return nil }
+
+func (m *failingAfterCommitMigration) AfterCommit(ctx context.Context) error {
+	return &MigrationVerificationError{MigrationID: m.ID(), Item: "files", Expected: 1, Actual: 0}
+}
+
+func TestRunWithDBClearsRecordWhenAfterCommitVerificationFails(t *testing.T) {
+	db := newMigrationTestDB(t, &database.MigrationRecord{})
+
+	originalMigrations := registeredMigrations
+	registeredMigrations = []Migration{&failingAfterCommitMigration{}}
+	t.Cleanup(func() { registeredMigrations = originalMigrations })
+
+	result, err := RunWithDB(context.Background(), db)
+	var verificationErr *MigrationVerificationError
+	if !errors.As(err, &verificationErr) {
+		t.Fatalf("expected a verification error, got %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Fatalf("expected no applied migrations, got %v", result.Applied)
+	}
+
+	var recordCount int64
+	if err := db.Model(&database.MigrationRecord{}).Count(&recordCount).Error; err != nil {
+		t.Fatalf("failed to count migration records: %v", err)
+	}
+	if recordCount != 0 {
+		t.Fatalf("expected the migration to stay pending, got %d records", recordCount)
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 	"wox/ai"
 	"wox/common"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/util"
 	"wox/util/clipboard"
+	"wox/util/notifier"
 
 	"github.com/samber/lo"
 )
@@ -37,12 +39,26 @@ type API interface {
 	HideApp(ctx context.Context)
 	ShowApp(ctx context.Context)
 	Notify(ctx context.Context, description string)
+	// ShowProgress creates or updates a progress notification identified by id, so a
+	// long running task updates one notification instead of stacking new ones.
+	// Ids are scoped to the calling plugin.
+	ShowProgress(ctx context.Context, id string, title string, percent int)
+	// CompleteProgress finalizes the progress notification identified by id with message.
+	CompleteProgress(ctx context.Context, id string, message string)
 	PushAttention(ctx context.Context, request PushAttentionRequest)
 	Log(ctx context.Context, level LogLevel, msg string)
 	GetTranslation(ctx context.Context, key string) string
 	GetSetting(ctx context.Context, key string) string
 	SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool)
+	// AddQueryHistory records a query in the query history of this plugin, e.g. so a
+	// calculator can list past calculations. It is kept apart from the global history.
+	AddQueryHistory(ctx context.Context, query common.PlainQuery)
+	// GetQueryHistory returns up to limit queries recorded with AddQueryHistory, newest first.
+	GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory
 	OnSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, value string))
+	// OnPluginSettingChanged is like OnSettingChanged but also passes the previous value,
+	// so a plugin can tell what changed (e.g. reconnect only when its api key changes).
+	OnPluginSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, oldValue string, newValue string))
 	OnGetDynamicSetting(ctx context.Context, callback func(ctx context.Context, key string) definition.PluginSettingDefinitionItem)
 	OnDeepLink(ctx context.Context, callback func(ctx context.Context, arguments map[string]string))
 	OnUnload(ctx context.Context, callback func(ctx context.Context))
@@ -248,6 +264,13 @@ func (a *APIImpl) ShowApp(ctx context.Context) {
 }
 
 func (a *APIImpl) Notify(ctx context.Context, message string) {
+	text := a.GetTranslation(ctx, message)
+	// Do not disturb also hides the toolbar message shown while the launcher is visible.
+	if notifier.Suppressed(text) {
+		a.Log(ctx, LogLevelDebug, fmt.Sprintf("notification suppressed by do not disturb: %s", text))
+		return
+	}
+
 	icon := a.pluginInstance.Metadata.Icon
 	if parsedIcon, err := common.ParseWoxImage(icon); err == nil {
 		convertedIcon := common.ConvertIcon(ctx, parsedIcon, a.pluginInstance.PluginDirectory)
@@ -256,12 +279,29 @@ func (a *APIImpl) Notify(ctx context.Context, message string) {
 
 	GetPluginManager().GetUI().Notify(ctx, common.NotifyMsg{
 		PluginId:       a.pluginInstance.Metadata.Id,
-		Text:           a.GetTranslation(ctx, message),
+		Text:           text,
 		Icon:           icon,
 		DisplaySeconds: 5,
 	})
 }
 
+func (a *APIImpl) ShowProgress(ctx context.Context, id string, title string, percent int) {
+	notifier.ShowProgress(a.progressNotificationId(id), a.GetTranslation(ctx, title), percent)
+}
+
+func (a *APIImpl) CompleteProgress(ctx context.Context, id string, message string) {
+	notifier.CompleteProgress(a.progressNotificationId(id), a.GetTranslation(ctx, message))
+}
+
+// progressNotificationId prefixes id with the plugin id, so plugins that pick
+// the same id do not update each other's notifications.
+func (a *APIImpl) progressNotificationId(id string) string {
+	if id == "" {
+		return ""
+	}
+	return a.pluginInstance.Metadata.Id + "_" + id
+}
+
 // PushAttention persists a plugin-owned item and refreshes the launcher unread badge.
 func (a *APIImpl) PushAttention(ctx context.Context, request PushAttentionRequest) {
 	if a.pluginInstance == nil {
@@ -345,6 +385,9 @@ func (a *APIImpl) GetSetting(ctx context.Context, key string) string {
 }
 
 func (a *APIImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
+	// the effective value before saving, which may come from a platform key or the metadata default
+	oldValue := a.GetSetting(ctx, key)
+
 	finalKey := key
 	if isPlatformSpecific {
 		finalKey = key + "@" + util.GetCurrentPlatform()
@@ -362,12 +405,27 @@ func (a *APIImpl) SaveSetting(ctx context.Context, key string, value string, isP
 			})
 		}
 	}
+	if oldValue != value {
+		a.pluginInstance.NotifyPluginSettingChanged(ctx, key, oldValue, value)
+	}
+}
+
+func (a *APIImpl) AddQueryHistory(ctx context.Context, query common.PlainQuery) {
+	setting.GetSettingManager().AddPluginQueryHistory(ctx, a.pluginInstance.Metadata.Id, query)
+}
+
+func (a *APIImpl) GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory {
+	return setting.GetSettingManager().GetPluginQueryHistory(ctx, a.pluginInstance.Metadata.Id, limit)
 }
 
 func (a *APIImpl) OnSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, value string)) {
 	a.pluginInstance.SettingChangeCallbacks = append(a.pluginInstance.SettingChangeCallbacks, callback)
 }
 
+func (a *APIImpl) OnPluginSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, oldValue string, newValue string)) {
+	a.pluginInstance.PluginSettingChangeCallbacks = append(a.pluginInstance.PluginSettingChangeCallbacks, callback)
+}
+
 func (a *APIImpl) OnGetDynamicSetting(
 	ctx context.Context,
 	callback func(ctx context.Context, key string) definition.PluginSettingDefinitionItem,
@@ -424,7 +482,7 @@ func (a *APIImpl) AIChatStream(ctx context.Context, model common.Model, conversa
 		return fmt.Errorf("plugin has no access to ai feature")
 	}
 
-	provider, providerErr := GetPluginManager().GetAIProvider(ctx, model.Provider, model.ProviderAlias)
+	provider, providerErr := GetPluginManager().GetAIProvider(ctx, model)
 	if providerErr != nil {
 		return providerErr
 	}
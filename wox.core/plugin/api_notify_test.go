@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"wox/common"
+	"wox/util"
+	"wox/util/notifier"
+)
+
+// notifyRecordingUI records the notifications that reach the UI.
+type notifyRecordingUI struct {
+	common.UI
+	messages []string
+}
+
+func (u *notifyRecordingUI) Notify(ctx context.Context, msg common.NotifyMsg) {
+	u.messages = append(u.messages, msg.Text)
+}
+
+func TestPluginNotifyHonorsDoNotDisturb(t *testing.T) {
+	ctx := context.Background()
+	manager := GetPluginManager()
+	ui := &notifyRecordingUI{}
+	originalUI := manager.ui
+	manager.ui = ui
+	suppressed := true
+	notifier.SetSuppressFunc(func() bool { return suppressed })
+	t.Cleanup(func() {
+		manager.ui = originalUI
+		notifier.SetSuppressFunc(nil)
+	})
+
+	api := &APIImpl{pluginInstance: &Instance{Metadata: Metadata{Id: "notify-test", Name: "Notify Test"}}, logger: util.GetLogger()}
+
+	api.Notify(ctx, "hidden while do not disturb is on")
+	if len(ui.messages) != 0 {
+		t.Fatalf("expected do not disturb to hide the plugin notification, got %v", ui.messages)
+	}
+	if entries := notifier.GetHistory(1); len(entries) != 1 || !entries[0].Suppressed || entries[0].Message != "hidden while do not disturb is on" {
+		t.Fatalf("expected the suppressed notification in the history, got %+v", entries)
+	}
+
+	suppressed = false
+	api.Notify(ctx, "shown")
+	if len(ui.messages) != 1 || ui.messages[0] != "shown" {
+		t.Fatalf("expected the notification to reach the UI, got %v", ui.messages)
+	}
+}
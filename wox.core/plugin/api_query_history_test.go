@@ -0,0 +1,38 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"wox/common"
+	"wox/database"
+	"wox/util"
+)
+
+func TestPluginQueryHistoryIsScopedToThePlugin(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	t.Setenv(util.TestWoxDataDirEnv, filepath.Join(root, "wox"))
+	t.Setenv(util.TestUserDataDirEnv, filepath.Join(root, "user"))
+	if err := util.GetLocation().Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+	if err := database.Init(ctx); err != nil {
+		t.Fatalf("failed to init database: %v", err)
+	}
+
+	calculator := &APIImpl{pluginInstance: &Instance{Metadata: Metadata{Id: "calculator-test", Name: "Calculator"}}, logger: util.GetLogger()}
+	notes := &APIImpl{pluginInstance: &Instance{Metadata: Metadata{Id: "notes-test", Name: "Notes"}}, logger: util.GetLogger()}
+
+	calculator.AddQueryHistory(ctx, common.PlainQuery{QueryType: QueryTypeInput, QueryText: "1+1"})
+	calculator.AddQueryHistory(ctx, common.PlainQuery{QueryType: QueryTypeInput, QueryText: "2*3"})
+	notes.AddQueryHistory(ctx, common.PlainQuery{QueryType: QueryTypeInput, QueryText: "todo"})
+
+	histories := calculator.GetQueryHistory(ctx, 10)
+	if len(histories) != 2 || histories[0].Query.QueryText != "2*3" || histories[1].Query.QueryText != "1+1" {
+		t.Fatalf("expected the calculator queries newest first, got %+v", histories)
+	}
+	if histories := notes.GetQueryHistory(ctx, 10); len(histories) != 1 || histories[0].Query.QueryText != "todo" {
+		t.Fatalf("expected only the notes query, got %+v", histories)
+	}
+}
@@ -160,7 +160,7 @@ func translateDoctorCheckText(ctx context.Context, text string) string {
 
 func checkWoxVersion(ctx context.Context) DoctorCheckResult {
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if woxSetting != nil && !woxSetting.EnableAutoUpdate.Get() {
+	if woxSetting != nil && !woxSetting.IsAutoUpdateEnabled() {
 		return DoctorCheckResult{
 			Name:        i18n.GetI18nManager().TranslateWox(ctx, "i18n:plugin_doctor_version"),
 			Type:        DoctorCheckUpdate,
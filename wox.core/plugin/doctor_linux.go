@@ -5,6 +5,7 @@ package plugin
 import (
 	"context"
 	"os/exec"
+	"slices"
 	"strings"
 	"wox/i18n"
 	"wox/setting"
@@ -217,7 +218,7 @@ func checkLinuxUinputGroup(ctx context.Context) (DoctorCheckResult, bool) {
 // doctor check so it only surfaces for users who actually need evdev.
 func userHasEvdevDependentHotkey(ctx context.Context) bool {
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if isEvdevDependentHotkey(woxSetting.MainHotkey.Get()) {
+	if slices.ContainsFunc(woxSetting.GetMainHotkeys(), isEvdevDependentHotkey) {
 		return true
 	}
 	if isEvdevDependentHotkey(woxSetting.SelectionHotkey.Get()) {
@@ -243,7 +244,7 @@ func userHasEvdevDependentHotkey(ctx context.Context) bool {
 // (CapsLock state restoration). Double-modifier hotkeys do not need uinput.
 func userHasCapsLockComboHotkey(ctx context.Context) bool {
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if hotkey.IsCapsLockHotkeyString(woxSetting.MainHotkey.Get()) {
+	if slices.ContainsFunc(woxSetting.GetMainHotkeys(), hotkey.IsCapsLockHotkeyString) {
 		return true
 	}
 	if hotkey.IsCapsLockHotkeyString(woxSetting.SelectionHotkey.Get()) {
@@ -65,7 +65,7 @@ var minimumNodejsVersion, _ = semver.NewVersion("v20.0.0")
 
 // ValidateNodejsExecutable verifies that a custom Node.js executable can run the Wox host.
 func ValidateNodejsExecutable(ctx context.Context, nodePath string) (*semver.Version, error) {
-	normalizedPath := strings.TrimSpace(nodePath)
+	normalizedPath := util.ExpandPath(nodePath)
 	if normalizedPath == "" {
 		message := "Node.js executable path is empty."
 		return nil, &runtimeExecutableError{statusCode: plugin.RuntimeHostStatusExecutableMissing, message: message}
@@ -79,6 +79,13 @@ func ValidateNodejsExecutable(ctx context.Context, nodePath string) (*semver.Ver
 		util.GetLogger().Warn(ctx, message)
 		return nil, &runtimeExecutableError{statusCode: plugin.RuntimeHostStatusExecutableMissing, message: message, path: normalizedPath}
 	}
+	if !util.IsWindows() {
+		if stat, statErr := os.Stat(normalizedPath); statErr == nil && !util.IsFileExecAny(stat.Mode()) {
+			message := fmt.Sprintf("custom Node.js path is not executable: %s", normalizedPath)
+			util.GetLogger().Warn(ctx, message)
+			return nil, &runtimeExecutableError{statusCode: plugin.RuntimeHostStatusExecutableMissing, message: message, path: normalizedPath}
+		}
+	}
 
 	installedVersion, versionErr := getNodejsExecutableVersion(ctx, normalizedPath)
 	if versionErr != nil {
@@ -98,7 +105,9 @@ func (n *NodejsHost) resolveNodejsPath(ctx context.Context) (string, error) {
 
 	// Bug fix: a missing custom path must stay actionable instead of silently
 	// falling back to another Node.js binary and later surfacing as "not started".
-	customPath := setting.GetSettingManager().GetWoxSetting(ctx).CustomNodejsPath.Get()
+	// The stored value may use ~ or environment variables so a synced
+	// setting works on every machine; it is expanded only when used.
+	customPath := util.ExpandPath(setting.GetSettingManager().GetWoxSetting(ctx).CustomNodejsPath.Get())
 	if customPath != "" {
 		installedVersion, validateErr := ValidateNodejsExecutable(ctx, customPath)
 		if validateErr != nil {
@@ -65,7 +65,7 @@ var minimumPythonVersion, _ = semver.NewVersion("v3.10.0")
 
 // ValidatePythonExecutable verifies that a custom Python executable can run the Wox host.
 func ValidatePythonExecutable(ctx context.Context, pythonPath string) (*semver.Version, error) {
-	normalizedPath := strings.TrimSpace(pythonPath)
+	normalizedPath := util.ExpandPath(pythonPath)
 	if normalizedPath == "" {
 		message := "Python executable path is empty."
 		return nil, &runtimeExecutableError{statusCode: plugin.RuntimeHostStatusExecutableMissing, message: message}
@@ -81,6 +81,13 @@ func ValidatePythonExecutable(ctx context.Context, pythonPath string) (*semver.V
 		util.GetLogger().Warn(ctx, message)
 		return nil, &runtimeExecutableError{statusCode: plugin.RuntimeHostStatusExecutableMissing, message: message, path: normalizedPath}
 	}
+	if !util.IsWindows() {
+		if stat, statErr := os.Stat(normalizedPath); statErr == nil && !util.IsFileExecAny(stat.Mode()) {
+			message := fmt.Sprintf("custom Python path is not executable: %s", normalizedPath)
+			util.GetLogger().Warn(ctx, message)
+			return nil, &runtimeExecutableError{statusCode: plugin.RuntimeHostStatusExecutableMissing, message: message, path: normalizedPath}
+		}
+	}
 
 	installedVersion, versionErr := getPythonExecutableVersion(ctx, normalizedPath)
 	if versionErr != nil {
@@ -100,7 +107,9 @@ func (n *PythonHost) resolvePythonPath(ctx context.Context) (string, error) {
 
 	// Bug fix: a broken custom path is a user-actionable configuration problem,
 	// not a generic host startup failure, so do not hide it behind auto-detect.
-	customPath := setting.GetSettingManager().GetWoxSetting(ctx).CustomPythonPath.Get()
+	// The stored value may use ~ or environment variables so a synced
+	// setting works on every machine; it is expanded only when used.
+	customPath := util.ExpandPath(setting.GetSettingManager().GetWoxSetting(ctx).CustomPythonPath.Get())
 	if customPath != "" {
 		installedVersion, validateErr := ValidatePythonExecutable(ctx, customPath)
 		if validateErr != nil {
@@ -196,10 +196,11 @@ func (s *ScriptPlugin) executeScript(ctx context.Context, request map[string]int
 		}
 
 		queryResult := plugin.QueryResult{
-			Title:    getStringFromMap(itemMap, "title"),
-			SubTitle: getStringFromMap(itemMap, "subtitle"),
-			Score:    int64(getFloatFromMap(itemMap, "score")),
-			ScoreKey: getFirstStringFromMap(itemMap, []string{"scoreKey", "score_key", "ScoreKey"}),
+			Title:     getStringFromMap(itemMap, "title"),
+			SubTitle:  getStringFromMap(itemMap, "subtitle"),
+			Score:     int64(getFloatFromMap(itemMap, "score")),
+			ScoreKey:  getFirstStringFromMap(itemMap, []string{"scoreKey", "score_key", "ScoreKey"}),
+			StableKey: getFirstStringFromMap(itemMap, []string{"stableKey", "stable_key", "StableKey"}),
 		}
 
 		// Icon: WoxImage.String() format, e.g. "base64:data:image/png;base64,xxx" or "emoji:🧮"
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -411,6 +412,28 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 		}
 		pluginInstance.API.Notify(ctx, message)
 		w.sendResponseToHost(ctx, request, "")
+	case "ShowProgress":
+		id, exist := request.Params["id"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] ShowProgress method must have an id parameter", request.PluginName))
+			return
+		}
+		percent, err := strconv.Atoi(request.Params["percent"])
+		if err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to parse ShowProgress percent: %s", request.PluginName, err))
+			w.sendResponseErrToHost(ctx, request, fmt.Errorf("failed to parse percent: %w", err))
+			return
+		}
+		pluginInstance.API.ShowProgress(ctx, id, request.Params["title"], percent)
+		w.sendResponseToHost(ctx, request, "")
+	case "CompleteProgress":
+		id, exist := request.Params["id"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] CompleteProgress method must have an id parameter", request.PluginName))
+			return
+		}
+		pluginInstance.API.CompleteProgress(ctx, id, request.Params["message"])
+		w.sendResponseToHost(ctx, request, "")
 	case "PushAttention":
 		rawRequest, exist := request.Params["request"]
 		if !exist {
@@ -501,6 +524,28 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 
 		pluginInstance.API.SaveSetting(ctx, key, value, isPlatformSpecific)
 		w.sendResponseToHost(ctx, request, "")
+	case "AddQueryHistory":
+		queryStr, exist := request.Params["query"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] AddQueryHistory method must have a query parameter", request.PluginName))
+			return
+		}
+		var query common.PlainQuery
+		if err := json.Unmarshal([]byte(queryStr), &query); err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal query history: %s", request.PluginName, err))
+			w.sendResponseErrToHost(ctx, request, fmt.Errorf("failed to unmarshal query: %w", err))
+			return
+		}
+		pluginInstance.API.AddQueryHistory(ctx, query)
+		w.sendResponseToHost(ctx, request, "")
+	case "GetQueryHistory":
+		limit, err := strconv.Atoi(request.Params["limit"])
+		if err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to parse GetQueryHistory limit: %s", request.PluginName, err))
+			w.sendResponseErrToHost(ctx, request, fmt.Errorf("failed to parse limit: %w", err))
+			return
+		}
+		w.sendResponseToHost(ctx, request, pluginInstance.API.GetQueryHistory(ctx, limit))
 	case "OnPluginSettingChanged":
 		callbackId, exist := request.Params["callbackId"]
 		if !exist {
@@ -5,6 +5,7 @@ import (
 	"wox/common"
 	"wox/setting"
 	"wox/setting/definition"
+	"wox/util"
 )
 
 type Instance struct {
@@ -19,14 +20,16 @@ type Instance struct {
 	Setting              *setting.PluginSetting // setting for this plugin
 	RuntimeQueryCommands []MetadataCommand      // query commands registered at runtime
 
-	DynamicSettingCallbacks   []func(ctx context.Context, key string) definition.PluginSettingDefinitionItem // dynamic setting callbacks
-	SettingChangeCallbacks    []func(ctx context.Context, key string, value string)
-	DeepLinkCallbacks         []func(ctx context.Context, arguments map[string]string)
-	UnloadCallbacks           []func(ctx context.Context)
-	MRURestoreCallbacks       []func(ctx context.Context, mruData MRUData) (*QueryResult, error) // MRU restore callbacks
-	PluginCommandHandlers     []PluginCommandHandler
-	EnterPluginQueryCallbacks []func(ctx context.Context)
-	LeavePluginQueryCallbacks []func(ctx context.Context)
+	DynamicSettingCallbacks []func(ctx context.Context, key string) definition.PluginSettingDefinitionItem // dynamic setting callbacks
+	SettingChangeCallbacks  []func(ctx context.Context, key string, value string)
+	// PluginSettingChangeCallbacks receive the previous value as well, see API.OnPluginSettingChanged
+	PluginSettingChangeCallbacks []func(ctx context.Context, key string, oldValue string, newValue string)
+	DeepLinkCallbacks            []func(ctx context.Context, arguments map[string]string)
+	UnloadCallbacks              []func(ctx context.Context)
+	MRURestoreCallbacks          []func(ctx context.Context, mruData MRUData) (*QueryResult, error) // MRU restore callbacks
+	PluginCommandHandlers        []PluginCommandHandler
+	EnterPluginQueryCallbacks    []func(ctx context.Context)
+	LeavePluginQueryCallbacks    []func(ctx context.Context)
 
 	// for measure performance
 	LoadStartTimestamp    int64
@@ -95,3 +98,13 @@ func (i *Instance) GetQueryCommands() []MetadataCommand {
 func (i *Instance) String() string {
 	return i.GetName(context.Background())
 }
+
+// NotifyPluginSettingChanged runs the OnPluginSettingChanged callbacks in the
+// background, so a plugin reconnecting to a remote API does not block the save.
+func (i *Instance) NotifyPluginSettingChanged(ctx context.Context, key string, oldValue string, newValue string) {
+	for _, callback := range i.PluginSettingChangeCallbacks {
+		util.Go(ctx, "plugin setting change callback", func() {
+			callback(ctx, key, oldValue, newValue)
+		})
+	}
+}
@@ -5,12 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
-	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -33,7 +31,6 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
-	"github.com/wissance/stringFormatter"
 )
 
 var managerInstance *Manager
@@ -227,6 +224,8 @@ func (m *Manager) Start(ctx context.Context, ui common.UI) error {
 		return fmt.Errorf("failed to load plugins: %w", loadErr)
 	}
 
+	setting.GetSettingManager().OnPluginSettingChanged(m.onPluginSettingReplaced)
+
 	// Start script plugin monitoring
 	util.Go(ctx, "start script plugin monitoring", func() {
 		m.startScriptPluginMonitoring(util.NewTraceContext())
@@ -239,6 +238,31 @@ func (m *Manager) Start(ctx context.Context, ui common.UI) error {
 	return nil
 }
 
+// onPluginSettingReplaced refreshes a loaded plugin after one of its settings
+// was replaced in bulk, e.g. by a settings import, and tells the plugin about
+// it like a change made through the plugin API.
+func (m *Manager) onPluginSettingReplaced(ctx context.Context, pluginId string, key string, oldValue string, newValue string) {
+	for _, instance := range m.GetPluginInstances() {
+		if instance.Metadata.Id != pluginId {
+			continue
+		}
+		if instance.Setting != nil {
+			instance.Setting.Reload()
+		}
+		if baseKey, platform, isPlatformKey := setting.SplitPlatformSettingKey(key); isPlatformKey {
+			if platform != util.GetCurrentPlatform() {
+				return
+			}
+			key = baseKey
+		}
+		for _, callback := range instance.SettingChangeCallbacks {
+			callback(ctx, key, newValue)
+		}
+		instance.NotifyPluginSettingChanged(ctx, key, oldValue, newValue)
+		return
+	}
+}
+
 func (m *Manager) Stop(ctx context.Context) {
 	// Stop script plugin monitoring
 	if m.scriptPluginWatcher != nil {
@@ -440,6 +464,9 @@ func (m *Manager) loadHostPlugin(ctx context.Context, host Host, metadata Metada
 		instance.API.Log(ctx, LogLevelError, fmt.Errorf("[SYS] failed to load plugin[%s] setting: %w", metadata.GetName(ctx), settingErr).Error())
 		return settingErr
 	}
+	if reconcileErr := pluginSetting.Reconcile(ctx, metadata.SettingDefinitions, false); reconcileErr != nil {
+		instance.API.Log(ctx, LogLevelWarning, fmt.Sprintf("[SYS] failed to reconcile plugin[%s] setting: %s", metadata.GetName(ctx), reconcileErr.Error()))
+	}
 	instance.Setting = pluginSetting
 
 	m.instances = append(m.instances, instance)
@@ -605,6 +632,10 @@ func (m *Manager) loadSystemPlugins(ctx context.Context) {
 				return
 			}
 
+			if reconcileErr := pluginSetting.Reconcile(ctx, metadata.SettingDefinitions, false); reconcileErr != nil {
+				logger.Warn(ctx, fmt.Sprintf("failed to reconcile system plugin[%s] setting: %s", metadata.GetName(ctx), reconcileErr.Error()))
+			}
+
 			instance.Setting = pluginSetting
 			if util.GetSystemTimestamp()-startTimestamp > 100 {
 				logger.Warn(ctx, fmt.Sprintf("load system plugin[%s] setting too slow, cost %d ms", metadata.GetName(ctx), util.GetSystemTimestamp()-startTimestamp))
@@ -984,6 +1015,27 @@ func (m *Manager) GetPluginInstanceById(pluginId string) *Instance {
 	return nil
 }
 
+// EnablePlugin clears the user's disabled flag of a loaded plugin.
+func (m *Manager) EnablePlugin(ctx context.Context, pluginId string) error {
+	return m.setPluginDisabled(ctx, pluginId, false)
+}
+
+// DisablePlugin marks a loaded plugin as disabled, canOperateQuery then skips it.
+func (m *Manager) DisablePlugin(ctx context.Context, pluginId string) error {
+	return m.setPluginDisabled(ctx, pluginId, true)
+}
+
+func (m *Manager) setPluginDisabled(ctx context.Context, pluginId string, disabled bool) error {
+	instance := m.GetPluginInstanceById(pluginId)
+	if instance == nil {
+		return fmt.Errorf("can't find plugin: %s", pluginId)
+	}
+	if err := instance.Setting.Disabled.Set(disabled); err != nil {
+		return fmt.Errorf("failed to save disabled state of plugin %s: %w", instance.Metadata.GetName(ctx), err)
+	}
+	return nil
+}
+
 // GetSystemPlugin returns the SystemPlugin implementation for the given plugin ID,
 // or nil if the plugin is not found or not a system plugin.
 func (m *Manager) GetSystemPlugin(pluginId string) SystemPlugin {
@@ -1357,7 +1409,7 @@ func (m *Manager) finalizePluginQueryResponse(ctx context.Context, pluginInstanc
 		resultTimingStart := time.Now()
 		defaultActionsStart := util.GetSystemTimestamp()
 		defaultActionsTimingStart := time.Now()
-		defaultActions := m.getDefaultActionsWithOpenPluginSettingAction(ctx, pluginInstance, query, response.Results[i].Title, response.Results[i].SubTitle, openPluginSettingAction)
+		defaultActions := m.getDefaultActionsWithOpenPluginSettingAction(ctx, pluginInstance, query, response.Results[i], openPluginSettingAction)
 		defaultActionsCost := util.GetSystemTimestamp() - defaultActionsStart
 		defaultActionsCostUs := time.Since(defaultActionsTimingStart).Microseconds()
 		totalDefaultActionsCost += defaultActionsCost
@@ -1482,21 +1534,26 @@ func (m *Manager) GetResultForFailedQuery(ctx context.Context, pluginMetadata Me
 	}
 }
 
-func (m *Manager) getDefaultActions(ctx context.Context, pluginInstance *Instance, query Query, title, subTitle string) (defaultActions []QueryResultAction) {
-	return m.getDefaultActionsWithOpenPluginSettingAction(ctx, pluginInstance, query, title, subTitle, m.newOpenPluginSettingAction(ctx, pluginInstance))
+func (m *Manager) getDefaultActions(ctx context.Context, pluginInstance *Instance, query Query, result QueryResult) (defaultActions []QueryResultAction) {
+	return m.getDefaultActionsWithOpenPluginSettingAction(ctx, pluginInstance, query, result, m.newOpenPluginSettingAction(ctx, pluginInstance))
 }
 
-func (m *Manager) getDefaultActionsWithOpenPluginSettingAction(ctx context.Context, pluginInstance *Instance, query Query, title, subTitle string, openPluginSettingAction QueryResultAction) (defaultActions []QueryResultAction) {
+func (m *Manager) getDefaultActionsWithOpenPluginSettingAction(ctx context.Context, pluginInstance *Instance, query Query, result QueryResult, openPluginSettingAction QueryResultAction) (defaultActions []QueryResultAction) {
+	favoriteHash := resultFavoriteHash(pluginInstance.Metadata.Id, result)
+
 	// Declare both actions first
 	var addToFavoriteAction func(context.Context, ActionContext)
 	var removeFromFavoriteAction func(context.Context, ActionContext)
 
 	// Define add to favorite action
 	addToFavoriteAction = func(ctx context.Context, actionContext ActionContext) {
-		setting.GetSettingManager().PinResult(ctx, pluginInstance.Metadata.Id, title, subTitle)
-
 		// Get API instance
 		api := NewAPI(pluginInstance)
+		if err := setting.GetSettingManager().PinResultByHash(ctx, favoriteHash); err != nil {
+			limit := setting.GetSettingManager().GetWoxSetting(ctx).MaxFavoriteResults.Get()
+			api.Notify(ctx, fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_manager_pin_in_query_limit_reached"), limit))
+			return
+		}
 		api.Notify(ctx, "i18n:plugin_manager_pin_in_query_success")
 
 		// Get current result state
@@ -1515,7 +1572,7 @@ func (m *Manager) getDefaultActionsWithOpenPluginSettingAction(ctx context.Conte
 
 	// Define remove from favorite action
 	removeFromFavoriteAction = func(ctx context.Context, actionContext ActionContext) {
-		setting.GetSettingManager().UnpinResult(ctx, pluginInstance.Metadata.Id, title, subTitle)
+		setting.GetSettingManager().UnpinResultByHash(ctx, favoriteHash)
 
 		// Get API instance
 		api := NewAPI(pluginInstance)
@@ -1535,7 +1592,7 @@ func (m *Manager) getDefaultActionsWithOpenPluginSettingAction(ctx context.Conte
 		api.UpdateResult(ctx, *updatableResult)
 	}
 
-	if setting.GetSettingManager().IsPinedResult(ctx, pluginInstance.Metadata.Id, title, subTitle) {
+	if setting.GetSettingManager().IsPinedResultByHash(ctx, favoriteHash) {
 		defaultActions = append(defaultActions, QueryResultAction{
 			Id:                     systemActionUnpinInQueryID,
 			Name:                   "i18n:plugin_manager_unpin_in_query",
@@ -1738,6 +1795,9 @@ func limitGlobalQueryPluginScore(query Query, score int64) int64 {
 }
 
 func resultScoreHash(pluginId string, result QueryResult) setting.ResultHash {
+	if stableKey := strings.TrimSpace(result.StableKey); stableKey != "" {
+		return setting.NewStableResultHash(pluginId, stableKey)
+	}
 	scoreKey := strings.TrimSpace(result.ScoreKey)
 	if scoreKey != "" {
 		return setting.NewResultHash(pluginId, scoreKey, "")
@@ -1745,47 +1805,20 @@ func resultScoreHash(pluginId string, result QueryResult) setting.ResultHash {
 	return setting.NewResultHash(pluginId, result.Title, result.SubTitle)
 }
 
-func (m *Manager) calculateResultScore(ctx context.Context, pluginId string, result QueryResult, currentQuery string) int64 {
-	var score int64 = 0
-
-	resultHash := resultScoreHash(pluginId, result)
-	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	actionResults, ok := woxSetting.ActionedResults.Get().Load(resultHash)
-	if !ok {
-		return score
-	}
-
-	// actioned score are based on actioned counts, the more actioned, the more score
-	// also, action timestamp will be considered, the more recent actioned, the more score weight. If action is in recent 7 days, it will be considered as recent actioned and add score weight
-	// we will use fibonacci sequence to calculate score, the more recent actioned, the more score: 5, 8, 13, 21, 34, 55, 89
-	// that means, actions in day one, we will add weight 89, day two, we will add weight 55, day three, we will add weight 34, and so on
-	// E.g. if actioned 3 times in day one, 2 times in day two, 1 time in day three, the score will be: 89*3 + 55*2 + 34*1 = 450
-
-	for _, actionResult := range actionResults {
-		var weight int64 = 2
-
-		hours := (util.GetSystemTimestamp() - actionResult.Timestamp) / 1000 / 60 / 60
-		if hours < 24*7 {
-			fibonacciIndex := int(math.Ceil(float64(hours) / 24))
-			if fibonacciIndex > 7 {
-				fibonacciIndex = 7
-			}
-			if fibonacciIndex < 1 {
-				fibonacciIndex = 1
-			}
-			fibonacci := []int64{5, 8, 13, 21, 34, 55, 89}
-			score += fibonacci[7-fibonacciIndex]
-		}
-
-		// If the current query is within the historical selected actions, it indicates a stronger connection and increases the score.
-		if currentQuery != "" && actionResult.Query == currentQuery {
-			score += 20
-		}
-
-		score += weight
+// resultFavoriteHash identifies a result in the favorites list. Unlike
+// resultScoreHash it ignores ScoreKey, which may be shared by several results.
+func resultFavoriteHash(pluginId string, result QueryResult) setting.ResultHash {
+	if stableKey := strings.TrimSpace(result.StableKey); stableKey != "" {
+		return setting.NewStableResultHash(pluginId, stableKey)
 	}
+	return setting.NewResultHash(pluginId, result.Title, result.SubTitle)
+}
 
-	return score
+// calculateResultScore returns the score the action history of a result adds
+// to its plugin score, weighted by the RankingMode setting.
+func (m *Manager) calculateResultScore(ctx context.Context, pluginId string, result QueryResult, currentQuery string) int64 {
+	rankingMode := setting.GetSettingManager().GetWoxSetting(ctx).RankingMode.Get()
+	return setting.GetSettingManager().GetActionedResultRankingScore(ctx, resultScoreHash(pluginId, result), currentQuery, rankingMode)
 }
 
 func (m *Manager) startSessionQueryCache(query Query) {
@@ -2743,6 +2776,9 @@ func (m *Manager) polishResult(ctx context.Context, pluginInstance *Instance, qu
 	}
 	ScoreFeatureCost := util.GetSystemTimestamp() - scoreFeatureStart
 	ScoreFeatureCostUs := time.Since(scoreFeatureTimingStart).Microseconds()
+	if stableKey := strings.TrimSpace(result.StableKey); stableKey != "" {
+		setting.GetSettingManager().MigrateToStableResultHash(ctx, pluginInstance.Metadata.Id, stableKey, result.Title, result.SubTitle)
+	}
 	autoScoreStart := util.GetSystemTimestamp()
 	autoScoreTimingStart := time.Now()
 	if !ignoreAutoScore {
@@ -2758,7 +2794,7 @@ func (m *Manager) polishResult(ctx context.Context, pluginInstance *Instance, qu
 	favoriteTimingStart := time.Now()
 	// check if result is favorite result
 	// favorite result will not be affected by ignoreAutoScore setting, except on the MRU page where MRU score owns ranking.
-	isFavorite := !isMRUQuery && setting.GetSettingManager().IsPinedResult(ctx, pluginInstance.Metadata.Id, result.Title, result.SubTitle)
+	isFavorite := !isMRUQuery && setting.GetSettingManager().IsPinedResultByHash(ctx, resultFavoriteHash(pluginInstance.Metadata.Id, result))
 	if isFavorite {
 		favScore := int64(100000)
 		logger.Debug(ctx, fmt.Sprintf("<%s> result(%s) is favorite result, add score: %d", pluginInstance.GetName(ctx), result.Title, favScore))
@@ -3048,7 +3084,7 @@ func (m *Manager) PolishUpdatableResult(ctx context.Context, pluginInstance *Ins
 
 		// Add system actions (like pin/unpin)
 		// System actions are added after user actions
-		systemActions := m.getDefaultActions(ctx, pluginInstance, resultCache.Query, resultCache.Result.Title, resultCache.Result.SubTitle)
+		systemActions := m.getDefaultActions(ctx, pluginInstance, resultCache.Query, resultCache.Result)
 		actions = append(actions, systemActions...)
 
 		// Translate action names
@@ -3127,7 +3163,7 @@ func (m *Manager) PolishUpdatableResult(ctx context.Context, pluginInstance *Ins
 		}
 
 		// Add favorite icon to tails if this is a favorite result
-		isFavorite := setting.GetSettingManager().IsPinedResult(ctx, pluginInstance.Metadata.Id, resultCache.Result.Title, resultCache.Result.SubTitle)
+		isFavorite := setting.GetSettingManager().IsPinedResultByHash(ctx, resultFavoriteHash(pluginInstance.Metadata.Id, resultCache.Result))
 		if isFavorite {
 			// Check if favorite tail already exists
 			hasFavoriteTail := false
@@ -3855,14 +3891,9 @@ func (m *Manager) NewQuery(ctx context.Context, plainQuery common.PlainQuery) (Q
 
 	if plainQuery.QueryType == QueryTypeInput {
 		newQuery := plainQuery.QueryText
-		woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-		if len(woxSetting.QueryShortcuts.Get()) > 0 {
-			originQuery := plainQuery.QueryText
-			expandedQuery := m.expandQueryShortcut(ctx, plainQuery.QueryText, woxSetting.QueryShortcuts.Get())
-			if originQuery != expandedQuery {
-				logger.Info(ctx, fmt.Sprintf("expand query shortcut: %s -> %s", originQuery, expandedQuery))
-				newQuery = expandedQuery
-			}
+		if expandedQuery, expanded := setting.GetSettingManager().ExpandShortcut(plainQuery.QueryText); expanded && expandedQuery != plainQuery.QueryText {
+			logger.Info(ctx, fmt.Sprintf("expand query shortcut: %s -> %s", plainQuery.QueryText, expandedQuery))
+			newQuery = expandedQuery
 		}
 		query, instance := newQueryInputWithPlugins(newQuery, GetPluginManager().GetPluginInstances())
 		query.Id = plainQuery.QueryId
@@ -3935,53 +3966,6 @@ func (m *Manager) getActiveFileExplorerPath(ctx context.Context) string {
 	return window.GetActiveFileExplorerPath()
 }
 
-func (m *Manager) expandQueryShortcut(ctx context.Context, query string, queryShorts []setting.QueryShortcut) (newQuery string) {
-	newQuery = query
-
-	//sort query shorts by shortcut length, we will expand the longest shortcut first
-	slices.SortFunc(queryShorts, func(i, j setting.QueryShortcut) int {
-		return len(j.Shortcut) - len(i.Shortcut)
-	})
-
-	for _, shortcut := range queryShorts {
-		if shortcut.Disabled {
-			continue
-		}
-
-		// Query shortcuts are command-style aliases for the first query token. Plain
-		// prefix matching made short aliases such as "th" rewrite normal queries like
-		// "theme xx", so the shortcut must end at the query boundary while still
-		// supporting "th args".
-		if query == shortcut.Shortcut || strings.HasPrefix(query, shortcut.Shortcut+" ") {
-			if !shortcut.HasPlaceholder() {
-				newQuery = strings.Replace(query, shortcut.Shortcut, shortcut.Query, 1)
-				break
-			} else {
-				queryWithoutShortcut := strings.Replace(query, shortcut.Shortcut, "", 1)
-				queryWithoutShortcut = strings.TrimLeft(queryWithoutShortcut, " ")
-				parameters := strings.Split(queryWithoutShortcut, " ")
-				placeholderCount := shortcut.PlaceholderCount()
-				var paramsCount = 0
-
-				var params []any
-				var nonPrams string
-				for _, param := range parameters {
-					if paramsCount < placeholderCount {
-						paramsCount++
-						params = append(params, param)
-					} else {
-						nonPrams += " " + param
-					}
-				}
-				newQuery = stringFormatter.Format(shortcut.Query, params...) + nonPrams
-				break
-			}
-		}
-	}
-
-	return newQuery
-}
-
 func (m *Manager) ExecuteAction(ctx context.Context, sessionId string, queryId string, resultId string, actionId string) error {
 	resultCache, found := m.findResultCacheInSession(sessionId, queryId, resultId)
 	if !found {
@@ -4016,7 +4000,7 @@ func (m *Manager) ExecuteAction(ctx context.Context, sessionId string, queryId s
 	})
 
 	util.Go(actionCtx, fmt.Sprintf("[%s] post execute action", resultCache.PluginInstance.GetName(actionCtx)), func() {
-		m.postExecuteAction(actionCtx, resultCache, actionCache.ContextData)
+		m.postExecuteAction(actionCtx, resultCache, actionCache)
 	})
 
 	return nil
@@ -4057,17 +4041,19 @@ func (m *Manager) SubmitFormAction(ctx context.Context, sessionId string, queryI
 	})
 
 	util.Go(actionCtx, fmt.Sprintf("[%s] post execute action", resultCache.PluginInstance.GetName(actionCtx)), func() {
-		m.postExecuteAction(actionCtx, resultCache, actionCache.ContextData)
+		m.postExecuteAction(actionCtx, resultCache, actionCache)
 	})
 
 	return nil
 }
 
-func (m *Manager) postExecuteAction(ctx context.Context, resultCache *QueryResultCache, contextData map[string]string) {
+func (m *Manager) postExecuteAction(ctx context.Context, resultCache *QueryResultCache, action *QueryResultAction) {
+	contextData := action.ContextData
+
 	// Add actioned result for statistics
 	meta := resultCache.PluginInstance.Metadata
 	scoreHash := resultScoreHash(meta.Id, resultCache.Result)
-	setting.GetSettingManager().AddActionedResultByHash(ctx, scoreHash, resultCache.Query.RawQuery)
+	setting.GetSettingManager().AddActionedResultByHash(ctx, scoreHash, resultCache.Query.RawQuery, action.Id, action.Name)
 
 	// Add to MRU if plugin supports it
 	if meta.IsSupportFeature(MetadataFeatureMRU) {
@@ -4298,32 +4284,36 @@ func (m *Manager) GetAIChatPluginChater(ctx context.Context) common.AIChater {
 	return nil
 }
 
-func (m *Manager) GetAIProvider(ctx context.Context, provider common.ProviderName, alias string) (ai.Provider, error) {
-	key := string(provider)
-	if alias != "" {
-		key = fmt.Sprintf("%s_%s", provider, alias)
-	}
-	if v, exist := m.aiProviders.Load(key); exist {
-		return v, nil
-	}
-
+func (m *Manager) GetAIProvider(ctx context.Context, model common.Model) (ai.Provider, error) {
 	//check if provider has setting
 	aiProviderSettings := setting.GetSettingManager().GetWoxSetting(ctx).AIProviders.Get()
-	providerSetting, providerSettingExist := lo.Find(aiProviderSettings, func(item setting.AIProvider) bool {
-		return item.Name == provider && item.Alias == alias
-	})
+	providerSetting, providerSettingExist := setting.FindAIProvider(aiProviderSettings, model)
 	if !providerSettingExist {
-		return nil, fmt.Errorf("ai provider setting not found: %s (alias=%s)", provider, alias)
+		return nil, fmt.Errorf("ai provider setting not found: %s (alias=%s, id=%s)", model.Provider, model.ProviderAlias, model.ProviderId)
+	}
+
+	// providers synced from an older device may not have an id yet
+	cacheKey := providerSetting.Id
+	if cacheKey == "" {
+		cacheKey = fmt.Sprintf("%s_%s", providerSetting.Name, providerSetting.Alias)
+	}
+	if v, exist := m.aiProviders.Load(cacheKey); exist {
+		return v, nil
 	}
 
 	newProvider, newProviderErr := ai.NewProvider(ctx, providerSetting)
 	if newProviderErr != nil {
 		return nil, newProviderErr
 	}
-	m.aiProviders.Store(key, newProvider)
+	m.aiProviders.Store(cacheKey, newProvider)
 	return newProvider, nil
 }
 
+// ClearAIProviderCache drops cached providers so edited hosts and api keys take effect.
+func (m *Manager) ClearAIProviderCache(ctx context.Context) {
+	m.aiProviders.Clear()
+}
+
 func (m *Manager) ExecutePluginDeeplink(ctx context.Context, pluginId string, arguments map[string]string) {
 	pluginInstance, exist := lo.Find(m.instances, func(item *Instance) bool {
 		return item.Metadata.Id == pluginId
@@ -18,22 +18,40 @@ func Test_QueryShortcut(t *testing.T) {
 			Shortcut: "wix",
 			Query:    "wpm install {0} x {1}",
 		},
+		{
+			Shortcut: "g",
+			Query:    "google $1",
+		},
+		{
+			Shortcut: "gh",
+			Query:    "github search $*",
+		},
 	}
 
-	query := GetPluginManager().expandQueryShortcut(util.NewTraceContext(), "wi 1 2", shortcuts)
+	query, _ := setting.ExpandQueryShortcut("wi 1 2", shortcuts)
 	assert.Equal(t, "wpm install 1 2", query)
 
-	query = GetPluginManager().expandQueryShortcut(util.NewTraceContext(), "wi wi 1 2", shortcuts)
+	query, _ = setting.ExpandQueryShortcut("wi wi 1 2", shortcuts)
 	assert.Equal(t, "wpm install wi 1 2", query)
 
-	query = GetPluginManager().expandQueryShortcut(util.NewTraceContext(), "wix 1 2", shortcuts)
+	query, _ = setting.ExpandQueryShortcut("wix 1 2", shortcuts)
 	assert.Equal(t, "wpm install 1 x 2", query)
 
-	query = GetPluginManager().expandQueryShortcut(util.NewTraceContext(), "wix 1 2 3 4", shortcuts)
+	query, _ = setting.ExpandQueryShortcut("wix 1 2 3 4", shortcuts)
 	assert.Equal(t, "wpm install 1 x 2 3 4", query)
 
-	query = GetPluginManager().expandQueryShortcut(util.NewTraceContext(), "wix 1", shortcuts)
+	query, _ = setting.ExpandQueryShortcut("wix 1", shortcuts)
 	assert.Equal(t, "wpm install 1 x {1}", query)
+
+	query, _ = setting.ExpandQueryShortcut("g wox launcher", shortcuts)
+	assert.Equal(t, "google wox launcher", query)
+
+	query, _ = setting.ExpandQueryShortcut("gh wox  launcher", shortcuts)
+	assert.Equal(t, "github search wox  launcher", query)
+
+	query, expanded := setting.ExpandQueryShortcut("gx 1", shortcuts)
+	assert.False(t, expanded)
+	assert.Equal(t, "gx 1", query)
 }
 
 func TestPolishUpdatableResultClearsPreviewForGlobalQuery(t *testing.T) {
@@ -233,6 +233,9 @@ type QueryResult struct {
 	Score int64
 	// ScoreKey is an optional stable identity for actioned-result scoring when title or subtitle is dynamic.
 	ScoreKey string
+	// StableKey is an optional language independent id of the result. When set, favorites and action
+	// history are keyed by it instead of the (translated) title, so they survive switching languages.
+	StableKey string
 	// Group results, Wox will group results by group name
 	Group string
 	// Score of the group, the higher the score, the more relevant the group is, more likely to be displayed on top
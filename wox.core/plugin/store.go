@@ -1017,6 +1017,7 @@ func (s *Store) uninstallLocked(ctx context.Context, plugin *Instance, skipClean
 				logger.Error(ctx, fmt.Sprintf("failed to delete plugin settings %s(%s): %s", plugin.Metadata.GetName(ctx), plugin.Metadata.Version, err.Error()))
 			}
 		}
+		setting.GetSettingManager().DeletePluginQueryHistory(ctx, plugin.Metadata.Id)
 	}
 
 	if !pluginAlreadyUnloaded {
@@ -8,6 +8,7 @@ import (
 	"time"
 	"wox/common"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/util/overlay"
 	"wox/util/selection"
@@ -65,6 +66,8 @@ func (a *aiCommandTestAPI) Notify(ctx context.Context, description string) {
 	default:
 	}
 }
+func (a *aiCommandTestAPI) ShowProgress(ctx context.Context, id string, title string, percent int) {}
+func (a *aiCommandTestAPI) CompleteProgress(ctx context.Context, id string, message string)        {}
 func (a *aiCommandTestAPI) PushAttention(ctx context.Context, request plugin.PushAttentionRequest) {}
 func (a *aiCommandTestAPI) Log(ctx context.Context, level plugin.LogLevel, msg string)             {}
 func (a *aiCommandTestAPI) GetTranslation(ctx context.Context, key string) string                  { return key }
@@ -72,8 +75,14 @@ func (a *aiCommandTestAPI) GetSetting(ctx context.Context, key string) string
 func (a *aiCommandTestAPI) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
 	a.settings[key] = value
 }
+func (a *aiCommandTestAPI) AddQueryHistory(ctx context.Context, query common.PlainQuery) {}
+func (a *aiCommandTestAPI) GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory {
+	return nil
+}
 func (a *aiCommandTestAPI) OnSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, value string)) {
 }
+func (a *aiCommandTestAPI) OnPluginSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, oldValue string, newValue string)) {
+}
 func (a *aiCommandTestAPI) OnGetDynamicSetting(ctx context.Context, callback func(ctx context.Context, key string) definition.PluginSettingDefinitionItem) {
 }
 func (a *aiCommandTestAPI) OnDeepLink(ctx context.Context, callback func(ctx context.Context, arguments map[string]string)) {
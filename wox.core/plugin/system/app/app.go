@@ -1659,7 +1659,7 @@ func (a *ApplicationPlugin) getReusableQueryMatches(ctx context.Context, query p
 }
 
 func shouldBypassPinyinQueryCache(ctx context.Context, previousSearch string, currentSearch string) bool {
-	if !setting.GetSettingManager().GetWoxSetting(ctx).UsePinYin.Get() {
+	if setting.GetSettingManager().GetWoxSetting(ctx).PinYinMatchMode.Get() == setting.PinYinMatchModeOff {
 		return false
 	}
 	if !isAsciiLetterSearch(previousSearch) || !isAsciiLetterSearch(currentSearch) {
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"wox/common"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/util"
 	"wox/util/fileicon"
@@ -34,6 +35,12 @@ func (e emptyAPIImpl) ShowApp(ctx context.Context) {
 func (e emptyAPIImpl) Notify(ctx context.Context, message string) {
 }
 
+func (e emptyAPIImpl) ShowProgress(ctx context.Context, id string, title string, percent int) {
+}
+
+func (e emptyAPIImpl) CompleteProgress(ctx context.Context, id string, message string) {
+}
+
 func (e emptyAPIImpl) PushAttention(ctx context.Context, request plugin.PushAttentionRequest) {
 }
 
@@ -51,9 +58,19 @@ func (e emptyAPIImpl) GetSetting(ctx context.Context, key string) string {
 func (e emptyAPIImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
 }
 
+func (e emptyAPIImpl) AddQueryHistory(ctx context.Context, query common.PlainQuery) {
+}
+
+func (e emptyAPIImpl) GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory {
+	return nil
+}
+
 func (e emptyAPIImpl) OnSettingChanged(ctx context.Context, callback func(context.Context, string, string)) {
 }
 
+func (e emptyAPIImpl) OnPluginSettingChanged(ctx context.Context, callback func(context.Context, string, string, string)) {
+}
+
 func (e emptyAPIImpl) OnDeepLink(ctx context.Context, callback func(context.Context, map[string]string)) {
 }
 
@@ -6,6 +6,7 @@ import (
 	"wox/common"
 	"wox/database"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 
 	"gorm.io/driver/sqlite"
@@ -23,6 +24,9 @@ func (a *attentionActionTestAPI) ChangeQuery(ctx context.Context, query common.P
 func (a *attentionActionTestAPI) HideApp(ctx context.Context)                    {}
 func (a *attentionActionTestAPI) ShowApp(ctx context.Context)                    {}
 func (a *attentionActionTestAPI) Notify(ctx context.Context, description string) {}
+func (a *attentionActionTestAPI) ShowProgress(ctx context.Context, id string, title string, percent int) {
+}
+func (a *attentionActionTestAPI) CompleteProgress(ctx context.Context, id string, message string) {}
 func (a *attentionActionTestAPI) PushAttention(ctx context.Context, request plugin.PushAttentionRequest) {
 }
 func (a *attentionActionTestAPI) Log(ctx context.Context, level plugin.LogLevel, msg string) {}
@@ -34,8 +38,14 @@ func (a *attentionActionTestAPI) GetSetting(ctx context.Context, key string) str
 }
 func (a *attentionActionTestAPI) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
 }
+func (a *attentionActionTestAPI) AddQueryHistory(ctx context.Context, query common.PlainQuery) {}
+func (a *attentionActionTestAPI) GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory {
+	return nil
+}
 func (a *attentionActionTestAPI) OnSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, value string)) {
 }
+func (a *attentionActionTestAPI) OnPluginSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, oldValue string, newValue string)) {
+}
 func (a *attentionActionTestAPI) OnGetDynamicSetting(ctx context.Context, callback func(ctx context.Context, key string) definition.PluginSettingDefinitionItem) {
 }
 func (a *attentionActionTestAPI) OnDeepLink(ctx context.Context, callback func(ctx context.Context, arguments map[string]string)) {
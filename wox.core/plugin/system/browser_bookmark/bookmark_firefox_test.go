@@ -8,6 +8,7 @@ import (
 	"testing"
 	"wox/common"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -72,11 +73,17 @@ func (m *mockAPI) Log(ctx context.Context, level plugin.LogLevel, msg string) {
 	}
 }
 func (m *mockAPI) Notify(ctx context.Context, msg string)                                 {}
+func (m *mockAPI) ShowProgress(ctx context.Context, id string, title string, percent int) {}
+func (m *mockAPI) CompleteProgress(ctx context.Context, id string, message string)        {}
 func (m *mockAPI) PushAttention(ctx context.Context, request plugin.PushAttentionRequest) {}
 func (m *mockAPI) GetTranslation(ctx context.Context, key string) string                  { return key }
 func (m *mockAPI) GetSetting(ctx context.Context, key string) string                      { return "" }
 func (m *mockAPI) SaveSetting(ctx context.Context, key string, value string, isGlobal bool) {
 }
+func (m *mockAPI) AddQueryHistory(ctx context.Context, query common.PlainQuery) {}
+func (m *mockAPI) GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory {
+	return nil
+}
 func (m *mockAPI) GetAllSettings(ctx context.Context) map[string]string     { return nil }
 func (m *mockAPI) OpenSettingDialog(ctx context.Context)                    {}
 func (m *mockAPI) HideApp(ctx context.Context)                              {}
@@ -87,6 +94,8 @@ func (m *mockAPI) ReloadPlugin(ctx context.Context, pluginId string)        {}
 func (m *mockAPI) RemovePlugin(ctx context.Context, pluginId string)        {}
 func (m *mockAPI) OnSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, value string)) {
 }
+func (m *mockAPI) OnPluginSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, oldValue string, newValue string)) {
+}
 func (m *mockAPI) OnGetDynamicSetting(
 	context.Context,
 	func(context.Context, string) definition.PluginSettingDefinitionItem,
@@ -10,6 +10,7 @@ import (
 	"wox/ai"
 	"wox/common"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/setting/validator"
 	"wox/util"
@@ -355,6 +356,19 @@ func (r *AIChatPlugin) GetDefaultModel(ctx context.Context) common.Model {
 			Name:          lastChat.Model.Name,
 			Provider:      lastChat.Model.Provider,
 			ProviderAlias: lastChat.Model.ProviderAlias,
+			ProviderId:    lastChat.Model.ProviderId,
+		}
+	}
+
+	// fall back to the first provider that pins a default model
+	for _, provider := range setting.GetSettingManager().GetWoxSetting(ctx).AIProviders.Get() {
+		if provider.DefaultModel != "" {
+			return common.Model{
+				Name:          provider.DefaultModel,
+				Provider:      provider.Name,
+				ProviderAlias: provider.Alias,
+				ProviderId:    provider.Id,
+			}
 		}
 	}
 
@@ -454,6 +468,7 @@ func (r *AIChatPlugin) loadAgents(ctx context.Context) ([]common.AIAgent, error)
 		modelName := gModel.Get("Name").String()
 		modelProvider := gModel.Get("Provider").String()
 		modelProviderAlias := gModel.Get("ProviderAlias").String()
+		modelProviderId := gModel.Get("ProviderId").String()
 
 		// Parse icon if available
 		var icon common.WoxImage
@@ -479,6 +494,7 @@ func (r *AIChatPlugin) loadAgents(ctx context.Context) ([]common.AIAgent, error)
 				Name:          modelName,
 				Provider:      common.ProviderName(modelProvider),
 				ProviderAlias: modelProviderAlias,
+				ProviderId:    modelProviderId,
 			},
 			Tools: lo.Map(agent.Get("tools").Array(), func(tool gjson.Result, _ int) string {
 				return tool.String()
@@ -2,6 +2,7 @@ package system
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/binary"
@@ -14,6 +15,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -44,6 +46,30 @@ var primaryActionValueCopy = "copy"
 var primaryActionValuePaste = "paste"
 var favoritesSettingKey = "favorites"
 
+// legacyHistorySettingKey held the whole clipboard history as JSON before the clipboard DB existed.
+var legacyHistorySettingKey = "history"
+
+// legacyDataMigratedSettingKey is set once the legacy history is migrated and the
+// images of old favorites are relocated, so later starts skip both, see migrateLegacyData.
+var legacyDataMigratedSettingKey = "legacy_data_migrated"
+
+// LegacyHistoryKeepCount is how many recent non-favorite entries of the legacy history
+// are imported into the clipboard DB, see migrateLegacyHistory. The import runs once,
+// during the first start of a version that has the clipboard DB and before the settings
+// UI is reachable, so it is not a plugin setting; set it before the plugin is
+// initialized to keep more. Zero keeps the historical favorites-only behavior.
+var LegacyHistoryKeepCount = 0
+
+// Favorites are stored in the plugin settings, so huge legacy text favorites are
+// not migrated as they are: text longer than legacyFavoriteTruncateBytes is
+// truncated and marked with IsTruncated, text longer than
+// legacyFavoriteMaxTextBytes is not migrated at all. These are fixed limits of
+// the one-time import, not settings.
+const (
+	legacyFavoriteTruncateBytes = 64 * 1024
+	legacyFavoriteMaxTextBytes  = 1024 * 1024
+)
+
 const (
 	clipboardTypeRefinementKey   = "clipboard_type"
 	clipboardTypeRefinementAll   = "all"
@@ -82,6 +108,8 @@ type FavoriteClipboardItem struct {
 	OCRText   *string  `json:"ocrText,omitempty"`
 	Timestamp int64    `json:"timestamp"`
 	CreatedAt int64    `json:"createdAt"`
+	// IsTruncated marks text cut to legacyFavoriteTruncateBytes while migrating legacy history.
+	IsTruncated bool `json:"isTruncated,omitempty"`
 }
 
 // ClipboardDBInterface defines the interface for clipboard database operations
@@ -95,6 +123,7 @@ type ClipboardDBInterface interface {
 	Delete(ctx context.Context, id string) error
 	GetRecent(ctx context.Context, limit, offset int) ([]ClipboardRecord, error)
 	GetRecentByType(ctx context.Context, recordType string, limit, offset int) ([]ClipboardRecord, error)
+	GetFavorites(ctx context.Context) ([]ClipboardRecord, error)
 	SearchText(ctx context.Context, searchTerm string, limit int) ([]ClipboardRecord, error)
 	SearchByType(ctx context.Context, searchTerm string, recordType string, limit int) ([]ClipboardRecord, error)
 	GetByID(ctx context.Context, id string) (*ClipboardRecord, error)
@@ -223,8 +252,10 @@ func (c *ClipboardPlugin) Init(ctx context.Context, initParams plugin.InitParams
 	}
 	c.db = db
 
-	// Migration is now handled by the central migrator during app startup
-	// No need for plugin-specific migration code here
+	// Settings are migrated by the central migrator during app startup. The legacy
+	// history spans the clipboard DB, the plugin settings and the image cache, which
+	// only this plugin knows, so it is migrated here, once.
+	c.migrateLegacyData(ctx)
 
 	// Register unload callback to close database connection
 	c.api.OnUnload(ctx, func(callbackCtx context.Context) {
@@ -2110,8 +2141,344 @@ func (c *ClipboardPlugin) saveFavoriteItems(ctx context.Context, favorites []Fav
 	return nil
 }
 
+// migrateLegacyData migrates the legacy history and relocates the images of old favorites
+// unless an earlier start finished that already, see legacyDataMigratedSettingKey.
+func (c *ClipboardPlugin) migrateLegacyData(ctx context.Context) {
+	if c.api.GetSetting(ctx, legacyDataMigratedSettingKey) == "true" {
+		return
+	}
+
+	migrateCtx, migrateDone := util.WithShutdown(ctx)
+	migrated := c.migrateLegacyHistory(migrateCtx, LegacyHistoryKeepCount)
+	migrateDone()
+	if !migrated {
+		return
+	}
+	c.relocateFavoriteImages(ctx)
+	c.api.SaveSetting(ctx, legacyDataMigratedSettingKey, "true", false)
+}
+
+// migrateLegacyHistory imports the JSON history setting written by old versions, together with
+// the favorites older clipboard DBs kept as flagged rows. Favorites are always kept; the most
+// recent keepRecentCount non-favorite entries are imported into the clipboard DB and the rest is
+// dropped. The legacy setting is cleared afterwards. When ctx is cancelled the setting is kept,
+// so the next start migrates it again. It reports whether the migration finished, which is also
+// the case when there was nothing to migrate.
+func (c *ClipboardPlugin) migrateLegacyHistory(ctx context.Context, keepRecentCount int) bool {
+	dbFavorites, dbErr := c.db.GetFavorites(ctx)
+	if dbErr != nil {
+		c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to load clipboard favorites from database: %s", dbErr.Error()))
+	}
+
+	historyJson := c.api.GetSetting(ctx, legacyHistorySettingKey)
+	if historyJson == "" && len(dbFavorites) == 0 {
+		return dbErr == nil
+	}
+
+	var histories []ClipboardHistory
+	if historyJson != "" {
+		if err := json.Unmarshal([]byte(historyJson), &histories); err != nil {
+			// Keep the setting so a later version can still read it.
+			c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to parse legacy clipboard history: %s", err.Error()))
+			return false
+		}
+	}
+
+	// Newest first, so the kept non-favorites are the most recent ones.
+	slices.SortFunc(histories, func(a, b ClipboardHistory) int {
+		return cmp.Compare(b.Timestamp, a.Timestamp)
+	})
+
+	var allFavoritesToMigrate []FavoriteClipboardItem
+	legacyFavoriteCount := 0
+	historyCount := 0
+	skippedFavoriteCount := 0
+	for _, history := range histories {
+		if ctx.Err() != nil {
+			c.api.Log(ctx, plugin.LogLevelWarning, "legacy clipboard history migration interrupted, it will be retried on next start")
+			return false
+		}
+		record := ClipboardRecord{
+			ID:         history.ID,
+			Type:       history.Type,
+			Content:    history.Text,
+			FilePath:   history.ImagePath,
+			Timestamp:  history.Timestamp,
+			IsFavorite: history.IsFavorite,
+			CreatedAt:  time.UnixMilli(history.Timestamp),
+		}
+		if record.ID == "" {
+			record.ID = uuid.NewString()
+		}
+
+		if history.IsFavorite {
+			favoriteItem, ok := c.sanitizeLegacyFavorite(ctx, record)
+			if !ok {
+				skippedFavoriteCount++
+				continue
+			}
+			allFavoritesToMigrate = append(allFavoritesToMigrate, favoriteItem)
+			legacyFavoriteCount++
+			continue
+		}
+
+		if historyCount >= keepRecentCount {
+			continue
+		}
+		if err := c.db.Insert(ctx, record); err != nil {
+			c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to migrate legacy clipboard entry %s: %s", record.ID, err.Error()))
+			continue
+		}
+		historyCount++
+	}
+	for _, record := range dbFavorites {
+		allFavoritesToMigrate = append(allFavoritesToMigrate, newFavoriteClipboardItem(record))
+	}
+
+	// The same favorite can be in the legacy JSON and in the database.
+	allFavoritesToMigrate, duplicateCount := dedupeFavoritesToMigrate(allFavoritesToMigrate)
+	favoriteCount, err := c.addFavoriteItems(ctx, allFavoritesToMigrate)
+	if err != nil {
+		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to migrate legacy clipboard favorites: %s", err.Error()))
+		return false
+	}
+
+	// The favorites are in the settings now, so the flagged rows would only show up twice.
+	for _, record := range dbFavorites {
+		if ctx.Err() != nil {
+			c.api.Log(ctx, plugin.LogLevelWarning, "legacy clipboard history migration interrupted, it will be retried on next start")
+			return false
+		}
+		if err := c.db.Delete(ctx, record.ID); err != nil {
+			c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("failed to remove migrated clipboard favorite %s from database: %s", record.ID, err.Error()))
+		}
+	}
+
+	if historyJson != "" {
+		c.api.SaveSetting(ctx, legacyHistorySettingKey, "", false)
+	}
+	c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("migrated legacy clipboard history: favorites=%d, duplicate favorites=%d, skipped favorites=%d, history=%d, dropped=%d", favoriteCount, duplicateCount, skippedFavoriteCount, historyCount, len(histories)-legacyFavoriteCount-skippedFavoriteCount-historyCount))
+	return dbErr == nil
+}
+
+// dedupeFavoritesToMigrate collapses favorites with the same ID, then favorites with the same
+// content under different IDs. Of each group the entry with the richest metadata is kept, see
+// favoriteMetadataScore, at the position of the first one, and gains the metadata only the
+// others had, see mergeFavoriteMetadata. It returns the kept favorites and how many were
+// collapsed.
+func dedupeFavoritesToMigrate(favorites []FavoriteClipboardItem) ([]FavoriteClipboardItem, int) {
+	var deduped []FavoriteClipboardItem
+	indexByID := map[string]int{}
+	indexByContent := map[string]int{}
+	for _, favorite := range favorites {
+		contentKey := favoriteContentKey(favorite)
+		index, found := indexByID[favorite.ID]
+		if !found {
+			index, found = indexByContent[contentKey]
+		}
+		if !found {
+			indexByID[favorite.ID] = len(deduped)
+			indexByContent[contentKey] = len(deduped)
+			deduped = append(deduped, favorite)
+			continue
+		}
+
+		kept, other := deduped[index], favorite
+		if isRicherFavorite(favorite, kept) {
+			kept, other = favorite, kept
+		}
+		mergeFavoriteMetadata(&kept, other)
+		deduped[index] = kept
+		indexByID[favorite.ID] = index
+		indexByContent[contentKey] = index
+		indexByContent[favoriteContentKey(kept)] = index
+	}
+	return deduped, len(favorites) - len(deduped)
+}
+
+// mergeFavoriteMetadata fills the optional fields kept lacks from other, a copy of the same
+// favorite, so collapsing the two does not lose e.g. the alias a user gave only one of them.
+// It reports whether kept changed.
+func mergeFavoriteMetadata(kept *FavoriteClipboardItem, other FavoriteClipboardItem) bool {
+	changed := false
+	fillString := func(target **string, value *string) {
+		if (*target == nil || **target == "") && value != nil && *value != "" {
+			*target = value
+			changed = true
+		}
+	}
+	fillString(&kept.Alias, other.Alias)
+	fillString(&kept.OCRText, other.OCRText)
+	fillString(&kept.ImageHash, other.ImageHash)
+	fillString(&kept.IconData, other.IconData)
+	if kept.Width == nil && other.Width != nil {
+		kept.Width = other.Width
+		changed = true
+	}
+	if kept.Height == nil && other.Height != nil {
+		kept.Height = other.Height
+		changed = true
+	}
+	if kept.FileSize == nil && other.FileSize != nil {
+		kept.FileSize = other.FileSize
+		changed = true
+	}
+	return changed
+}
+
+// favoriteContentKey identifies the content of a favorite: the image hash for images when
+// known, the paths for files and the text otherwise.
+func favoriteContentKey(favorite FavoriteClipboardItem) string {
+	content := favorite.Content
+	switch {
+	case favorite.ImageHash != nil && *favorite.ImageHash != "":
+		content = *favorite.ImageHash
+	case len(favorite.FilePaths) > 0:
+		content = strings.Join(favorite.FilePaths, "\n")
+	case favorite.Type == string(clipboard.ClipboardTypeImage):
+		content = favorite.FilePath
+	}
+	sum := sha256.Sum256([]byte(favorite.Type + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// isRicherFavorite reports whether candidate should replace current when both are the same
+// favorite: untruncated text wins, then more metadata, then the newer entry.
+func isRicherFavorite(candidate FavoriteClipboardItem, current FavoriteClipboardItem) bool {
+	if candidate.IsTruncated != current.IsTruncated {
+		return !candidate.IsTruncated
+	}
+	if candidateScore, currentScore := favoriteMetadataScore(candidate), favoriteMetadataScore(current); candidateScore != currentScore {
+		return candidateScore > currentScore
+	}
+	return candidate.Timestamp > current.Timestamp
+}
+
+// favoriteMetadataScore counts the optional fields a favorite has filled in.
+func favoriteMetadataScore(favorite FavoriteClipboardItem) int {
+	score := 0
+	for _, filled := range []bool{
+		favorite.Alias != nil && *favorite.Alias != "",
+		favorite.OCRText != nil && *favorite.OCRText != "",
+		favorite.ImageHash != nil && *favorite.ImageHash != "",
+		favorite.IconData != nil && *favorite.IconData != "",
+		favorite.Width != nil,
+		favorite.Height != nil,
+		favorite.FileSize != nil,
+		favorite.FilePath != "",
+		len(favorite.FilePaths) > 0,
+	} {
+		if filled {
+			score++
+		}
+	}
+	return score
+}
+
+// sanitizeLegacyFavorite converts a legacy favorite and applies the text size
+// limits, see legacyFavoriteMaxTextBytes. It returns false when the favorite
+// must be skipped.
+func (c *ClipboardPlugin) sanitizeLegacyFavorite(ctx context.Context, record ClipboardRecord) (FavoriteClipboardItem, bool) {
+	favoriteItem := newFavoriteClipboardItem(record)
+	if favoriteItem.Type != string(clipboard.ClipboardTypeText) || len(favoriteItem.Content) <= legacyFavoriteTruncateBytes {
+		return favoriteItem, true
+	}
+
+	if len(favoriteItem.Content) > legacyFavoriteMaxTextBytes {
+		c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("skip migrating legacy clipboard favorite %s: text is %d bytes, more than the limit of %d bytes", record.ID, len(favoriteItem.Content), legacyFavoriteMaxTextBytes))
+		return FavoriteClipboardItem{}, false
+	}
+
+	originalSize := len(favoriteItem.Content)
+	favoriteItem.Content = truncateUTF8(favoriteItem.Content, legacyFavoriteTruncateBytes)
+	favoriteItem.IsTruncated = true
+	c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("truncated legacy clipboard favorite %s from %d to %d bytes while migrating", record.ID, originalSize, len(favoriteItem.Content)))
+	return favoriteItem, true
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a character.
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// getFavoriteImagesDirectory returns where favorite images are kept. Images of normal
+// history records live in the image cache, which both the cache expiry and the orphan
+// cleanup (favorites are not in the clipboard DB) would delete.
+func (c *ClipboardPlugin) getFavoriteImagesDirectory() string {
+	return path.Join(util.GetLocation().GetPluginSettingDirectory(), "clipboard_images")
+}
+
+// relocateFavoriteImage copies the image of a favorite into the favorite images directory
+// and rewrites its FilePath. It returns false if nothing changed, e.g. the source is missing.
+func (c *ClipboardPlugin) relocateFavoriteImage(ctx context.Context, item *FavoriteClipboardItem) bool {
+	if item.Type != string(clipboard.ClipboardTypeImage) || item.FilePath == "" {
+		return false
+	}
+
+	imagesDir := c.getFavoriteImagesDirectory()
+	if filepath.Dir(filepath.Clean(item.FilePath)) == filepath.Clean(imagesDir) {
+		return false
+	}
+
+	content, readErr := os.ReadFile(item.FilePath)
+	if readErr != nil {
+		c.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("skip relocating favorite image %s: %s", item.ID, readErr.Error()))
+		return false
+	}
+	if err := util.GetLocation().EnsureDirectoryExist(imagesDir); err != nil {
+		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to create favorite images directory: %s", err.Error()))
+		return false
+	}
+
+	targetPath := path.Join(imagesDir, filepath.Base(item.FilePath))
+	if err := util.WriteFileAtomic(targetPath, content, 0644); err != nil {
+		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to copy favorite image %s: %s", item.ID, err.Error()))
+		return false
+	}
+
+	// The cache copy is left to the regular cache cleanup.
+	item.FilePath = targetPath
+	return true
+}
+
+// relocateFavoriteImages moves images of favorites saved by older versions out of the image cache.
+func (c *ClipboardPlugin) relocateFavoriteImages(ctx context.Context) {
+	favorites, err := c.getFavoriteItems(ctx)
+	if err != nil {
+		return
+	}
+
+	relocatedCount := 0
+	for i := range favorites {
+		if c.relocateFavoriteImage(ctx, &favorites[i]) {
+			relocatedCount++
+		}
+	}
+	if relocatedCount == 0 {
+		return
+	}
+
+	if err := c.saveFavoriteItems(ctx, favorites); err != nil {
+		c.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("failed to save relocated favorites: %s", err.Error()))
+		return
+	}
+	c.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("relocated %d favorite images to %s", relocatedCount, c.getFavoriteImagesDirectory()))
+}
+
 // addToFavorites adds an item to favorites settings
 func (c *ClipboardPlugin) addToFavorites(ctx context.Context, record ClipboardRecord) error {
+	return c.addFavoriteItem(ctx, newFavoriteClipboardItem(record))
+}
+
+// addFavoriteItem stores a favorite unless one with the same id exists.
+func (c *ClipboardPlugin) addFavoriteItem(ctx context.Context, favoriteItem FavoriteClipboardItem) error {
 	favorites, err := c.getFavoriteItems(ctx)
 	if err != nil {
 		return err
@@ -2119,13 +2486,63 @@ func (c *ClipboardPlugin) addToFavorites(ctx context.Context, record ClipboardRe
 
 	// Check if already exists
 	for _, fav := range favorites {
-		if fav.ID == record.ID {
+		if fav.ID == favoriteItem.ID {
 			return nil // Already exists
 		}
 	}
 
-	// Convert ClipboardRecord to FavoriteClipboardItem
-	favoriteItem := FavoriteClipboardItem{
+	c.relocateFavoriteImage(ctx, &favoriteItem)
+
+	favorites = append(favorites, favoriteItem)
+	return c.saveFavoriteItems(ctx, favorites)
+}
+
+// addFavoriteItems stores the favorites that are not stored yet with a single save and returns
+// how many were added. A favorite already stored under the same id or with the same content is
+// not added again, the stored one only gains the metadata it lacks, see mergeFavoriteMetadata.
+func (c *ClipboardPlugin) addFavoriteItems(ctx context.Context, favoriteItems []FavoriteClipboardItem) (int, error) {
+	favorites, err := c.getFavoriteItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	indexByID := map[string]int{}
+	indexByContent := map[string]int{}
+	for index, fav := range favorites {
+		indexByID[fav.ID] = index
+		indexByContent[favoriteContentKey(fav)] = index
+	}
+
+	added := 0
+	changed := false
+	for _, favoriteItem := range favoriteItems {
+		index, found := indexByID[favoriteItem.ID]
+		if !found {
+			index, found = indexByContent[favoriteContentKey(favoriteItem)]
+		}
+		if found {
+			if mergeFavoriteMetadata(&favorites[index], favoriteItem) {
+				changed = true
+			}
+			continue
+		}
+
+		c.relocateFavoriteImage(ctx, &favoriteItem)
+		indexByID[favoriteItem.ID] = len(favorites)
+		indexByContent[favoriteContentKey(favoriteItem)] = len(favorites)
+		favorites = append(favorites, favoriteItem)
+		added++
+		changed = true
+	}
+	if !changed {
+		return 0, nil
+	}
+	return added, c.saveFavoriteItems(ctx, favorites)
+}
+
+// newFavoriteClipboardItem converts a ClipboardRecord to a FavoriteClipboardItem.
+func newFavoriteClipboardItem(record ClipboardRecord) FavoriteClipboardItem {
+	return FavoriteClipboardItem{
 		ID:        record.ID,
 		Type:      record.Type,
 		Content:   record.Content,
@@ -2141,9 +2558,6 @@ func (c *ClipboardPlugin) addToFavorites(ctx context.Context, record ClipboardRe
 		Timestamp: record.Timestamp,
 		CreatedAt: record.CreatedAt.Unix(),
 	}
-
-	favorites = append(favorites, favoriteItem)
-	return c.saveFavoriteItems(ctx, favorites)
 }
 
 // removeFromFavorites removes an item from favorites settings
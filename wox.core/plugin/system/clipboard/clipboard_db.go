@@ -246,6 +246,25 @@ func (c *ClipboardDB) GetRecent(ctx context.Context, limit, offset int) ([]Clipb
 	return c.scanRecords(rows)
 }
 
+// GetFavorites retrieves the records flagged as favorite. Favorites live in the
+// plugin settings now, so only databases written by older versions have any.
+func (c *ClipboardDB) GetFavorites(ctx context.Context) ([]ClipboardRecord, error) {
+	querySQL := `
+	SELECT id, type, content, file_path, file_paths, image_hash, icon_data, width, height, file_size, alias, ocr_text, timestamp, is_favorite, created_at
+	FROM clipboard_history
+	WHERE is_favorite = TRUE
+	ORDER BY timestamp DESC
+	`
+
+	rows, err := c.db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return c.scanRecords(rows)
+}
+
 // GetRecentByType retrieves recent clipboard records for one content type.
 func (c *ClipboardDB) GetRecentByType(ctx context.Context, recordType string, limit, offset int) ([]ClipboardRecord, error) {
 	querySQL := `
@@ -0,0 +1,202 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+	"wox/plugin"
+	"wox/util"
+	"wox/util/clipboard"
+)
+
+// migrationTestAPI keeps plugin settings in memory. Methods the migration does
+// not call are left to the embedded nil API.
+type migrationTestAPI struct {
+	plugin.API
+	settings map[string]string
+}
+
+func (a *migrationTestAPI) GetSetting(ctx context.Context, key string) string {
+	return a.settings[key]
+}
+
+func (a *migrationTestAPI) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
+	a.settings[key] = value
+}
+
+func (a *migrationTestAPI) Log(ctx context.Context, level plugin.LogLevel, msg string) {}
+
+// newMigrationTestPlugin returns a clipboard plugin with a clipboard DB in a temp dir.
+func newMigrationTestPlugin(t *testing.T, settings map[string]string) (*ClipboardPlugin, *ClipboardDB) {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv(util.TestWoxDataDirEnv, filepath.Join(root, "wox"))
+	t.Setenv(util.TestUserDataDirEnv, filepath.Join(root, "user"))
+	if err := util.GetLocation().Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+
+	db, err := NewClipboardDB(context.Background(), "clipboard-migration-test")
+	if err != nil {
+		t.Fatalf("failed to open clipboard db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &ClipboardPlugin{api: &migrationTestAPI{settings: settings}, db: db}, db
+}
+
+func TestMigrateLegacyHistoryDedupesAgainstStoredFavoritesAndKeepsAliases(t *testing.T) {
+	ctx := context.Background()
+	storedFavorites, _ := json.Marshal([]FavoriteClipboardItem{
+		{ID: "stored-1", Type: string(clipboard.ClipboardTypeText), Content: "hello world", Timestamp: 1500000000000},
+	})
+	legacyHistory, _ := json.Marshal([]ClipboardHistory{
+		{ID: "legacy-1", Type: string(clipboard.ClipboardTypeText), Text: "hello world", Timestamp: 1700000000000, IsFavorite: true},
+		{ID: "legacy-2", Type: string(clipboard.ClipboardTypeText), Text: "new favorite", Timestamp: 1700000000001, IsFavorite: true},
+		{ID: "legacy-3", Type: string(clipboard.ClipboardTypeText), Text: "not a favorite", Timestamp: 1700000000002},
+	})
+	settings := map[string]string{
+		favoritesSettingKey:     string(storedFavorites),
+		legacyHistorySettingKey: string(legacyHistory),
+	}
+	c, db := newMigrationTestPlugin(t, settings)
+
+	alias := "greeting"
+	if err := db.Insert(ctx, ClipboardRecord{
+		ID:         "db-1",
+		Type:       string(clipboard.ClipboardTypeText),
+		Content:    "hello world",
+		Alias:      &alias,
+		Timestamp:  1600000000000,
+		IsFavorite: true,
+		CreatedAt:  time.UnixMilli(1600000000000),
+	}); err != nil {
+		t.Fatalf("failed to insert favorite row: %v", err)
+	}
+
+	c.migrateLegacyHistory(ctx, 0)
+
+	favorites, err := c.getFavoriteItems(ctx)
+	if err != nil {
+		t.Fatalf("failed to read favorites: %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Fatalf("expected the stored favorite and one new favorite, got %+v", favorites)
+	}
+	if favorites[0].ID != "stored-1" || favorites[0].Alias == nil || *favorites[0].Alias != alias {
+		t.Fatalf("expected the stored favorite to gain the alias of its database copy, got %+v", favorites[0])
+	}
+	if favorites[1].ID != "legacy-2" {
+		t.Fatalf("expected the new legacy favorite to be added, got %+v", favorites[1])
+	}
+
+	if rows, err := db.GetFavorites(ctx); err != nil || len(rows) != 0 {
+		t.Fatalf("expected the migrated favorite rows to be removed, got %d (%v)", len(rows), err)
+	}
+	if settings[legacyHistorySettingKey] != "" {
+		t.Fatalf("expected the legacy history setting to be cleared")
+	}
+
+	// A second run finds nothing to migrate and leaves the favorites alone.
+	c.migrateLegacyHistory(ctx, 0)
+	if again, _ := c.getFavoriteItems(ctx); len(again) != 2 {
+		t.Fatalf("expected a second migration to add nothing, got %+v", again)
+	}
+}
+
+func TestMigrateLegacyDataRunsOnce(t *testing.T) {
+	ctx := context.Background()
+	legacyHistory, _ := json.Marshal([]ClipboardHistory{
+		{ID: "legacy-1", Type: string(clipboard.ClipboardTypeText), Text: "favorite", Timestamp: 1700000000000, IsFavorite: true},
+	})
+	settings := map[string]string{legacyHistorySettingKey: string(legacyHistory)}
+	c, _ := newMigrationTestPlugin(t, settings)
+
+	c.migrateLegacyData(ctx)
+	if settings[legacyDataMigratedSettingKey] != "true" || settings[legacyHistorySettingKey] != "" {
+		t.Fatalf("expected the legacy history to be migrated and the migration recorded, got %v", settings)
+	}
+
+	// Later starts skip the migration, even when a legacy setting shows up again.
+	settings[legacyHistorySettingKey] = string(legacyHistory)
+	c.migrateLegacyData(ctx)
+	if settings[legacyHistorySettingKey] == "" {
+		t.Fatalf("expected a finished migration not to run again")
+	}
+}
+
+func TestMigrateLegacyDataRetriesUnreadableHistory(t *testing.T) {
+	settings := map[string]string{legacyHistorySettingKey: "not json"}
+	c, _ := newMigrationTestPlugin(t, settings)
+
+	c.migrateLegacyData(context.Background())
+	if settings[legacyDataMigratedSettingKey] != "" || settings[legacyHistorySettingKey] != "not json" {
+		t.Fatalf("expected an unreadable legacy history to be kept for the next start, got %v", settings)
+	}
+}
+
+func TestDedupeFavoritesToMigrateCollapsesFavoriteInBothSources(t *testing.T) {
+	alias := "greeting"
+	legacyFavorite := newFavoriteClipboardItem(ClipboardRecord{
+		ID:        "favorite-1",
+		Type:      string(clipboard.ClipboardTypeText),
+		Content:   "hello world",
+		Timestamp: 1700000000000,
+		CreatedAt: time.UnixMilli(1700000000000),
+	})
+	dbFavorite := newFavoriteClipboardItem(ClipboardRecord{
+		ID:        "favorite-1",
+		Type:      string(clipboard.ClipboardTypeText),
+		Content:   "hello world",
+		Alias:     &alias,
+		Timestamp: 1600000000000,
+		CreatedAt: time.UnixMilli(1600000000000),
+	})
+	// Same text saved under another id, e.g. favorited again after an older migration.
+	sameContentFavorite := newFavoriteClipboardItem(ClipboardRecord{
+		ID:        "favorite-2",
+		Type:      string(clipboard.ClipboardTypeText),
+		Content:   "hello world",
+		Timestamp: 1800000000000,
+		CreatedAt: time.UnixMilli(1800000000000),
+	})
+	otherFavorite := newFavoriteClipboardItem(ClipboardRecord{
+		ID:        "favorite-3",
+		Type:      string(clipboard.ClipboardTypeText),
+		Content:   "something else",
+		Timestamp: 1700000000000,
+		CreatedAt: time.UnixMilli(1700000000000),
+	})
+
+	deduped, duplicateCount := dedupeFavoritesToMigrate([]FavoriteClipboardItem{legacyFavorite, otherFavorite, dbFavorite, sameContentFavorite})
+	if duplicateCount != 2 {
+		t.Fatalf("expected 2 collapsed duplicates, got %d", duplicateCount)
+	}
+	if len(deduped) != 2 {
+		t.Fatalf("expected 2 favorites, got %d: %+v", len(deduped), deduped)
+	}
+	if deduped[0].ID != "favorite-1" || deduped[0].Alias == nil || *deduped[0].Alias != alias {
+		t.Fatalf("expected the database favorite with its alias to be kept, got %+v", deduped[0])
+	}
+	if deduped[1].ID != "favorite-3" {
+		t.Fatalf("expected the unrelated favorite to be kept, got %+v", deduped[1])
+	}
+}
+
+func TestDedupeFavoritesToMigratePrefersUntruncatedText(t *testing.T) {
+	alias := "greeting"
+	truncated := FavoriteClipboardItem{ID: "favorite-1", Type: string(clipboard.ClipboardTypeText), Content: "hello", Alias: &alias, IsTruncated: true, Timestamp: 2}
+	full := FavoriteClipboardItem{ID: "favorite-1", Type: string(clipboard.ClipboardTypeText), Content: "hello world", Timestamp: 1}
+
+	deduped, duplicateCount := dedupeFavoritesToMigrate([]FavoriteClipboardItem{truncated, full})
+	if duplicateCount != 1 || len(deduped) != 1 {
+		t.Fatalf("expected one favorite after collapsing one duplicate, got %d favorites and %d duplicates", len(deduped), duplicateCount)
+	}
+	if deduped[0].IsTruncated || deduped[0].Content != "hello world" {
+		t.Fatalf("expected the untruncated favorite to be kept, got %+v", deduped[0])
+	}
+	if deduped[0].Alias == nil || *deduped[0].Alias != alias {
+		t.Fatalf("expected the alias of the collapsed favorite to be kept, got %+v", deduped[0])
+	}
+}
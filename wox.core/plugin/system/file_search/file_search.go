@@ -272,7 +272,7 @@ func (c *FileSearchPlugin) Query(ctx context.Context, query plugin.Query) plugin
 	}
 
 	searchStartedAt := util.GetSystemTimestamp()
-	usePinyin := setting.GetSettingManager().GetWoxSetting(ctx).UsePinYin.Get()
+	usePinyin := setting.GetSettingManager().GetWoxSetting(ctx).PinYinMatchMode.Get() != setting.PinYinMatchModeOff
 	// File search uses its own indexed engine instead of plugin.IsStringMatch,
 	// so the global pinyin option must be passed explicitly. Without this bridge,
 	// disabling pinyin in Wox settings still allowed pinyin-derived candidates
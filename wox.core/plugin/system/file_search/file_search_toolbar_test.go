@@ -6,6 +6,7 @@ import (
 	"testing"
 	"wox/common"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/util/filesearch"
 )
@@ -16,6 +17,10 @@ func (a fileSearchToolbarTestAPI) ChangeQuery(ctx context.Context, query common.
 func (a fileSearchToolbarTestAPI) HideApp(ctx context.Context)                              {}
 func (a fileSearchToolbarTestAPI) ShowApp(ctx context.Context)                              {}
 func (a fileSearchToolbarTestAPI) Notify(ctx context.Context, description string)           {}
+func (a fileSearchToolbarTestAPI) ShowProgress(ctx context.Context, id string, title string, percent int) {
+}
+func (a fileSearchToolbarTestAPI) CompleteProgress(ctx context.Context, id string, message string) {
+}
 func (a fileSearchToolbarTestAPI) PushAttention(ctx context.Context, request plugin.PushAttentionRequest) {
 }
 func (a fileSearchToolbarTestAPI) Log(ctx context.Context, level plugin.LogLevel, msg string) {
@@ -33,8 +38,14 @@ func (a fileSearchToolbarTestAPI) GetTranslation(ctx context.Context, key string
 func (a fileSearchToolbarTestAPI) GetSetting(ctx context.Context, key string) string { return "" }
 func (a fileSearchToolbarTestAPI) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
 }
+func (a fileSearchToolbarTestAPI) AddQueryHistory(ctx context.Context, query common.PlainQuery) {}
+func (a fileSearchToolbarTestAPI) GetQueryHistory(ctx context.Context, limit int) []setting.QueryHistory {
+	return nil
+}
 func (a fileSearchToolbarTestAPI) OnSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, value string)) {
 }
+func (a fileSearchToolbarTestAPI) OnPluginSettingChanged(ctx context.Context, callback func(ctx context.Context, key string, oldValue string, newValue string)) {
+}
 func (a fileSearchToolbarTestAPI) OnGetDynamicSetting(ctx context.Context, callback func(ctx context.Context, key string) definition.PluginSettingDefinitionItem) {
 }
 func (a fileSearchToolbarTestAPI) OnDeepLink(ctx context.Context, callback func(ctx context.Context, arguments map[string]string)) {
@@ -2,7 +2,6 @@ package system
 
 import (
 	"context"
-	"strings"
 	"wox/common"
 	"wox/plugin"
 	"wox/setting"
@@ -53,33 +52,21 @@ func (i *QueryHistoryPlugin) Init(ctx context.Context, initParams plugin.InitPar
 
 func (i *QueryHistoryPlugin) Query(ctx context.Context, query plugin.Query) plugin.QueryResponse {
 	var results []plugin.QueryResult
-	queryHistories := setting.GetSettingManager().GetWoxSetting(ctx).QueryHistories.Get()
-
-	maxResultCount := 0
-	for k := len(queryHistories) - 1; k >= 0; k-- {
-		var history = queryHistories[k]
-
-		if strings.Contains(history.Query.String(), query.Search) {
-			results = append(results, plugin.QueryResult{
-				Title:    history.Query.String(),
-				SubTitle: util.FormatTimestamp(history.Timestamp),
-				Icon:     queryHistoryIcon,
-				Actions: []plugin.QueryResultAction{
-					{
-						Name:                   "i18n:plugin_query_history_use",
-						PreventHideAfterAction: true,
-						Action: func(ctx context.Context, actionContext plugin.ActionContext) {
-							i.api.ChangeQuery(ctx, history.Query)
-						},
+	for _, history := range setting.GetSettingManager().SearchQueryHistory(ctx, query.Search, 20) {
+		results = append(results, plugin.QueryResult{
+			Title:    history.Query.String(),
+			SubTitle: util.FormatTimestamp(history.Timestamp),
+			Icon:     queryHistoryIcon,
+			Actions: []plugin.QueryResultAction{
+				{
+					Name:                   "i18n:plugin_query_history_use",
+					PreventHideAfterAction: true,
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						i.api.ChangeQuery(ctx, history.Query)
 					},
 				},
-			})
-
-			maxResultCount++
-			if maxResultCount >= 20 {
-				break
-			}
-		}
+			},
+		})
 	}
 
 	return plugin.NewQueryResponse(results)
@@ -71,7 +71,7 @@ func (p *UpdatePlugin) Query(ctx context.Context, query plugin.Query) plugin.Que
 	autoUpdateEnabled := true
 	releaseChannel := string(setting.ReleaseChannelStable)
 	if woxSetting := setting.GetSettingManager().GetWoxSetting(ctx); woxSetting != nil {
-		autoUpdateEnabled = woxSetting.EnableAutoUpdate.Get()
+		autoUpdateEnabled = woxSetting.IsAutoUpdateEnabled()
 		releaseChannel = string(setting.NormalizeReleaseChannel(string(woxSetting.ReleaseChannel.Get())))
 	}
 	channelVersions := p.getActionChannelVersions(ctx)
@@ -130,7 +130,8 @@ func (p *UpdatePlugin) getActionChannelVersions(ctx context.Context) []updater.U
 func (p *UpdatePlugin) buildActions(ctx context.Context, info updater.UpdateInfo, autoUpdateEnabled bool, releaseChannel string, channelVersions []updater.UpdateChannelVersion) []plugin.QueryResultAction {
 	actions := []plugin.QueryResultAction{}
 
-	if !autoUpdateEnabled {
+	// The enable action would only fail while a machine policy locks auto update.
+	if !autoUpdateEnabled && setting.CheckAutoUpdatePolicy() == nil {
 		actions = append(actions,
 			plugin.QueryResultAction{
 				Name:                   "i18n:plugin_update_action_enable_auto_update",
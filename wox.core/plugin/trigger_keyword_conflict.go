@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"wox/common"
@@ -103,6 +104,46 @@ func (m *Manager) findTriggerKeywordConflicts(targetKeyword string) []TriggerKey
 	return conflicts
 }
 
+// SetPluginTriggerKeywords saves user defined trigger keywords for a plugin,
+// replacing the ones declared in plugin.json. An empty list restores the
+// declared keywords. Keywords already used by another enabled plugin are
+// rejected with a TriggerKeywordConflictError, because query dispatch would
+// otherwise block them as ambiguous.
+func (m *Manager) SetPluginTriggerKeywords(ctx context.Context, pluginId string, keywords []string) error {
+	pluginInstance := m.GetPluginInstanceById(pluginId)
+	if pluginInstance == nil || pluginInstance.Setting == nil {
+		return fmt.Errorf("can't find plugin: %s", pluginId)
+	}
+
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || slices.Contains(normalized, keyword) {
+			continue
+		}
+		normalized = append(normalized, keyword)
+	}
+
+	for _, keyword := range normalized {
+		if keyword == "*" {
+			continue
+		}
+		for _, other := range m.instances {
+			if other == nil || other == pluginInstance || other.Setting == nil || other.Setting.Disabled.Get() {
+				continue
+			}
+			if slices.Contains(other.GetTriggerKeywords(), keyword) {
+				return &TriggerKeywordConflictError{Conflict: TriggerKeywordConflict{
+					Keyword:         keyword,
+					PluginInstances: []*Instance{other, pluginInstance},
+				}}
+			}
+		}
+	}
+
+	return pluginInstance.Setting.TriggerKeywords.Set(normalized)
+}
+
 func formatTriggerKeywordConflictDetails(ctx context.Context, conflicts []TriggerKeywordConflict) string {
 	items := make([]string, 0, len(conflicts))
 	for _, conflict := range conflicts {
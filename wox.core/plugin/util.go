@@ -13,7 +13,7 @@ func IsStringMatch(ctx context.Context, term string, subTerm string) bool {
 
 func IsStringMatchScore(ctx context.Context, term string, subTerm string) (bool, int64) {
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	result := fuzzymatch.FuzzyMatch(term, subTerm, woxSetting.UsePinYin.Get())
+	result := fuzzymatch.FuzzyMatchWithPinYinMode(term, subTerm, woxSetting.PinYinMatchMode.Get())
 	return result.IsMatch, result.Score
 }
 
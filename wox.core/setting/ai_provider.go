@@ -0,0 +1,108 @@
+package setting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"wox/common"
+
+	"github.com/google/uuid"
+)
+
+const MaxAIProviderTimeoutSeconds = 600
+
+// UnmarshalJSON accepts TimeoutSeconds as a number or a numeric string,
+// because the settings table edits every column as text.
+func (p *AIProvider) UnmarshalJSON(b []byte) error {
+	type aiProviderAlias AIProvider
+	aux := struct {
+		*aiProviderAlias
+		TimeoutSeconds json.RawMessage
+	}{aiProviderAlias: (*aiProviderAlias)(p)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	p.TimeoutSeconds = 0
+	raw := strings.TrimSpace(string(aux.TimeoutSeconds))
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = strings.TrimSpace(unquoted)
+		if raw == "" {
+			return nil
+		}
+	}
+	timeout, err := strconv.Atoi(raw)
+	if err != nil {
+		return fmt.Errorf("invalid TimeoutSeconds %q for ai provider %s", raw, p.Name)
+	}
+	p.TimeoutSeconds = timeout
+	return nil
+}
+
+// ValidateAIProviders checks the fields the settings UI cannot constrain.
+func ValidateAIProviders(providers []AIProvider) error {
+	for _, provider := range providers {
+		if provider.Name == "" {
+			return fmt.Errorf("ai provider name is empty")
+		}
+		if provider.TimeoutSeconds < 0 || provider.TimeoutSeconds > MaxAIProviderTimeoutSeconds {
+			return fmt.Errorf("timeout of ai provider %s must be between 0 and %d seconds", provider.Name, MaxAIProviderTimeoutSeconds)
+		}
+	}
+	return nil
+}
+
+// AssignAIProviderIds gives every provider without an Id a stable one. The
+// settings UI may send providers back without their Id, so an Id is first
+// reused from the previous list by matching Name and Alias before a new one
+// is generated; otherwise models saved with the old Id would stop resolving.
+func AssignAIProviderIds(previous []AIProvider, providers []AIProvider) []AIProvider {
+	usedIds := map[string]bool{}
+	for _, provider := range providers {
+		if provider.Id != "" {
+			usedIds[provider.Id] = true
+		}
+	}
+
+	result := make([]AIProvider, len(providers))
+	for i, provider := range providers {
+		if provider.Id == "" {
+			for _, old := range previous {
+				if old.Id != "" && !usedIds[old.Id] && old.Name == provider.Name && old.Alias == provider.Alias {
+					provider.Id = old.Id
+					break
+				}
+			}
+		}
+		if provider.Id == "" {
+			provider.Id = uuid.NewString()
+		}
+		usedIds[provider.Id] = true
+		result[i] = provider
+	}
+	return result
+}
+
+// FindAIProvider returns the provider config a model belongs to. Models saved
+// before provider ids existed only carry Provider and ProviderAlias, so those
+// are used when the model has no ProviderId or the id is no longer configured.
+func FindAIProvider(providers []AIProvider, model common.Model) (AIProvider, bool) {
+	if model.ProviderId != "" {
+		for _, provider := range providers {
+			if provider.Id == model.ProviderId {
+				return provider, true
+			}
+		}
+	}
+
+	for _, provider := range providers {
+		if provider.Name == model.Provider && provider.Alias == model.ProviderAlias {
+			return provider, true
+		}
+	}
+	return AIProvider{}, false
+}
@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wox/util"
+)
+
+// DefaultAppDataMaxAgeDays is how long an actioned result is kept after it was
+// last used when app data is compacted at startup.
+const DefaultAppDataMaxAgeDays = 180
+
+// CompactAppData drops actioned results that were last used more than
+// maxAgeDays ago, e.g. results of uninstalled plugins or deleted files, and
+// returns how many results were removed. Their ranking boost has decayed to
+// almost nothing by then (see actionedResultHalfLife), so ranking is unaffected.
+func (m *Manager) CompactAppData(ctx context.Context, maxAgeDays int) (int, error) {
+	if maxAgeDays <= 0 {
+		return 0, fmt.Errorf("max age must be at least one day, got %d", maxAgeDays)
+	}
+	cutoff := util.GetSystemTimestamp() - (time.Duration(maxAgeDays) * 24 * time.Hour).Milliseconds()
+
+	m.appDataMu.Lock()
+	actionedResults := m.currentWoxSetting().ActionedResults.Get()
+	var expired []ResultHash
+	actionedResults.Range(func(hash ResultHash, actions []ActionedResult) bool {
+		var newest int64
+		for _, action := range actions {
+			newest = max(newest, action.Timestamp)
+		}
+		if len(actions) == 0 || newest < cutoff {
+			expired = append(expired, hash)
+		}
+		return true
+	})
+	for _, hash := range expired {
+		actionedResults.Delete(hash)
+	}
+	if len(expired) > 0 {
+		saveAppData(ctx, m, m.currentWoxSetting().ActionedResults, actionedResults)
+	}
+	m.appDataMu.Unlock()
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	if err := m.Flush(ctx); err != nil {
+		return len(expired), fmt.Errorf("failed to save compacted app data: %w", err)
+	}
+	logger.Info(ctx, fmt.Sprintf("compacted app data: removed %d actioned results unused for %d days, %d left", len(expired), maxAgeDays, actionedResults.Len()))
+	return len(expired), nil
+}
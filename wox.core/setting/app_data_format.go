@@ -0,0 +1,146 @@
+package setting
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"wox/util"
+)
+
+// AppDataFormat selects how app data (actioned and pinned results, completion
+// feedback) is stored in wox.db.
+type AppDataFormat string
+
+const (
+	// AppDataFormatJSON is the default and the only format other tools,
+	// settings bundles and cloud sync understand.
+	AppDataFormatJSON AppDataFormat = "json"
+	// AppDataFormatBinary stores app data gob encoded, which is smaller and
+	// faster to write for large histories. Fields added by newer Wox versions
+	// are not kept in this format.
+	AppDataFormatBinary AppDataFormat = "binary"
+)
+
+func IsValidAppDataFormat(value AppDataFormat) bool {
+	return value == AppDataFormatJSON || value == AppDataFormatBinary
+}
+
+// binaryAppDataPrefix marks a stored value as base64 encoded gob, so loading
+// detects the format of each row regardless of the current setting.
+const binaryAppDataPrefix = "wox-gob1:"
+
+// appDataStoreTypes maps the store keys of app data values to a constructor of
+// their decoding target. Only these keys are ever stored in binary form.
+var appDataStoreTypes = map[string]func() any{
+	"PinedResults":            func() any { return util.NewHashMap[ResultHash, bool]() },
+	"ActionedResults":         func() any { return util.NewHashMap[ResultHash, []ActionedResult]() },
+	"FavoriteLabels":          func() any { return util.NewHashMap[ResultHash, string]() },
+	"QueryCompletionFeedback": func() any { return &[]QueryCompletionFeedback{} },
+}
+
+// binaryAppDataEnabled mirrors the AppDataFormat setting for the store, which
+// cannot read settings while it writes them.
+var binaryAppDataEnabled atomic.Bool
+
+// serializeAppDataValue returns the stored form of an app data value in the
+// current format. ok is false for keys that are not app data or when JSON is
+// selected, the caller then serializes as usual.
+func serializeAppDataValue(key string, value any) (string, bool, error) {
+	newTarget, isAppData := appDataStoreTypes[key]
+	if !isAppData || !binaryAppDataEnabled.Load() || value == nil {
+		return "", false, nil
+	}
+
+	// Values with struct fields arrive as JSON after preserveUnknownFields, see
+	// SettingValue.withUnknownFields, so they are decoded into their type first.
+	target := newTarget()
+	if reflect.TypeOf(value) == reflect.TypeOf(target) {
+		target = value
+	} else {
+		serialized, err := SerializeValue(value)
+		if err != nil {
+			return "", true, err
+		}
+		if err := json.Unmarshal([]byte(serialized), target); err != nil {
+			return "", true, fmt.Errorf("failed to decode %s before binary encoding: %w", key, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(target); err != nil {
+		return "", true, fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return binaryAppDataPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), true, nil
+}
+
+func isBinaryAppDataValue(raw string) bool {
+	return strings.HasPrefix(raw, binaryAppDataPrefix)
+}
+
+// decodeBinaryAppDataValue decodes a value written by serializeAppDataValue
+// into target.
+func decodeBinaryAppDataValue(raw string, target any) error {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(raw, binaryAppDataPrefix))
+	if err != nil {
+		return fmt.Errorf("failed to decode binary app data: %w", err)
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
+
+// binaryAppDataToJSON converts a binary app data value back to JSON for
+// callers that read raw rows, such as settings bundles and cloud sync.
+func binaryAppDataToJSON(key string, raw string) (string, error) {
+	newTarget, ok := appDataStoreTypes[key]
+	if !ok {
+		return "", fmt.Errorf("setting %s is not app data but stored in binary form", key)
+	}
+	target := newTarget()
+	if err := decodeBinaryAppDataValue(raw, target); err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return SerializeValue(target)
+}
+
+// SetAppDataFormat changes the app data format and converts the stored app
+// data right away, so the database does not keep a mix of both formats. Rows
+// in either format are always readable, e.g. after restoring an old backup.
+func (m *Manager) SetAppDataFormat(ctx context.Context, format AppDataFormat) error {
+	if !IsValidAppDataFormat(format) {
+		return fmt.Errorf("invalid app data format: %s", format)
+	}
+
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	if err := m.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to save pending app data: %w", err)
+	}
+
+	woxSetting := m.currentWoxSetting()
+	previous := woxSetting.AppDataFormat.Get()
+	if err := woxSetting.AppDataFormat.Set(format); err != nil {
+		return err
+	}
+	binaryAppDataEnabled.Store(format == AppDataFormatBinary)
+
+	// Loading the values first makes persist write them even if they were
+	// never read since startup.
+	woxSetting.PinedResults.Get()
+	woxSetting.ActionedResults.Get()
+	woxSetting.QueryCompletionFeedbacks.Get()
+	woxSetting.FavoriteLabels.Get()
+	for _, persist := range []func() error{woxSetting.PinedResults.persist, woxSetting.ActionedResults.persist, woxSetting.QueryCompletionFeedbacks.persist, woxSetting.FavoriteLabels.persist} {
+		if err := persist(); err != nil {
+			return fmt.Errorf("failed to convert app data to %s: %w", format, err)
+		}
+	}
+
+	logger.Info(ctx, fmt.Sprintf("converted app data from %s to %s", previous, format))
+	return nil
+}
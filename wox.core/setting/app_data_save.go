@@ -0,0 +1,100 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wox/util"
+)
+
+// DefaultAppDataFlushInterval is how long app data changes are batched before
+// they are written to the database.
+const DefaultAppDataFlushInterval = 3 * time.Second
+
+// saveAppData updates an app data value in memory and schedules the database
+// write. Query history and actioned results change on every launch, and
+// writing the whole serialized value each time churns the disk for no benefit.
+func saveAppData[T any](ctx context.Context, m *Manager, value *WoxSettingValue[T], newValue T) {
+	value.setInMemory(newValue)
+	m.markAppDataDirty(ctx, value.Key(), value.persistMerging)
+}
+
+// SetAppDataFlushInterval changes how long app data writes are batched. A zero
+// or negative interval writes every change immediately.
+func (m *Manager) SetAppDataFlushInterval(interval time.Duration) {
+	m.appDataSaveMu.Lock()
+	defer m.appDataSaveMu.Unlock()
+
+	m.appDataFlushInterval = interval
+}
+
+func (m *Manager) markAppDataDirty(ctx context.Context, key string, persist func() (int, error)) {
+	m.appDataSaveMu.Lock()
+	if m.appDataFlushInterval <= 0 {
+		m.appDataSaveMu.Unlock()
+		start := time.Now()
+		size, err := persist()
+		LogSettingOperation(ctx, SettingOperationAppDataSave, key, start, err)
+		if err == nil {
+			m.recordAppDataSize(key, size)
+			m.checkAppDataSize(ctx)
+		}
+		return
+	}
+
+	m.appDataDirty[key] = persist
+	if m.appDataFlushTimer == nil {
+		m.appDataFlushTimer = time.AfterFunc(m.appDataFlushInterval, func() {
+			flushCtx := util.NewTraceContext()
+			if err := m.Flush(flushCtx); err != nil {
+				logger.Error(flushCtx, fmt.Sprintf("failed to flush app data: %s", err.Error()))
+			}
+		})
+	}
+	m.appDataSaveMu.Unlock()
+}
+
+// Flush writes pending app data changes to the database. It must be called
+// before the process exits, otherwise up to one flush interval of query
+// history and actioned results is lost.
+func (m *Manager) Flush(ctx context.Context) error {
+	m.appDataSaveMu.Lock()
+	dirty := m.appDataDirty
+	m.appDataDirty = map[string]func() (int, error){}
+	if m.appDataFlushTimer != nil {
+		m.appDataFlushTimer.Stop()
+		m.appDataFlushTimer = nil
+	}
+	m.appDataSaveMu.Unlock()
+
+	var errs []error
+	for key, persist := range dirty {
+		start := time.Now()
+		size, err := persist()
+		LogSettingOperation(ctx, SettingOperationAppDataSave, key, start, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to save %s: %w", key, err))
+			continue
+		}
+		m.recordAppDataSize(key, size)
+	}
+	if len(dirty) > 0 {
+		m.checkAppDataSize(ctx)
+	}
+	return errors.Join(errs...)
+}
+
+// discardPendingAppData drops unsaved app data, used when the stored rows are
+// replaced in bulk and the pending values would overwrite them.
+func (m *Manager) discardPendingAppData() {
+	m.appDataSaveMu.Lock()
+	m.appDataDirty = map[string]func() (int, error){}
+	if m.appDataFlushTimer != nil {
+		m.appDataFlushTimer.Stop()
+		m.appDataFlushTimer = nil
+	}
+	m.appDataSaveMu.Unlock()
+
+	m.resetAppDataSizes()
+}
@@ -0,0 +1,138 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"wox/database"
+)
+
+// AppDataSizeWarningBytes is the stored size of app data above which saving
+// logs a warning and diagnostics suggest compacting. Every save rewrites the
+// whole value, so large histories make each write slower.
+const AppDataSizeWarningBytes int64 = 5 * 1024 * 1024
+
+// AppDataSize returns how many bytes the stored app data rows take in wox.db.
+// It returns 0 when settings are not kept in the database, e.g. in read-only
+// mode or with a memory store.
+func (m *Manager) AppDataSize(ctx context.Context) int64 {
+	sizes, err := m.measureAppDataSizes()
+	if err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to measure app data size: %s", err.Error()))
+		return 0
+	}
+
+	var size int64
+	for _, keySize := range sizes {
+		size += keySize
+	}
+	return size
+}
+
+// measureAppDataSizes reads the stored length of every app data row.
+func (m *Manager) measureAppDataSizes() (map[string]int64, error) {
+	sizes := map[string]int64{}
+	store, ok := m.woxStore.(*WoxSettingStore)
+	if !ok {
+		return sizes, nil
+	}
+
+	keys := make([]string, 0, len(appDataStoreTypes))
+	for key := range appDataStoreTypes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var rows []struct {
+		Key  string
+		Size int64
+	}
+	err := store.db.Model(&database.WoxSetting{}).
+		Where("key IN ?", keys).
+		Select("key, LENGTH(CAST(value AS BLOB)) AS size").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		sizes[row.Key] = row.Size
+	}
+	return sizes, nil
+}
+
+// recordAppDataSize updates the tracked size of an app data key after it was
+// saved, with the stored length the save returned.
+func (m *Manager) recordAppDataSize(key string, size int) {
+	m.appDataSizesMu.Lock()
+	defer m.appDataSizesMu.Unlock()
+
+	if m.appDataSizes != nil {
+		m.appDataSizes[key] = int64(size)
+	}
+}
+
+// resetAppDataSizes drops the tracked sizes after the rows were replaced in
+// bulk, so the next check measures them again.
+func (m *Manager) resetAppDataSizes() {
+	m.appDataSizesMu.Lock()
+	defer m.appDataSizesMu.Unlock()
+
+	m.appDataSizes = nil
+}
+
+// trackedAppDataSize returns the total of the tracked app data sizes. The
+// rows are only read when nothing is tracked yet, saves keep the sizes up to
+// date afterwards, see recordAppDataSize.
+func (m *Manager) trackedAppDataSize(ctx context.Context) int64 {
+	m.appDataSizesMu.Lock()
+	defer m.appDataSizesMu.Unlock()
+
+	if m.appDataSizes == nil {
+		sizes, err := m.measureAppDataSizes()
+		if err != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to measure app data size: %s", err.Error()))
+			return 0
+		}
+		m.appDataSizes = sizes
+	}
+
+	var size int64
+	for _, keySize := range m.appDataSizes {
+		size += keySize
+	}
+	return size
+}
+
+// checkAppDataSize warns once when app data grows past
+// AppDataSizeWarningBytes, and again only after it dropped below and grew
+// back, so a large history does not log on every save.
+func (m *Manager) checkAppDataSize(ctx context.Context) {
+	if _, ok := m.woxStore.(*WoxSettingStore); !ok {
+		return
+	}
+	size := m.trackedAppDataSize(ctx)
+	if size <= AppDataSizeWarningBytes {
+		m.appDataSizeWarned.Store(false)
+		return
+	}
+	if m.appDataSizeWarned.Swap(true) {
+		return
+	}
+	logger.Warn(ctx, fmt.Sprintf("app data is %s, larger than %s, run compaction to remove old actioned results", formatAppDataSize(size), formatAppDataSize(AppDataSizeWarningBytes)))
+}
+
+func (m *Manager) diagnoseAppDataSize(ctx context.Context) DiagnosticCheck {
+	const name = "app data size"
+	size := m.AppDataSize(ctx)
+	if size > AppDataSizeWarningBytes {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusWarn, Message: fmt.Sprintf("%s stored, above %s, compact app data to speed up saving", formatAppDataSize(size), formatAppDataSize(AppDataSizeWarningBytes))}
+	}
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: fmt.Sprintf("%s stored", formatAppDataSize(size))}
+}
+
+func formatAppDataSize(size int64) string {
+	if size < 1024*1024 {
+		return fmt.Sprintf("%d KB", size/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/1024/1024)
+}
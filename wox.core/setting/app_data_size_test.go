@@ -0,0 +1,60 @@
+package setting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"wox/database"
+)
+
+func TestAppDataSizeIsTrackedFromSaves(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+	m.SetAppDataFlushInterval(0)
+
+	// The first save measures the stored rows once.
+	m.AddActionedResult(ctx, "calculator", "2", "", "1+1", "")
+	if tracked, stored := m.trackedAppDataSize(ctx), m.AppDataSize(ctx); tracked == 0 || tracked != stored {
+		t.Fatalf("expected the tracked size to match the stored rows, tracked %d, stored %d", tracked, stored)
+	}
+
+	// Later saves update the size of their key without reading the rows, so a
+	// row written behind the manager's back is not counted.
+	external := strings.Repeat("x", 1000)
+	if err := db.Save(&database.WoxSetting{Key: "FavoriteLabels", Value: external}).Error; err != nil {
+		t.Fatalf("failed to write row: %v", err)
+	}
+	m.AddActionedResult(ctx, "calculator", "4", "", "2+2", "")
+	actionedResults := int64(len(storedRow(t, db, "ActionedResults")))
+	if tracked := m.trackedAppDataSize(ctx); tracked != actionedResults {
+		t.Fatalf("expected only the saved ActionedResults to be tracked, tracked %d, row %d", tracked, actionedResults)
+	}
+	if stored := m.AppDataSize(ctx); stored != actionedResults+int64(len(external)) {
+		t.Fatalf("expected AppDataSize to read every row, got %d", stored)
+	}
+
+	// Replacing the rows in bulk measures them again.
+	m.reloadWoxSetting()
+	if tracked, stored := m.trackedAppDataSize(ctx), m.AppDataSize(ctx); tracked != stored {
+		t.Fatalf("expected the sizes to be measured again after a reload, tracked %d, stored %d", tracked, stored)
+	}
+}
+
+func TestCheckAppDataSizeWarnsOncePerCrossing(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+
+	m.appDataSizes = map[string]int64{"ActionedResults": AppDataSizeWarningBytes + 1}
+	m.checkAppDataSize(ctx)
+	if !m.appDataSizeWarned.Load() {
+		t.Fatalf("expected a warning above the limit")
+	}
+
+	m.recordAppDataSize("ActionedResults", 10)
+	m.checkAppDataSize(ctx)
+	if m.appDataSizeWarned.Load() {
+		t.Fatalf("expected the warning to be cleared below the limit")
+	}
+}
@@ -0,0 +1,210 @@
+package setting
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"wox/util"
+)
+
+const (
+	settingAuditFileName = "settings_audit.log"
+
+	// maxSettingAuditLogSize caps the audit log. When an append crosses the cap
+	// the oldest half of the entries is dropped.
+	maxSettingAuditLogSize = 1024 * 1024
+
+	redactedSettingValue = "******"
+)
+
+// Sources recorded in the settings audit log.
+const (
+	SettingAuditSourceSettingsUI = "settings_ui"
+	SettingAuditSourceReset      = "reset"
+	SettingAuditSourceExternal   = "external"
+	SettingAuditSourceProfile    = "profile"
+	SettingAuditSourceImport     = "import"
+	SettingAuditSourceRestore    = "restore"
+)
+
+// AuditEntry is one line of the settings audit log.
+type AuditEntry struct {
+	Timestamp int64
+	Key       string
+	OldValue  string
+	NewValue  string
+	Source    string
+}
+
+var settingAuditMu sync.Mutex
+
+func getSettingAuditPath() string {
+	return filepath.Join(util.GetLocation().GetLogDirectory(), settingAuditFileName)
+}
+
+// RecordSettingAudit appends a setting change to the audit log. Secret fields
+// such as api keys are redacted before the entry is written. Unchanged values
+// are ignored so callers can record unconditionally after an update.
+func (m *Manager) RecordSettingAudit(ctx context.Context, key string, oldValue string, newValue string, source string) {
+	if oldValue == newValue {
+		return
+	}
+
+	entry := AuditEntry{
+		Timestamp: util.GetSystemTimestamp(),
+		Key:       key,
+		OldValue:  redactSettingValue(key, oldValue),
+		NewValue:  redactSettingValue(key, newValue),
+		Source:    source,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Warn(ctx, fmt.Sprintf("failed to encode setting audit entry for %s: %s", key, err.Error()))
+		return
+	}
+
+	settingAuditMu.Lock()
+	defer settingAuditMu.Unlock()
+
+	if err := appendSettingAuditLine(getSettingAuditPath(), line); err != nil {
+		logger.Warn(ctx, fmt.Sprintf("failed to write setting audit entry for %s: %s", key, err.Error()))
+	}
+}
+
+// GetSettingAudit returns up to limit audit entries, newest first. A limit of
+// zero or less returns every entry.
+func (m *Manager) GetSettingAudit(ctx context.Context, limit int) []AuditEntry {
+	settingAuditMu.Lock()
+	content, err := os.ReadFile(getSettingAuditPath())
+	settingAuditMu.Unlock()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn(ctx, fmt.Sprintf("failed to read setting audit log: %s", err.Error()))
+		}
+		return []AuditEntry{}
+	}
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSettingAuditLogSize)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a partially written last line should not hide the rest of the log
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	result := make([]AuditEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, entries[i])
+	}
+	return result
+}
+
+// SerializedWoxSettingValue returns the serialized value of a Wox setting by
+// field name (e.g. MainHotkey) or by stored key (e.g. MainHotkey@darwin).
+func (m *Manager) SerializedWoxSettingValue(key string) (string, bool) {
+	settingValue := reflect.ValueOf(m.currentWoxSetting()).Elem()
+	settingType := settingValue.Type()
+	for i := 0; i < settingValue.NumField(); i++ {
+		field := settingValue.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		value, ok := field.Interface().(interface {
+			settingValueSerializer
+			Key() string
+		})
+		if !ok || (settingType.Field(i).Name != key && value.Key() != key) {
+			continue
+		}
+
+		serialized, err := value.serializedValue()
+		if err != nil {
+			return "", false
+		}
+		return serialized, true
+	}
+	return "", false
+}
+
+// appendSettingAuditLine appends line to the log. Only the file size is
+// checked on a regular append; the log is read when it has to be trimmed.
+func appendSettingAuditLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if size+int64(len(line))+1 <= maxSettingAuditLogSize {
+		file, openErr := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if openErr != nil {
+			return openErr
+		}
+		defer file.Close()
+		_, writeErr := file.Write(append(line, '\n'))
+		return writeErr
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// Keep the newest half so the log does not need trimming on every append.
+	kept := content[len(content)/2:]
+	if index := bytes.IndexByte(kept, '\n'); index >= 0 {
+		kept = kept[index+1:]
+	}
+	kept = append(append([]byte{}, kept...), line...)
+	kept = append(kept, '\n')
+	return util.WriteFileAtomic(path, kept, 0600)
+}
+
+// settingValueRedactors hide credentials that are part of a setting value
+// rather than a sealed secret field, see redactSettingValue.
+var settingValueRedactors = map[string]func(value string) string{
+	"HttpProxyUrl": util.RedactProxyURL,
+}
+
+// redactSettingValue blanks secrets with a marker so the log still shows that
+// a secret was set or changed without revealing it.
+func redactSettingValue(key string, value string) string {
+	baseKey := key
+	if platformKey, _, ok := SplitPlatformSettingKey(key); ok {
+		baseKey = platformKey
+	}
+	if redactor, ok := settingValueRedactors[baseKey]; ok {
+		return redactor(value)
+	}
+	sealer, ok := secretSettingSealers[baseKey]
+	if !ok || value == "" {
+		return value
+	}
+
+	redacted, err := sealer(value, func(secret string) (string, error) {
+		if secret == "" {
+			return "", nil
+		}
+		return redactedSettingValue, nil
+	})
+	if err != nil {
+		return redactedSettingValue
+	}
+	return redacted
+}
@@ -0,0 +1,121 @@
+package setting
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"wox/util"
+)
+
+// backupEncryptedMagic prefixes every file of an encrypted backup. Files
+// without it are plaintext, which is how legacy backups are restored as-is.
+var backupEncryptedMagic = []byte("WOXBAK1\n")
+
+const backupInfoFileName = "backup.json"
+
+// encryptBackupDirectory encrypts every file of a backup in place. The key is
+// the setting secret key kept in the OS keychain (see loadOrCreateSecretKey),
+// so encrypted backups can only be restored on the machine that made them.
+// backup.json stays readable so backups can be listed without the key.
+func encryptBackupDirectory(dir string) error {
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return fmt.Errorf("failed to load backup key: %w", err)
+	}
+
+	return walkBackupFiles(dir, func(path string, content []byte) ([]byte, bool, error) {
+		if bytes.HasPrefix(content, backupEncryptedMagic) {
+			return nil, false, nil
+		}
+
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, false, fmt.Errorf("failed to read nonce: %w", err)
+		}
+		sealed := append(bytes.Clone(backupEncryptedMagic), nonce...)
+		return gcm.Seal(sealed, nonce, content, []byte(filepath.ToSlash(backupRelPath(dir, path)))), true, nil
+	})
+}
+
+// decryptBackupDirectory decrypts every encrypted file below dir in place and
+// leaves plaintext files untouched.
+func decryptBackupDirectory(dir string) error {
+	return walkBackupFiles(dir, func(path string, content []byte) ([]byte, bool, error) {
+		if !bytes.HasPrefix(content, backupEncryptedMagic) {
+			return nil, false, nil
+		}
+		plaintext, err := openBackupContent(backupRelPath(dir, path), content)
+		return plaintext, err == nil, err
+	})
+}
+
+// ReadBackupFile returns the plaintext content of one backup file. It is
+// registered with database.SetBackupFileReader so a corrupt wox.db can be
+// restored from an encrypted backup at startup.
+func ReadBackupFile(backupDir string, name string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(backupDir, name))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(content, backupEncryptedMagic) {
+		return content, nil
+	}
+	return openBackupContent(name, content)
+}
+
+func openBackupContent(relPath string, content []byte) ([]byte, error) {
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backup key: %w", err)
+	}
+
+	sealed := content[len(backupEncryptedMagic):]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted backup file %s is too short", relPath)
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(filepath.ToSlash(relPath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup file %s, it may have been encrypted on another machine: %w", relPath, err)
+	}
+	return plaintext, nil
+}
+
+// walkBackupFiles rewrites each regular file below dir, except backup.json,
+// with the content returned by transform when it reports a change.
+func walkBackupFiles(dir string, transform func(path string, content []byte) ([]byte, bool, error)) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || (entry.Name() == backupInfoFileName && filepath.Dir(path) == filepath.Clean(dir)) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated, changed, err := transform(path, content)
+		if err != nil || !changed {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return util.WriteFileAtomic(path, updated, info.Mode().Perm())
+	})
+}
+
+func backupRelPath(base string, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return rel
+}
@@ -0,0 +1,151 @@
+package setting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/database"
+	"wox/util"
+)
+
+// useTestSecretKey makes the setting secret key a fixed test key instead of
+// the one in the OS keychain.
+func useTestSecretKey(t *testing.T) {
+	t.Helper()
+	useSecretKey(t, 7)
+}
+
+func TestBackupEncryptionRoundTrip(t *testing.T) {
+	useTestSecretKey(t)
+	dir := t.TempDir()
+	files := map[string][]byte{
+		backupDatabaseFileName:                  []byte("sqlite database content"),
+		filepath.Join("plugins", "plugin.json"): []byte(`{"Id":"plugin"}`),
+		backupInfoFileName:                      []byte(`{"Name":"backup"}`),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := encryptBackupDirectory(dir); err != nil {
+		t.Fatalf("failed to encrypt backup: %v", err)
+	}
+	for name, content := range files {
+		stored, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		encrypted := bytes.HasPrefix(stored, backupEncryptedMagic)
+		if name == backupInfoFileName {
+			if encrypted || !bytes.Equal(stored, content) {
+				t.Fatalf("expected %s to stay readable, got %q", name, stored)
+			}
+			continue
+		}
+		if !encrypted || bytes.Contains(stored, content) {
+			t.Fatalf("expected %s to be encrypted, got %q", name, stored)
+		}
+
+		plaintext, err := ReadBackupFile(dir, name)
+		if err != nil || !bytes.Equal(plaintext, content) {
+			t.Fatalf("expected ReadBackupFile to return the plaintext of %s, got %q (%v)", name, plaintext, err)
+		}
+	}
+
+	// encrypting twice must not wrap files again
+	if err := encryptBackupDirectory(dir); err != nil {
+		t.Fatalf("failed to encrypt backup again: %v", err)
+	}
+	if err := decryptBackupDirectory(dir); err != nil {
+		t.Fatalf("failed to decrypt backup: %v", err)
+	}
+	for name, content := range files {
+		stored, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || !bytes.Equal(stored, content) {
+			t.Fatalf("expected %s to be restored, got %q (%v)", name, stored, err)
+		}
+	}
+}
+
+func TestBackupFileMovedToAnotherNameFailsToDecrypt(t *testing.T) {
+	useTestSecretKey(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := encryptBackupDirectory(dir); err != nil {
+		t.Fatalf("failed to encrypt backup: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")); err != nil {
+		t.Fatalf("failed to rename file: %v", err)
+	}
+
+	if _, err := ReadBackupFile(dir, "b.json"); err == nil {
+		t.Fatalf("expected a file moved to another path to fail authentication")
+	}
+}
+
+func TestDatabaseInitRestoresFromEncryptedBackup(t *testing.T) {
+	useTestSecretKey(t)
+	ctx := context.Background()
+	m, db := newProfileTestManager(t)
+	database.SetBackupFileReader(ReadBackupFile)
+	t.Cleanup(func() { database.SetBackupFileReader(nil) })
+
+	if err := db.Create(&database.WoxSetting{Key: "LangCode", Value: "zh_CN"}).Error; err != nil {
+		t.Fatalf("failed to write setting: %v", err)
+	}
+	if err := m.currentWoxSetting().EncryptBackups.Set(true); err != nil {
+		t.Fatalf("failed to enable backup encryption: %v", err)
+	}
+	if err := m.Backup(ctx, BackupTypeManual); err != nil {
+		t.Fatalf("failed to backup: %v", err)
+	}
+
+	// A newer backup encrypted with another key must be skipped, not restored.
+	foreignDir := filepath.Join(util.GetLocation().GetBackupDirectory(), fmt.Sprintf("%d", util.GetSystemTimestamp()+1000))
+	if err := os.MkdirAll(foreignDir, 0755); err != nil {
+		t.Fatalf("failed to create backup dir: %v", err)
+	}
+	foreignDatabase := append(bytes.Clone(backupEncryptedMagic), bytes.Repeat([]byte{1}, 64)...)
+	if err := os.WriteFile(filepath.Join(foreignDir, backupDatabaseFileName), foreignDatabase, 0644); err != nil {
+		t.Fatalf("failed to write foreign backup: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(foreignDir, backupInfoFileName), []byte(`{"Encrypted":true}`), 0644); err != nil {
+		t.Fatalf("failed to write foreign backup info: %v", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	dbPath := util.GetLocation().GetDatabasePath()
+	if err := os.WriteFile(dbPath, nil, 0644); err != nil {
+		t.Fatalf("failed to empty database: %v", err)
+	}
+
+	if err := database.Init(ctx); err != nil {
+		t.Fatalf("expected init to recover from the encrypted backup, got: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.GetDB().DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	var restored database.WoxSetting
+	if err := database.GetDB().Where("key = ?", "LangCode").First(&restored).Error; err != nil {
+		t.Fatalf("expected setting restored from the encrypted backup: %v", err)
+	}
+	if restored.Value != "zh_CN" {
+		t.Fatalf("expected restored value zh_CN, got %s", restored.Value)
+	}
+}
@@ -10,18 +10,26 @@ import (
 	"slices"
 	"strings"
 	"time"
+	"wox/database"
 	"wox/util"
 
 	"github.com/google/uuid"
 	cp "github.com/otiai10/copy"
+	"github.com/samber/lo"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 type BackupType string
 
 const (
-	BackupTypeAuto   BackupType = "auto"
-	BackupTypeManual BackupType = "manual"
-	BackupTypeUpdate BackupType = "update" // backup before update Wox
+	BackupTypeAuto    BackupType = "auto"
+	BackupTypeManual  BackupType = "manual"
+	BackupTypeUpdate  BackupType = "update"  // backup before update Wox
+	BackupTypeImport  BackupType = "import"  // backup before importing a settings bundle
+	BackupTypeRestore BackupType = "restore" // backup before restoring another backup
+	BackupTypeReset   BackupType = "reset"   // backup before resetting all settings to defaults
 )
 
 type Backup struct {
@@ -30,11 +38,51 @@ type Backup struct {
 	Timestamp int64
 	Type      BackupType
 	Path      string // backup file path
+	Encrypted bool   // files are encrypted, see encryptBackupDirectory
+	// HasDatabase reports whether the backup contains wox.db, which holds all
+	// settings and app data. Like Path it is filled when backups are listed.
+	HasDatabase bool
 }
 
+// BackupInfo is a backup as listed to users, with its size on disk.
+type BackupInfo struct {
+	Id        string
+	Timestamp int64
+	Type      BackupType
+	Path      string
+	Size      int64 // bytes
+	// DatabaseSize is the size of the backed up wox.db, 0 when the backup has none.
+	DatabaseSize int64
+}
+
+// backupDatabaseFileName is the database inside the user data directory and
+// inside every backup.
+const backupDatabaseFileName = "wox.db"
+
 func (m *Manager) StartAutoBackup(ctx context.Context) {
+	if m.IsReadOnly() {
+		logger.Info(ctx, "settings are read-only, auto backup is disabled")
+		return
+	}
+	// Both Init and main start it; a second loop would double the backups and
+	// miss half of the reschedule signals.
+	if m.autoBackupStarted.Swap(true) {
+		return
+	}
+
 	util.Go(ctx, "backup", func() {
-		for range time.NewTimer(24 * time.Hour).C {
+		for {
+			interval := time.Duration(m.currentWoxSetting().AutoBackupIntervalHours.Get()) * time.Hour
+			timer := time.NewTimer(interval)
+			select {
+			case <-m.autoBackupReschedule:
+				// Start over with the new interval instead of waiting out the old one.
+				timer.Stop()
+				logger.Info(ctx, fmt.Sprintf("auto backup rescheduled, interval: %d hours", m.currentWoxSetting().AutoBackupIntervalHours.Get()))
+				continue
+			case <-timer.C:
+			}
+
 			// Check if auto backup is enabled in settings
 			settings := m.GetWoxSetting(ctx)
 			if settings == nil {
@@ -55,25 +103,68 @@ func (m *Manager) StartAutoBackup(ctx context.Context) {
 	})
 }
 
+// RescheduleAutoBackup restarts the auto backup timer, e.g. after AutoBackupIntervalHours changed.
+func (m *Manager) RescheduleAutoBackup(ctx context.Context) {
+	select {
+	case m.autoBackupReschedule <- struct{}{}:
+	default:
+		// A reschedule is already pending and will read the latest interval.
+	}
+}
+
 func (m *Manager) Backup(ctx context.Context, backupType BackupType) error {
+	if m.IsReadOnly() {
+		return ErrSettingsReadOnly
+	}
 	logger.Info(ctx, fmt.Sprintf("backing up data: %s", backupType))
+	if err := m.Flush(ctx); err != nil {
+		logger.Warn(ctx, fmt.Sprintf("failed to flush app data before backup: %s", err.Error()))
+	}
 
 	ts := util.GetSystemTimestamp()
 	backupName := fmt.Sprintf("%d", ts)
 	backupPath := path.Join(util.GetLocation().GetBackupDirectory(), backupName)
 	logger.Info(ctx, fmt.Sprintf("backup path: %s", backupPath))
 
-	err := cp.Copy(util.GetLocation().GetUserDataDirectory(), backupPath)
+	// The database is snapshotted separately, copying wox.db while Wox writes
+	// to it could produce a torn file.
+	userDataDir := util.GetLocation().GetUserDataDirectory()
+	err := cp.Copy(userDataDir, backupPath, cp.Options{
+		Skip: func(_ os.FileInfo, src string, _ string) (bool, error) {
+			return isDatabaseFile(userDataDir, src), nil
+		},
+	})
 	if err != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to backup data: %s", err.Error()))
 		return err
 	}
 
+	if snapshotErr := database.Snapshot(ctx, filepath.Join(backupPath, backupDatabaseFileName)); snapshotErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to backup database: %s", snapshotErr.Error()))
+		if rmErr := os.RemoveAll(backupPath); rmErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to remove backup data: %s", rmErr.Error()))
+		}
+		return snapshotErr
+	}
+
+	encrypted := m.currentWoxSetting().EncryptBackups.Get()
+	if encrypted {
+		if encryptErr := encryptBackupDirectory(backupPath); encryptErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to encrypt backup data: %s", encryptErr.Error()))
+			// never leave a half encrypted backup behind
+			if rmErr := os.RemoveAll(backupPath); rmErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to remove backup data: %s", rmErr.Error()))
+			}
+			return encryptErr
+		}
+	}
+
 	backup := Backup{
 		Id:        uuid.New().String(),
 		Name:      backupName,
 		Timestamp: ts,
 		Type:      backupType,
+		Encrypted: encrypted,
 	}
 	marshal, marshalErr := json.Marshal(backup)
 	if marshalErr != nil {
@@ -86,8 +177,8 @@ func (m *Manager) Backup(ctx context.Context, backupType BackupType) error {
 		return marshalErr
 	}
 
-	backupInfoPath := path.Join(backupPath, "backup.json")
-	writeErr := os.WriteFile(backupInfoPath, marshal, 0644)
+	backupInfoPath := path.Join(backupPath, backupInfoFileName)
+	writeErr := util.WriteFileAtomic(backupInfoPath, marshal, 0644)
 	if writeErr != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to write backup info: %s", writeErr.Error()))
 		// remove backup data
@@ -108,6 +199,9 @@ func (m *Manager) Backup(ctx context.Context, backupType BackupType) error {
 }
 
 func (m *Manager) Restore(ctx context.Context, backupId string) error {
+	if m.IsReadOnly() {
+		return ErrSettingsReadOnly
+	}
 	logger.Info(ctx, fmt.Sprintf("restoring backup data: %s", backupId))
 	backups, getErr := m.FindAllBackups(ctx)
 	if getErr != nil {
@@ -119,6 +213,12 @@ func (m *Manager) Restore(ctx context.Context, backupId string) error {
 	for _, backup := range backups {
 		if backup.Id == backupId {
 			backupName = backup.Name
+			// Restoring replaces the whole user data directory, a backup
+			// without database would silently reset every setting.
+			if !backup.HasDatabase {
+				logger.Error(ctx, fmt.Sprintf("backup %s does not contain %s", backupId, backupDatabaseFileName))
+				return fmt.Errorf("backup %s does not contain %s", backupId, backupDatabaseFileName)
+			}
 			break
 		}
 	}
@@ -161,17 +261,217 @@ func (m *Manager) Restore(ctx context.Context, backupId string) error {
 		return cpErr
 	}
 
-	backupInfoPath := path.Join(userDataDir, "backup.json")
+	// Legacy backups have no encrypted files, so this is a no-op for them.
+	if decryptErr := decryptBackupDirectory(userDataDir); decryptErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to decrypt restored backup data: %s", decryptErr.Error()))
+		if userDataBackupDir != "" {
+			_ = os.RemoveAll(userDataDir)
+			_ = os.Rename(userDataBackupDir, userDataDir)
+		}
+		return decryptErr
+	}
+
+	backupInfoPath := path.Join(userDataDir, backupInfoFileName)
 	if rmErr := os.Remove(backupInfoPath); rmErr != nil && !os.IsNotExist(rmErr) {
 		logger.Error(ctx, fmt.Sprintf("failed to remove restored backup info: %s", rmErr.Error()))
 		return rmErr
 	}
 
+	invalidateAllPluginSettingCaches()
 	logger.Info(ctx, "backup data restored successfully")
 
 	return nil
 }
 
+// ListBackups returns all backups, newest first.
+func (m *Manager) ListBackups(ctx context.Context) []BackupInfo {
+	backups, err := m.FindAllBackups(ctx)
+	if err != nil {
+		return []BackupInfo{}
+	}
+
+	slices.SortFunc(backups, func(i, j Backup) int {
+		return int(j.Timestamp - i.Timestamp)
+	})
+
+	infos := make([]BackupInfo, 0, len(backups))
+	for _, backup := range backups {
+		var size int64
+		walkErr := filepath.WalkDir(backup.Path, func(_ string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if info, infoErr := entry.Info(); infoErr == nil && !entry.IsDir() {
+				size += info.Size()
+			}
+			return nil
+		})
+		if walkErr != nil {
+			logger.Warn(ctx, fmt.Sprintf("failed to calculate size of backup %s: %s", backup.Name, walkErr.Error()))
+		}
+
+		var databaseSize int64
+		if info, statErr := os.Stat(filepath.Join(backup.Path, backupDatabaseFileName)); statErr == nil {
+			databaseSize = info.Size()
+		}
+
+		infos = append(infos, BackupInfo{
+			Id:           backup.Id,
+			Timestamp:    backup.Timestamp,
+			Type:         backup.Type,
+			Path:         backup.Path,
+			Size:         size,
+			DatabaseSize: databaseSize,
+		})
+	}
+
+	return infos
+}
+
+// SetBackupMigrator sets the function that runs the data migrations on a
+// backed up database before RestoreBackup reads it, so settings saved by an
+// older Wox are upgraded like on startup. The migration package depends on
+// this one, which is why it is passed in by main.
+func (m *Manager) SetBackupMigrator(migrator func(ctx context.Context, db *gorm.DB) error) {
+	m.backupMigrator = migrator
+}
+
+// RestoreBackup replaces the current Wox and plugin settings with the ones stored in a backup
+// and reloads them in memory, so the restore takes effect without restarting Wox.
+// Unlike Restore, it keeps the running database open and writes through the
+// setting stores, so restored values are synced like regular changes, and the
+// language, proxy, hotkeys and plugins are updated for every changed setting.
+func (m *Manager) RestoreBackup(ctx context.Context, backupId string) error {
+	if m.IsReadOnly() {
+		return ErrSettingsReadOnly
+	}
+	backups, err := m.FindAllBackups(ctx)
+	if err != nil {
+		return err
+	}
+	backup, found := lo.Find(backups, func(item Backup) bool { return item.Id == backupId })
+	if !found {
+		return fmt.Errorf("backup not found: %s", backupId)
+	}
+
+	// Read the backup before snapshotting the current state, because the
+	// snapshot may prune the oldest backup, which could be this one.
+	woxSettings, pluginSettings, err := m.readBackupDirectorySettings(ctx, backup.Path)
+	if err != nil {
+		return fmt.Errorf("invalid backup %s: %w", backupId, err)
+	}
+
+	if err := m.Backup(ctx, BackupTypeRestore); err != nil {
+		return fmt.Errorf("failed to backup current settings before restore: %w", err)
+	}
+
+	// Rows hold secrets sealed with the key of the machine that wrote them;
+	// the store seals them again with the local key.
+	woxValues, err := openStoredWoxSettings(woxSettings)
+	if err != nil {
+		return fmt.Errorf("failed to read backup %s: %w", backupId, err)
+	}
+	pluginValues := map[string]map[string]string{}
+	for _, row := range pluginSettings {
+		if pluginValues[row.PluginID] == nil {
+			pluginValues[row.PluginID] = map[string]string{}
+		}
+		pluginValues[row.PluginID][row.Key] = row.Value
+	}
+
+	oldValues := m.serializedWoxSettingValues()
+	if err := m.replaceWoxSettings(ctx, woxValues, nil); err != nil {
+		return fmt.Errorf("failed to restore backup %s: %w", backupId, err)
+	}
+	if err := m.replacePluginSettings(ctx, pluginValues); err != nil {
+		return fmt.Errorf("failed to restore plugin settings from backup %s: %w", backupId, err)
+	}
+
+	m.applyAppDataFormat()
+	m.notifyChangedSettings(ctx, oldValues, SettingAuditSourceRestore)
+	logger.Info(ctx, fmt.Sprintf("restored settings from backup %s (%s): wox settings=%d, plugin settings=%d", backupId, util.FormatTimestamp(backup.Timestamp), len(woxSettings), len(pluginSettings)))
+	return nil
+}
+
+// readBackupDirectorySettings returns the setting rows of a backup, migrated
+// to the current version. The database is copied into a temporary directory
+// first, decrypted if needed, because sqlite can only open plaintext files and
+// the migrations must not change the backup itself.
+func (m *Manager) readBackupDirectorySettings(ctx context.Context, backupDir string) ([]database.WoxSetting, []database.PluginSetting, error) {
+	if !util.IsFileExists(filepath.Join(backupDir, backupDatabaseFileName)) {
+		return nil, nil, fmt.Errorf("backup does not contain wox.db")
+	}
+
+	tempDir, err := os.MkdirTemp("", "wox-backup-restore-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The WAL may still hold committed rows that were not checkpointed into
+	// wox.db when the backup was copied.
+	for _, name := range []string{"wox.db", "wox.db-wal"} {
+		if !util.IsFileExists(filepath.Join(backupDir, name)) {
+			continue
+		}
+		plaintext, readErr := ReadBackupFile(backupDir, name)
+		if readErr != nil {
+			return nil, nil, readErr
+		}
+		if writeErr := os.WriteFile(filepath.Join(tempDir, name), plaintext, 0600); writeErr != nil {
+			return nil, nil, writeErr
+		}
+	}
+	return m.readBackupSettings(ctx, filepath.Join(tempDir, "wox.db"))
+}
+
+// readBackupSettings opens a copy of a backed up wox.db, runs the data
+// migrations on it and returns its setting rows.
+func (m *Manager) readBackupSettings(ctx context.Context, dbPath string) ([]database.WoxSetting, []database.PluginSetting, error) {
+	backupDB, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if sqlDB, sqlErr := backupDB.DB(); sqlErr == nil {
+		defer sqlDB.Close()
+	}
+
+	var quickCheck string
+	if err := backupDB.Raw("PRAGMA quick_check").Scan(&quickCheck).Error; err != nil {
+		return nil, nil, err
+	}
+	if quickCheck != "ok" {
+		return nil, nil, fmt.Errorf("backup database is corrupt: %s", quickCheck)
+	}
+	if !backupDB.Migrator().HasTable(&database.WoxSetting{}) {
+		return nil, nil, fmt.Errorf("backup database has no settings")
+	}
+
+	if m.backupMigrator != nil {
+		// Backups of older versions lack the tables added since, which the
+		// migrations expect like on a regular start.
+		if err := database.MigrateSchema(backupDB); err != nil {
+			return nil, nil, fmt.Errorf("failed to upgrade backup database schema: %w", err)
+		}
+		if err := m.backupMigrator(ctx, backupDB); err != nil {
+			return nil, nil, fmt.Errorf("failed to migrate backup database: %w", err)
+		}
+	}
+
+	var woxSettings []database.WoxSetting
+	if err := backupDB.Find(&woxSettings).Error; err != nil {
+		return nil, nil, err
+	}
+	var pluginSettings []database.PluginSetting
+	if backupDB.Migrator().HasTable(&database.PluginSetting{}) {
+		if err := backupDB.Find(&pluginSettings).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return woxSettings, pluginSettings, nil
+}
+
 func ensureUniquePath(candidate string) string {
 	if _, err := os.Stat(candidate); os.IsNotExist(err) {
 		return candidate
@@ -188,6 +488,21 @@ func ensureUniquePath(candidate string) string {
 	}
 }
 
+// isDatabaseFile reports whether path is wox.db or one of its journal files
+// in the user data directory.
+func isDatabaseFile(userDataDir string, path string) bool {
+	if filepath.Dir(filepath.Clean(path)) != filepath.Clean(userDataDir) {
+		return false
+	}
+	name := filepath.Base(path)
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		if name == backupDatabaseFileName+suffix {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) FindAllBackups(ctx context.Context) ([]Backup, error) {
 	var backupList []Backup = make([]Backup, 0)
 
@@ -204,7 +519,7 @@ func (m *Manager) FindAllBackups(ctx context.Context) ([]Backup, error) {
 		}
 
 		//  read backup info file
-		backupInfoPath := path.Join(backupDir, entry.Name(), "backup.json")
+		backupInfoPath := path.Join(backupDir, entry.Name(), backupInfoFileName)
 		file, readErr := os.ReadFile(backupInfoPath)
 		if readErr != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to read backup info file: %s", readErr.Error()))
@@ -219,6 +534,7 @@ func (m *Manager) FindAllBackups(ctx context.Context) ([]Backup, error) {
 		}
 
 		backupInfo.Path = path.Join(backupDir, entry.Name())
+		backupInfo.HasDatabase = util.IsFileExists(filepath.Join(backupInfo.Path, backupDatabaseFileName))
 		backupList = append(backupList, backupInfo)
 	}
 
@@ -227,7 +543,7 @@ func (m *Manager) FindAllBackups(ctx context.Context) ([]Backup, error) {
 
 func (m *Manager) cleanBackups(ctx context.Context) error {
 	logger.Info(ctx, "cleaning backups")
-	maxBackups := 5
+	maxBackups := m.currentWoxSetting().AutoBackupMaxCount.Get()
 
 	backups, getErr := m.FindAllBackups(ctx)
 	if getErr != nil {
@@ -235,7 +551,7 @@ func (m *Manager) cleanBackups(ctx context.Context) error {
 		return getErr
 	}
 
-	// keep 5 backups
+	// keep the newest AutoBackupMaxCount backups
 	if len(backups) <= maxBackups {
 		return nil
 	}
@@ -0,0 +1,170 @@
+package setting
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"wox/util"
+)
+
+// SettingBundleSchemaVersion is bumped whenever the layout of an exported
+// bundle changes in a way older importers cannot read.
+const SettingBundleSchemaVersion = 1
+
+const (
+	settingBundleManifestName       = "manifest.json"
+	settingBundleWoxSettingsName    = "wox_settings.json"
+	settingBundlePluginSettingsName = "plugin_settings.json"
+)
+
+// SettingBundleManifest describes an exported settings bundle.
+type SettingBundleManifest struct {
+	SchemaVersion  int
+	Timestamp      int64
+	IncludeSecrets bool
+}
+
+// ExportAll writes every Wox setting (including app data such as query
+// history and pinned results, which live in the same table) and every plugin
+// setting into a zip bundle. Values are exported in their serialized form so
+// the bundle round-trips without knowing each setting's type.
+func (m *Manager) ExportAll(ctx context.Context, w io.Writer, includeSecrets bool) error {
+	storedValues, err := m.listWoxSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load wox settings: %w", err)
+	}
+	pluginValues, err := m.listPluginSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load plugin settings: %w", err)
+	}
+
+	// Sealed secrets are bound to this device's key, so the bundle always
+	// carries plaintext or nothing at all; listed values are already opened.
+	woxValues := make(map[string]string, len(storedValues))
+	for key, value := range storedValues {
+		if key == LastModifiedKey {
+			continue
+		}
+		if !includeSecrets {
+			value, err = stripSecretSettingValue(key, value)
+			if err != nil {
+				return fmt.Errorf("failed to strip secrets from setting %s: %w", key, err)
+			}
+		}
+		woxValues[key] = value
+	}
+
+	manifest := SettingBundleManifest{
+		SchemaVersion:  SettingBundleSchemaVersion,
+		Timestamp:      util.GetSystemTimestamp(),
+		IncludeSecrets: includeSecrets,
+	}
+
+	zipWriter := zip.NewWriter(w)
+	for name, content := range map[string]any{
+		settingBundleManifestName:       manifest,
+		settingBundleWoxSettingsName:    woxValues,
+		settingBundlePluginSettingsName: pluginValues,
+	} {
+		entry, err := zipWriter.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to create bundle entry %s: %w", name, err)
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(content); err != nil {
+			return fmt.Errorf("failed to write bundle entry %s: %w", name, err)
+		}
+	}
+
+	logger.Info(ctx, fmt.Sprintf("exported settings bundle: wox settings=%d, plugins=%d, include secrets=%t", len(woxValues), len(pluginValues), includeSecrets))
+	return zipWriter.Close()
+}
+
+// ImportAll replaces all Wox and plugin settings with the content of a bundle
+// produced by ExportAll. The current user data is backed up first so a bad
+// import can be rolled back through the regular restore flow. A bundle
+// exported without secrets keeps the secrets stored locally. Values are
+// written through the setting stores, so they are synced like regular
+// changes, and derived state such as the language, proxy and hotkeys is
+// re-applied for every changed setting.
+func (m *Manager) ImportAll(ctx context.Context, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read settings bundle: %w", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("invalid settings bundle: %w", err)
+	}
+
+	var manifest SettingBundleManifest
+	if err := readSettingBundleEntry(zipReader, settingBundleManifestName, &manifest); err != nil {
+		return err
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > SettingBundleSchemaVersion {
+		return fmt.Errorf("unsupported settings bundle version %d, this Wox supports up to %d", manifest.SchemaVersion, SettingBundleSchemaVersion)
+	}
+
+	var woxValues map[string]string
+	if err := readSettingBundleEntry(zipReader, settingBundleWoxSettingsName, &woxValues); err != nil {
+		return err
+	}
+	var pluginValues map[string]map[string]string
+	if err := readSettingBundleEntry(zipReader, settingBundlePluginSettingsName, &pluginValues); err != nil {
+		return err
+	}
+
+	if err := m.Backup(ctx, BackupTypeImport); err != nil {
+		return fmt.Errorf("failed to backup current settings before import: %w", err)
+	}
+
+	if !manifest.IncludeSecrets {
+		localValues, err := m.listWoxSettings()
+		if err != nil {
+			return fmt.Errorf("failed to load local secrets: %w", err)
+		}
+		for key, value := range woxValues {
+			if woxValues[key], err = keepLocalSecretSettingValue(key, value, localValues[key]); err != nil {
+				return fmt.Errorf("failed to keep local secrets of setting %s: %w", key, err)
+			}
+		}
+	}
+
+	oldValues := m.serializedWoxSettingValues()
+	if err := m.replaceWoxSettings(ctx, woxValues, nil); err != nil {
+		return fmt.Errorf("failed to import settings bundle: %w", err)
+	}
+	if err := m.replacePluginSettings(ctx, pluginValues); err != nil {
+		return fmt.Errorf("failed to import plugin settings from bundle: %w", err)
+	}
+
+	logger.Info(ctx, fmt.Sprintf("imported settings bundle from %s: wox settings=%d, plugins=%d", util.FormatTimestamp(manifest.Timestamp), len(woxValues), len(pluginValues)))
+	m.notifyChangedSettings(ctx, oldValues, SettingAuditSourceImport)
+	return nil
+}
+
+func readSettingBundleEntry(zipReader *zip.Reader, name string, target any) error {
+	file, err := zipReader.Open(name)
+	if err != nil {
+		return fmt.Errorf("settings bundle is missing %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(target); err != nil {
+		return fmt.Errorf("failed to parse %s in settings bundle: %w", name, err)
+	}
+	return nil
+}
+
+// stripSecretSettingValue blanks secret fields so a bundle can be shared without credentials.
+func stripSecretSettingValue(key string, raw string) (string, error) {
+	sealer, ok := secretSettingSealers[key]
+	if !ok || raw == "" {
+		return raw, nil
+	}
+	return sealer(raw, func(string) (string, error) { return "", nil })
+}
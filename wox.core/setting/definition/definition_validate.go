@@ -0,0 +1,85 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"wox/setting/validator"
+
+	"github.com/samber/lo"
+)
+
+// Validate checks a value about to be saved for key against the plugin's setting
+// definitions, so a UI bug or an external edit cannot store a non-numeric value
+// for a number setting or an option a select does not offer. Keys without a
+// definition are accepted because plugins may also save settings of their own.
+func (c PluginSettingDefinitions) Validate(key string, value string) error {
+	for _, item := range c {
+		if item.Value == nil || item.Value.GetKey() != key {
+			continue
+		}
+
+		switch v := item.Value.(type) {
+		case *PluginSettingValueTextBox:
+			return validateWithValidators(key, value, v.Validators)
+		case *PluginSettingValueCheckBox:
+			if value != "true" && value != "false" {
+				return fmt.Errorf("setting %s must be true or false, got %q", key, value)
+			}
+		case *PluginSettingValueSelect:
+			if err := validateSelectValue(key, value, v); err != nil {
+				return err
+			}
+			return validateWithValidators(key, value, v.Validators)
+		case *PluginSettingValueTable:
+			if value != "" && !json.Valid([]byte(value)) {
+				return fmt.Errorf("setting %s must be a JSON encoded table", key)
+			}
+		}
+		return nil
+	}
+
+	return nil
+}
+
+func validateSelectValue(key string, value string, selectValue *PluginSettingValueSelect) error {
+	// options of some selects are filled at runtime, there is nothing to compare against
+	if len(selectValue.Options) == 0 {
+		return nil
+	}
+
+	allowed := lo.Map(selectValue.Options, func(option PluginSettingValueSelectOption, _ int) string {
+		return option.Value
+	})
+
+	if !selectValue.IsMulti {
+		if !lo.Contains(allowed, value) {
+			return fmt.Errorf("setting %s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+		}
+		return nil
+	}
+
+	// multi select values are stored as a comma separated list, matching the settings UI
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == PluginSettingValueSelectOptionValueSelectAll {
+			continue
+		}
+		if !lo.Contains(allowed, part) {
+			return fmt.Errorf("setting %s does not offer option %q, allowed options are %s", key, part, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+func validateWithValidators(key string, value string, validators []validator.PluginSettingValidator) error {
+	for _, settingValidator := range validators {
+		if settingValidator.Value == nil {
+			continue
+		}
+		if err := settingValidator.Value.Validate(value); err != nil {
+			return fmt.Errorf("invalid value for setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
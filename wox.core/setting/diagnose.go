@@ -0,0 +1,241 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"wox/resource"
+	"wox/util"
+	"wox/util/autostart"
+	"wox/util/hotkey"
+)
+
+type DiagnosticStatus string
+
+const (
+	DiagnosticStatusPass DiagnosticStatus = "pass"
+	DiagnosticStatusWarn DiagnosticStatus = "warn"
+	DiagnosticStatusFail DiagnosticStatus = "fail"
+)
+
+// DiagnosticCheck is the outcome of one settings health check.
+type DiagnosticCheck struct {
+	Name    string
+	Status  DiagnosticStatus
+	Message string
+}
+
+// DiagnosticReport collects the settings health checks so users can paste it
+// into bug reports.
+type DiagnosticReport struct {
+	Timestamp int64
+	Platform  string
+	Checks    []DiagnosticCheck
+}
+
+var errStoredValueInvalid = errors.New("stored value is not valid")
+
+// appDataSettingKeys are the WoxSetting fields that hold usage data rather
+// than user preferences. They are checked separately so a corrupt history is
+// not reported as a broken configuration.
+var appDataSettingKeys = map[string]bool{
+	"QueryCompletionFeedbacks": true,
+	"PinedResults":             true,
+	"ActionedResults":          true,
+	"FavoriteLabels":           true,
+}
+
+// Diagnose runs read-only health checks over the settings and the environment
+// they depend on. Checks never change settings; Init still owns the autostart
+// repair.
+func (m *Manager) Diagnose(ctx context.Context) DiagnosticReport {
+	return DiagnosticReport{
+		Timestamp: util.GetSystemTimestamp(),
+		Platform:  util.GetCurrentPlatform(),
+		Checks: []DiagnosticCheck{
+			m.diagnoseStoredSettings("settings", false, util.GetLocation().GetWoxSettingPath()),
+			m.diagnoseStoredSettings("app data", true, util.GetLocation().GetWoxAppDataPath()),
+			m.diagnoseAppDataSize(ctx),
+			m.diagnoseAutostart(ctx),
+			m.diagnoseProxy(ctx),
+			m.diagnoseHotkeys(ctx),
+			m.diagnoseTheme(ctx),
+			diagnosePluginSettingDirectory(),
+		},
+	}
+}
+
+// String formats the report as plain text, one line per check.
+func (r DiagnosticReport) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Wox settings diagnostics (%s, %s)\n", r.Platform, util.FormatTimestampWithMs(r.Timestamp)))
+	for _, check := range r.Checks {
+		builder.WriteString(fmt.Sprintf("[%s] %s: %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message))
+	}
+	return builder.String()
+}
+
+// diagnoseStoredSettings decodes every stored WoxSetting row of one group.
+// legacyPath is the JSON file used before settings moved into the database;
+// it is only read by the migration, so a broken file is a warning.
+func (m *Manager) diagnoseStoredSettings(name string, appData bool, legacyPath string) DiagnosticCheck {
+	var failed []string
+	var invalid []string
+
+	settingValue := reflect.ValueOf(m.currentWoxSetting()).Elem()
+	settingType := settingValue.Type()
+	for i := 0; i < settingValue.NumField(); i++ {
+		if appDataSettingKeys[settingType.Field(i).Name] != appData {
+			continue
+		}
+		field := settingValue.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		value, ok := field.Interface().(interface {
+			checkStored() error
+			Key() string
+		})
+		if !ok {
+			continue
+		}
+
+		if err := value.checkStored(); err != nil {
+			if errors.Is(err, errStoredValueInvalid) {
+				invalid = append(invalid, value.Key())
+			} else {
+				failed = append(failed, fmt.Sprintf("%s (%s)", value.Key(), err.Error()))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("failed to decode %s, defaults are used instead", strings.Join(failed, ", "))}
+	}
+	if len(invalid) > 0 {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusWarn, Message: fmt.Sprintf("invalid stored values for %s, defaults are used instead", strings.Join(invalid, ", "))}
+	}
+	if content, err := os.ReadFile(legacyPath); err == nil && !json.Valid(content) {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusWarn, Message: fmt.Sprintf("legacy file %s is not valid json", legacyPath)}
+	}
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: "all stored values are readable"}
+}
+
+func (m *Manager) diagnoseAutostart(ctx context.Context) DiagnosticCheck {
+	const name = "autostart"
+	configured := m.currentWoxSetting().EnableAutostart.Get()
+	actual, err := autostart.IsAutostart(ctx)
+	if err != nil {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("failed to read autostart status: %s", err.Error())}
+	}
+	if actual != configured {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusWarn, Message: fmt.Sprintf("setting is %v but the system reports %v", configured, actual)}
+	}
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: fmt.Sprintf("enabled: %v", configured)}
+}
+
+func (m *Manager) diagnoseProxy(ctx context.Context) DiagnosticCheck {
+	const name = "proxy"
+	if !m.currentWoxSetting().HttpProxyEnabled.Get() {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: "disabled"}
+	}
+
+	proxyUrl := m.currentWoxSetting().HttpProxyUrl.Get()
+	if strings.TrimSpace(proxyUrl) == "" {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: "enabled but no proxy url is configured"}
+	}
+	if _, err := util.ParseProxyURL(proxyUrl); err != nil {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("invalid proxy url: %s", err.Error())}
+	}
+	if err := util.TestProxy(ctx, proxyUrl); err != nil {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("proxy is not reachable: %s", err.Error())}
+	}
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: "proxy is reachable"}
+}
+
+// diagnoseHotkeys checks that every configured hotkey parses and that no two
+// settings share one. Registered hotkeys are not probed with the OS because
+// Wox itself holds them, so a probe would always report them as taken.
+func (m *Manager) diagnoseHotkeys(ctx context.Context) DiagnosticCheck {
+	const name = "hotkeys"
+	bindings := m.HotkeyBindings(ctx)
+
+	var invalid []string
+	for _, binding := range bindings {
+		if strings.TrimSpace(binding.Hotkey) == "" {
+			continue
+		}
+		if _, err := hotkey.Parse(binding.Hotkey); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s %q (%s)", binding.Setting, binding.Hotkey, err.Error()))
+		}
+	}
+	if len(invalid) > 0 {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("invalid hotkeys: %s", strings.Join(invalid, ", "))}
+	}
+
+	conflicts := FindHotkeyConflicts(bindings)
+	if len(conflicts) > 0 {
+		var descriptions []string
+		for _, conflict := range conflicts {
+			descriptions = append(descriptions, fmt.Sprintf("%s is used by %s and %s", conflict.Hotkey, conflict.First.Setting, conflict.Second.Setting))
+		}
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusWarn, Message: strings.Join(descriptions, "; ")}
+	}
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: fmt.Sprintf("%d hotkeys configured", len(bindings))}
+}
+
+// diagnoseTheme looks the configured theme up among the embedded themes and
+// the user theme directory, the same places the UI loads themes from.
+func (m *Manager) diagnoseTheme(ctx context.Context) DiagnosticCheck {
+	const name = "theme"
+	themeId := m.currentWoxSetting().ThemeId.Get()
+
+	var contents []string
+	contents = append(contents, resource.GetEmbedThemes(ctx)...)
+	themeDirectory := util.GetLocation().GetThemeDirectory()
+	if entries, err := os.ReadDir(themeDirectory); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if content, readErr := os.ReadFile(filepath.Join(themeDirectory, entry.Name())); readErr == nil {
+				contents = append(contents, string(content))
+			}
+		}
+	}
+
+	for _, content := range contents {
+		var theme struct{ ThemeId string }
+		if json.Unmarshal([]byte(content), &theme) == nil && theme.ThemeId == themeId {
+			return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: fmt.Sprintf("theme %s is installed", themeId)}
+		}
+	}
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusWarn, Message: fmt.Sprintf("theme %s is not installed, the default theme is used instead", themeId)}
+}
+
+// diagnosePluginSettingDirectory checks that the directory holding legacy
+// setting files and plugin data such as clipboard images is writable.
+func diagnosePluginSettingDirectory() DiagnosticCheck {
+	const name = "plugin setting directory"
+	directory := util.GetLocation().GetPluginSettingDirectory()
+	info, err := os.Stat(directory)
+	if err != nil {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("cannot access %s: %s", directory, err.Error())}
+	}
+	if !info.IsDir() {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("%s is not a directory", directory)}
+	}
+
+	probe, err := os.CreateTemp(directory, ".wox-diagnose-*")
+	if err != nil {
+		return DiagnosticCheck{Name: name, Status: DiagnosticStatusFail, Message: fmt.Sprintf("%s is not writable: %s", directory, err.Error())}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return DiagnosticCheck{Name: name, Status: DiagnosticStatusPass, Message: fmt.Sprintf("%s is writable", directory)}
+}
@@ -0,0 +1,66 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SettingChange is one field that differs between two WoxSetting values.
+// Values are serialized the same way they are sent to /setting/wox/update.
+type SettingChange struct {
+	Key      string // field name, without the @platform suffix for platform settings
+	OldValue string
+	NewValue string
+}
+
+// settingValueSerializer is implemented by every value type stored in WoxSetting.
+type settingValueSerializer interface {
+	serializedValue() (string, error)
+}
+
+// serializedValue returns the current value in its stored form. Platform
+// values are bound to the current platform's key, so this is the value for the
+// current platform only.
+func (v *SettingValue[T]) serializedValue() (string, error) {
+	return SerializeValue(v.Get())
+}
+
+// DiffWoxSetting compares candidate against the current settings and returns
+// every field whose value differs. Nil fields in candidate are treated as
+// unchanged, so a partially filled candidate only reports what it sets.
+func (m *Manager) DiffWoxSetting(ctx context.Context, candidate *WoxSetting) []SettingChange {
+	if candidate == nil {
+		return nil
+	}
+
+	current := reflect.ValueOf(m.currentWoxSetting()).Elem()
+	next := reflect.ValueOf(candidate).Elem()
+	fieldTypes := current.Type()
+
+	var changes []SettingChange
+	for i := 0; i < current.NumField(); i++ {
+		currentField, nextField := current.Field(i), next.Field(i)
+		if currentField.IsNil() || nextField.IsNil() {
+			continue
+		}
+		currentValue, ok := currentField.Interface().(settingValueSerializer)
+		if !ok {
+			continue
+		}
+		nextValue := nextField.Interface().(settingValueSerializer)
+
+		key := fieldTypes.Field(i).Name
+		oldValue, oldErr := currentValue.serializedValue()
+		newValue, newErr := nextValue.serializedValue()
+		if oldErr != nil || newErr != nil {
+			logger.Warn(ctx, fmt.Sprintf("failed to compare setting %s: %v", key, errors.Join(oldErr, newErr)))
+			continue
+		}
+		if oldValue != newValue {
+			changes = append(changes, SettingChange{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes
+}
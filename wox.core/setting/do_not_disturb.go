@@ -0,0 +1,54 @@
+package setting
+
+import (
+	"time"
+)
+
+const doNotDisturbTimeLayout = "15:04"
+
+// IsValidDoNotDisturbTime accepts an empty value, which turns the schedule off,
+// or a 24 hour clock time such as 21:00.
+func IsValidDoNotDisturbTime(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse(doNotDisturbTimeLayout, value)
+	return err == nil
+}
+
+// IsDoNotDisturbActive reports whether notifications should be hidden at now,
+// either because DoNotDisturb is switched on or because now falls inside the
+// scheduled window.
+func (m *Manager) IsDoNotDisturbActive(now time.Time) bool {
+	woxSetting := m.currentWoxSetting()
+	if woxSetting.DoNotDisturb.Get() {
+		return true
+	}
+	return IsInDoNotDisturbWindow(woxSetting.DoNotDisturbStart.Get(), woxSetting.DoNotDisturbEnd.Get(), now)
+}
+
+// IsInDoNotDisturbWindow reports whether the local clock time of now lies in
+// [start, end). A window whose end is before its start spans midnight, so
+// 21:00-08:00 covers the night. The schedule is off when either bound is empty
+// or invalid, or when both bounds are equal.
+func IsInDoNotDisturbWindow(start string, end string, now time.Time) bool {
+	if start == "" || end == "" {
+		return false
+	}
+	startTime, startErr := time.Parse(doNotDisturbTimeLayout, start)
+	endTime, endErr := time.Parse(doNotDisturbTimeLayout, end)
+	if startErr != nil || endErr != nil {
+		return false
+	}
+
+	startMinute := startTime.Hour()*60 + startTime.Minute()
+	endMinute := endTime.Hour()*60 + endTime.Minute()
+	nowMinute := now.Hour()*60 + now.Minute()
+	if startMinute == endMinute {
+		return false
+	}
+	if startMinute < endMinute {
+		return nowMinute >= startMinute && nowMinute < endMinute
+	}
+	return nowMinute >= startMinute || nowMinute < endMinute
+}
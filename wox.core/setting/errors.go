@@ -0,0 +1,73 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for failed setting updates. Updates return them wrapped in
+// a *SettingUpdateError, so callers can tell the cases apart with errors.Is
+// and still log a readable message.
+var (
+	ErrUnknownSettingKey   = errors.New("unknown setting key")
+	ErrInvalidSettingValue = errors.New("invalid setting value")
+	ErrSettingSaveFailed   = errors.New("failed to save setting")
+	ErrSettingPolicyLocked = errors.New("setting is locked by machine policy")
+)
+
+// SettingUpdateError describes why updating the setting Key failed. Kind is
+// one of the sentinel errors above, Err holds the details.
+type SettingUpdateError struct {
+	Key  string
+	Kind error
+	Err  error
+}
+
+func (e *SettingUpdateError) Error() string {
+	switch {
+	case e.Err == nil:
+		return fmt.Sprintf("%s: %s", e.Kind.Error(), e.Key)
+	case errors.Is(e.Kind, ErrInvalidSettingValue):
+		// Validation messages already name the problem, e.g. "invalid show position: x".
+		return e.Err.Error()
+	default:
+		return fmt.Sprintf("%s %s: %s", e.Kind.Error(), e.Key, e.Err.Error())
+	}
+}
+
+func (e *SettingUpdateError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Code returns a stable identifier of Kind for API responses.
+func (e *SettingUpdateError) Code() string {
+	switch {
+	case errors.Is(e.Kind, ErrUnknownSettingKey):
+		return "unknown_key"
+	case errors.Is(e.Kind, ErrInvalidSettingValue):
+		return "invalid_value"
+	case errors.Is(e.Kind, ErrSettingPolicyLocked):
+		return "policy_locked"
+	default:
+		return "save_failed"
+	}
+}
+
+func NewUnknownSettingKeyError(key string) error {
+	return &SettingUpdateError{Key: key, Kind: ErrUnknownSettingKey}
+}
+
+func NewInvalidSettingValueError(key string, err error) error {
+	return &SettingUpdateError{Key: key, Kind: ErrInvalidSettingValue, Err: err}
+}
+
+func NewSettingSaveError(key string, err error) error {
+	return &SettingUpdateError{Key: key, Kind: ErrSettingSaveFailed, Err: err}
+}
+
+func NewSettingPolicyLockedError(key string, err error) error {
+	return &SettingUpdateError{Key: key, Kind: ErrSettingPolicyLocked, Err: err}
+}
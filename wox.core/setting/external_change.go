@@ -0,0 +1,271 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+	"wox/database"
+	"wox/util"
+
+	"github.com/fsnotify/fsnotify"
+	"gorm.io/gorm"
+)
+
+// externalChangeDebounce groups the burst of file events a single sqlite commit produces.
+const externalChangeDebounce = 500 * time.Millisecond
+
+// settingWriteTracker remembers the stored value of every Wox setting row as last
+// seen or written by this process. Comparing it with the database tells external
+// edits (a sqlite client, a sync tool writing wox.db in place) apart from our own
+// writes, which are recorded here while they happen.
+type settingWriteTracker struct {
+	mu      sync.Mutex
+	enabled bool
+	values  map[string]string
+}
+
+var selfWrites = &settingWriteTracker{}
+
+// trackedWrite is one row written by this process, see settingWriteTracker.
+type trackedWrite struct {
+	key     string
+	value   string
+	deleted bool
+	// lastModified is the LastModified stamp stored along with the row.
+	lastModified string
+}
+
+// track runs write while holding the tracker, so the watcher never sees a row
+// that was written by us but not yet recorded. deleted marks a row removal.
+// write returns the LastModified stamp it stored along with the row.
+func (t *settingWriteTracker) track(key string, value string, deleted bool, write func() (string, error)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lastModified, err := write()
+	if err != nil {
+		return err
+	}
+	t.record(trackedWrite{key: key, value: value, deleted: deleted, lastModified: lastModified})
+	return nil
+}
+
+// trackTransaction runs a transaction while holding the tracker and records
+// the writes it collected once it committed. A rolled back transaction leaves
+// the tracker untouched.
+func (t *settingWriteTracker) trackTransaction(writes *[]trackedWrite, run func() error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := run(); err != nil {
+		return err
+	}
+	for _, write := range *writes {
+		t.record(write)
+	}
+	return nil
+}
+
+// record stores one write. The caller holds t.mu.
+func (t *settingWriteTracker) record(write trackedWrite) {
+	if !t.enabled {
+		return
+	}
+	if write.deleted {
+		delete(t.values, write.key)
+	} else {
+		t.values[write.key] = write.value
+	}
+	t.values[LastModifiedKey] = write.lastModified
+}
+
+// externalValue reports whether the stored row of key changed since this
+// process last saw or wrote it, and returns the stored value. The
+// LastModified stamp is checked first, so the common case of no external
+// change costs one small read.
+func (t *settingWriteTracker) externalValue(db *gorm.DB, key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.enabled {
+		return "", false
+	}
+
+	var rows []database.WoxSetting
+	if err := db.Where("key IN ?", []string{LastModifiedKey, key}).Find(&rows).Error; err != nil {
+		return "", false
+	}
+	stored := map[string]string{}
+	for _, row := range rows {
+		stored[row.Key] = row.Value
+	}
+	if stored[LastModifiedKey] == t.values[LastModifiedKey] || stored[key] == t.values[key] {
+		return "", false
+	}
+	return stored[key], true
+}
+
+// stampChanged reports whether the stored LastModified stamp differs from the
+// one this process wrote or saw last. Every write through WoxSettingStore
+// advances the stamp and records it here, so writes of this process, and
+// writes to other tables of wox.db, never count as external changes.
+func (t *settingWriteTracker) stampChanged(db *gorm.DB) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.enabled {
+		return false, nil
+	}
+
+	var row database.WoxSetting
+	if err := db.Where("key = ?", LastModifiedKey).Find(&row).Error; err != nil {
+		return false, err
+	}
+	return row.Value != t.values[LastModifiedKey], nil
+}
+
+// diff loads the current rows and returns the keys that changed since the last
+// call, together with their new stored values. Removed keys map to "".
+func (t *settingWriteTracker) diff(db *gorm.DB) (map[string]string, error) {
+	var rows []database.WoxSetting
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[string]string, len(rows))
+	changed := map[string]string{}
+	for _, row := range rows {
+		current[row.Key] = row.Value
+		if previous, ok := t.values[row.Key]; !ok || previous != row.Value {
+			changed[row.Key] = row.Value
+		}
+	}
+	for key := range t.values {
+		if _, ok := current[key]; !ok {
+			changed[key] = ""
+		}
+	}
+
+	t.values = current
+	t.enabled = true
+	return changed, nil
+}
+
+// OnSettingChanged registers a handler that is called for every Wox setting
+// changed outside the settings API, e.g. by an external edit of wox.db.
+func (m *Manager) OnSettingChanged(handler func(ctx context.Context, key string, value string)) {
+	m.settingChangeHandlersMu.Lock()
+	defer m.settingChangeHandlersMu.Unlock()
+
+	m.settingChangeHandlers = append(m.settingChangeHandlers, handler)
+}
+
+// watchExternalChanges reloads settings when wox.db is modified by another process.
+// Only in-place modifications are seen; a file replaced by rename keeps the
+// running connections on the old file until restart. Changes are detected by
+// the LastModified stamp, so edits that do not advance it, e.g. a row changed
+// by hand in a sqlite client, are only picked up on the next start. Settings
+// that are not kept in wox.db, e.g. in a memory store, are not watched.
+func (m *Manager) watchExternalChanges(ctx context.Context) error {
+	db, ok := m.settingDB()
+	if !ok {
+		return nil
+	}
+	if _, err := selfWrites.diff(db); err != nil {
+		return fmt.Errorf("failed to snapshot settings: %w", err)
+	}
+
+	var debounceTimer *time.Timer
+	var debounceMu sync.Mutex
+	_, err := util.WatchDirectoryChanges(ctx, util.GetLocation().GetUserDataDirectory(), func(event fsnotify.Event) {
+		if filepath.Base(event.Name) != "wox.db" || !event.Has(fsnotify.Write|fsnotify.Create) {
+			return
+		}
+
+		debounceMu.Lock()
+		defer debounceMu.Unlock()
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+		}
+		debounceTimer = time.AfterFunc(externalChangeDebounce, func() {
+			m.applyExternalChanges(util.NewTraceContext(), db)
+		})
+	})
+	return err
+}
+
+func (m *Manager) applyExternalChanges(ctx context.Context, db *gorm.DB) {
+	// Most events come from our own writes, which only need the stamp read.
+	if changed, err := selfWrites.stampChanged(db); err != nil || !changed {
+		if err != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to check external setting changes: %s", err.Error()))
+		}
+		return
+	}
+
+	// Save pending app data first so the reload below does not discard it.
+	if err := m.Flush(ctx); err != nil {
+		logger.Warn(ctx, fmt.Sprintf("failed to flush app data before reload: %s", err.Error()))
+	}
+
+	changed, err := selfWrites.diff(db)
+	if err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to check external setting changes: %s", err.Error()))
+		return
+	}
+	delete(changed, LastModifiedKey)
+	if len(changed) == 0 {
+		return
+	}
+
+	logger.Info(ctx, fmt.Sprintf("detected %d externally changed setting(s), reloading", len(changed)))
+	oldValues := map[string]string{}
+	for key := range changed {
+		if oldValue, ok := m.SerializedWoxSettingValue(key); ok {
+			oldValues[key] = oldValue
+		}
+	}
+	m.reloadWoxSetting()
+
+	for key, storedValue := range changed {
+		if key == LastModifiedKey {
+			continue
+		}
+		value, openErr := OpenStoredSettingValue(key, storedValue)
+		if openErr != nil {
+			logger.Warn(ctx, fmt.Sprintf("failed to open externally changed setting %s: %s", key, openErr.Error()))
+			continue
+		}
+		if oldValue, ok := oldValues[key]; ok {
+			m.RecordSettingAudit(ctx, key, oldValue, value, SettingAuditSourceExternal)
+		}
+		m.notifySettingChanged(ctx, key, value)
+	}
+}
+
+// settingDB returns the database the Wox settings are stored in. It reports
+// false when the store keeps them elsewhere, e.g. NewMemorySettingStore or the
+// read-only overlay.
+func (m *Manager) settingDB() (*gorm.DB, bool) {
+	store, ok := m.woxStore.(*WoxSettingStore)
+	if !ok {
+		return nil, false
+	}
+	return store.db, true
+}
+
+// notifySettingChanged calls every handler registered with OnSettingChanged.
+func (m *Manager) notifySettingChanged(ctx context.Context, key string, value string) {
+	m.settingChangeHandlersMu.Lock()
+	handlers := append([]func(ctx context.Context, key string, value string){}, m.settingChangeHandlers...)
+	m.settingChangeHandlersMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(ctx, key, value)
+	}
+}
@@ -0,0 +1,138 @@
+package setting
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"wox/util"
+)
+
+// FavoriteExport is one favorite in the file written by ExportFavorites. Hash
+// is what identifies the favorite; plugin, title and subtitle are informative
+// and only filled in when this install knows them.
+type FavoriteExport struct {
+	Hash     ResultHash
+	PluginId string `json:",omitempty"`
+	Title    string `json:",omitempty"`
+	SubTitle string `json:",omitempty"`
+	Label    string `json:",omitempty"`
+}
+
+// FavoriteImportResult counts what ImportFavorites did with the entries of a
+// file. Skipped entries were already favorites (or repeated in the file),
+// invalid ones are reported in Warnings.
+type FavoriteImportResult struct {
+	Added    int
+	Skipped  int
+	Invalid  int
+	Warnings []string
+}
+
+// ExportFavorites writes the favorites and their labels as the JSON array
+// read by ImportFavorites.
+func (m *Manager) ExportFavorites(ctx context.Context, w io.Writer) error {
+	// Favorites sort first, so the first PinedResults.Len() results hold all
+	// favorites GetRecentResults can render.
+	var exports []FavoriteExport
+	for _, result := range m.GetRecentResults(ctx, m.currentWoxSetting().PinedResults.Get().Len()) {
+		if result.IsFavorite {
+			exports = append(exports, FavoriteExport{
+				Hash:     result.Hash,
+				PluginId: result.PluginId,
+				Title:    result.Title,
+				SubTitle: result.SubTitle,
+				Label:    result.Label,
+			})
+		}
+	}
+
+	// Favorites GetRecentResults cannot render have no metadata but are kept.
+	exported := map[ResultHash]bool{}
+	for _, export := range exports {
+		exported[export.Hash] = true
+	}
+	m.appDataMu.RLock()
+	woxSetting := m.currentWoxSetting()
+	favoriteLabels := woxSetting.FavoriteLabels.Get()
+	woxSetting.PinedResults.Get().Range(func(hash ResultHash, pinned bool) bool {
+		if pinned && !exported[hash] {
+			label, _ := favoriteLabels.Load(hash)
+			exports = append(exports, FavoriteExport{Hash: hash, Label: label})
+		}
+		return true
+	})
+	m.appDataMu.RUnlock()
+
+	if exports == nil {
+		exports = []FavoriteExport{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exports); err != nil {
+		return fmt.Errorf("failed to encode favorites: %w", err)
+	}
+	return nil
+}
+
+// ImportFavorites reads a JSON array written by ExportFavorites. With merge
+// the entries are added to the current favorites, otherwise they replace them
+// (labels included). Nothing is changed when the result would exceed
+// MaxFavoriteResults.
+func (m *Manager) ImportFavorites(ctx context.Context, r io.Reader, merge bool) (FavoriteImportResult, error) {
+	var imported []FavoriteExport
+	if err := json.NewDecoder(r).Decode(&imported); err != nil {
+		return FavoriteImportResult{}, fmt.Errorf("failed to decode favorites: %w", err)
+	}
+
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	woxSetting := m.currentWoxSetting()
+	favorites := util.NewHashMap[ResultHash, bool]()
+	labels := util.NewHashMap[ResultHash, string]()
+	if merge {
+		favorites = woxSetting.PinedResults.Get().Clone()
+		labels = woxSetting.FavoriteLabels.Get().Clone()
+	}
+
+	var result FavoriteImportResult
+	for i, entry := range imported {
+		if !isValidResultHash(entry.Hash) {
+			result.Invalid++
+			result.Warnings = append(result.Warnings, fmt.Sprintf("entry %d skipped: invalid hash %q", i+1, entry.Hash))
+			continue
+		}
+		if favorites.Exist(entry.Hash) {
+			result.Skipped++
+			continue
+		}
+
+		favorites.Store(entry.Hash, true)
+		if label := strings.TrimSpace(entry.Label); label != "" {
+			labels.Store(entry.Hash, label)
+		}
+		result.Added++
+	}
+
+	if limit := woxSetting.MaxFavoriteResults.Get(); favorites.Len() > limit {
+		return FavoriteImportResult{}, fmt.Errorf("%w: importing would give %d favorites but at most %d are allowed", ErrFavoriteResultsLimitReached, favorites.Len(), limit)
+	}
+
+	saveAppData(ctx, m, woxSetting.PinedResults, favorites)
+	saveAppData(ctx, m, woxSetting.FavoriteLabels, labels)
+	logger.Info(ctx, fmt.Sprintf("imported favorites: merge=%t, added=%d, skipped=%d, invalid=%d", merge, result.Added, result.Skipped, result.Invalid))
+	return result, nil
+}
+
+// isValidResultHash reports whether hash looks like the hex MD5 digest every
+// ResultHash constructor produces.
+func isValidResultHash(hash ResultHash) bool {
+	if len(hash) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(string(hash))
+	return err == nil
+}
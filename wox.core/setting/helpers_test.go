@@ -0,0 +1,54 @@
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestDB opens an empty wox.db with the tables the setting package uses.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	if logger == nil {
+		logger = util.GetLogger()
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "wox.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get underlying sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(
+		&database.WoxSetting{},
+		&database.PluginSetting{},
+		&database.Oplog{},
+		&database.MRURecord{},
+		&database.QueryHistoryRecord{},
+		&database.MigrationRecord{},
+	); err != nil {
+		t.Fatalf("failed to migrate schema: %v", err)
+	}
+	return db
+}
+
+// storedRow returns the raw stored value of key, or "" when there is none.
+func storedRow(t *testing.T, db *gorm.DB, key string) string {
+	t.Helper()
+	var row database.WoxSetting
+	if err := db.Where("key = ?", key).Limit(1).Find(&row).Error; err != nil {
+		t.Fatalf("failed to read %s: %v", key, err)
+	}
+	return row.Value
+}
@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+)
+
+// PushHideOnLostFocusSuppression keeps the launcher visible when it loses focus
+// until the matching PopHideOnLostFocusSuppression, e.g. while a plugin shows
+// an external picker dialog. Calls are reference counted so nested modal
+// interactions restore the user's HideOnLostFocus setting only after the
+// outermost one ends. The stored setting itself is never changed.
+func (m *Manager) PushHideOnLostFocusSuppression(ctx context.Context) {
+	m.hideOnLostFocusSuppressionMu.Lock()
+	defer m.hideOnLostFocusSuppressionMu.Unlock()
+	m.hideOnLostFocusSuppression++
+}
+
+// PopHideOnLostFocusSuppression releases one PushHideOnLostFocusSuppression.
+// An unbalanced pop is logged and ignored instead of going negative, which
+// would otherwise swallow the next push.
+func (m *Manager) PopHideOnLostFocusSuppression(ctx context.Context) {
+	m.hideOnLostFocusSuppressionMu.Lock()
+	defer m.hideOnLostFocusSuppressionMu.Unlock()
+	if m.hideOnLostFocusSuppression == 0 {
+		logger.Warn(ctx, "hide on lost focus suppression popped without a matching push")
+		return
+	}
+	m.hideOnLostFocusSuppression--
+}
+
+// ShouldHideOnLostFocus returns the effective HideOnLostFocus value: the user
+// setting, unless a suppression is active.
+func (m *Manager) ShouldHideOnLostFocus(ctx context.Context) bool {
+	m.hideOnLostFocusSuppressionMu.Lock()
+	suppression := m.hideOnLostFocusSuppression
+	m.hideOnLostFocusSuppressionMu.Unlock()
+	if suppression > 0 {
+		logger.Debug(ctx, fmt.Sprintf("hide on lost focus suppressed by %d active request(s)", suppression))
+		return false
+	}
+	return m.GetWoxSetting(ctx).HideOnLostFocus.Get()
+}
@@ -0,0 +1,75 @@
+package setting
+
+import (
+	"context"
+	"strings"
+	"wox/util/hotkey"
+)
+
+// HotkeyBinding is one hotkey owned by a Wox setting.
+type HotkeyBinding struct {
+	Setting string // MainHotkey (for every main hotkey), SelectionHotkey or QueryHotkeys
+	Name    string // display name of the query hotkey, empty for global hotkeys
+	Hotkey  string
+}
+
+// HotkeyConflict reports two settings bound to the same key combination.
+type HotkeyConflict struct {
+	Hotkey string // canonical form shared by both bindings
+	First  HotkeyBinding
+	Second HotkeyBinding
+}
+
+// HotkeyBindings returns every non-empty hotkey configured for the current platform.
+// Disabled query hotkeys are skipped because they are never registered.
+func (m *Manager) HotkeyBindings(ctx context.Context) []HotkeyBinding {
+	var bindings []HotkeyBinding
+	for _, mainHotkey := range m.currentWoxSetting().GetMainHotkeys() {
+		bindings = append(bindings, HotkeyBinding{Setting: "MainHotkey", Hotkey: mainHotkey})
+	}
+	bindings = append(bindings, HotkeyBinding{Setting: "SelectionHotkey", Hotkey: m.currentWoxSetting().SelectionHotkey.Get()})
+	for _, queryHotkey := range m.currentWoxSetting().QueryHotkeys.Get() {
+		if queryHotkey.Disabled {
+			continue
+		}
+		bindings = append(bindings, HotkeyBinding{Setting: "QueryHotkeys", Name: queryHotkey.DisplayName(), Hotkey: queryHotkey.Hotkey})
+	}
+	return bindings
+}
+
+// CheckHotkeyConflicts returns every pair of configured hotkeys that share a key combination.
+func (m *Manager) CheckHotkeyConflicts(ctx context.Context) []HotkeyConflict {
+	return FindHotkeyConflicts(m.HotkeyBindings(ctx))
+}
+
+// FindHotkeyConflicts compares bindings by canonical hotkey so "Space+Alt" and
+// "alt+space" are reported as the same combination.
+func FindHotkeyConflicts(bindings []HotkeyBinding) []HotkeyConflict {
+	var conflicts []HotkeyConflict
+	seen := map[string][]HotkeyBinding{}
+	for _, binding := range bindings {
+		compareKey := HotkeyConflictKey(binding.Hotkey)
+		if compareKey == "" {
+			continue
+		}
+		for _, previous := range seen[compareKey] {
+			conflicts = append(conflicts, HotkeyConflict{Hotkey: compareKey, First: previous, Second: binding})
+		}
+		seen[compareKey] = append(seen[compareKey], binding)
+	}
+	return conflicts
+}
+
+// HotkeyConflictKey returns the form hotkeys are compared in, so different
+// spellings of one combination, e.g. "Alt+Space" and "alt+space", match.
+func HotkeyConflictKey(hotkeyStr string) string {
+	if strings.TrimSpace(hotkeyStr) == "" {
+		return ""
+	}
+	parsed, err := hotkey.Parse(hotkeyStr)
+	if err != nil {
+		// Unparseable values can still collide textually, e.g. after a manual edit.
+		return strings.ToLower(strings.TrimSpace(hotkeyStr))
+	}
+	return parsed.String()
+}
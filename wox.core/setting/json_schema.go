@@ -0,0 +1,242 @@
+package setting
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"wox/i18n"
+	"wox/setting/definition"
+	"wox/util"
+)
+
+const jsonSchemaDraft07 = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchemaEnumsByType lists the allowed values of named string types used in settings.
+var jsonSchemaEnumsByType = map[reflect.Type]func() []string{
+	reflect.TypeFor[PositionType](): func() []string {
+		var positions []string
+		for _, position := range ValidPositionTypes() {
+			positions = append(positions, string(position))
+		}
+		return positions
+	},
+	reflect.TypeFor[UiDensity](): func() []string {
+		return []string{string(UiDensityCompact), string(UiDensityNormal), string(UiDensityComfortable)}
+	},
+	reflect.TypeFor[ReleaseChannel](): func() []string {
+		return []string{string(ReleaseChannelStable), string(ReleaseChannelBeta)}
+	},
+	reflect.TypeFor[AutostartReconcileMode](): func() []string {
+		return []string{string(AutostartReconcileModeTrustConfig), string(AutostartReconcileModeTrustOS), string(AutostartReconcileModeAsk)}
+	},
+	reflect.TypeFor[AppDataFormat](): func() []string {
+		return []string{string(AppDataFormatJSON), string(AppDataFormatBinary)}
+	},
+	reflect.TypeFor[RankingMode](): func() []string {
+		var modes []string
+		for _, mode := range ValidRankingModes() {
+			modes = append(modes, string(mode))
+		}
+		return modes
+	},
+	reflect.TypeFor[PinYinMatchMode](): func() []string {
+		return []string{string(PinYinMatchModeOff), string(PinYinMatchModeFull), string(PinYinMatchModeInitials), string(PinYinMatchModeBoth)}
+	},
+	reflect.TypeFor[i18n.LangCode](): func() []string {
+		var codes []string
+		for _, code := range i18n.SupportedLangs() {
+			codes = append(codes, string(code))
+		}
+		return codes
+	},
+}
+
+// jsonSchemaEnumsByField covers settings whose type is an alias of string,
+// which reflection cannot tell apart from a plain string.
+var jsonSchemaEnumsByField = map[string][]string{
+	"LaunchMode": {LaunchModeFresh, LaunchModeContinue},
+	"StartPage":  {StartPageBlank, StartPageMRU},
+}
+
+// ExportJSONSchema returns a draft-07 JSON schema describing WoxSetting, so
+// external tools and editors can validate and autocomplete settings. Property
+// names match the stored setting keys; platform settings get one property per
+// platform with the usual @platform suffix.
+func ExportJSONSchema() ([]byte, error) {
+	properties := map[string]any{}
+	settingType := reflect.TypeFor[WoxSetting]()
+	for i := 0; i < settingType.NumField(); i++ {
+		field := settingType.Field(i)
+		valueType, isPlatform, ok := settingValueType(field.Type)
+		if !ok {
+			continue
+		}
+
+		schema := jsonSchemaForType(valueType, map[reflect.Type]bool{})
+		if enum, ok := jsonSchemaEnumsByField[field.Name]; ok {
+			schema["enum"] = enum
+		}
+		if !isPlatform {
+			properties[field.Name] = schema
+			continue
+		}
+		for _, platform := range []util.Platform{util.PlatformWindows, util.PlatformMacOS, util.PlatformLinux} {
+			properties[fmt.Sprintf("%s@%s", field.Name, platform)] = schema
+		}
+	}
+
+	return marshalJSONSchema(map[string]any{
+		"$schema":    jsonSchemaDraft07,
+		"title":      "WoxSetting",
+		"type":       "object",
+		"properties": properties,
+	})
+}
+
+// ExportPluginSettingJSONSchema returns a draft-07 JSON schema for the settings
+// declared by one plugin. Plugin settings are stored as strings, so checkboxes
+// are "true"/"false" and tables and multi selects hold serialized JSON.
+func ExportPluginSettingJSONSchema(pluginName string, definitions definition.PluginSettingDefinitions) ([]byte, error) {
+	properties := map[string]any{}
+	for _, item := range definitions {
+		if item.Value == nil || item.Value.GetKey() == "" {
+			continue
+		}
+
+		schema := map[string]any{"type": "string"}
+		if defaultValue := item.Value.GetDefaultValue(); defaultValue != "" {
+			schema["default"] = defaultValue
+		}
+
+		switch value := item.Value.(type) {
+		case *definition.PluginSettingValueTextBox:
+			setJSONSchemaDescription(schema, value.Label, value.Tooltip)
+		case *definition.PluginSettingValueCheckBox:
+			setJSONSchemaDescription(schema, value.Label, value.Tooltip)
+			schema["enum"] = []string{"true", "false"}
+		case *definition.PluginSettingValueSelect:
+			setJSONSchemaDescription(schema, value.Label, value.Tooltip)
+			if !value.IsMulti {
+				var options []string
+				for _, option := range value.Options {
+					options = append(options, option.Value)
+				}
+				if len(options) > 0 {
+					schema["enum"] = options
+				}
+			}
+		case *definition.PluginSettingValueTable:
+			setJSONSchemaDescription(schema, value.Title, value.Tooltip)
+		default:
+			// head, label and newline items carry no stored value
+			if item.Type != definition.PluginSettingDefinitionTypeSelectAIModel {
+				continue
+			}
+		}
+
+		properties[item.Value.GetKey()] = schema
+	}
+
+	return marshalJSONSchema(map[string]any{
+		"$schema":    jsonSchemaDraft07,
+		"title":      pluginName,
+		"type":       "object",
+		"properties": properties,
+	})
+}
+
+// settingValueType unwraps *WoxSettingValue[T] and *PlatformValue[T] to T.
+func settingValueType(t reflect.Type) (valueType reflect.Type, isPlatform bool, ok bool) {
+	for t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct {
+		t = t.Elem()
+		if strings.HasPrefix(t.Name(), "PlatformValue[") {
+			isPlatform = true
+		}
+		if field, found := t.FieldByName("value"); found && strings.HasPrefix(t.Name(), "SettingValue[") {
+			return field.Type, isPlatform, true
+		}
+		if t.NumField() == 0 || !t.Field(0).Anonymous {
+			return nil, false, false
+		}
+		t = t.Field(0).Type
+	}
+	return nil, false, false
+}
+
+// jsonSchemaForType maps a Go type to a schema following encoding/json rules.
+// visiting guards against recursive struct types.
+func jsonSchemaForType(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if enum, ok := jsonSchemaEnumsByType[t]; ok {
+		return map[string]any{"type": "string", "enum": enum()}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchemaForType(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaForType(t.Elem(), visiting)}
+	case reflect.Struct:
+		// util.HashMap marshals as its inner map
+		if strings.HasPrefix(t.Name(), "HashMap[") {
+			if inner, ok := t.FieldByName("inner"); ok {
+				return jsonSchemaForType(inner.Type, visiting)
+			}
+		}
+		if visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag := field.Tag.Get("json"); tag != "" {
+				tagName, _, _ := strings.Cut(tag, ",")
+				if tagName == "-" {
+					continue
+				}
+				if tagName != "" {
+					name = tagName
+				}
+			}
+			properties[name] = jsonSchemaForType(field.Type, visiting)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	default:
+		return map[string]any{}
+	}
+}
+
+func setJSONSchemaDescription(schema map[string]any, label string, tooltip string) {
+	description := strings.TrimSpace(strings.Join([]string{label, tooltip}, " "))
+	if description != "" {
+		schema["description"] = description
+	}
+}
+
+func marshalJSONSchema(schema map[string]any) ([]byte, error) {
+	bytes, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode json schema: %w", err)
+	}
+	return bytes, nil
+}
@@ -0,0 +1,133 @@
+package setting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// LastModifiedKey is the wox_settings row holding the LastModified stamp.
+// It is bookkeeping, not a setting: it has no WoxSetting field and must stay
+// out of cloud sync and exports.
+const LastModifiedKey = "LastModified"
+
+// LastModified stamps every write to the Wox settings rows. When wox.db is
+// synced between machines by a cloud drive, a stamp that differs from the one
+// this process wrote last tells that another machine changed the file, even
+// before the file watcher reloads it. Counter is monotonic across machines
+// (each write takes the stored counter plus one), so it orders writes even
+// when wall clocks disagree; UnixMilli is the wall clock for humans and never
+// goes backwards.
+type LastModified struct {
+	UnixMilli int64
+	Counter   int64
+}
+
+// touchLastModified advances the stored stamp in db with a single upsert and
+// returns the new serialized value. A damaged stamp is replaced by a fresh one.
+func touchLastModified(db *gorm.DB) (string, error) {
+	now := util.GetSystemTimestamp()
+	var value string
+	err := db.Raw(`INSERT INTO wox_settings (key, value) VALUES (?, json_object('UnixMilli', ?, 'Counter', 1))
+ON CONFLICT(key) DO UPDATE SET value = CASE WHEN json_valid(value) THEN json_object(
+	'UnixMilli', max(?, ifnull(json_extract(value, '$.UnixMilli'), 0)),
+	'Counter', ifnull(json_extract(value, '$.Counter'), 0) + 1
+) ELSE excluded.value END
+RETURNING value`, LastModifiedKey, now, now).Scan(&value).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to save last modified stamp: %w", err)
+	}
+	return value, nil
+}
+
+// loadLastModified returns the stored stamp, or the zero stamp for a
+// database written before stamps existed.
+func loadLastModified(db *gorm.DB) (LastModified, error) {
+	var row database.WoxSetting
+	if err := db.Where("key = ?", LastModifiedKey).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return LastModified{}, nil
+		}
+		return LastModified{}, err
+	}
+
+	var stamp LastModified
+	if err := json.Unmarshal([]byte(row.Value), &stamp); err != nil {
+		// A damaged stamp only costs one merge; the next write replaces it.
+		return LastModified{}, nil
+	}
+	return stamp, nil
+}
+
+// GetLastModified returns the stamp of the last write to the Wox settings,
+// by this or another machine sharing wox.db. Settings that are not kept in
+// wox.db have no stamp and return the zero stamp.
+func (m *Manager) GetLastModified() (LastModified, error) {
+	db, ok := m.settingDB()
+	if !ok {
+		return LastModified{}, nil
+	}
+	return loadLastModified(db)
+}
+
+// appDataMerger is implemented by app data types that can take entries from
+// another copy, see util.HashMap.AddMissing.
+type appDataMerger[T any] interface {
+	AddMissing(other T, base T)
+	Clone() T
+}
+
+// rememberMergeBase keeps a copy of value, the stored value as this process
+// last read or wrote it, as the base of the next persistMerging. The caller
+// holds v.mu.
+func (v *SettingValue[T]) rememberMergeBase(value T) {
+	if merger, ok := any(value).(appDataMerger[T]); ok {
+		v.mergeBase = merger.Clone()
+	}
+}
+
+// persistMerging is persist for app data that may also have been changed on
+// another machine. When the stored row changed since this process last read
+// or wrote it, entries only present in the stored copy are added to the
+// in-memory value first, so a flush does not drop favorites or history
+// recorded elsewhere. Entries removed here since the last read or write stay
+// removed, and entries present in both keep the local value. Types that
+// cannot merge are written as before, with a warning. It returns the length
+// of the stored value, see writeStoreSized.
+func (v *SettingValue[T]) persistMerging() (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.isLoaded {
+		return 0, nil
+	}
+
+	store, isDatabaseStore := v.settingStore.(*WoxSettingStore)
+	if !isDatabaseStore {
+		return v.writeStoreSized(v.value)
+	}
+
+	if stored, changed := selfWrites.externalValue(store.db, v.key); changed && stored != "" {
+		if merger, ok := any(v.value).(appDataMerger[T]); ok {
+			var storedValue T
+			if err := v.settingStore.Get(v.key, &storedValue); err != nil {
+				util.GetLogger().Warn(util.NewTraceContext(), fmt.Sprintf("failed to load externally changed %s for merging: %s", v.key, err.Error()))
+			} else {
+				merger.AddMissing(storedValue, v.mergeBase)
+				util.GetLogger().Info(util.NewTraceContext(), fmt.Sprintf("merged externally changed %s before saving", v.key))
+			}
+		} else {
+			util.GetLogger().Warn(util.NewTraceContext(), fmt.Sprintf("%s was changed on another machine and is overwritten by the local copy", v.key))
+		}
+	}
+	size, err := v.writeStoreSized(v.value)
+	if err != nil {
+		return 0, err
+	}
+	v.rememberMergeBase(v.value)
+	return size, nil
+}
@@ -0,0 +1,112 @@
+package setting
+
+import (
+	"context"
+	"testing"
+	"wox/database"
+	"wox/util"
+)
+
+// useFreshWriteTracker gives the test its own selfWrites tracker and restores
+// the shared one when the test ends.
+func useFreshWriteTracker(t *testing.T) {
+	t.Helper()
+	original := selfWrites
+	selfWrites = &settingWriteTracker{}
+	t.Cleanup(func() { selfWrites = original })
+}
+
+func TestTouchLastModifiedAdvancesStamp(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := touchLastModified(db); err != nil {
+		t.Fatalf("failed to touch stamp: %v", err)
+	}
+	first, _ := loadLastModified(db)
+	if _, err := touchLastModified(db); err != nil {
+		t.Fatalf("failed to touch stamp: %v", err)
+	}
+	second, _ := loadLastModified(db)
+	if first.Counter != 1 || second.Counter != 2 || second.UnixMilli < first.UnixMilli {
+		t.Fatalf("expected the stamp to advance, got %+v then %+v", first, second)
+	}
+
+	if err := db.Save(&database.WoxSetting{Key: LastModifiedKey, Value: "damaged"}).Error; err != nil {
+		t.Fatalf("failed to damage stamp: %v", err)
+	}
+	if _, err := touchLastModified(db); err != nil {
+		t.Fatalf("failed to replace damaged stamp: %v", err)
+	}
+	if replaced, _ := loadLastModified(db); replaced.Counter != 1 {
+		t.Fatalf("expected a damaged stamp to start over, got %+v", replaced)
+	}
+}
+
+func TestPersistMergingAddsRemoteEntriesAndKeepsLocalRemovals(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	useFreshWriteTracker(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+	pinned := m.GetWoxSetting(ctx).PinedResults
+
+	initial := util.NewHashMap[ResultHash, bool]()
+	initial.Store("a", true)
+	initial.Store("b", true)
+	if err := pinned.Set(initial); err != nil {
+		t.Fatalf("failed to save favorites: %v", err)
+	}
+	if _, err := selfWrites.diff(db); err != nil {
+		t.Fatalf("failed to snapshot settings: %v", err)
+	}
+
+	// Another machine adds c to the copy it read before a was unpinned here.
+	remote := util.NewHashMap[ResultHash, bool]()
+	remote.Store("a", true)
+	remote.Store("b", true)
+	remote.Store("c", true)
+	remoteValue, err := SerializeValue(remote)
+	if err != nil {
+		t.Fatalf("failed to serialize remote favorites: %v", err)
+	}
+	if err := db.Save(&database.WoxSetting{Key: "PinedResults", Value: remoteValue}).Error; err != nil {
+		t.Fatalf("failed to write remote favorites: %v", err)
+	}
+	if err := db.Save(&database.WoxSetting{Key: LastModifiedKey, Value: `{"UnixMilli":1,"Counter":99}`}).Error; err != nil {
+		t.Fatalf("failed to write remote stamp: %v", err)
+	}
+
+	local := pinned.Get()
+	local.Delete("a")
+	if _, err := pinned.persistMerging(); err != nil {
+		t.Fatalf("failed to persist favorites: %v", err)
+	}
+
+	stored := util.NewHashMap[ResultHash, bool]()
+	if err := NewWoxSettingStore(db).Get("PinedResults", stored); err != nil {
+		t.Fatalf("failed to read stored favorites: %v", err)
+	}
+	if stored.Exist("a") || !stored.Exist("b") || !stored.Exist("c") {
+		t.Fatalf("expected b and the remote c without the unpinned a, got %v", stored.ToMap())
+	}
+}
+
+func TestWritesInForeignTransactionAreNotTracked(t *testing.T) {
+	db := newTestDB(t)
+	useFreshWriteTracker(t)
+	if _, err := selfWrites.diff(db); err != nil {
+		t.Fatalf("failed to snapshot settings: %v", err)
+	}
+
+	tx := db.Begin()
+	if err := NewWoxSettingStore(tx).Set("ShowTray", false); err != nil {
+		t.Fatalf("failed to save in transaction: %v", err)
+	}
+	tx.Rollback()
+
+	if _, tracked := selfWrites.values["ShowTray"]; tracked {
+		t.Fatalf("expected a rolled back write not to be tracked")
+	}
+	if changed, err := selfWrites.stampChanged(db); err != nil || changed {
+		t.Fatalf("expected the stamp to be unchanged after the rollback, got %v (%v)", changed, err)
+	}
+}
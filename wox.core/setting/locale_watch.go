@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wox/i18n"
+	"wox/util"
+	"wox/util/locale"
+)
+
+// SystemLangCodeChangedKey is the key passed to OnSettingChanged handlers when
+// the OS locale changes to a language other than the configured LangCode. The
+// value is the suggested LangCode. LangCode itself is left untouched because
+// the user may have picked a different language on purpose.
+const SystemLangCodeChangedKey = "SystemLangCode"
+
+const localeCheckInterval = 10 * time.Minute
+
+// startLocaleWatch periodically re-detects the OS locale. Only a change of the
+// OS locale is reported, so a user who keeps a language different from the
+// system is not asked again on every check.
+func (m *Manager) startLocaleWatch(ctx context.Context) {
+	lastLangCode, _ := i18n.LangCodeFromLocale(locale.GetLocale())
+
+	util.Go(ctx, "locale watch", func() {
+		ticker := time.NewTicker(localeCheckInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			systemLangCode, ok := i18n.LangCodeFromLocale(locale.Refresh())
+			if !ok || systemLangCode == lastLangCode {
+				continue
+			}
+			lastLangCode = systemLangCode
+
+			checkCtx := util.NewTraceContext()
+			if systemLangCode == m.currentWoxSetting().LangCode.Get() {
+				continue
+			}
+			logger.Info(checkCtx, fmt.Sprintf("system language changed to %s, current wox language is %s", systemLangCode, m.currentWoxSetting().LangCode.Get()))
+			m.notifySettingChanged(checkCtx, SystemLangCodeChangedKey, string(systemLangCode))
+		}
+	})
+}
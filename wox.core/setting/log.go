@@ -0,0 +1,35 @@
+package setting
+
+import (
+	"context"
+	"time"
+	"wox/util"
+)
+
+// Setting operations logged by LogSettingOperation, as the op field.
+const (
+	SettingOperationLoad        = "load"
+	SettingOperationSave        = "save"
+	SettingOperationUpdate      = "update"
+	SettingOperationAppDataSave = "app_data_save"
+)
+
+// LogSettingOperation writes one structured line for a setting operation:
+//
+//	setting op=save key=ThemeId duration=2ms
+//	setting op=update key=MaxResultCount duration=1ms err="invalid value"
+//
+// Successful operations are logged at debug level, failures as errors. A zero
+// start omits the duration.
+func LogSettingOperation(ctx context.Context, op string, key string, start time.Time, err error) {
+	fields := []any{"op", op, "key", key}
+	if !start.IsZero() {
+		fields = append(fields, "duration", time.Since(start).Round(time.Microsecond))
+	}
+	if err != nil {
+		fields = append(fields, "err", err.Error())
+		util.GetLogger().Error(ctx, "setting "+util.LogFields(fields...))
+		return
+	}
+	util.GetLogger().Debug(ctx, "setting "+util.LogFields(fields...))
+}
@@ -0,0 +1,47 @@
+package setting
+
+import "strings"
+
+// GetMainHotkeys returns every key combination that toggles Wox. MainHotkey
+// is kept as the first entry so clients that only know the single hotkey
+// keep working; when such a client changes MainHotkey, the change replaces
+// the first entry and the other hotkeys are kept.
+func (w *WoxSetting) GetMainHotkeys() []string {
+	hotkeys := w.MainHotkeys.Get()
+	var others []string
+	if len(hotkeys) > 0 {
+		others = hotkeys[1:]
+	}
+	return NormalizeMainHotkeys(append([]string{w.MainHotkey.Get()}, others...))
+}
+
+// SetMainHotkeys saves the main hotkeys and mirrors the first one, or an
+// empty value when there is none, into MainHotkey.
+func (w *WoxSetting) SetMainHotkeys(hotkeys []string) error {
+	hotkeys = NormalizeMainHotkeys(hotkeys)
+	if err := w.MainHotkeys.Set(hotkeys); err != nil {
+		return err
+	}
+	primary := ""
+	if len(hotkeys) > 0 {
+		primary = hotkeys[0]
+	}
+	return w.MainHotkey.Set(primary)
+}
+
+// NormalizeMainHotkeys trims the hotkeys and drops empty entries and repeats
+// of the same combination, keeping the first spelling.
+func NormalizeMainHotkeys(hotkeys []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, hotkeyStr := range hotkeys {
+		hotkeyStr = strings.TrimSpace(hotkeyStr)
+		compareKey := HotkeyConflictKey(hotkeyStr)
+		if compareKey == "" || seen[compareKey] {
+			continue
+		}
+		seen[compareKey] = true
+		normalized = append(normalized, hotkeyStr)
+	}
+	return normalized
+}
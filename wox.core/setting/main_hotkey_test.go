@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestMainHotkeysKeepMainHotkeyFirst(t *testing.T) {
+	db := newTestDB(t)
+	woxSetting := NewManager(NewWoxSettingStore(db), db).GetWoxSetting(context.Background())
+
+	if err := woxSetting.SetMainHotkeys([]string{" alt+space ", "", "ctrl+shift+k", "Alt+Space"}); err != nil {
+		t.Fatalf("failed to save main hotkeys: %v", err)
+	}
+	if got := woxSetting.GetMainHotkeys(); !slices.Equal(got, []string{"alt+space", "ctrl+shift+k"}) {
+		t.Fatalf("expected trimmed hotkeys without repeats, got %v", got)
+	}
+	if got := woxSetting.MainHotkey.Get(); got != "alt+space" {
+		t.Fatalf("expected MainHotkey to mirror the first hotkey, got %q", got)
+	}
+
+	// Clients that only know MainHotkey replace the first entry and keep the others.
+	if err := woxSetting.MainHotkey.Set("ctrl+space"); err != nil {
+		t.Fatalf("failed to save MainHotkey: %v", err)
+	}
+	if got := woxSetting.GetMainHotkeys(); !slices.Equal(got, []string{"ctrl+space", "ctrl+shift+k"}) {
+		t.Fatalf("expected MainHotkey to replace the first hotkey, got %v", got)
+	}
+
+	if err := woxSetting.SetMainHotkeys(nil); err != nil {
+		t.Fatalf("failed to clear main hotkeys: %v", err)
+	}
+	if got := woxSetting.GetMainHotkeys(); len(got) != 0 || woxSetting.MainHotkey.Get() != "" {
+		t.Fatalf("expected no main hotkeys, got %v and MainHotkey %q", got, woxSetting.MainHotkey.Get())
+	}
+}
@@ -1,17 +1,22 @@
 package setting
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-	"wox/common"
 	"wox/database"
 	"wox/util"
 	"wox/util/autostart"
+	"wox/util/notifier"
 
-	"github.com/samber/lo"
+	"gorm.io/gorm"
 )
 
 var managerInstance *Manager
@@ -20,7 +25,69 @@ var logger *util.Log
 
 type Manager struct {
 	woxSetting *WoxSetting
+	woxStore   SettingStore
 	mruManager *MRUManager
+	// db holds the app data tables (query history, MRU) that are not plain
+	// key/value settings and therefore stay outside woxStore.
+	db *gorm.DB
+
+	// woxSettingMu guards the woxSetting pointer, which is replaced wholesale
+	// on restore or external changes.
+	woxSettingMu sync.RWMutex
+	// appDataMu serializes read-modify-write cycles on app data (query history,
+	// actioned and pinned results, completion feedback) so concurrent plugin
+	// actions cannot drop each other's updates or race on the shared maps.
+	appDataMu sync.RWMutex
+
+	// appDataDirty holds app data values changed in memory but not yet saved.
+	appDataDirty         map[string]func() (int, error)
+	appDataFlushTimer    *time.Timer
+	appDataFlushInterval time.Duration
+	appDataSaveMu        sync.Mutex
+	// appDataSizes holds the stored length of every app data key, measured
+	// once and then updated by each save, see checkAppDataSize. nil until
+	// measured, and reset when the rows are replaced in bulk.
+	appDataSizes   map[string]int64
+	appDataSizesMu sync.Mutex
+	// appDataSizeWarned is set while app data is above
+	// AppDataSizeWarningBytes and the warning was logged, see checkAppDataSize.
+	appDataSizeWarned atomic.Bool
+
+	// autoBackupReschedule wakes the auto backup loop when its interval changes.
+	autoBackupReschedule chan struct{}
+	autoBackupStarted    atomic.Bool
+	// backupMigrator upgrades a backed up database before it is restored, see
+	// SetBackupMigrator.
+	backupMigrator func(ctx context.Context, db *gorm.DB) error
+
+	settingChangeHandlers       []func(ctx context.Context, key string, value string)
+	pluginSettingChangeHandlers []func(ctx context.Context, pluginId string, key string, oldValue string, newValue string)
+	settingChangeHandlersMu     sync.Mutex
+
+	// autostartMismatch is set by checkAutostart in ask mode until the UI
+	// has told the user, see TakeAutostartMismatch.
+	autostartMismatch   *AutostartMismatch
+	autostartMismatchMu sync.Mutex
+
+	// hideOnLostFocusSuppression counts active PushHideOnLostFocusSuppression
+	// calls, see ShouldHideOnLostFocus.
+	hideOnLostFocusSuppression   int
+	hideOnLostFocusSuppressionMu sync.Mutex
+
+	// profileMu serializes profile creation and switches, see profile.go.
+	profileMu sync.Mutex
+
+	// readOnly is set by checkWritable when settings are kept in memory only.
+	readOnly atomic.Bool
+
+	// stableResultHashMigrations holds the stable hashes whose title based
+	// data was migrated already, see MigrateToStableResultHash.
+	stableResultHashMigrations sync.Map
+
+	// queryHistoryExcludes caches the compiled QueryHistoryExcludePatterns,
+	// see compiledQueryHistoryExcludes.
+	queryHistoryExcludes   queryHistoryExcludeCache
+	queryHistoryExcludesMu sync.Mutex
 }
 
 const queryCompletionFeedbackLimit = 1000
@@ -34,149 +101,484 @@ func GetSettingManager() *Manager {
 			panic("database not initialized")
 		}
 
-		store := NewWoxSettingStore(db)
-		managerInstance = &Manager{}
-		managerInstance.woxSetting = NewWoxSetting(store)
-		managerInstance.mruManager = NewMRUManager(db)
+		managerInstance = NewManager(NewWoxSettingStore(db), db)
 	})
 	return managerInstance
 }
 
+// NewManager creates a setting manager backed by store. The default manager
+// uses the wox_settings table; tests or alternative backends can pass any
+// SettingStore, e.g. NewMemorySettingStore. db is still required for the app
+// data tables.
+func NewManager(store SettingStore, db *gorm.DB) *Manager {
+	if logger == nil {
+		logger = util.GetLogger()
+	}
+
+	return &Manager{
+		woxStore:             store,
+		db:                   db,
+		woxSetting:           NewWoxSetting(store),
+		mruManager:           NewMRUManager(db),
+		autoBackupReschedule: make(chan struct{}, 1),
+		appDataDirty:         map[string]func() (int, error){},
+		appDataFlushInterval: DefaultAppDataFlushInterval,
+	}
+}
+
 func (m *Manager) Init(ctx context.Context) error {
+	m.checkWritable(ctx)
 	m.StartAutoBackup(ctx)
 
+	if err := m.watchExternalChanges(ctx); err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to watch external setting changes: %v", err))
+	}
+
 	if err := m.checkAutostart(ctx); err != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to check autostart status: %v", err))
 	}
 
+	m.startLocaleWatch(ctx)
+
+	util.Go(ctx, "compact app data", func() {
+		if _, err := m.CompactAppData(ctx, DefaultAppDataMaxAgeDays); err != nil {
+			logger.Warn(ctx, fmt.Sprintf("failed to compact app data: %v", err))
+		}
+	})
+
+	notifier.SetSuppressFunc(func() bool {
+		return m.IsDoNotDisturbActive(time.Now())
+	})
+	notifier.SetSoundFunc(m.NotificationSound)
+	m.applyAppDataFormat()
+
 	return nil
 }
 
+// applyAppDataFormat passes the loaded AppDataFormat on to the store.
+func (m *Manager) applyAppDataFormat() {
+	binaryAppDataEnabled.Store(m.currentWoxSetting().AppDataFormat.Get() == AppDataFormatBinary)
+}
+
+// AutostartMismatch records a startup disagreement between EnableAutostart and
+// the OS autostart entry that was left for the user to resolve.
+type AutostartMismatch struct {
+	Configured bool
+	Actual     bool
+}
+
+// checkAutostart reconciles EnableAutostart with the OS autostart entry as
+// chosen by AutostartReconcileMode. The OS entry can disappear without the
+// user asking for it (antivirus, cleanup tools), so by default the setting is
+// re-applied and never overwritten when that fails.
 func (m *Manager) checkAutostart(ctx context.Context) error {
 	actualAutostart, err := autostart.IsAutostart(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check autostart status: %w", err)
 	}
 
-	configAutostart := m.woxSetting.EnableAutostart.Get()
-	if actualAutostart != configAutostart {
-		util.GetLogger().Warn(ctx, fmt.Sprintf("Autostart setting mismatch: config %v, actual %v", configAutostart, actualAutostart))
+	configAutostart := m.currentWoxSetting().EnableAutostart.Get()
+	if actualAutostart == configAutostart {
+		return nil
+	}
+
+	mode := m.currentWoxSetting().AutostartReconcileMode.Get()
+	util.GetLogger().Warn(ctx, fmt.Sprintf("Autostart setting mismatch: config %v, actual %v, mode %s", configAutostart, actualAutostart, mode))
 
-		if configAutostart {
-			util.GetLogger().Info(ctx, "Attempting to fix autostart configuration...")
-			if err := autostart.SetAutostart(ctx, true); err != nil {
-				util.GetLogger().Error(ctx, fmt.Sprintf("Failed to fix autostart: %s", err.Error()))
-				m.woxSetting.EnableAutostart.Set(false)
-			} else {
-				util.GetLogger().Info(ctx, "Autostart configuration fixed successfully")
-			}
-		} else {
-			// This case is less common, but we can ensure it's disabled if config says so.
-			if err := autostart.SetAutostart(ctx, false); err != nil {
-				util.GetLogger().Error(ctx, fmt.Sprintf("Failed to disable autostart: %s", err.Error()))
-				m.woxSetting.EnableAutostart.Set(true) // Revert setting if action fails
-			}
+	switch mode {
+	case AutostartReconcileModeTrustOS:
+		if err := m.currentWoxSetting().EnableAutostart.Set(actualAutostart); err != nil {
+			return fmt.Errorf("failed to update autostart setting: %w", err)
+		}
+		util.GetLogger().Info(ctx, fmt.Sprintf("Autostart setting updated to match the system: %v", actualAutostart))
+	case AutostartReconcileModeAsk:
+		m.autostartMismatchMu.Lock()
+		m.autostartMismatch = &AutostartMismatch{Configured: configAutostart, Actual: actualAutostart}
+		m.autostartMismatchMu.Unlock()
+	default:
+		if err := autostart.SetAutostart(ctx, configAutostart); err != nil {
+			// Keep the user's preference; the next start tries again and
+			// Diagnose reports the mismatch in the meantime.
+			return fmt.Errorf("failed to apply autostart setting %v: %w", configAutostart, err)
 		}
+		util.GetLogger().Info(ctx, "Autostart configuration re-applied successfully")
 	}
 	return nil
 }
 
+// TakeAutostartMismatch returns the mismatch found at startup in ask mode and
+// clears it, so the user is told about it only once.
+func (m *Manager) TakeAutostartMismatch() (AutostartMismatch, bool) {
+	m.autostartMismatchMu.Lock()
+	defer m.autostartMismatchMu.Unlock()
+
+	if m.autostartMismatch == nil {
+		return AutostartMismatch{}, false
+	}
+	mismatch := *m.autostartMismatch
+	m.autostartMismatch = nil
+	return mismatch, true
+}
+
+// GetWoxSetting returns the live settings. Values read through it may change
+// at any time; use GetWoxSettingSnapshot when a consistent copy is needed.
 func (m *Manager) GetWoxSetting(ctx context.Context) *WoxSetting {
+	return m.currentWoxSetting()
+}
+
+func (m *Manager) currentWoxSetting() *WoxSetting {
+	m.woxSettingMu.RLock()
+	defer m.woxSettingMu.RUnlock()
+
 	return m.woxSetting
 }
 
-func (m *Manager) GetLatestQueryHistory(ctx context.Context, limit int) []QueryHistory {
-	histories := m.woxSetting.QueryHistories.Get()
+// reloadWoxSetting drops every lazily cached value after the underlying rows
+// were replaced in bulk, so the next Get reads the new data from the store.
+func (m *Manager) reloadWoxSetting() {
+	m.discardPendingAppData()
+	woxSetting := NewWoxSetting(m.woxStore)
 
-	// Sort by timestamp descending and limit results
-	var result []QueryHistory
-	count := 0
-	for i := len(histories) - 1; i >= 0 && count < limit; i-- {
-		result = append(result, histories[i])
-		count++
+	m.woxSettingMu.Lock()
+	defer m.woxSettingMu.Unlock()
+	m.woxSetting = woxSetting
+}
+
+// SetQueryHotkeySilent toggles silent execution of the query hotkey bound to hotkey on the current platform.
+// Registered hotkeys capture their entry, so callers should re-register query hotkeys afterwards.
+func (m *Manager) SetQueryHotkeySilent(ctx context.Context, hotkey string, silent bool) error {
+	compareKey := HotkeyConflictKey(hotkey)
+	if compareKey == "" {
+		return fmt.Errorf("hotkey is empty")
+	}
+
+	queryHotkeys := m.currentWoxSetting().QueryHotkeys.Get()
+	index := slices.IndexFunc(queryHotkeys, func(queryHotkey QueryHotkey) bool {
+		return HotkeyConflictKey(queryHotkey.Hotkey) == compareKey
+	})
+	if index < 0 {
+		return fmt.Errorf("query hotkey not found: %s", hotkey)
 	}
 
-	return result
+	// Copy before editing so the cached setting value is only replaced by Set.
+	updated := slices.Clone(queryHotkeys)
+	updated[index].IsSilentExecution = silent
+	return m.currentWoxSetting().QueryHotkeys.Set(updated)
 }
 
 func (m *Manager) LoadPluginSetting(ctx context.Context, pluginId string, defaultSettings map[string]string) (*PluginSetting, error) {
-	pluginSettingStore := NewPluginSettingStore(database.GetDB(), pluginId)
+	pluginSettingStore := NewPluginSettingStore(m.db, pluginId)
 	pluginSetting := NewPluginSetting(pluginSettingStore, defaultSettings)
 	return pluginSetting, nil
 }
 
-func (m *Manager) AddActionedResult(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string, query string) {
+// ListAllPluginSettings returns the stored settings of every plugin that has at least one
+// setting row, keyed by plugin ID. Metadata defaults are not applied because the plugins
+// may not be loaded; use LoadPluginSetting for that.
+func (m *Manager) ListAllPluginSettings(ctx context.Context) map[string]*PluginSetting {
+	pluginSettings := map[string]*PluginSetting{}
+
+	var pluginIds []string
+	if err := m.db.Model(&database.PluginSetting{}).Distinct("plugin_id").Pluck("plugin_id", &pluginIds).Error; err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to list plugin settings: %s", err.Error()))
+		return pluginSettings
+	}
+
+	for _, pluginId := range pluginIds {
+		pluginSetting, err := m.LoadPluginSetting(ctx, pluginId, map[string]string{})
+		if err != nil {
+			logger.Warn(ctx, fmt.Sprintf("failed to load plugin setting of %s: %s", pluginId, err.Error()))
+			continue
+		}
+		pluginSettings[pluginId] = pluginSetting
+	}
+
+	return pluginSettings
+}
+
+// GetDisabledPluginIds returns the plugins the user disabled. The flag lives in
+// each plugin's own "Disabled" setting row, so this works before the plugins
+// are loaded and needs no separate list in WoxSetting.
+func (m *Manager) GetDisabledPluginIds(ctx context.Context) []string {
+	var pluginIds []string
+	if err := m.db.Model(&database.PluginSetting{}).Where("key = ? AND value = ?", "Disabled", "true").Pluck("plugin_id", &pluginIds).Error; err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to list disabled plugins: %s", err.Error()))
+		return []string{}
+	}
+	return pluginIds
+}
+
+func (m *Manager) AddActionedResult(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string, query string, actionName string) {
 	resultHash := NewResultHash(pluginId, resultTitle, resultSubTitle)
-	m.AddActionedResultByHash(ctx, resultHash, query)
+	m.AddActionedResultByHash(ctx, resultHash, query, "", actionName)
 }
 
 // AddActionedResultByHash stores an actioned result for callers that own a stable result identity.
-func (m *Manager) AddActionedResultByHash(ctx context.Context, resultHash ResultHash, query string) {
+// actionId is optional, plugins that do not set action ids get random ones that only live for one query.
+func (m *Manager) AddActionedResultByHash(ctx context.Context, resultHash ResultHash, query string, actionId string, actionName string) {
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
 	actionedResult := ActionedResult{
-		Timestamp: util.GetSystemTimestamp(),
-		Query:     query,
+		Timestamp:  util.GetSystemTimestamp(),
+		Query:      query,
+		ActionId:   actionId,
+		ActionName: actionName,
 	}
 
-	actionedResults := m.woxSetting.ActionedResults.Get()
+	actionedResults := m.currentWoxSetting().ActionedResults.Get()
 	if v, ok := actionedResults.Load(resultHash); ok {
 		v = append(v, actionedResult)
-		if len(v) > 100 {
-			v = v[len(v)-100:]
+		if len(v) > maxActionedResultsPerHash {
+			v = v[len(v)-maxActionedResultsPerHash:]
 		}
 		actionedResults.Store(resultHash, v)
 	} else {
 		actionedResults.Store(resultHash, []ActionedResult{actionedResult})
 	}
-	m.woxSetting.ActionedResults.Set(actionedResults)
+	saveAppData(ctx, m, m.currentWoxSetting().ActionedResults, actionedResults)
 }
 
-func (m *Manager) PinResult(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string) {
-	util.GetLogger().Info(ctx, fmt.Sprintf("pin result: %s, %s", resultTitle, resultSubTitle))
+// maxActionedResultsPerHash is how many actions are kept per result.
+const maxActionedResultsPerHash = 100
+
+// ActionedResultSeed is one action loaded by SeedActionedResults. A zero
+// Timestamp means now.
+type ActionedResultSeed struct {
+	PluginId  string
+	Title     string
+	SubTitle  string
+	Query     string
+	Timestamp int64
+}
+
+// SeedActionedResults adds many actions at once, e.g. for integration tests or
+// preset rankings, and saves ActionedResults once at the end instead of once
+// per action like AddActionedResult. Actions are kept in time order and
+// trimmed to the same per result limit.
+func (m *Manager) SeedActionedResults(ctx context.Context, entries []ActionedResultSeed) {
+	if len(entries) == 0 {
+		return
+	}
+
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	now := util.GetSystemTimestamp()
+	seeded := map[ResultHash][]ActionedResult{}
+	for _, entry := range entries {
+		timestamp := entry.Timestamp
+		if timestamp <= 0 {
+			timestamp = now
+		}
+		resultHash := NewResultHash(entry.PluginId, entry.Title, entry.SubTitle)
+		seeded[resultHash] = append(seeded[resultHash], ActionedResult{Timestamp: timestamp, Query: entry.Query})
+	}
+
+	actionedResults := m.currentWoxSetting().ActionedResults.Get()
+	for resultHash, actions := range seeded {
+		existing, _ := actionedResults.Load(resultHash)
+		merged := append(slices.Clone(existing), actions...)
+		slices.SortStableFunc(merged, func(a, b ActionedResult) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+		if len(merged) > maxActionedResultsPerHash {
+			merged = merged[len(merged)-maxActionedResultsPerHash:]
+		}
+		actionedResults.Store(resultHash, merged)
+	}
+	saveAppData(ctx, m, m.currentWoxSetting().ActionedResults, actionedResults)
+	logger.Info(ctx, fmt.Sprintf("seeded %d actioned results for %d results", len(entries), len(seeded)))
+}
+
+// actionedResultHalfLife is how long it takes for one action to lose half of its ranking weight.
+const actionedResultHalfLife = 7 * 24 * time.Hour
+
+// GetActionedResultScore returns a recency and frequency score for a result.
+// Every stored action contributes 1 when it just happened and decays
+// exponentially with actionedResultHalfLife, so a result used ten times last
+// month can still rank below one used three times today. Results that were
+// never actioned score 0.
+func (m *Manager) GetActionedResultScore(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string) float64 {
+	m.appDataMu.RLock()
+	defer m.appDataMu.RUnlock()
+
 	resultHash := NewResultHash(pluginId, resultTitle, resultSubTitle)
-	results := m.woxSetting.PinedResults.Get()
+	actionedResults, ok := m.currentWoxSetting().ActionedResults.Get().Load(resultHash)
+	if !ok {
+		return 0
+	}
+
+	now := util.GetSystemTimestamp()
+	halfLifeMs := float64(actionedResultHalfLife.Milliseconds())
+	var score float64
+	for _, actionedResult := range actionedResults {
+		// Clock adjustments can leave timestamps in the future; count them as fresh.
+		age := max(now-actionedResult.Timestamp, 0)
+		score += math.Exp2(-float64(age) / halfLifeMs)
+	}
+	return score
+}
+
+// GetActionsForQuery returns the results the user actioned while typing
+// query, most recently actioned first. Queries are compared case-insensitively
+// and without surrounding spaces, matching how the user retypes them.
+func (m *Manager) GetActionsForQuery(ctx context.Context, query string) []ResultHash {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []ResultHash{}
+	}
+
+	m.appDataMu.RLock()
+	lastUsed := map[ResultHash]int64{}
+	m.currentWoxSetting().ActionedResults.Get().Range(func(hash ResultHash, actions []ActionedResult) bool {
+		for _, action := range actions {
+			if strings.EqualFold(strings.TrimSpace(action.Query), query) {
+				lastUsed[hash] = max(lastUsed[hash], action.Timestamp)
+			}
+		}
+		return true
+	})
+	m.appDataMu.RUnlock()
+
+	hashes := make([]ResultHash, 0, len(lastUsed))
+	for hash := range lastUsed {
+		hashes = append(hashes, hash)
+	}
+	slices.SortFunc(hashes, func(a, b ResultHash) int {
+		if lastUsed[a] != lastUsed[b] {
+			return cmp.Compare(lastUsed[b], lastUsed[a])
+		}
+		return strings.Compare(string(a), string(b))
+	})
+	return hashes
+}
+
+// ErrFavoriteResultsLimitReached is returned when pinning a new result would
+// exceed the MaxFavoriteResults setting.
+var ErrFavoriteResultsLimitReached = errors.New("favorite results limit reached")
+
+// PinResult marks a result as favorite. It returns an error wrapping
+// ErrFavoriteResultsLimitReached when MaxFavoriteResults favorites exist.
+func (m *Manager) PinResult(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string) error {
+	util.GetLogger().Info(ctx, fmt.Sprintf("pin result: %s, %s", resultTitle, resultSubTitle))
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	return m.pinResultByHash(ctx, NewResultHash(pluginId, resultTitle, resultSubTitle))
+}
+
+// PinResultByHash marks a result as favorite for callers that own a stable
+// result identity, see NewStableResultHash.
+func (m *Manager) PinResultByHash(ctx context.Context, resultHash ResultHash) error {
+	util.GetLogger().Info(ctx, fmt.Sprintf("pin result: %s", resultHash))
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	return m.pinResultByHash(ctx, resultHash)
+}
+
+// pinResultByHash rejects new favorites beyond MaxFavoriteResults instead of
+// evicting old ones: favorites are chosen by the user and carry no usage
+// time, so there is no safe candidate to drop. Pinning an existing favorite
+// again always succeeds.
+func (m *Manager) pinResultByHash(ctx context.Context, resultHash ResultHash) error {
+	results := m.currentWoxSetting().PinedResults.Get()
+	if !results.Exist(resultHash) {
+		limit := m.currentWoxSetting().MaxFavoriteResults.Get()
+		if results.Len() >= limit {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("refuse to pin result %s, already %d favorites (limit %d)", resultHash, results.Len(), limit))
+			return fmt.Errorf("%w: at most %d favorites are allowed, remove some favorites or raise MaxFavoriteResults", ErrFavoriteResultsLimitReached, limit)
+		}
+	}
+
 	results.Store(resultHash, true)
-	m.woxSetting.PinedResults.Set(results)
+	saveAppData(ctx, m, m.currentWoxSetting().PinedResults, results)
+	return nil
 }
 
 func (m *Manager) IsPinedResult(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string) bool {
-	resultHash := NewResultHash(pluginId, resultTitle, resultSubTitle)
-	return m.woxSetting.PinedResults.Get().Exist(resultHash)
+	return m.IsPinedResultByHash(ctx, NewResultHash(pluginId, resultTitle, resultSubTitle))
+}
+
+func (m *Manager) IsPinedResultByHash(ctx context.Context, resultHash ResultHash) bool {
+	m.appDataMu.RLock()
+	defer m.appDataMu.RUnlock()
+
+	return m.currentWoxSetting().PinedResults.Get().Exist(resultHash)
 }
 
 func (m *Manager) UnpinResult(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string) {
 	util.GetLogger().Info(ctx, fmt.Sprintf("unpin result: %s, %s", resultTitle, resultSubTitle))
-	resultHash := NewResultHash(pluginId, resultTitle, resultSubTitle)
-	results := m.woxSetting.PinedResults.Get()
-	results.Delete(resultHash)
-	m.woxSetting.PinedResults.Set(results)
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	m.unpinResultByHash(ctx, NewResultHash(pluginId, resultTitle, resultSubTitle))
 }
 
-func (m *Manager) AddQueryHistory(ctx context.Context, query common.PlainQuery) {
-	histories := m.woxSetting.QueryHistories.Get()
-	newHistory := QueryHistory{
-		Query:     query,
-		Timestamp: util.GetSystemTimestamp(),
+func (m *Manager) UnpinResultByHash(ctx context.Context, resultHash ResultHash) {
+	util.GetLogger().Info(ctx, fmt.Sprintf("unpin result: %s", resultHash))
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	m.unpinResultByHash(ctx, resultHash)
+}
+
+func (m *Manager) unpinResultByHash(ctx context.Context, resultHash ResultHash) {
+	results := m.currentWoxSetting().PinedResults.Get()
+	results.Delete(resultHash)
+	saveAppData(ctx, m, m.currentWoxSetting().PinedResults, results)
+
+	labels := m.currentWoxSetting().FavoriteLabels.Get()
+	if labels.Exist(resultHash) {
+		labels.Delete(resultHash)
+		saveAppData(ctx, m, m.currentWoxSetting().FavoriteLabels, labels)
 	}
+}
 
-	// Remove duplicate if exists (same query text)
-	histories = lo.Filter(histories, func(item QueryHistory, index int) bool {
-		return !item.Query.IsEmpty() && item.Query.QueryText != query.QueryText
-	})
+// SetFavoriteLabel gives a favorite a name that the favorites list shows
+// instead of its title. An empty label removes the name again.
+func (m *Manager) SetFavoriteLabel(ctx context.Context, pluginId string, resultTitle string, resultSubTitle string, label string) error {
+	return m.SetFavoriteLabelByHash(ctx, NewResultHash(pluginId, resultTitle, resultSubTitle), label)
+}
+
+// SetFavoriteLabelByHash is SetFavoriteLabel for callers that own a stable
+// result identity, see NewStableResultHash.
+func (m *Manager) SetFavoriteLabelByHash(ctx context.Context, resultHash ResultHash, label string) error {
+	label = strings.TrimSpace(label)
 
-	// Add new history at the end
-	histories = append(histories, newHistory)
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
 
-	// Keep only the most recent 1000 entries
-	if len(histories) > 1000 {
-		histories = histories[len(histories)-1000:]
+	if !m.currentWoxSetting().PinedResults.Get().Exist(resultHash) {
+		return fmt.Errorf("result %s is not a favorite", resultHash)
 	}
 
-	m.woxSetting.QueryHistories.Set(histories)
+	labels := m.currentWoxSetting().FavoriteLabels.Get()
+	if label == "" {
+		labels.Delete(resultHash)
+	} else {
+		labels.Store(resultHash, label)
+	}
+	saveAppData(ctx, m, m.currentWoxSetting().FavoriteLabels, labels)
+	return nil
+}
+
+// GetFavoriteLabel returns the label of a favorite, or "" if it has none.
+func (m *Manager) GetFavoriteLabel(ctx context.Context, resultHash ResultHash) string {
+	m.appDataMu.RLock()
+	defer m.appDataMu.RUnlock()
+
+	label, _ := m.currentWoxSetting().FavoriteLabels.Get().Load(resultHash)
+	return label
 }
 
 // GetQueryCompletionFeedbacks returns accepted inline completion feedback for ranking.
 func (m *Manager) GetQueryCompletionFeedbacks(ctx context.Context) []QueryCompletionFeedback {
-	feedbacks := m.woxSetting.QueryCompletionFeedbacks.Get()
+	m.appDataMu.RLock()
+	defer m.appDataMu.RUnlock()
+
+	feedbacks := m.currentWoxSetting().QueryCompletionFeedbacks.Get()
 	return append([]QueryCompletionFeedback(nil), feedbacks...)
 }
 
@@ -186,7 +588,10 @@ func (m *Manager) RecordQueryCompletionFeedback(ctx context.Context, inputPrefix
 		return false
 	}
 
-	feedbacks := m.woxSetting.QueryCompletionFeedbacks.Get()
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	feedbacks := m.currentWoxSetting().QueryCompletionFeedbacks.Get()
 	now := util.GetSystemTimestamp()
 	updated := false
 	var acceptedFeedback QueryCompletionFeedback
@@ -220,13 +625,17 @@ func (m *Manager) RecordQueryCompletionFeedback(ctx context.Context, inputPrefix
 		feedbacks = feedbacks[len(feedbacks)-queryCompletionFeedbackLimit:]
 	}
 
-	m.woxSetting.QueryCompletionFeedbacks.Set(feedbacks)
+	saveAppData(ctx, m, m.currentWoxSetting().QueryCompletionFeedbacks, feedbacks)
 	return true
 }
 
 // MRU related methods
 
+// MRU writes are skipped while the data directory is read-only, see checkWritable.
 func (m *Manager) AddMRUItem(ctx context.Context, item MRUItem) error {
+	if m.IsReadOnly() {
+		return nil
+	}
 	return m.mruManager.AddMRUItem(ctx, item)
 }
 
@@ -235,10 +644,16 @@ func (m *Manager) GetMRUItems(ctx context.Context, limit int) ([]MRUItem, error)
 }
 
 func (m *Manager) RemoveMRUItem(ctx context.Context, hash string) error {
+	if m.IsReadOnly() {
+		return nil
+	}
 	return m.mruManager.RemoveMRUItem(ctx, hash)
 }
 
 func (m *Manager) CleanupOldMRUItems(ctx context.Context, keepCount int) error {
+	if m.IsReadOnly() {
+		return nil
+	}
 	return m.mruManager.CleanupOldMRUItems(ctx, keepCount)
 }
 
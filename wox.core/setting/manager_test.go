@@ -0,0 +1,101 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"wox/util"
+)
+
+func TestNewManagerWithMemoryStoreKeepsSettingsOutOfDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	store := NewMemorySettingStore()
+	m := NewManager(store, db)
+
+	if err := m.GetWoxSetting(ctx).ShowTray.Set(false); err != nil {
+		t.Fatalf("failed to save setting: %v", err)
+	}
+	var showTray bool
+	if err := store.Get("ShowTray", &showTray); err != nil || showTray {
+		t.Fatalf("expected ShowTray=false in the memory store, got %v (%v)", showTray, err)
+	}
+	if got := storedRow(t, db, "ShowTray"); got != "" {
+		t.Fatalf("expected no ShowTray row in the database, got %s", got)
+	}
+
+	// bulk replacements go through the injected store as well
+	if err := m.replaceWoxSettings(ctx, map[string]string{}, nil); err != nil {
+		t.Fatalf("failed to replace settings: %v", err)
+	}
+	if !m.GetWoxSetting(ctx).ShowTray.Get() {
+		t.Fatalf("expected ShowTray to fall back to its default after the replace")
+	}
+	if values, _ := store.List(); len(values) != 0 {
+		t.Fatalf("expected an empty memory store, got %v", values)
+	}
+
+	if stamp, err := m.GetLastModified(); err != nil || stamp != (LastModified{}) {
+		t.Fatalf("expected no LastModified stamp for a memory store, got %+v (%v)", stamp, err)
+	}
+	if got := storedRow(t, db, LastModifiedKey); got != "" {
+		t.Fatalf("expected no LastModified row in the database, got %s", got)
+	}
+	if err := m.watchExternalChanges(ctx); err != nil {
+		t.Fatalf("expected no external change watcher for a memory store, got %v", err)
+	}
+}
+
+func TestSeedActionedResultsMergesSortsAndTrims(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+
+	m.AddActionedResult(ctx, "calculator", "2", "", "existing", "")
+	var busy []ActionedResultSeed
+	for i := 1; i <= maxActionedResultsPerHash+5; i++ {
+		busy = append(busy, ActionedResultSeed{PluginId: "app", Title: "Terminal", Timestamp: int64(i)})
+	}
+	m.SeedActionedResults(ctx, append(busy,
+		ActionedResultSeed{PluginId: "calculator", Title: "2", Query: "newer", Timestamp: 100},
+		ActionedResultSeed{PluginId: "calculator", Title: "2", Query: "older", Timestamp: 50},
+		ActionedResultSeed{PluginId: "calculator", Title: "2", Query: "now"},
+	))
+	if err := m.Flush(ctx); err != nil {
+		t.Fatalf("failed to flush app data: %v", err)
+	}
+
+	stored := util.NewHashMap[ResultHash, []ActionedResult]()
+	if err := NewWoxSettingStore(db).Get("ActionedResults", stored); err != nil {
+		t.Fatalf("failed to read stored actioned results: %v", err)
+	}
+
+	calculator, _ := stored.Load(NewResultHash("calculator", "2", ""))
+	var queries []string
+	for _, action := range calculator {
+		queries = append(queries, action.Query)
+	}
+	if len(queries) != 4 || queries[0] != "older" || queries[1] != "newer" || queries[2] != "existing" || queries[3] != "now" {
+		t.Fatalf("expected the seeded actions merged with the existing one in time order, got %v", queries)
+	}
+
+	terminal, _ := stored.Load(NewResultHash("app", "Terminal", ""))
+	if len(terminal) != maxActionedResultsPerHash || terminal[0].Timestamp != 6 || terminal[len(terminal)-1].Timestamp != int64(maxActionedResultsPerHash+5) {
+		t.Fatalf("expected the newest %d actions to be kept, got %d from %d", maxActionedResultsPerHash, len(terminal), terminal[0].Timestamp)
+	}
+}
+
+func TestSetRejectsValuesTheValidatorRejects(t *testing.T) {
+	db := newTestDB(t)
+	woxSetting := NewManager(NewWoxSettingStore(db), db).GetWoxSetting(context.Background())
+
+	if err := woxSetting.QueryDebounceMs.Set(MaxQueryDebounceMs + 1); !errors.Is(err, ErrInvalidSettingValue) {
+		t.Fatalf("expected an invalid setting value error, got %v", err)
+	}
+	if got := woxSetting.QueryDebounceMs.Get(); got != 0 {
+		t.Fatalf("expected the rejected value not to be kept, got %d", got)
+	}
+	if err := woxSetting.QueryDebounceMs.Set(150); err != nil || woxSetting.QueryDebounceMs.Get() != 150 {
+		t.Fatalf("expected a valid value to be saved, got %d (%v)", woxSetting.QueryDebounceMs.Get(), err)
+	}
+}
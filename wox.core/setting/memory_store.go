@@ -0,0 +1,65 @@
+package setting
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// MemorySettingStore keeps settings in memory only. Values are stored in their
+// serialized form so reads behave exactly like the database backed store,
+// including gorm.ErrRecordNotFound for missing keys, which makes setting
+// values fall back to their defaults.
+type MemorySettingStore struct {
+	values map[string]string
+	mu     sync.RWMutex
+}
+
+func NewMemorySettingStore() *MemorySettingStore {
+	return &MemorySettingStore{
+		values: map[string]string{},
+	}
+}
+
+func (s *MemorySettingStore) Get(key string, target interface{}) error {
+	s.mu.RLock()
+	strValue, ok := s.values[key]
+	s.mu.RUnlock()
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	return deserializeValue(strValue, target)
+}
+
+func (s *MemorySettingStore) Set(key string, value interface{}) error {
+	strValue, err := SerializeValue(value)
+	if err != nil {
+		return fmt.Errorf("failed to serialize value: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = strValue
+	return nil
+}
+
+func (s *MemorySettingStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// List returns a copy of every stored value.
+func (s *MemorySettingStore) List() (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values := make(map[string]string, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,162 @@
+package setting
+
+import (
+	"reflect"
+	"sort"
+)
+
+// SettingValueType is how a setting value is written in an update request.
+type SettingValueType string
+
+const (
+	SettingValueTypeBool   SettingValueType = "bool"
+	SettingValueTypeInt    SettingValueType = "int"
+	SettingValueTypeString SettingValueType = "string"
+	SettingValueTypeJSON   SettingValueType = "json" // slices, maps and structs, sent as serialized JSON
+)
+
+// SettingMeta describes a Wox setting key, so callers can check a key and
+// format its value before updating it.
+type SettingMeta struct {
+	Key        string
+	Type       SettingValueType
+	IsPlatform bool // stored per platform with an @platform suffix
+	// RequiresValidation is set when an update can be rejected for a well
+	// typed value, e.g. an unparsable hotkey or an out of range number.
+	RequiresValidation bool
+}
+
+// updatableSetting is an entry of updatableSettings.
+type updatableSetting struct {
+	// validated is set for keys the update handler checks against the system
+	// (hotkey registration, interpreter paths, proxy reachability, policies)
+	// although their value has no validator.
+	validated bool
+	// legacyType is the value type of keys without a WoxSetting field, which
+	// the update handler maps onto other settings.
+	legacyType SettingValueType
+}
+
+// updatableSettings lists every key the settings UI can update, see
+// handleSettingWoxUpdate in the ui package. Other WoxSetting fields are app
+// data or written by dedicated endpoints and have no metadata.
+var updatableSettings = map[string]updatableSetting{
+	"MainHotkey":                         {validated: true},
+	"MainHotkeys":                        {validated: true},
+	"SelectionHotkey":                    {validated: true},
+	"QueryHotkeys":                       {validated: true},
+	"ReleaseChannel":                     {},
+	"EnableAutostart":                    {},
+	"AutostartReconcileMode":             {validated: true},
+	"IgnoredHotkeyApps":                  {},
+	"LogLevel":                           {},
+	"UsePinYin":                          {legacyType: SettingValueTypeBool}, // sets PinYinMatchMode
+	"PinYinMatchMode":                    {validated: true},
+	"SwitchInputMethodABC":               {},
+	"HideOnStart":                        {},
+	"OnboardingFinished":                 {},
+	"HideOnLostFocus":                    {},
+	"ShowTray":                           {},
+	"LangCode":                           {},
+	"QueryShortcuts":                     {},
+	"CloudSyncServerUrl":                 {validated: true},
+	"CloudSyncDisabledPlugins":           {},
+	"TrayQueries":                        {},
+	"LaunchMode":                         {},
+	"StartPage":                          {},
+	"ShowPosition":                       {},
+	"AIProviders":                        {validated: true},
+	"EnableAutoBackup":                   {},
+	"AutoBackupIntervalHours":            {},
+	"AutoBackupMaxCount":                 {},
+	"EncryptBackups":                     {},
+	"EnableAutoUpdate":                   {validated: true},
+	"DoNotDisturb":                       {},
+	"DoNotDisturbStart":                  {},
+	"DoNotDisturbEnd":                    {},
+	"NotificationSound":                  {},
+	"CustomPythonPath":                   {validated: true},
+	"CustomNodejsPath":                   {validated: true},
+	"HttpProxyEnabled":                   {validated: true},
+	"HttpProxyUrl":                       {validated: true},
+	"AppWidth":                           {validated: true},
+	"QueryHistoryExcludePatterns":        {},
+	"AppDataFormat":                      {validated: true},
+	"MaxFavoriteResults":                 {},
+	"MaxResultCount":                     {},
+	"UiDensity":                          {},
+	"ThemeId":                            {},
+	"AppFontFamily":                      {},
+	"EnableQueryCompletionHint":          {},
+	"EnableGlance":                       {},
+	"PrimaryGlance":                      {},
+	"HideGlanceIcon":                     {},
+	"QueryModeSettings":                  {},
+	"RankingMode":                        {validated: true},
+	"QueryDebounceMs":                    {validated: true},
+	"ShowScoreTail":                      {},
+	"ShowPerformanceTail":                {},
+	"ShowPerformanceTailBatch":           {},
+	"ShowPerformanceTailPluginQuery":     {},
+	"ShowPerformanceTailBackendPrepared": {},
+	"ShowPerformanceTailUiReceived":      {},
+	"EnableAnonymousUsageStats":          {},
+}
+
+// UpdatableSettingKeys returns every key the settings UI can update, sorted.
+func UpdatableSettingKeys() []string {
+	keys := make([]string, 0, len(updatableSettings))
+	for key := range updatableSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SettingMetadata returns the metadata of the Wox setting key, which is the
+// setting name without any platform suffix. ok is false for keys the settings
+// UI cannot update.
+func (m *Manager) SettingMetadata(key string) (SettingMeta, bool) {
+	updatable, ok := updatableSettings[key]
+	if !ok {
+		return SettingMeta{}, false
+	}
+	if updatable.legacyType != "" {
+		return SettingMeta{Key: key, Type: updatable.legacyType, RequiresValidation: updatable.validated}, true
+	}
+
+	settingValue := reflect.ValueOf(m.currentWoxSetting()).Elem()
+	field, found := settingValue.Type().FieldByName(key)
+	if !found {
+		return SettingMeta{}, false
+	}
+	valueType, isPlatform, ok := settingValueType(field.Type)
+	if !ok {
+		return SettingMeta{}, false
+	}
+
+	meta := SettingMeta{
+		Key:                key,
+		Type:               settingValueTypeOf(valueType),
+		IsPlatform:         isPlatform,
+		RequiresValidation: updatable.validated,
+	}
+	if value, ok := settingValue.FieldByIndex(field.Index).Interface().(interface{ hasValidator() bool }); ok && value.hasValidator() {
+		meta.RequiresValidation = true
+	}
+	return meta, true
+}
+
+func settingValueTypeOf(t reflect.Type) SettingValueType {
+	switch t.Kind() {
+	case reflect.Bool:
+		return SettingValueTypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SettingValueTypeInt
+	case reflect.String:
+		return SettingValueTypeString
+	default:
+		return SettingValueTypeJSON
+	}
+}
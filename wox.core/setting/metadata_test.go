@@ -0,0 +1,26 @@
+package setting
+
+import "testing"
+
+func TestSettingMetadataCoversUpdatableKeysOnly(t *testing.T) {
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+
+	for _, key := range UpdatableSettingKeys() {
+		if _, ok := m.SettingMetadata(key); !ok {
+			t.Fatalf("expected metadata for updatable key %s", key)
+		}
+	}
+	for _, key := range []string{"ActionedResults", "PinedResults", "LastWindowX", "ActiveProfile", "FavoriteLabels", "IgnoredDoctorChecks", "NoSuchSetting"} {
+		if _, ok := m.SettingMetadata(key); ok {
+			t.Fatalf("expected no metadata for %s, which the settings UI cannot update", key)
+		}
+	}
+
+	if meta, _ := m.SettingMetadata("UsePinYin"); meta.Type != SettingValueTypeBool {
+		t.Fatalf("expected UsePinYin to be a bool, got %+v", meta)
+	}
+	if meta, _ := m.SettingMetadata("MainHotkey"); !meta.IsPlatform || !meta.RequiresValidation || meta.Type != SettingValueTypeString {
+		t.Fatalf("expected MainHotkey to be a validated platform string, got %+v", meta)
+	}
+}
@@ -0,0 +1,24 @@
+package setting
+
+import (
+	"strings"
+	"wox/util"
+	"wox/util/notifier"
+)
+
+// IsValidNotificationSound accepts notifier.SoundDefault, notifier.SoundSilent
+// or a non-empty sound file path. Whether the file exists is checked when the
+// user saves the setting, a file removed later just plays nothing.
+func IsValidNotificationSound(value string) bool {
+	return strings.TrimSpace(value) != ""
+}
+
+// NotificationSound returns the configured notification sound with ~ and
+// environment variables in custom file paths expanded.
+func (m *Manager) NotificationSound() string {
+	sound := m.currentWoxSetting().NotificationSound.Get()
+	if sound == notifier.SoundDefault || sound == notifier.SoundSilent {
+		return sound
+	}
+	return util.ExpandPath(sound)
+}
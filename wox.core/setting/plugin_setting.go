@@ -23,6 +23,13 @@ func NewPluginSetting(store *PluginSettingStore, defaultSettingsInMetadata map[s
 	}
 }
 
+// Reload drops the cached typed values, so they are read from the store again
+// after their rows were replaced in bulk, see Manager.OnPluginSettingChanged.
+func (p *PluginSetting) Reload() {
+	p.Disabled.unload()
+	p.TriggerKeywords.unload()
+}
+
 // Try to get the value of the setting. If the setting is not found, return the default value in metadata if exist, otherwise return empty string
 func (p *PluginSetting) Get(key string) (string, bool) {
 	var val string
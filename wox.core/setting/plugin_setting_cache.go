@@ -0,0 +1,153 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"wox/database"
+
+	"gorm.io/gorm"
+)
+
+// pluginSettingCacheKey includes the database because tests and tools open
+// several databases that use the same plugin ids.
+type pluginSettingCacheKey struct {
+	db       *gorm.DB
+	pluginId string
+}
+
+type pluginSettingCacheEntry struct {
+	values map[string]string
+	loaded bool
+	// generation is bumped by every invalidation, so a load that raced with a
+	// write does not store the values it read before the write.
+	generation uint64
+}
+
+// pluginSettingCache keeps the raw stored values of each plugin in memory, so
+// PluginSetting.Get during queries does not hit sqlite for every key. It is
+// shared by all PluginSettingStore instances, because the cloud sync applier
+// and the plugin installer create their own stores for the same plugin.
+var pluginSettingCache = struct {
+	sync.Mutex
+	entries map[pluginSettingCacheKey]*pluginSettingCacheEntry
+}{entries: map[pluginSettingCacheKey]*pluginSettingCacheEntry{}}
+
+// cachedValue returns the stored value of key, loading all settings of the
+// plugin on first use. Values are kept as strings, so every caller
+// deserializes into its own copy and cannot mutate the cache. A missing key
+// returns gorm.ErrRecordNotFound like the uncached query did.
+func (s *PluginSettingStore) cachedValue(key string) (string, error) {
+	if value, found, overridden := readOnlyPluginSettings.lookup(s.pluginId, key); overridden {
+		if !found {
+			return "", gorm.ErrRecordNotFound
+		}
+		return value, nil
+	}
+
+	pluginSettingCache.Lock()
+	entry := pluginSettingCache.entries[s.cacheKey()]
+	if entry != nil && entry.loaded {
+		value, found := entry.values[key]
+		pluginSettingCache.Unlock()
+		if !found {
+			return "", gorm.ErrRecordNotFound
+		}
+		return value, nil
+	}
+	pluginSettingCache.Unlock()
+
+	values, err := s.loadIntoCache()
+	if err != nil {
+		return "", err
+	}
+	value, found := values[key]
+	if !found {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+// loadIntoCache reads every stored setting of the plugin in one query and
+// caches the result unless a write invalidated the plugin meanwhile.
+func (s *PluginSettingStore) loadIntoCache() (map[string]string, error) {
+	db, err := s.scoped()
+	if err != nil {
+		return nil, err
+	}
+
+	pluginSettingCache.Lock()
+	entry, ok := pluginSettingCache.entries[s.cacheKey()]
+	if !ok {
+		entry = &pluginSettingCacheEntry{}
+		pluginSettingCache.entries[s.cacheKey()] = entry
+	}
+	generation := entry.generation
+	pluginSettingCache.Unlock()
+
+	var rows []database.PluginSetting
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if err := s.checkNamespace(row); err != nil {
+			return nil, err
+		}
+		values[row.Key] = row.Value
+	}
+
+	pluginSettingCache.Lock()
+	if entry.generation == generation {
+		entry.values = values
+		entry.loaded = true
+	}
+	pluginSettingCache.Unlock()
+
+	return values, nil
+}
+
+// invalidateCache drops the cached values of this store's plugin. Every write
+// through a store calls it once the database write is done.
+func (s *PluginSettingStore) invalidateCache() {
+	pluginSettingCache.Lock()
+	defer pluginSettingCache.Unlock()
+
+	if entry, ok := pluginSettingCache.entries[s.cacheKey()]; ok {
+		entry.invalidate()
+	}
+}
+
+func (s *PluginSettingStore) cacheKey() pluginSettingCacheKey {
+	return pluginSettingCacheKey{db: s.db, pluginId: s.pluginId}
+}
+
+func (e *pluginSettingCacheEntry) invalidate() {
+	e.values = nil
+	e.loaded = false
+	e.generation++
+}
+
+// invalidateAllPluginSettingCaches is used after plugin_settings is rewritten
+// in bulk, e.g. by a backup restore or a settings bundle import.
+func invalidateAllPluginSettingCaches() {
+	pluginSettingCache.Lock()
+	defer pluginSettingCache.Unlock()
+
+	for _, entry := range pluginSettingCache.entries {
+		entry.invalidate()
+	}
+}
+
+// ReloadPluginSetting drops the cached settings of a plugin and reads them
+// again from the database. Call it after the settings were changed outside
+// Wox, e.g. by editing wox.db with an external tool.
+func (m *Manager) ReloadPluginSetting(ctx context.Context, pluginId string) error {
+	store := NewPluginSettingStore(m.db, pluginId)
+	store.invalidateCache()
+	if _, err := store.loadIntoCache(); err != nil {
+		return fmt.Errorf("failed to reload plugin setting of %s: %w", pluginId, err)
+	}
+	logger.Info(ctx, fmt.Sprintf("reloaded plugin setting of %s", pluginId))
+	return nil
+}
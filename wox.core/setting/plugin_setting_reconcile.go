@@ -0,0 +1,84 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"wox/setting/definition"
+)
+
+// pluginSettingReservedKeys are stored by Wox itself and never come from plugin definitions.
+var pluginSettingReservedKeys = []string{"Disabled", "TriggerKeywords"}
+
+// Reconcile aligns stored values with the current setting definitions of the plugin.
+// Values that no longer fit their definition (e.g. a textbox turned into a checkbox)
+// are reset to the definition default. When removeStale is true, stored keys without a
+// definition are deleted as well; this is opt-in because plugins also store their own
+// data through the settings API and a downgraded plugin may still need old keys.
+func (p *PluginSetting) Reconcile(ctx context.Context, definitions definition.PluginSettingDefinitions, removeStale bool) error {
+	keys, err := p.store.Keys()
+	if err != nil {
+		return fmt.Errorf("failed to list plugin setting keys: %w", err)
+	}
+
+	// Dynamic definitions are resolved at runtime, so their stored keys cannot be known here.
+	hasDynamic := slices.ContainsFunc(definitions, func(item definition.PluginSettingDefinitionItem) bool {
+		return item.Type == definition.PluginSettingDefinitionTypeDynamic
+	})
+
+	for _, key := range keys {
+		baseKey := key
+		if splitKey, _, ok := SplitPlatformSettingKey(key); ok {
+			baseKey = splitKey
+		}
+		if slices.Contains(pluginSettingReservedKeys, baseKey) {
+			continue
+		}
+
+		index := slices.IndexFunc(definitions, func(item definition.PluginSettingDefinitionItem) bool {
+			return item.Value != nil && item.Value.GetKey() == baseKey
+		})
+		if index < 0 {
+			if removeStale && !hasDynamic {
+				logger.Info(ctx, fmt.Sprintf("removing stale plugin setting %s of plugin %s", key, p.store.pluginId))
+				if deleteErr := p.Delete(key); deleteErr != nil {
+					return deleteErr
+				}
+			}
+			continue
+		}
+
+		value, _ := p.Get(key)
+		if isPluginSettingValueCompatible(definitions[index], value) {
+			continue
+		}
+		defaultValue := definitions[index].Value.GetDefaultValue()
+		logger.Info(ctx, fmt.Sprintf("resetting plugin setting %s of plugin %s to default, stored value does not match %s definition", key, p.store.pluginId, definitions[index].Type))
+		if setErr := p.Set(key, defaultValue); setErr != nil {
+			return setErr
+		}
+	}
+
+	return nil
+}
+
+// isPluginSettingValueCompatible reports whether a stored value can still be read by the definition's setting type.
+// Only structural types are checked: select options are often computed at runtime (installed browsers,
+// apps, ...) and multi selects are stored comma separated, so any string stays a valid select value.
+func isPluginSettingValueCompatible(item definition.PluginSettingDefinitionItem, value string) bool {
+	if value == item.Value.GetDefaultValue() {
+		return true
+	}
+
+	switch item.Type {
+	case definition.PluginSettingDefinitionTypeCheckBox:
+		return value == "true" || value == "false"
+	case definition.PluginSettingDefinitionTypeTable:
+		var rows []map[string]any
+		return strings.TrimSpace(value) == "" || json.Unmarshal([]byte(value), &rows) == nil
+	default:
+		return true
+	}
+}
@@ -0,0 +1,110 @@
+package setting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"wox/util"
+)
+
+// AutoUpdatePolicyEnv disables auto update for every user of the machine when
+// set to a true value, e.g. by a deployment script.
+const AutoUpdatePolicyEnv = "WOX_DISABLE_AUTO_UPDATE"
+
+// MachinePolicy is the machine-level policy file administrators deploy next
+// to (not inside) the per-user data directories, see machinePolicyPath.
+type MachinePolicy struct {
+	DisableAutoUpdate bool
+}
+
+// AutoUpdatePolicy tells whether auto update is forced off and by what, so
+// the UI can explain why the toggle is read-only.
+type AutoUpdatePolicy struct {
+	Locked bool
+	Source string // the env var name or the policy file path
+}
+
+// loadAutoUpdatePolicy returns the policy of this machine, read once per
+// process. Tests replace it to simulate a locked or unlocked machine.
+var loadAutoUpdatePolicy = sync.OnceValue(func() AutoUpdatePolicy {
+	return readAutoUpdatePolicy(os.LookupEnv, machinePolicyPath())
+})
+
+// readAutoUpdatePolicy reads the policy from the AutoUpdatePolicyEnv variable,
+// looked up with lookupEnv, and from the policy file at path. The variable
+// wins; a missing or unreadable file leaves auto update unlocked.
+func readAutoUpdatePolicy(lookupEnv func(key string) (string, bool), path string) AutoUpdatePolicy {
+	if value, ok := lookupEnv(AutoUpdatePolicyEnv); ok {
+		if disabled, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil && disabled {
+			return AutoUpdatePolicy{Locked: true, Source: AutoUpdatePolicyEnv}
+		}
+	}
+
+	if path == "" {
+		return AutoUpdatePolicy{}
+	}
+	policy, err := readMachinePolicy(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Error(util.NewTraceContext(), fmt.Sprintf("failed to read machine policy %s: %s", path, err.Error()))
+		}
+		return AutoUpdatePolicy{}
+	}
+	return AutoUpdatePolicy{Locked: policy.DisableAutoUpdate, Source: path}
+}
+
+// GetAutoUpdatePolicy returns the machine policy for auto update. It is read
+// once per process; changing the policy takes effect after a restart.
+func GetAutoUpdatePolicy() AutoUpdatePolicy {
+	return loadAutoUpdatePolicy()
+}
+
+// IsAutoUpdateEnabled returns the effective auto update state: the user
+// setting, unless the machine policy forces it off.
+func (w *WoxSetting) IsAutoUpdateEnabled() bool {
+	return !GetAutoUpdatePolicy().Locked && w.EnableAutoUpdate.Get()
+}
+
+// CheckAutoUpdatePolicy returns a policy locked error for EnableAutoUpdate
+// when the machine policy forces auto update off, nil otherwise.
+func CheckAutoUpdatePolicy() error {
+	policy := GetAutoUpdatePolicy()
+	if !policy.Locked {
+		return nil
+	}
+	return NewSettingPolicyLockedError("EnableAutoUpdate", fmt.Errorf("auto update is disabled by %s and cannot be changed", policy.Source))
+}
+
+func readMachinePolicy(path string) (MachinePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MachinePolicy{}, err
+	}
+	var policy MachinePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return MachinePolicy{}, fmt.Errorf("invalid policy file: %w", err)
+	}
+	return policy, nil
+}
+
+// machinePolicyPath returns where administrators put the policy file, a
+// location regular users cannot write to.
+func machinePolicyPath() string {
+	switch {
+	case util.IsWindows():
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			return ""
+		}
+		return filepath.Join(programData, "Wox", "policy.json")
+	case util.IsMacOS():
+		return "/Library/Application Support/Wox/policy.json"
+	default:
+		return "/etc/wox/policy.json"
+	}
+}
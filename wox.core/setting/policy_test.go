@@ -0,0 +1,79 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/util"
+)
+
+func TestReadAutoUpdatePolicy(t *testing.T) {
+	if logger == nil {
+		logger = util.GetLogger()
+	}
+	dir := t.TempDir()
+	writePolicy := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write policy: %v", err)
+		}
+		return path
+	}
+	lockedFile := writePolicy("locked.json", `{"DisableAutoUpdate": true}`)
+	unlockedFile := writePolicy("unlocked.json", `{"DisableAutoUpdate": false}`)
+	invalidFile := writePolicy("invalid.json", `{`)
+	env := func(value string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			return value, key == AutoUpdatePolicyEnv && value != ""
+		}
+	}
+
+	tests := []struct {
+		name     string
+		env      string
+		path     string
+		expected AutoUpdatePolicy
+	}{
+		{"env locks", " true ", unlockedFile, AutoUpdatePolicy{Locked: true, Source: AutoUpdatePolicyEnv}},
+		{"env false falls back to the file", "0", lockedFile, AutoUpdatePolicy{Locked: true, Source: lockedFile}},
+		{"file locks", "", lockedFile, AutoUpdatePolicy{Locked: true, Source: lockedFile}},
+		{"file unlocks", "", unlockedFile, AutoUpdatePolicy{Source: unlockedFile}},
+		{"missing file", "", filepath.Join(dir, "missing.json"), AutoUpdatePolicy{}},
+		{"invalid file", "", invalidFile, AutoUpdatePolicy{}},
+		{"no policy location", "not a bool", "", AutoUpdatePolicy{}},
+	}
+	for _, test := range tests {
+		if policy := readAutoUpdatePolicy(env(test.env), test.path); policy != test.expected {
+			t.Fatalf("%s: expected %+v, got %+v", test.name, test.expected, policy)
+		}
+	}
+}
+
+func TestAutoUpdatePolicyLocksEnableAutoUpdate(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	woxSetting := NewManager(NewWoxSettingStore(db), db).GetWoxSetting(ctx)
+	if err := woxSetting.EnableAutoUpdate.Set(true); err != nil {
+		t.Fatalf("failed to enable auto update: %v", err)
+	}
+
+	original := loadAutoUpdatePolicy
+	t.Cleanup(func() { loadAutoUpdatePolicy = original })
+
+	loadAutoUpdatePolicy = func() AutoUpdatePolicy { return AutoUpdatePolicy{} }
+	if err := CheckAutoUpdatePolicy(); err != nil || !woxSetting.IsAutoUpdateEnabled() {
+		t.Fatalf("expected auto update to follow the user setting without a policy, got %v", err)
+	}
+
+	loadAutoUpdatePolicy = func() AutoUpdatePolicy { return AutoUpdatePolicy{Locked: true, Source: AutoUpdatePolicyEnv} }
+	if woxSetting.IsAutoUpdateEnabled() {
+		t.Fatalf("expected the policy to force auto update off")
+	}
+	err := CheckAutoUpdatePolicy()
+	var updateErr *SettingUpdateError
+	if !errors.As(err, &updateErr) || updateErr.Key != "EnableAutoUpdate" || !errors.Is(updateErr.Kind, ErrSettingPolicyLocked) {
+		t.Fatalf("expected EnableAutoUpdate changes to be rejected as policy locked, got %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DefaultProfileName is the profile that holds the settings Wox had before
+// profiles were used.
+const DefaultProfileName = "default"
+
+const maxProfileNameLength = 64
+
+// Profile is a saved set of Wox settings, plugin settings and app data
+// (query history, favorites, MRU). Each profile is a database snapshot in its
+// own directory below <wox data>/profiles; the active profile lives in wox.db
+// and is written back to its directory when switching away from it.
+type Profile struct {
+	Name     string
+	IsActive bool
+}
+
+// profileData is the content of a profile, the rows that SwitchProfile swaps.
+type profileData struct {
+	woxSettings    []database.WoxSetting
+	pluginSettings []database.PluginSetting
+	queryHistory   []database.QueryHistoryRecord
+	mruRecords     []database.MRURecord
+}
+
+func getProfilesDirectory() string {
+	return filepath.Join(util.GetLocation().GetWoxDataDirectory(), "profiles")
+}
+
+func getProfileDatabasePath(name string) string {
+	return filepath.Join(getProfilesDirectory(), name, backupDatabaseFileName)
+}
+
+// ValidateProfileName rejects names that cannot be used as a directory name
+// on every platform.
+func ValidateProfileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("profile name is empty")
+	}
+	if name != strings.TrimSpace(name) {
+		return fmt.Errorf("profile name %q must not start or end with spaces", name)
+	}
+	if len(name) > maxProfileNameLength {
+		return fmt.Errorf("profile name must be at most %d bytes", maxProfileNameLength)
+	}
+	if name == "." || name == ".." || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if strings.ContainsAny(name, `/\:*?"<>|`) || strings.ContainsFunc(name, unicode.IsControl) {
+		return fmt.Errorf("profile name %q must not contain any of / \\ : * ? \" < > |", name)
+	}
+	return nil
+}
+
+// ActiveProfile returns the name of the profile in use.
+func (m *Manager) ActiveProfile(ctx context.Context) string {
+	return m.currentWoxSetting().ActiveProfile.Get()
+}
+
+// ListProfiles returns the saved profiles sorted by name. The active and the
+// default profile are always included, even before they were first saved.
+func (m *Manager) ListProfiles(ctx context.Context) ([]Profile, error) {
+	names := []string{DefaultProfileName, m.ActiveProfile(ctx)}
+
+	entries, err := os.ReadDir(getProfilesDirectory())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && util.IsFileExists(getProfileDatabasePath(entry.Name())) {
+			names = append(names, entry.Name())
+		}
+	}
+
+	slices.Sort(names)
+	names = slices.Compact(names)
+	profiles := make([]Profile, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, Profile{Name: name, IsActive: name == m.ActiveProfile(ctx)})
+	}
+	return profiles, nil
+}
+
+// CreateProfile saves a new profile that starts as a copy of the current
+// settings and app data. It does not switch to the new profile.
+func (m *Manager) CreateProfile(ctx context.Context, name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if m.IsReadOnly() {
+		return ErrSettingsReadOnly
+	}
+
+	m.profileMu.Lock()
+	defer m.profileMu.Unlock()
+
+	if name == m.ActiveProfile(ctx) || util.IsFileExists(getProfileDatabasePath(name)) {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+	// App data changed in memory is only in the snapshot once it is saved.
+	if err := m.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to save pending app data: %w", err)
+	}
+	if err := m.saveActiveProfile(ctx, name); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, fmt.Sprintf("created profile %s from profile %s", name, m.ActiveProfile(ctx)))
+	return nil
+}
+
+// SwitchProfile saves the active profile to its directory and replaces the
+// settings, plugin settings and app data in wox.db with the ones of profile
+// name. Settings and plugin settings are written through their stores, so
+// changes get oplogs and LastModified advances like for any other write.
+// Handlers registered with OnSettingChanged and OnPluginSettingChanged are
+// called for every changed value, so derived state such as the language,
+// proxy, hotkeys and loaded plugin settings follows without a restart. When a
+// step fails, the active profile is restored from the snapshot saved first.
+func (m *Manager) SwitchProfile(ctx context.Context, name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if m.IsReadOnly() {
+		return ErrSettingsReadOnly
+	}
+
+	m.profileMu.Lock()
+	defer m.profileMu.Unlock()
+
+	activeProfile := m.ActiveProfile(ctx)
+	if name == activeProfile {
+		return nil
+	}
+	if !util.IsFileExists(getProfileDatabasePath(name)) {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+
+	// Read the target first so a broken profile never leaves the active one
+	// half saved.
+	data, err := readProfileData(getProfileDatabasePath(name))
+	if err != nil {
+		return fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+	if err := m.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to save pending app data: %w", err)
+	}
+	if err := m.saveActiveProfile(ctx, activeProfile); err != nil {
+		return err
+	}
+
+	oldValues := m.serializedWoxSettingValues()
+	if err := m.applyProfileData(ctx, name, data); err != nil {
+		// The steps commit one by one, so put the snapshot saved above back
+		// instead of leaving a mix of both profiles.
+		if restoreErr := m.restoreSavedProfile(ctx, activeProfile); restoreErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to restore profile %s after a failed switch: %s", activeProfile, restoreErr.Error()))
+		}
+		m.notifyChangedSettings(ctx, oldValues, SettingAuditSourceProfile)
+		return fmt.Errorf("failed to switch to profile %s: %w", name, err)
+	}
+	logger.Info(ctx, fmt.Sprintf("switched profile from %s to %s", activeProfile, name))
+
+	m.notifyChangedSettings(ctx, oldValues, SettingAuditSourceProfile)
+	return nil
+}
+
+// restoreSavedProfile applies the snapshot of profile name that
+// saveActiveProfile wrote, undoing a switch that failed halfway.
+func (m *Manager) restoreSavedProfile(ctx context.Context, name string) error {
+	data, err := readProfileData(getProfileDatabasePath(name))
+	if err != nil {
+		return fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+	return m.applyProfileData(ctx, name, data)
+}
+
+// applyProfileData replaces the settings, plugin settings and app data in
+// wox.db with data and makes name the active profile.
+func (m *Manager) applyProfileData(ctx context.Context, name string, data profileData) error {
+	woxValues, err := openStoredWoxSettings(data.woxSettings)
+	if err != nil {
+		return err
+	}
+
+	activeProfileKey := m.currentWoxSetting().ActiveProfile.Key()
+	err = m.replaceWoxSettings(ctx, woxValues, func(key string) bool {
+		return key == activeProfileKey
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := m.replacePluginSettings(ctx, data.pluginSettingValues()); err != nil {
+		return err
+	}
+
+	// Query history and MRU are local app data tables without oplogs, so
+	// their rows are swapped as they are.
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&database.QueryHistoryRecord{}, &database.MRURecord{}} {
+			if err := tx.Where("1 = 1").Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		for _, rows := range []any{&data.queryHistory, &data.mruRecords} {
+			if reflect.ValueOf(rows).Elem().Len() == 0 {
+				continue
+			}
+			if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := m.currentWoxSetting().ActiveProfile.Set(name); err != nil {
+		return fmt.Errorf("failed to save active profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// saveActiveProfile writes the current database to the directory of profile
+// name, replacing an older save of it.
+func (m *Manager) saveActiveProfile(ctx context.Context, name string) error {
+	dbPath := getProfileDatabasePath(name)
+	tempPath := dbPath + ".tmp"
+	if err := os.Remove(tempPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale profile snapshot: %w", err)
+	}
+	if err := database.Snapshot(ctx, tempPath); err != nil {
+		return fmt.Errorf("failed to save profile %s: %w", name, err)
+	}
+	if err := os.Rename(tempPath, dbPath); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to save profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// serializedWoxSettingValues returns the serialized value of every Wox
+// setting, keyed by field name.
+func (m *Manager) serializedWoxSettingValues() map[string]string {
+	values := map[string]string{}
+	settingType := reflect.TypeOf(m.currentWoxSetting()).Elem()
+	for i := 0; i < settingType.NumField(); i++ {
+		name := settingType.Field(i).Name
+		if value, ok := m.SerializedWoxSettingValue(name); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// pluginSettingValues returns the plugin settings of the profile keyed by
+// plugin id and key, as replacePluginSettings takes them.
+func (d profileData) pluginSettingValues() map[string]map[string]string {
+	values := map[string]map[string]string{}
+	for _, row := range d.pluginSettings {
+		if values[row.PluginID] == nil {
+			values[row.PluginID] = map[string]string{}
+		}
+		values[row.PluginID][row.Key] = row.Value
+	}
+	return values
+}
+
+// openReadOnlyProfileDB opens the database of a saved profile read-only.
+// SQLite only reads the mode parameter from URI filenames, hence the file:
+// prefix.
+func openReadOnlyProfileDB(dbPath string) (*gorm.DB, error) {
+	return gorm.Open(sqlite.Open("file:"+filepath.ToSlash(dbPath)+"?mode=ro"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+}
+
+// readProfileData opens a saved profile read-only and returns its rows.
+func readProfileData(dbPath string) (profileData, error) {
+	profileDB, err := openReadOnlyProfileDB(dbPath)
+	if err != nil {
+		return profileData{}, err
+	}
+	if sqlDB, sqlErr := profileDB.DB(); sqlErr == nil {
+		defer sqlDB.Close()
+	}
+
+	var data profileData
+	for _, rows := range []any{&data.woxSettings, &data.pluginSettings, &data.queryHistory, &data.mruRecords} {
+		if err := profileDB.Find(rows).Error; err != nil {
+			return profileData{}, err
+		}
+	}
+	return data, nil
+}
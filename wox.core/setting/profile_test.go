@@ -0,0 +1,185 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"wox/cloudsync"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// newProfileTestManager points the data directories at a temp dir and opens
+// wox.db there, because profiles are snapshots of the global database.
+func newProfileTestManager(t *testing.T) (*Manager, *gorm.DB) {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv(util.TestWoxDataDirEnv, filepath.Join(root, "wox"))
+	t.Setenv(util.TestUserDataDirEnv, filepath.Join(root, "user"))
+	if err := util.GetLocation().Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+	if logger == nil {
+		logger = util.GetLogger()
+	}
+	if err := database.Init(context.Background()); err != nil {
+		t.Fatalf("failed to init database: %v", err)
+	}
+	db := database.GetDB()
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return NewManager(NewWoxSettingStore(db), db), db
+}
+
+func TestCreateProfileSavesPendingAppData(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newProfileTestManager(t)
+	m.SetAppDataFlushInterval(time.Hour)
+
+	m.AddActionedResult(ctx, "calculator", "2", "", "1+1", "")
+	if err := m.CreateProfile(ctx, "work"); err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+
+	data, err := readProfileData(getProfileDatabasePath("work"))
+	if err != nil {
+		t.Fatalf("failed to read profile: %v", err)
+	}
+	for _, row := range data.woxSettings {
+		if row.Key == "ActionedResults" {
+			return
+		}
+	}
+	t.Fatalf("expected the profile to contain the app data changed before it was created")
+}
+
+func TestSwitchProfileWritesThroughStoresAndNotifies(t *testing.T) {
+	ctx := context.Background()
+	m, db := newProfileTestManager(t)
+	pluginStore := NewPluginSettingStore(db, "calculator")
+	if err := pluginStore.Set("Precision", "4"); err != nil {
+		t.Fatalf("failed to save plugin setting: %v", err)
+	}
+	if err := m.CreateProfile(ctx, "work"); err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+
+	if err := pluginStore.Set("Precision", "8"); err != nil {
+		t.Fatalf("failed to save plugin setting: %v", err)
+	}
+	if err := m.GetWoxSetting(ctx).ShowTray.Set(false); err != nil {
+		t.Fatalf("failed to save ShowTray: %v", err)
+	}
+	lastModifiedBefore := storedRow(t, db, LastModifiedKey)
+
+	changedSettings := map[string]string{}
+	m.OnSettingChanged(func(ctx context.Context, key string, value string) {
+		changedSettings[key] = value
+	})
+	changedPluginSettings := map[string]string{}
+	m.OnPluginSettingChanged(func(ctx context.Context, pluginId string, key string, oldValue string, newValue string) {
+		changedPluginSettings[pluginId+"/"+key] = newValue
+	})
+
+	if err := m.SwitchProfile(ctx, "work"); err != nil {
+		t.Fatalf("failed to switch profile: %v", err)
+	}
+
+	var precision string
+	if err := pluginStore.Get("Precision", &precision); err != nil || precision != "4" {
+		t.Fatalf("expected the plugin setting of the profile, got %q (%v)", precision, err)
+	}
+	if changedPluginSettings["calculator/Precision"] != "4" {
+		t.Fatalf("expected plugin setting handlers to be told about the switch, got %v", changedPluginSettings)
+	}
+	if !m.GetWoxSetting(ctx).ShowTray.Get() || changedSettings["ShowTray"] != "true" {
+		t.Fatalf("expected ShowTray of the profile to be applied and announced, got %v", changedSettings)
+	}
+	if m.ActiveProfile(ctx) != "work" {
+		t.Fatalf("expected work to be the active profile, got %s", m.ActiveProfile(ctx))
+	}
+
+	var pluginOplogs int64
+	if err := db.Model(&database.Oplog{}).Where("entity_type = ? AND key = ? AND value = ?", cloudsync.EntityPluginSetting, "Precision", "4").Count(&pluginOplogs).Error; err != nil || pluginOplogs == 0 {
+		t.Fatalf("expected the switched plugin setting to get an oplog, got %d (%v)", pluginOplogs, err)
+	}
+	if lastModifiedAfter := storedRow(t, db, LastModifiedKey); lastModifiedAfter < lastModifiedBefore {
+		t.Fatalf("expected LastModified not to go back in time, was %s, now %s", lastModifiedBefore, lastModifiedAfter)
+	}
+}
+
+func TestSwitchProfileRestoresActiveProfileOnFailure(t *testing.T) {
+	ctx := context.Background()
+	m, db := newProfileTestManager(t)
+	pluginStore := NewPluginSettingStore(db, "calculator")
+	if err := pluginStore.Set("Precision", "4"); err != nil {
+		t.Fatalf("failed to save plugin setting: %v", err)
+	}
+	if err := db.Create(&database.MRURecord{Hash: "h1", PluginID: "calculator", Title: "1+1", LastUsed: 1}).Error; err != nil {
+		t.Fatalf("failed to save mru record: %v", err)
+	}
+	if err := m.CreateProfile(ctx, "work"); err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+
+	if err := db.Where("1 = 1").Delete(&database.MRURecord{}).Error; err != nil {
+		t.Fatalf("failed to clear mru records: %v", err)
+	}
+	if err := pluginStore.Set("Precision", "8"); err != nil {
+		t.Fatalf("failed to save plugin setting: %v", err)
+	}
+	if err := m.GetWoxSetting(ctx).ShowTray.Set(false); err != nil {
+		t.Fatalf("failed to save ShowTray: %v", err)
+	}
+
+	// Fail the last step, after settings and plugin settings were replaced.
+	callbackName := "test:fail_mru_create"
+	if err := db.Callback().Create().Before("gorm:create").Register(callbackName, func(tx *gorm.DB) {
+		if tx.Statement.Table == "mru_records" {
+			tx.AddError(errors.New("disk is full"))
+		}
+	}); err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Callback().Create().Remove(callbackName) })
+
+	if err := m.SwitchProfile(ctx, "work"); err == nil {
+		t.Fatalf("expected the switch to fail")
+	}
+
+	var precision string
+	if err := pluginStore.Get("Precision", &precision); err != nil || precision != "8" {
+		t.Fatalf("expected the plugin setting of the active profile to be restored, got %q (%v)", precision, err)
+	}
+	if m.GetWoxSetting(ctx).ShowTray.Get() {
+		t.Fatalf("expected ShowTray of the active profile to be restored")
+	}
+	if m.ActiveProfile(ctx) != DefaultProfileName {
+		t.Fatalf("expected %s to stay the active profile, got %s", DefaultProfileName, m.ActiveProfile(ctx))
+	}
+}
+
+func TestReadOnlyProfileDBRejectsWrites(t *testing.T) {
+	m, _ := newProfileTestManager(t)
+	if err := m.CreateProfile(context.Background(), "work"); err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+
+	profileDB, err := openReadOnlyProfileDB(getProfileDatabasePath("work"))
+	if err != nil {
+		t.Fatalf("failed to open profile: %v", err)
+	}
+	if sqlDB, err := profileDB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	if err := profileDB.Create(&database.WoxSetting{Key: "ShowTray", Value: "false"}).Error; err == nil {
+		t.Fatalf("expected writing to a profile opened read-only to fail")
+	}
+}
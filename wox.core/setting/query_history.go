@@ -0,0 +1,216 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"wox/common"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// MaxQueryHistoryCount is how many distinct queries are kept in the query history.
+const MaxQueryHistoryCount = 1000
+
+// LegacyQueryHistoriesKey is the setting row that held the query history
+// before it moved to the query_history table. The history is local only: the
+// table writes no oplogs and is not part of the cloud sync snapshot, because
+// typed queries are private and change on every launch. Remote changes of the
+// retired row, sent by devices that were not upgraded yet, are ignored.
+const LegacyQueryHistoriesKey = "QueryHistories"
+
+// AddQueryHistory records a submitted query. A previous entry with the same
+// query text is replaced so every query appears once, at its latest time.
+func (m *Manager) AddQueryHistory(ctx context.Context, query common.PlainQuery) {
+	m.addQueryHistory(ctx, "", query, MaxQueryHistoryCount)
+}
+
+// addQueryHistory records query in the history of pluginId, or in the global
+// history when pluginId is empty, and keeps the newest limit entries of it.
+func (m *Manager) addQueryHistory(ctx context.Context, pluginId string, query common.PlainQuery, limit int) {
+	if query.IsEmpty() {
+		return
+	}
+	if m.isQueryHistoryExcluded(ctx, query.String()) {
+		return
+	}
+	// The history is not kept while the data directory is read-only.
+	if m.IsReadOnly() {
+		return
+	}
+
+	plainQuery, err := json.Marshal(query)
+	if err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to encode query history: %s", err.Error()))
+		return
+	}
+
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plugin_id = ? AND query = ?", pluginId, query.String()).Delete(&database.QueryHistoryRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&database.QueryHistoryRecord{
+			PluginID:   pluginId,
+			Query:      query.String(),
+			PlainQuery: string(plainQuery),
+			Timestamp:  util.GetSystemTimestamp(),
+		}).Error; err != nil {
+			return err
+		}
+		kept := tx.Model(&database.QueryHistoryRecord{}).Select("id").Where("plugin_id = ?", pluginId).Order("timestamp DESC, id DESC").Limit(limit)
+		return tx.Where("plugin_id = ? AND id NOT IN (?)", pluginId, kept).Delete(&database.QueryHistoryRecord{}).Error
+	})
+	if err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to add query history: %s", err.Error()))
+	}
+}
+
+// ValidateQueryHistoryExcludePatterns reports the first pattern that is not
+// a valid regular expression.
+func ValidateQueryHistoryExcludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid query history exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func IsValidQueryHistoryExcludePatterns(patterns []string) bool {
+	return ValidateQueryHistoryExcludePatterns(patterns) == nil
+}
+
+// queryHistoryExcludeCache holds QueryHistoryExcludePatterns compiled for
+// the patterns they were compiled from.
+type queryHistoryExcludeCache struct {
+	patterns []string
+	compiled []*regexp.Regexp
+}
+
+// isQueryHistoryExcluded reports whether the query matches one of the
+// QueryHistoryExcludePatterns. The query itself is never logged, it may be
+// exactly what the user wants to keep private.
+func (m *Manager) isQueryHistoryExcluded(ctx context.Context, query string) bool {
+	for _, re := range m.compiledQueryHistoryExcludes(ctx) {
+		if re.MatchString(query) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledQueryHistoryExcludes returns the compiled exclude patterns. They
+// are only compiled again when the setting changed since the last call,
+// whether it was changed in the settings UI, by a restore or by cloud sync.
+func (m *Manager) compiledQueryHistoryExcludes(ctx context.Context) []*regexp.Regexp {
+	patterns := m.currentWoxSetting().QueryHistoryExcludePatterns.Get()
+
+	m.queryHistoryExcludesMu.Lock()
+	defer m.queryHistoryExcludesMu.Unlock()
+
+	if m.queryHistoryExcludes.compiled != nil && slices.Equal(m.queryHistoryExcludes.patterns, patterns) {
+		return m.queryHistoryExcludes.compiled
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Warn(ctx, fmt.Sprintf("skip invalid query history exclude pattern %q: %s", pattern, err.Error()))
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	m.queryHistoryExcludes = queryHistoryExcludeCache{patterns: slices.Clone(patterns), compiled: compiled}
+	return compiled
+}
+
+// GetLatestQueryHistory returns up to limit queries, newest first.
+func (m *Manager) GetLatestQueryHistory(ctx context.Context, limit int) []QueryHistory {
+	return m.SearchQueryHistory(ctx, "", limit)
+}
+
+// SearchQueryHistory returns up to limit queries containing keyword, newest
+// first. An empty keyword matches every query.
+func (m *Manager) SearchQueryHistory(ctx context.Context, keyword string, limit int) []QueryHistory {
+	return m.searchQueryHistory(ctx, "", keyword, limit)
+}
+
+// searchQueryHistory is SearchQueryHistory for the history of pluginId, or
+// the global history when pluginId is empty.
+func (m *Manager) searchQueryHistory(ctx context.Context, pluginId string, keyword string, limit int) []QueryHistory {
+	if limit <= 0 {
+		return []QueryHistory{}
+	}
+
+	db := m.db.Where("plugin_id = ?", pluginId).Order("timestamp DESC, id DESC").Limit(limit)
+	if keyword != "" {
+		db = db.Where("query LIKE ? ESCAPE '\\'", "%"+escapeLikePattern(keyword)+"%")
+	}
+
+	var records []database.QueryHistoryRecord
+	if err := db.Find(&records).Error; err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to load query history: %s", err.Error()))
+		return []QueryHistory{}
+	}
+
+	histories := make([]QueryHistory, 0, len(records))
+	for _, record := range records {
+		var query common.PlainQuery
+		if err := json.Unmarshal([]byte(record.PlainQuery), &query); err != nil {
+			logger.Warn(ctx, fmt.Sprintf("failed to decode query history %d: %s", record.ID, err.Error()))
+			continue
+		}
+		histories = append(histories, QueryHistory{Query: query, Timestamp: record.Timestamp})
+	}
+	return histories
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// MaxPluginQueryHistoryCount is how many distinct queries are kept per plugin.
+const MaxPluginQueryHistoryCount = 100
+
+// AddPluginQueryHistory records a query in the history of one plugin, e.g. so
+// a calculator can list past calculations. It is kept apart from the global
+// history in the same local table, and QueryHistoryExcludePatterns apply the
+// same way. A previous entry with the same query text is replaced.
+func (m *Manager) AddPluginQueryHistory(ctx context.Context, pluginId string, query common.PlainQuery) {
+	if pluginId == "" {
+		return
+	}
+	m.addQueryHistory(ctx, pluginId, query, MaxPluginQueryHistoryCount)
+}
+
+// GetPluginQueryHistory returns up to limit queries of one plugin, newest first.
+func (m *Manager) GetPluginQueryHistory(ctx context.Context, pluginId string, limit int) []QueryHistory {
+	if pluginId == "" {
+		return []QueryHistory{}
+	}
+	return m.searchQueryHistory(ctx, pluginId, "", limit)
+}
+
+// DeletePluginQueryHistory forgets the query history of a plugin, e.g. when
+// it is uninstalled.
+func (m *Manager) DeletePluginQueryHistory(ctx context.Context, pluginId string) {
+	if pluginId == "" || m.IsReadOnly() {
+		return
+	}
+
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	if err := m.db.Where("plugin_id = ?", pluginId).Delete(&database.QueryHistoryRecord{}).Error; err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to delete query history of plugin %s: %s", pluginId, err.Error()))
+	}
+}
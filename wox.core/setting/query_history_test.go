@@ -0,0 +1,81 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"wox/common"
+	"wox/database"
+)
+
+func TestQueryHistoryExcludePatternsCompileOncePerChange(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+	woxSetting := m.GetWoxSetting(ctx)
+	if err := woxSetting.QueryHistoryExcludePatterns.Set([]string{"^secret"}); err != nil {
+		t.Fatalf("failed to save exclude patterns: %v", err)
+	}
+
+	m.AddQueryHistory(ctx, common.PlainQuery{QueryType: "input", QueryText: "secret token"})
+	m.AddQueryHistory(ctx, common.PlainQuery{QueryType: "input", QueryText: "hello"})
+	if histories := m.GetLatestQueryHistory(ctx, 10); len(histories) != 1 || histories[0].Query.QueryText != "hello" {
+		t.Fatalf("expected only the query not matching the pattern to be recorded, got %+v", histories)
+	}
+
+	first := m.compiledQueryHistoryExcludes(ctx)
+	if second := m.compiledQueryHistoryExcludes(ctx); len(first) != 1 || &first[0] != &second[0] {
+		t.Fatalf("expected unchanged patterns to reuse the compiled expressions")
+	}
+
+	if err := woxSetting.QueryHistoryExcludePatterns.Set([]string{"^hello"}); err != nil {
+		t.Fatalf("failed to save exclude patterns: %v", err)
+	}
+	if changed := m.compiledQueryHistoryExcludes(ctx); len(changed) != 1 || changed[0].String() != "^hello" {
+		t.Fatalf("expected changed patterns to be compiled again, got %v", changed)
+	}
+	m.AddQueryHistory(ctx, common.PlainQuery{QueryType: "input", QueryText: "hello again"})
+	m.AddQueryHistory(ctx, common.PlainQuery{QueryType: "input", QueryText: "secret token"})
+	if histories := m.GetLatestQueryHistory(ctx, 10); len(histories) != 2 || histories[0].Query.QueryText != "secret token" {
+		t.Fatalf("expected the new pattern to apply, got %+v", histories)
+	}
+}
+
+func TestPluginQueryHistoryIsKeptApartFromTheGlobalHistory(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+
+	m.AddQueryHistory(ctx, common.PlainQuery{QueryType: "input", QueryText: "1+1"})
+	for i := 0; i <= MaxPluginQueryHistoryCount; i++ {
+		m.AddPluginQueryHistory(ctx, "calculator", common.PlainQuery{QueryType: "input", QueryText: fmt.Sprintf("%d*2", i)})
+	}
+	m.AddPluginQueryHistory(ctx, "calculator", common.PlainQuery{QueryType: "input", QueryText: "1+1"})
+	m.AddPluginQueryHistory(ctx, "notes", common.PlainQuery{QueryType: "input", QueryText: "todo"})
+
+	histories := m.GetPluginQueryHistory(ctx, "calculator", MaxPluginQueryHistoryCount+10)
+	if len(histories) != MaxPluginQueryHistoryCount || histories[0].Query.QueryText != "1+1" {
+		t.Fatalf("expected the newest %d calculator queries, got %d starting with %+v", MaxPluginQueryHistoryCount, len(histories), histories[0])
+	}
+	if global := m.GetLatestQueryHistory(ctx, 10); len(global) != 1 || global[0].Query.QueryText != "1+1" {
+		t.Fatalf("expected plugin queries to stay out of the global history, got %+v", global)
+	}
+	var globalRows int64
+	db.Model(&database.QueryHistoryRecord{}).Where("plugin_id = ?", "").Count(&globalRows)
+	if globalRows != 1 {
+		t.Fatalf("expected the global 1+1 to be kept next to the calculator one, got %d global rows", globalRows)
+	}
+	var oplogs int64
+	db.Model(&database.Oplog{}).Count(&oplogs)
+	if oplogs != 0 {
+		t.Fatalf("expected plugin query history to stay local, got %d oplogs", oplogs)
+	}
+
+	m.DeletePluginQueryHistory(ctx, "calculator")
+	if histories := m.GetPluginQueryHistory(ctx, "calculator", 10); len(histories) != 0 {
+		t.Fatalf("expected the calculator history to be deleted, got %+v", histories)
+	}
+	if histories := m.GetPluginQueryHistory(ctx, "notes", 10); len(histories) != 1 {
+		t.Fatalf("expected other plugin histories to be kept, got %+v", histories)
+	}
+}
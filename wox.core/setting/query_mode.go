@@ -0,0 +1,77 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// QueryModeConfig overrides window defaults for one query mode, which is the
+// show source that opened the launcher (common.ShowSource). Zero fields fall
+// back to the top-level MaxResultCount and ShowPosition.
+type QueryModeConfig struct {
+	MaxResultCount int          `json:",omitempty"`
+	ShowPosition   PositionType `json:",omitempty"`
+}
+
+func (c QueryModeConfig) isEmpty() bool {
+	return c.MaxResultCount == 0 && c.ShowPosition == ""
+}
+
+// ValidateQueryModeConfig reports why an override cannot be applied.
+func ValidateQueryModeConfig(mode string, config QueryModeConfig) error {
+	if strings.TrimSpace(mode) == "" {
+		return fmt.Errorf("query mode is empty")
+	}
+	if config.MaxResultCount != 0 && !IsValidMaxResultCount(config.MaxResultCount) {
+		return fmt.Errorf("max result count of query mode %s must be between %d and %d", mode, MinMaxResultCount, MaxMaxResultCount)
+	}
+	if config.ShowPosition != "" && !IsValidPositionType(config.ShowPosition) {
+		return fmt.Errorf("show position of query mode %s is invalid: %s", mode, config.ShowPosition)
+	}
+	return nil
+}
+
+func IsValidQueryModeSettings(settings map[string]QueryModeConfig) bool {
+	for mode, config := range settings {
+		if ValidateQueryModeConfig(mode, config) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// GetQueryModeConfig returns the effective window defaults for mode, with the
+// top-level settings filled in where the mode has no override.
+func (m *Manager) GetQueryModeConfig(ctx context.Context, mode string) QueryModeConfig {
+	woxSetting := m.currentWoxSetting()
+	config := woxSetting.QueryModeSettings.Get()[mode]
+	if config.MaxResultCount == 0 {
+		config.MaxResultCount = woxSetting.MaxResultCount.Get()
+	}
+	if config.ShowPosition == "" {
+		config.ShowPosition = woxSetting.ShowPosition.Get()
+	}
+	return config
+}
+
+// SetQueryModeConfig stores the overrides of mode. An empty config removes
+// them, so the mode follows the top-level settings again.
+func (m *Manager) SetQueryModeConfig(ctx context.Context, mode string, config QueryModeConfig) error {
+	if err := ValidateQueryModeConfig(mode, config); err != nil {
+		return err
+	}
+
+	// Copy before editing so the cached setting value is only replaced by Set.
+	settings := maps.Clone(m.currentWoxSetting().QueryModeSettings.Get())
+	if settings == nil {
+		settings = map[string]QueryModeConfig{}
+	}
+	if config.isEmpty() {
+		delete(settings, mode)
+	} else {
+		settings[mode] = config
+	}
+	return m.currentWoxSetting().QueryModeSettings.Set(settings)
+}
@@ -0,0 +1,184 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wissance/stringFormatter"
+)
+
+// dollarPlaceholderRegex matches shell style placeholders: $1, $2... for
+// positional arguments and $* for everything typed after the shortcut.
+var dollarPlaceholderRegex = regexp.MustCompile(`\$(\*|[1-9][0-9]*)`)
+
+// ExpandShortcut expands the first enabled query shortcut matching input.
+// It returns the input unchanged and false when no shortcut applies.
+func (m *Manager) ExpandShortcut(input string) (string, bool) {
+	return ExpandQueryShortcut(input, m.currentWoxSetting().QueryShortcuts.Get())
+}
+
+// ImportMode controls how imported entries are combined with existing ones.
+type ImportMode string
+
+const (
+	// ImportModeMerge keeps existing entries and lets imported entries replace
+	// the ones with the same identity.
+	ImportModeMerge ImportMode = "merge"
+	// ImportModeReplace drops existing entries before importing.
+	ImportModeReplace ImportMode = "replace"
+)
+
+// ImportQueryShortcuts reads a JSON array of QueryShortcut from r and saves it.
+// In merge mode an imported shortcut replaces an existing one with the same
+// Shortcut text. Entries without a shortcut or query are skipped, and the
+// returned warnings describe every skipped entry.
+func (m *Manager) ImportQueryShortcuts(ctx context.Context, r io.Reader, mode ImportMode) ([]string, error) {
+	if mode != ImportModeMerge && mode != ImportModeReplace {
+		return nil, fmt.Errorf("invalid import mode: %s", mode)
+	}
+
+	var imported []QueryShortcut
+	if err := json.NewDecoder(r).Decode(&imported); err != nil {
+		return nil, fmt.Errorf("failed to decode query shortcuts: %w", err)
+	}
+
+	var shortcuts []QueryShortcut
+	if mode == ImportModeMerge {
+		shortcuts = slices.Clone(m.currentWoxSetting().QueryShortcuts.Get())
+	}
+
+	var warnings []string
+	for i, shortcut := range imported {
+		shortcut.Shortcut = strings.TrimSpace(shortcut.Shortcut)
+		if shortcut.Shortcut == "" {
+			warnings = append(warnings, fmt.Sprintf("entry %d skipped: shortcut is empty", i+1))
+			continue
+		}
+		if strings.TrimSpace(shortcut.Query) == "" {
+			warnings = append(warnings, fmt.Sprintf("entry %d (%s) skipped: query is empty", i+1, shortcut.Shortcut))
+			continue
+		}
+
+		existingIndex := slices.IndexFunc(shortcuts, func(existing QueryShortcut) bool {
+			return existing.Shortcut == shortcut.Shortcut
+		})
+		if existingIndex >= 0 {
+			shortcuts[existingIndex] = shortcut
+		} else {
+			shortcuts = append(shortcuts, shortcut)
+		}
+	}
+
+	if shortcuts == nil {
+		shortcuts = []QueryShortcut{}
+	}
+	if err := m.currentWoxSetting().QueryShortcuts.Set(shortcuts); err != nil {
+		return warnings, fmt.Errorf("failed to save query shortcuts: %w", err)
+	}
+	logger.Info(ctx, fmt.Sprintf("imported query shortcuts: mode=%s, total=%d, skipped=%d", mode, len(shortcuts), len(warnings)))
+	return warnings, nil
+}
+
+// ExportQueryShortcuts writes the query shortcuts as the JSON array read by
+// ImportQueryShortcuts.
+func (m *Manager) ExportQueryShortcuts(ctx context.Context, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(m.currentWoxSetting().QueryShortcuts.Get()); err != nil {
+		return fmt.Errorf("failed to encode query shortcuts: %w", err)
+	}
+	return nil
+}
+
+// ExpandQueryShortcut splits input into a shortcut and its arguments and
+// substitutes the arguments into the shortcut query. Three forms are supported:
+//
+//	"wi"  => "wpm install {0} to {1}" (index placeholders, zero based)
+//	"g"   => "google $1"              (positional placeholders, one based)
+//	"gh"  => "github search $*"       (all remaining text)
+//
+// Arguments not consumed by a placeholder are appended to the expanded query,
+// and a shortcut without placeholders simply replaces its own text.
+func ExpandQueryShortcut(input string, shortcuts []QueryShortcut) (string, bool) {
+	// Expand the longest shortcut first so "gh" wins over "g" for "gh wox".
+	sorted := slices.Clone(shortcuts)
+	slices.SortStableFunc(sorted, func(i, j QueryShortcut) int {
+		return len(j.Shortcut) - len(i.Shortcut)
+	})
+
+	for _, shortcut := range sorted {
+		if shortcut.Disabled || shortcut.Shortcut == "" {
+			continue
+		}
+
+		// Query shortcuts are command-style aliases for the first query token. Plain
+		// prefix matching made short aliases such as "th" rewrite normal queries like
+		// "theme xx", so the shortcut must end at the query boundary while still
+		// supporting "th args".
+		if input != shortcut.Shortcut && !strings.HasPrefix(input, shortcut.Shortcut+" ") {
+			continue
+		}
+
+		argText := strings.TrimLeft(strings.TrimPrefix(input, shortcut.Shortcut), " ")
+		switch {
+		case shortcut.HasPlaceholder():
+			return expandIndexPlaceholders(shortcut, argText), true
+		case dollarPlaceholderRegex.MatchString(shortcut.Query):
+			return expandDollarPlaceholders(shortcut, argText), true
+		default:
+			return shortcut.Query + strings.TrimPrefix(input, shortcut.Shortcut), true
+		}
+	}
+
+	return input, false
+}
+
+func expandIndexPlaceholders(shortcut QueryShortcut, argText string) string {
+	placeholderCount := shortcut.PlaceholderCount()
+
+	var params []any
+	var rest string
+	for _, param := range strings.Split(argText, " ") {
+		if len(params) < placeholderCount {
+			params = append(params, param)
+		} else {
+			rest += " " + param
+		}
+	}
+	return stringFormatter.Format(shortcut.Query, params...) + rest
+}
+
+func expandDollarPlaceholders(shortcut QueryShortcut, argText string) string {
+	args := strings.Fields(argText)
+	usesAll := false
+	maxIndex := 0
+
+	expanded := dollarPlaceholderRegex.ReplaceAllStringFunc(shortcut.Query, func(placeholder string) string {
+		name := placeholder[1:]
+		if name == "*" {
+			usesAll = true
+			return argText
+		}
+
+		index, err := strconv.Atoi(name)
+		if err != nil {
+			return placeholder
+		}
+		maxIndex = max(maxIndex, index)
+		if index > len(args) {
+			return ""
+		}
+		return args[index-1]
+	})
+
+	if !usesAll && maxIndex < len(args) {
+		expanded += " " + strings.Join(args[maxIndex:], " ")
+	}
+	return expanded
+}
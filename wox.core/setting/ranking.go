@@ -0,0 +1,103 @@
+package setting
+
+import (
+	"context"
+	"math"
+	"slices"
+	"wox/util"
+)
+
+// RankingMode decides how the action history of a result adds to the score
+// its plugin gave it.
+type RankingMode string
+
+const (
+	// RankingModeRelevance ranks by the plugin score only, e.g. fuzzy match.
+	RankingModeRelevance RankingMode = "relevance"
+	// RankingModeFrequency favors results that were actioned often, no matter when.
+	RankingModeFrequency RankingMode = "frequency"
+	// RankingModeRecency favors the results actioned last.
+	RankingModeRecency RankingMode = "recency"
+	// RankingModeHybrid weighs both how often and how recently a result was
+	// actioned. It is the default and matches the ranking of older versions.
+	RankingModeHybrid RankingMode = "hybrid"
+)
+
+func ValidRankingModes() []RankingMode {
+	return []RankingMode{RankingModeRelevance, RankingModeFrequency, RankingModeRecency, RankingModeHybrid}
+}
+
+func IsValidRankingMode(value RankingMode) bool {
+	return slices.Contains(ValidRankingModes(), value)
+}
+
+const (
+	// rankingQueryMatchWeight is added for every action recorded with the
+	// current query, which is a strong hint the user is after that result.
+	rankingQueryMatchWeight = 20
+	// rankingFrequencyWeight is what each action adds in frequency mode.
+	rankingFrequencyWeight = 10
+	// rankingHybridActionWeight is the base each action adds in hybrid mode,
+	// on top of its hybridRecencyWeights bonus.
+	rankingHybridActionWeight = 2
+	// rankingRecencyMaxWeight is what the last action adds in recency mode
+	// when it just happened; it halves every rankingRecencyHalfLife.
+	rankingRecencyMaxWeight = 100
+	rankingRecencyHalfLife  = 24 * 60 * 60 * 1000
+)
+
+// hybridRecencyWeights holds the bonus of an action made on day 7, 6, ... 1
+// before now. Older actions only count with rankingHybridActionWeight.
+var hybridRecencyWeights = []int64{5, 8, 13, 21, 34, 55, 89}
+
+// GetActionedResultRankingScore returns the score the action history of
+// resultHash adds in mode. query is the current raw query; actions recorded
+// with the same query weigh more in every mode but relevance. Results never
+// actioned score 0.
+func (m *Manager) GetActionedResultRankingScore(ctx context.Context, resultHash ResultHash, query string, mode RankingMode) int64 {
+	if mode == RankingModeRelevance {
+		return 0
+	}
+
+	m.appDataMu.RLock()
+	actionedResults, ok := m.currentWoxSetting().ActionedResults.Get().Load(resultHash)
+	m.appDataMu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return scoreActionedResults(actionedResults, query, mode, util.GetSystemTimestamp())
+}
+
+// scoreActionedResults sums the weights of every action of one result as of
+// now. Each action adds its mode weight plus rankingQueryMatchWeight when it
+// was made with query; recency mode scores only the newest action, decayed by
+// its age.
+func scoreActionedResults(actionedResults []ActionedResult, query string, mode RankingMode, now int64) int64 {
+	var score int64
+	var lastActioned int64
+	for _, actionedResult := range actionedResults {
+		if query != "" && actionedResult.Query == query {
+			score += rankingQueryMatchWeight
+		}
+		lastActioned = max(lastActioned, actionedResult.Timestamp)
+
+		switch mode {
+		case RankingModeFrequency:
+			score += rankingFrequencyWeight
+		case RankingModeHybrid:
+			score += rankingHybridActionWeight
+			hours := (now - actionedResult.Timestamp) / 1000 / 60 / 60
+			if hours < 24*7 {
+				day := min(max(int(math.Ceil(float64(hours)/24)), 1), 7)
+				score += hybridRecencyWeights[7-day]
+			}
+		}
+	}
+
+	if mode == RankingModeRecency && lastActioned > 0 {
+		// Clock adjustments can leave timestamps in the future; count them as fresh.
+		age := max(now-lastActioned, 0)
+		score += int64(math.Round(rankingRecencyMaxWeight * math.Exp2(-float64(age)/rankingRecencyHalfLife)))
+	}
+	return score
+}
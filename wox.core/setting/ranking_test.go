@@ -0,0 +1,41 @@
+package setting
+
+import "testing"
+
+func TestScoreActionedResults(t *testing.T) {
+	const hour = int64(60 * 60 * 1000)
+	now := 10 * 24 * hour
+	actionedResults := []ActionedResult{
+		{Timestamp: now - hour, Query: "calc"},
+		{Timestamp: now - 72*hour, Query: "other"},
+		{Timestamp: now - 10*24*hour, Query: "calc"},
+	}
+
+	tests := []struct {
+		mode     RankingMode
+		expected int64
+	}{
+		// 2 query matches and 3 actions of 10 each.
+		{RankingModeFrequency, 2*rankingQueryMatchWeight + 3*rankingFrequencyWeight},
+		// 2 query matches, 3 actions of 2 each, and the day 1 and day 3 bonus.
+		{RankingModeHybrid, 2*rankingQueryMatchWeight + 3*rankingHybridActionWeight + 89 + 34},
+		// 2 query matches and the newest action, an hour old, decayed to 97.
+		{RankingModeRecency, 2*rankingQueryMatchWeight + 97},
+	}
+	for _, test := range tests {
+		if score := scoreActionedResults(actionedResults, "calc", test.mode, now); score != test.expected {
+			t.Fatalf("expected %s score %d, got %d", test.mode, test.expected, score)
+		}
+	}
+
+	if score := scoreActionedResults(actionedResults, "", RankingModeFrequency, now); score != 3*rankingFrequencyWeight {
+		t.Fatalf("expected an empty query to match no action, got %d", score)
+	}
+	future := []ActionedResult{{Timestamp: now + hour}}
+	if score := scoreActionedResults(future, "", RankingModeRecency, now); score != rankingRecencyMaxWeight {
+		t.Fatalf("expected an action in the future to count as fresh, got %d", score)
+	}
+	if score := scoreActionedResults(nil, "calc", RankingModeHybrid, now); score != 0 {
+		t.Fatalf("expected no actions to score 0, got %d", score)
+	}
+}
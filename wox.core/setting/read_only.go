@@ -0,0 +1,244 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+var errWriteProbeRollback = errors.New("write probe rollback")
+
+// ErrSettingsReadOnly is returned by operations that have to write the data
+// directory, e.g. backups and profile switches, while it is read-only.
+var ErrSettingsReadOnly = errors.New("settings are read-only")
+
+// readOnlyOverlayStore serves settings when the data directory cannot be
+// written, e.g. on a read-only mount or a locked-down machine. Reads fall
+// through to the underlying store, so the saved settings still apply, while
+// writes and deletes only live in memory for the rest of the session.
+type readOnlyOverlayStore struct {
+	base    SettingStore
+	overlay *MemorySettingStore
+
+	// deleted holds keys deleted this session, which must not fall through
+	// to the value still saved in the underlying store.
+	deleted   map[string]bool
+	deletedMu sync.RWMutex
+}
+
+func newReadOnlyOverlayStore(base SettingStore) *readOnlyOverlayStore {
+	return &readOnlyOverlayStore{
+		base:    base,
+		overlay: NewMemorySettingStore(),
+		deleted: map[string]bool{},
+	}
+}
+
+func (s *readOnlyOverlayStore) Get(key string, target interface{}) error {
+	s.deletedMu.RLock()
+	deleted := s.deleted[key]
+	s.deletedMu.RUnlock()
+	if deleted {
+		return gorm.ErrRecordNotFound
+	}
+
+	err := s.overlay.Get(key, target)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.base.Get(key, target)
+	}
+	return err
+}
+
+func (s *readOnlyOverlayStore) Set(key string, value interface{}) error {
+	if err := s.overlay.Set(key, value); err != nil {
+		return err
+	}
+
+	s.deletedMu.Lock()
+	defer s.deletedMu.Unlock()
+	delete(s.deleted, key)
+	return nil
+}
+
+func (s *readOnlyOverlayStore) Delete(key string) error {
+	s.deletedMu.Lock()
+	defer s.deletedMu.Unlock()
+	s.deleted[key] = true
+	return s.overlay.Delete(key)
+}
+
+// List returns the stored rows with this session's changes applied.
+func (s *readOnlyOverlayStore) List() (map[string]string, error) {
+	values := map[string]string{}
+	if lister, ok := s.base.(ListableStore); ok {
+		baseValues, err := lister.List()
+		if err != nil {
+			return nil, err
+		}
+		values = baseValues
+	}
+
+	s.deletedMu.RLock()
+	for key := range s.deleted {
+		delete(values, key)
+	}
+	s.deletedMu.RUnlock()
+
+	overlayValues, err := s.overlay.List()
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range overlayValues {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// IsReadOnly reports whether settings fell back to memory because the data
+// directory is not writable. Changes made in this mode are lost on exit.
+func (m *Manager) IsReadOnly() bool {
+	return m.readOnly.Load()
+}
+
+// ProbeDataDirectoryWritable checks with a throwaway file that the user data
+// directory, which holds wox.db, can be written. main calls it before opening
+// the database, so a read-only directory opens wox.db read-only instead of
+// failing, see database.InitReadOnly.
+func ProbeDataDirectoryWritable() error {
+	return probeDirectoryWritable(util.GetLocation().GetUserDataDirectory())
+}
+
+// checkWritable probes the user data directory and the settings database with
+// a throwaway write. When either fails, Wox settings, app data and plugin
+// settings switch to in-memory overlays so the session keeps working instead
+// of failing on every save. Query history and MRU writes are skipped, and
+// operations that write files, such as backups, return ErrSettingsReadOnly.
+func (m *Manager) checkWritable(ctx context.Context) {
+	probeErr := ProbeDataDirectoryWritable()
+	if probeErr == nil && m.db != nil {
+		probeErr = probeDatabaseWritable(m.db)
+	}
+	if probeErr == nil {
+		return
+	}
+	m.enterReadOnlyMode(ctx, probeErr)
+}
+
+// enterReadOnlyMode routes every setting writer to memory for the rest of the
+// session, see checkWritable.
+func (m *Manager) enterReadOnlyMode(ctx context.Context, reason error) {
+	logger.Warn(ctx, fmt.Sprintf("data directory is not writable, settings will not be saved this session: %s", reason.Error()))
+	m.readOnly.Store(true)
+	m.woxStore = newReadOnlyOverlayStore(m.woxStore)
+	readOnlyPluginSettings.enable()
+	m.reloadWoxSetting()
+}
+
+func probeDirectoryWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, ".wox-write-probe-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file in %s: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// probeDatabaseWritable inserts a row in a transaction that is always rolled
+// back. SQLite has to open its journal for the insert, which fails for a
+// read-only database file or directory.
+func probeDatabaseWritable(db *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&database.WoxSetting{Key: "__write_probe__"}).Error; err != nil {
+			return err
+		}
+		return errWriteProbeRollback
+	})
+	if errors.Is(err, errWriteProbeRollback) {
+		return nil
+	}
+	return fmt.Errorf("failed to write settings database: %w", err)
+}
+
+// readOnlyPluginSettings keeps plugin setting changes in memory while the
+// data directory is read-only. PluginSettingStore consults it before the
+// database, so plugins read back what they wrote this session. A nil value
+// marks a key deleted this session.
+var readOnlyPluginSettings = &readOnlyPluginSettingOverlay{}
+
+type readOnlyPluginSettingOverlay struct {
+	mu      sync.RWMutex
+	enabled bool
+	values  map[string]map[string]*string
+}
+
+func (o *readOnlyPluginSettingOverlay) enable() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.enabled = true
+	o.values = map[string]map[string]*string{}
+}
+
+func (o *readOnlyPluginSettingOverlay) isEnabled() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.enabled
+}
+
+// lookup returns the value written this session. overridden is false when
+// the key was not changed this session and must be read from the database.
+func (o *readOnlyPluginSettingOverlay) lookup(pluginId string, key string) (value string, found bool, overridden bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	stored, ok := o.values[pluginId][key]
+	if !ok {
+		return "", false, false
+	}
+	if stored == nil {
+		return "", false, true
+	}
+	return *stored, true, true
+}
+
+// set records value, or a deletion for nil, and reports whether the overlay
+// took the write. It does not while the data directory is writable.
+func (o *readOnlyPluginSettingOverlay) set(pluginId string, key string, value *string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.enabled {
+		return false
+	}
+	if o.values[pluginId] == nil {
+		o.values[pluginId] = map[string]*string{}
+	}
+	o.values[pluginId][key] = value
+	return true
+}
+
+// applyToKeys adds the keys set this session to keys and drops the deleted ones.
+func (o *readOnlyPluginSettingOverlay) applyToKeys(pluginId string, keys []string) []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	changed := o.values[pluginId]
+	if len(changed) == 0 {
+		return keys
+	}
+
+	result := make([]string, 0, len(keys)+len(changed))
+	for _, key := range keys {
+		if _, ok := changed[key]; !ok {
+			result = append(result, key)
+		}
+	}
+	for key, value := range changed {
+		if value != nil {
+			result = append(result, key)
+		}
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"wox/common"
+	"wox/database"
+)
+
+// newReadOnlyTestManager returns a manager that switched to read-only mode
+// like checkWritable does for a data directory that cannot be written.
+func newReadOnlyTestManager(t *testing.T) (*Manager, *PluginSettingStore) {
+	t.Helper()
+	db := newTestDB(t)
+	pluginStore := NewPluginSettingStore(db, "calculator")
+	if err := pluginStore.Set("Precision", "4"); err != nil {
+		t.Fatalf("failed to save plugin setting: %v", err)
+	}
+
+	m := NewManager(NewWoxSettingStore(db), db)
+	t.Cleanup(func() { readOnlyPluginSettings = &readOnlyPluginSettingOverlay{} })
+	m.enterReadOnlyMode(context.Background(), errors.New("test"))
+	return m, pluginStore
+}
+
+func TestReadOnlyModeKeepsWritesInMemory(t *testing.T) {
+	ctx := context.Background()
+	m, pluginStore := newReadOnlyTestManager(t)
+	db := m.db
+
+	if err := m.GetWoxSetting(ctx).ShowTray.Set(false); err != nil {
+		t.Fatalf("failed to change ShowTray: %v", err)
+	}
+	if m.GetWoxSetting(ctx).ShowTray.Get() || storedRow(t, db, "ShowTray") != "" {
+		t.Fatalf("expected ShowTray to change in memory only")
+	}
+
+	if err := pluginStore.SetWithSync("Precision", "8", true); err != nil {
+		t.Fatalf("failed to change plugin setting: %v", err)
+	}
+	var precision string
+	if err := pluginStore.Get("Precision", &precision); err != nil || precision != "8" {
+		t.Fatalf("expected the plugin to read back its change, got %q (%v)", precision, err)
+	}
+	var row database.PluginSetting
+	if err := db.Where("plugin_id = ? AND key = ?", "calculator", "Precision").First(&row).Error; err != nil || row.Value != "4" {
+		t.Fatalf("expected the stored plugin setting to be left alone, got %q (%v)", row.Value, err)
+	}
+	var oplogs int64
+	if err := db.Model(&database.Oplog{}).Count(&oplogs).Error; err != nil || oplogs != 0 {
+		t.Fatalf("expected no oplogs in read-only mode, got %d (%v)", oplogs, err)
+	}
+
+	if err := pluginStore.Delete("Precision"); err != nil {
+		t.Fatalf("failed to delete plugin setting: %v", err)
+	}
+	if err := pluginStore.Get("Precision", &precision); err == nil {
+		t.Fatalf("expected the deleted plugin setting to be gone this session")
+	}
+	if keys, err := pluginStore.Keys(); err != nil || len(keys) != 0 {
+		t.Fatalf("expected no keys after the deletion, got %v (%v)", keys, err)
+	}
+}
+
+func TestReadOnlyModeSkipsHistoryAndRejectsFileWrites(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newReadOnlyTestManager(t)
+
+	m.AddQueryHistory(ctx, common.PlainQuery{QueryType: "input", QueryText: "hello"})
+	if err := m.AddMRUItem(ctx, MRUItem{Hash: "hash", PluginID: "calculator", Title: "hello"}); err != nil {
+		t.Fatalf("expected MRU writes to be skipped silently, got %v", err)
+	}
+	var histories, mruRecords int64
+	m.db.Model(&database.QueryHistoryRecord{}).Count(&histories)
+	m.db.Model(&database.MRURecord{}).Count(&mruRecords)
+	if histories != 0 || mruRecords != 0 {
+		t.Fatalf("expected no history rows in read-only mode, got %d query histories and %d MRU records", histories, mruRecords)
+	}
+
+	if err := m.Backup(ctx, BackupTypeManual); !errors.Is(err, ErrSettingsReadOnly) {
+		t.Fatalf("expected backups to be rejected, got %v", err)
+	}
+	if err := m.CreateProfile(ctx, "work"); !errors.Is(err, ErrSettingsReadOnly) {
+		t.Fatalf("expected profile creation to be rejected, got %v", err)
+	}
+	if err := m.ResetToDefaults(ctx, true); !errors.Is(err, ErrSettingsReadOnly) {
+		t.Fatalf("expected reset to be rejected, got %v", err)
+	}
+}
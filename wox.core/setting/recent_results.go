@@ -0,0 +1,110 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"wox/common"
+	"wox/database"
+)
+
+// RecentResult is a result the user actioned or marked as favorite, with
+// enough metadata to render it again without querying its plugin.
+type RecentResult struct {
+	Hash        ResultHash
+	PluginId    string
+	Title       string
+	SubTitle    string
+	Icon        common.WoxImage
+	ContextData common.ContextData
+	LastUsed    int64
+	IsFavorite  bool
+	Label       string // name given with SetFavoriteLabel, shown instead of Title when set
+}
+
+// GetRecentResults merges actioned and favorite results into one list for the
+// empty query view. Favorites come first, the rest are ordered by the last time
+// they were actioned. Actioned and favorite results are stored by hash only, so
+// the title and icon come from the MRU table, or from the hashes seen since
+// startup; results without either cannot be rendered and are skipped.
+func (m *Manager) GetRecentResults(ctx context.Context, limit int) []RecentResult {
+	if limit <= 0 {
+		return []RecentResult{}
+	}
+
+	var records []database.MRURecord
+	if err := m.db.Find(&records).Error; err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to load recent results: %s", err.Error()))
+		return []RecentResult{}
+	}
+
+	resultsByHash := map[ResultHash]*RecentResult{}
+	for _, record := range records {
+		var icon common.WoxImage
+		if err := json.Unmarshal([]byte(record.Icon), &icon); err != nil {
+			icon = common.WoxImage{}
+		}
+		resultsByHash[ResultHash(record.Hash)] = &RecentResult{
+			Hash:        ResultHash(record.Hash),
+			PluginId:    record.PluginID,
+			Title:       record.Title,
+			SubTitle:    record.SubTitle,
+			Icon:        icon,
+			ContextData: common.UnmarshalContextData(record.ContextData),
+			LastUsed:    record.LastUsed,
+		}
+	}
+
+	lookup := func(hash ResultHash) *RecentResult {
+		if result, ok := resultsByHash[hash]; ok {
+			return result
+		}
+		sources := InspectResultHash(hash)
+		if len(sources) == 0 {
+			return nil
+		}
+		result := &RecentResult{Hash: hash, PluginId: sources[0].PluginId, Title: sources[0].Title, SubTitle: sources[0].SubTitle}
+		resultsByHash[hash] = result
+		return result
+	}
+
+	m.appDataMu.RLock()
+	woxSetting := m.currentWoxSetting()
+	woxSetting.ActionedResults.Get().Range(func(hash ResultHash, actions []ActionedResult) bool {
+		if result := lookup(hash); result != nil {
+			for _, action := range actions {
+				result.LastUsed = max(result.LastUsed, action.Timestamp)
+			}
+		}
+		return true
+	})
+	favoriteLabels := woxSetting.FavoriteLabels.Get()
+	woxSetting.PinedResults.Get().Range(func(hash ResultHash, pinned bool) bool {
+		if result := lookup(hash); result != nil && pinned {
+			result.IsFavorite = true
+			result.Label, _ = favoriteLabels.Load(hash)
+		}
+		return true
+	})
+	m.appDataMu.RUnlock()
+
+	results := make([]RecentResult, 0, len(resultsByHash))
+	for _, result := range resultsByHash {
+		results = append(results, *result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].IsFavorite != results[j].IsFavorite {
+			return results[i].IsFavorite
+		}
+		if results[i].LastUsed != results[j].LastUsed {
+			return results[i].LastUsed > results[j].LastUsed
+		}
+		return results[i].Hash < results[j].Hash
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,267 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"wox/database"
+	"wox/util"
+
+	"gorm.io/gorm"
+)
+
+// ListableStore is implemented by setting stores that can return all their
+// rows. Bulk operations such as a bundle import, a backup restore or a profile
+// switch need it to find the rows to remove, see replaceWoxSettings.
+type ListableStore interface {
+	// List returns every stored value keyed by setting key, in the form Get
+	// returns it for a *string target.
+	List() (map[string]string, error)
+}
+
+// TransactionalStore is implemented by setting stores that can apply several
+// writes atomically. fn receives a store bound to the transaction.
+type TransactionalStore interface {
+	Transaction(fn func(store SettingStore) error) error
+}
+
+// replaceWoxSettings makes the stored Wox settings equal values. Keys for
+// which keep returns true are left alone, and unchanged rows are not
+// rewritten. Every change goes through the setting store, in one transaction
+// when the store supports it, so secrets are sealed, LastModified advances and
+// syncable settings get oplogs like single writes do.
+func (m *Manager) replaceWoxSettings(ctx context.Context, values map[string]string, keep func(key string) bool) error {
+	current, err := m.listWoxSettings()
+	if err != nil {
+		return fmt.Errorf("failed to list current settings: %w", err)
+	}
+	skip := func(key string) bool {
+		return key == LastModifiedKey || (keep != nil && keep(key))
+	}
+
+	isSyncable := m.settingKeySyncability()
+	apply := func(store SettingStore) error {
+		for key := range current {
+			if _, replaced := values[key]; replaced || skip(key) {
+				continue
+			}
+			if err := deleteStoredSetting(store, key, isSyncable(key)); err != nil {
+				return fmt.Errorf("failed to delete setting %s: %w", key, err)
+			}
+		}
+		for key, value := range values {
+			if skip(key) {
+				continue
+			}
+			if currentValue, exists := current[key]; exists && currentValue == value {
+				continue
+			}
+			if err := setStoredSetting(store, key, value, isSyncable(key)); err != nil {
+				return fmt.Errorf("failed to save setting %s: %w", key, err)
+			}
+		}
+		return nil
+	}
+
+	if transactional, ok := m.woxStore.(TransactionalStore); ok {
+		err = transactional.Transaction(apply)
+	} else {
+		err = apply(m.woxStore)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.reloadWoxSetting()
+	logger.Info(ctx, fmt.Sprintf("replaced stored settings: %d values, %d stored before", len(values), len(current)))
+	return nil
+}
+
+// openStoredWoxSettings converts rows read from another database, e.g. a
+// backup or a profile, into the values replaceWoxSettings takes. Secrets are
+// opened, so the store seals them again with the local key.
+func openStoredWoxSettings(rows []database.WoxSetting) (map[string]string, error) {
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		value, err := OpenStoredSettingValue(row.Key, row.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open setting %s: %w", row.Key, err)
+		}
+		values[row.Key] = value
+	}
+	return values, nil
+}
+
+// listWoxSettings returns every stored Wox setting row, see ListableStore.
+func (m *Manager) listWoxSettings() (map[string]string, error) {
+	lister, ok := m.woxStore.(ListableStore)
+	if !ok {
+		return nil, fmt.Errorf("setting store %T cannot list its settings", m.woxStore)
+	}
+	return lister.List()
+}
+
+func setStoredSetting(store SettingStore, key string, value string, syncable bool) error {
+	if syncStore, ok := store.(SyncableStore); ok {
+		return syncStore.SetWithSync(key, value, syncable)
+	}
+	return store.Set(key, value)
+}
+
+func deleteStoredSetting(store SettingStore, key string, syncable bool) error {
+	if syncStore, ok := store.(SyncableStore); ok {
+		return syncStore.DeleteWithSync(key, syncable)
+	}
+	return store.Delete(key)
+}
+
+// settingKeySyncability returns whether changes of a stored key are synced.
+// Keys of other platforms follow the setting of the current platform, and
+// rows without a setting, e.g. left by older versions, are synced like the
+// cloud sync snapshot does.
+func (m *Manager) settingKeySyncability() func(key string) bool {
+	syncable := map[string]bool{}
+	settingValue := reflect.ValueOf(m.currentWoxSetting()).Elem()
+	for i := 0; i < settingValue.NumField(); i++ {
+		field := settingValue.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		if value, ok := field.Interface().(interface {
+			Key() string
+			IsSyncable() bool
+		}); ok {
+			syncable[value.Key()] = value.IsSyncable()
+		}
+	}
+
+	return func(key string) bool {
+		if baseKey, _, isPlatformKey := SplitPlatformSettingKey(key); isPlatformKey {
+			key = PlatformSettingKey(baseKey, util.GetCurrentPlatform())
+		}
+		if value, ok := syncable[key]; ok {
+			return value
+		}
+		return true
+	}
+}
+
+// notifyChangedSettings records and announces every Wox setting whose
+// serialized value differs from oldValues, which is keyed by field name as
+// returned by serializedWoxSettingValues. Handlers registered with
+// OnSettingChanged re-apply derived state such as the language, proxy and
+// hotkeys, so bulk changes take effect without a restart.
+func (m *Manager) notifyChangedSettings(ctx context.Context, oldValues map[string]string, source string) {
+	for name, oldValue := range oldValues {
+		newValue, ok := m.SerializedWoxSettingValue(name)
+		if !ok || newValue == oldValue {
+			continue
+		}
+		m.RecordSettingAudit(ctx, name, oldValue, newValue, source)
+		m.notifySettingChanged(ctx, name, newValue)
+	}
+}
+
+// pluginSettingChange is one plugin setting changed by a bulk operation.
+type pluginSettingChange struct {
+	pluginId string
+	key      string
+	oldValue string
+	newValue string
+}
+
+// replacePluginSettings makes the stored plugin settings equal values, keyed
+// by plugin id and setting key. Writes go through PluginSettingStore in one
+// transaction and get oplogs like regular plugin setting changes. Handlers
+// registered with OnPluginSettingChanged are told about every changed key.
+func (m *Manager) replacePluginSettings(ctx context.Context, values map[string]map[string]string) error {
+	current, err := m.listPluginSettings()
+	if err != nil {
+		return fmt.Errorf("failed to list current plugin settings: %w", err)
+	}
+
+	var changes []pluginSettingChange
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		changes = nil
+		pluginIds := map[string]bool{}
+		for pluginId := range current {
+			pluginIds[pluginId] = true
+		}
+		for pluginId := range values {
+			pluginIds[pluginId] = true
+		}
+
+		for pluginId := range pluginIds {
+			store := NewPluginSettingStore(tx, pluginId)
+			for key, oldValue := range current[pluginId] {
+				if _, replaced := values[pluginId][key]; replaced {
+					continue
+				}
+				if err := store.DeleteWithSync(key, true); err != nil {
+					return fmt.Errorf("failed to delete setting %s of plugin %s: %w", key, pluginId, err)
+				}
+				changes = append(changes, pluginSettingChange{pluginId: pluginId, key: key, oldValue: oldValue})
+			}
+			for key, newValue := range values[pluginId] {
+				oldValue, exists := current[pluginId][key]
+				if exists && oldValue == newValue {
+					continue
+				}
+				if err := store.SetWithSync(key, newValue, true); err != nil {
+					return fmt.Errorf("failed to save setting %s of plugin %s: %w", key, pluginId, err)
+				}
+				changes = append(changes, pluginSettingChange{pluginId: pluginId, key: key, oldValue: oldValue, newValue: newValue})
+			}
+		}
+		return nil
+	})
+	// Stores bound to the transaction cache under its handle, so the shared
+	// caches are dropped even when the transaction rolled back.
+	invalidateAllPluginSettingCaches()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		m.notifyPluginSettingChanged(ctx, change)
+	}
+	logger.Info(ctx, fmt.Sprintf("replaced stored plugin settings: %d changed keys", len(changes)))
+	return nil
+}
+
+// listPluginSettings returns the stored plugin settings keyed by plugin id and key.
+func (m *Manager) listPluginSettings() (map[string]map[string]string, error) {
+	var rows []database.PluginSetting
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	values := map[string]map[string]string{}
+	for _, row := range rows {
+		if values[row.PluginID] == nil {
+			values[row.PluginID] = map[string]string{}
+		}
+		values[row.PluginID][row.Key] = row.Value
+	}
+	return values, nil
+}
+
+// OnPluginSettingChanged registers a handler that is called for every plugin
+// setting replaced in bulk, e.g. by a bundle import, a backup restore or a
+// profile switch. newValue is "" for a removed setting.
+func (m *Manager) OnPluginSettingChanged(handler func(ctx context.Context, pluginId string, key string, oldValue string, newValue string)) {
+	m.settingChangeHandlersMu.Lock()
+	defer m.settingChangeHandlersMu.Unlock()
+
+	m.pluginSettingChangeHandlers = append(m.pluginSettingChangeHandlers, handler)
+}
+
+func (m *Manager) notifyPluginSettingChanged(ctx context.Context, change pluginSettingChange) {
+	m.settingChangeHandlersMu.Lock()
+	handlers := append([]func(ctx context.Context, pluginId string, key string, oldValue string, newValue string){}, m.pluginSettingChangeHandlers...)
+	m.settingChangeHandlersMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(ctx, change.pluginId, change.key, change.oldValue, change.newValue)
+	}
+}
@@ -0,0 +1,123 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"wox/database"
+
+	"gorm.io/gorm"
+)
+
+// platformSettingResetter is implemented by PlatformValue so settings can be
+// reset by name without knowing their value type.
+type platformSettingResetter interface {
+	resetToDefault() (string, error)
+}
+
+// resetToDefault stores the current platform's default value. Only the
+// Key@platform row is written, so values saved on other platforms are kept.
+func (v *PlatformValue[T]) resetToDefault() (string, error) {
+	if err := v.Set(v.defaultValue); err != nil {
+		return "", err
+	}
+	return SerializeValue(v.defaultValue)
+}
+
+// ResetToDefault restores a platform-scoped setting such as EnableAutostart or
+// HttpProxyUrl to its default on the current platform and returns the new
+// serialized value, so callers can apply it like a regular setting update.
+func (m *Manager) ResetToDefault(ctx context.Context, key string) (string, error) {
+	field := reflect.ValueOf(m.currentWoxSetting()).Elem().FieldByName(key)
+	if !field.IsValid() || field.IsNil() {
+		return "", fmt.Errorf("unknown setting: %s", key)
+	}
+
+	resetter, ok := field.Interface().(platformSettingResetter)
+	if !ok {
+		return "", fmt.Errorf("setting %s is not platform specific", key)
+	}
+
+	value, err := resetter.resetToDefault()
+	if err != nil {
+		return "", fmt.Errorf("failed to reset setting %s: %w", key, err)
+	}
+
+	logger.Info(ctx, fmt.Sprintf("reset setting %s to default on current platform", key))
+	return value, nil
+}
+
+// ResetToDefaults deletes every stored Wox setting so all of them fall back to
+// their defaults. The current user data is backed up first. With keepHistory,
+// usage data (query history, favorites, actioned results, MRU) is kept;
+// otherwise it is cleared as well. The active profile and plugin settings are
+// not touched.
+//
+// Rows are deleted through the setting store, so syncable settings get oplogs
+// like single writes do, and handlers registered with OnSettingChanged are
+// called for every value that changed.
+func (m *Manager) ResetToDefaults(ctx context.Context, keepHistory bool) error {
+	if err := m.Backup(ctx, BackupTypeReset); err != nil {
+		return fmt.Errorf("failed to backup current settings before reset: %w", err)
+	}
+	return m.resetToDefaults(ctx, keepHistory)
+}
+
+// resetToDefaults is ResetToDefaults without the backup.
+func (m *Manager) resetToDefaults(ctx context.Context, keepHistory bool) error {
+	oldValues := m.serializedWoxSettingValues()
+	keys := map[string]bool{}
+	settingValue := reflect.ValueOf(m.currentWoxSetting()).Elem()
+	settingType := settingValue.Type()
+	for i := 0; i < settingValue.NumField(); i++ {
+		name := settingType.Field(i).Name
+		if keepHistory && appDataSettingKeys[name] {
+			continue
+		}
+		field := settingValue.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		value, ok := field.Interface().(interface{ Key() string })
+		if !ok {
+			continue
+		}
+		// PlatformValue keys carry an @platform suffix; the values of other
+		// platforms are reset too.
+		key, _, _ := strings.Cut(value.Key(), "@")
+		keys[key] = true
+	}
+	// Resetting must not switch profiles behind the user's back.
+	delete(keys, m.currentWoxSetting().ActiveProfile.Key())
+
+	if !keepHistory {
+		// Pending app data would be written back after the reset otherwise.
+		m.discardPendingAppData()
+	}
+
+	// Rows of settings that are not reset are kept.
+	err := m.replaceWoxSettings(ctx, map[string]string{}, func(key string) bool {
+		baseKey, _, _ := strings.Cut(key, "@")
+		return !keys[baseKey]
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset settings: %w", err)
+	}
+	if !keepHistory {
+		// Query history and MRU live in their own tables, see Manager.db.
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("1 = 1").Delete(&database.QueryHistoryRecord{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("1 = 1").Delete(&database.MRURecord{}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clear history: %w", err)
+		}
+	}
+
+	logger.Info(ctx, fmt.Sprintf("reset %d settings to defaults, keep history: %v", len(keys), keepHistory))
+	m.notifyChangedSettings(ctx, oldValues, SettingAuditSourceReset)
+	return nil
+}
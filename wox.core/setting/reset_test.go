@@ -0,0 +1,55 @@
+package setting
+
+import (
+	"context"
+	"testing"
+	"wox/cloudsync"
+	"wox/database"
+)
+
+func TestResetToDefaultsDeletesThroughStoreAndKeepsActiveProfile(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+	woxSetting := m.GetWoxSetting(ctx)
+	if err := woxSetting.ShowTray.Set(false); err != nil {
+		t.Fatalf("failed to save ShowTray: %v", err)
+	}
+	if err := woxSetting.ActiveProfile.Set("work"); err != nil {
+		t.Fatalf("failed to save ActiveProfile: %v", err)
+	}
+
+	changed := map[string]string{}
+	m.OnSettingChanged(func(ctx context.Context, key string, value string) {
+		changed[key] = value
+	})
+
+	if err := m.resetToDefaults(ctx, true); err != nil {
+		t.Fatalf("failed to reset settings: %v", err)
+	}
+
+	if got := storedRow(t, db, "ShowTray"); got != "" {
+		t.Fatalf("expected the ShowTray row to be deleted, got %s", got)
+	}
+	if !m.GetWoxSetting(ctx).ShowTray.Get() {
+		t.Fatalf("expected ShowTray to fall back to its default")
+	}
+	if got := m.GetWoxSetting(ctx).ActiveProfile.Get(); got != "work" {
+		t.Fatalf("expected reset to keep the active profile, got %s", got)
+	}
+
+	var deletes int64
+	if err := db.Model(&database.Oplog{}).Where("key = ? AND operation = ?", "ShowTray", cloudsync.OpDelete).Count(&deletes).Error; err != nil {
+		t.Fatalf("failed to count oplogs: %v", err)
+	}
+	if deletes != 1 {
+		t.Fatalf("expected one delete oplog for ShowTray, got %d", deletes)
+	}
+
+	if value, ok := changed["ShowTray"]; !ok || value != "true" {
+		t.Fatalf("expected a ShowTray change notification with true, got %v", changed)
+	}
+	if _, ok := changed["ActiveProfile"]; ok {
+		t.Fatalf("expected no ActiveProfile change notification, got %v", changed)
+	}
+}
@@ -0,0 +1,150 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"wox/util"
+)
+
+const (
+	// resultHashRegistryMaxHashes bounds how many hashes are tracked for
+	// diagnostics. Hashes seen after the limit is reached are not recorded.
+	resultHashRegistryMaxHashes = 5000
+	// resultHashRegistryMaxSources bounds how many distinct inputs are kept per hash.
+	resultHashRegistryMaxSources = 10
+)
+
+// ResultHashSource is one set of inputs that produced a ResultHash.
+type ResultHashSource struct {
+	PluginId string
+	Title    string
+	SubTitle string
+	// ContextId is the stable key of hashes made by NewStableResultHash.
+	ContextId string
+}
+
+// resultHashSources maps every recorded ResultHash to a pointer to an
+// immutable []ResultHashSource. Hashes are computed for every result of every
+// query, so recording must not serialize them: a source that was already seen
+// costs one map load, and new sources are added with compare-and-swap.
+var (
+	resultHashSources     sync.Map
+	resultHashSourceCount atomic.Int64
+)
+
+// NewStableResultHash hashes a result by a language independent key the plugin
+// provides instead of its visible title, so favorites and action history
+// survive switching the UI language. The "stable" prefix keeps these hashes
+// apart from title based ones.
+func NewStableResultHash(pluginId, stableKey string) ResultHash {
+	hash := ResultHash(util.Md5([]byte(fmt.Sprintf("stable|%d:%s|%d:%s", len(pluginId), pluginId, len(stableKey), stableKey))))
+	recordResultHashSource(hash, ResultHashSource{PluginId: pluginId, ContextId: stableKey})
+	return hash
+}
+
+// MigrateToStableResultHash moves the favorites and action history a result
+// collected under its title based hash, before its plugin set stableKey, to
+// its stable hash, so users keep them. Only the result's own title hash is
+// migrated: a ScoreKey hash is shared by several results and stays where it
+// is. The migration runs once per stable hash and process, later calls only
+// cost a map lookup, so it can be called for every query result.
+func (m *Manager) MigrateToStableResultHash(ctx context.Context, pluginId string, stableKey string, title string, subTitle string) {
+	stableHash := NewStableResultHash(pluginId, stableKey)
+	if _, migrated := m.stableResultHashMigrations.LoadOrStore(stableHash, true); migrated {
+		return
+	}
+	m.migrateResultHash(ctx, NewResultHash(pluginId, title, subTitle), stableHash)
+}
+
+// migrateResultHash moves the favorite flag and action history stored under
+// from to to. Actions already recorded under to are kept and merged by time.
+func (m *Manager) migrateResultHash(ctx context.Context, from ResultHash, to ResultHash) {
+	if from == to {
+		return
+	}
+
+	m.appDataMu.RLock()
+	woxSetting := m.currentWoxSetting()
+	hasData := woxSetting.PinedResults.Get().Exist(from) || woxSetting.ActionedResults.Get().Exist(from)
+	m.appDataMu.RUnlock()
+	if !hasData {
+		return
+	}
+
+	m.appDataMu.Lock()
+	defer m.appDataMu.Unlock()
+
+	woxSetting = m.currentWoxSetting()
+	pinedResults := woxSetting.PinedResults.Get()
+	if pined, ok := pinedResults.Load(from); ok {
+		if pined {
+			pinedResults.Store(to, true)
+		}
+		pinedResults.Delete(from)
+		saveAppData(ctx, m, woxSetting.PinedResults, pinedResults)
+	}
+
+	actionedResults := woxSetting.ActionedResults.Get()
+	if actions, ok := actionedResults.Load(from); ok {
+		existing, _ := actionedResults.Load(to)
+		merged := append(slices.Clone(existing), actions...)
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].Timestamp < merged[j].Timestamp
+		})
+		if len(merged) > maxActionedResultsPerHash {
+			merged = merged[len(merged)-maxActionedResultsPerHash:]
+		}
+		actionedResults.Store(to, merged)
+		actionedResults.Delete(from)
+		saveAppData(ctx, m, woxSetting.ActionedResults, actionedResults)
+	}
+
+	favoriteLabels := woxSetting.FavoriteLabels.Get()
+	if label, ok := favoriteLabels.Load(from); ok {
+		favoriteLabels.Store(to, label)
+		favoriteLabels.Delete(from)
+		saveAppData(ctx, m, woxSetting.FavoriteLabels, favoriteLabels)
+	}
+
+	logger.Info(ctx, fmt.Sprintf("migrated result data from hash %s to %s", from, to))
+}
+
+// InspectResultHash lists every distinct title/subtitle seen for the hash since
+// startup. More than one entry means different results share action history.
+func InspectResultHash(hash ResultHash) []ResultHashSource {
+	sources, ok := resultHashSources.Load(hash)
+	if !ok {
+		return nil
+	}
+	return slices.Clone(*sources.(*[]ResultHashSource))
+}
+
+func recordResultHashSource(hash ResultHash, source ResultHashSource) {
+	for {
+		stored, ok := resultHashSources.Load(hash)
+		if !ok {
+			if resultHashSourceCount.Load() >= resultHashRegistryMaxHashes {
+				return
+			}
+			if _, loaded := resultHashSources.LoadOrStore(hash, &[]ResultHashSource{source}); !loaded {
+				resultHashSourceCount.Add(1)
+				return
+			}
+			continue
+		}
+
+		sources := *stored.(*[]ResultHashSource)
+		if len(sources) >= resultHashRegistryMaxSources || slices.Contains(sources, source) {
+			return
+		}
+		// Replace the slice instead of appending in place, readers may hold the old one.
+		updated := append(slices.Clone(sources), source)
+		if resultHashSources.CompareAndSwap(hash, stored, &updated) {
+			return
+		}
+	}
+}
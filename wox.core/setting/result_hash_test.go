@@ -0,0 +1,38 @@
+package setting
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMigrateToStableResultHashMovesTitleHashOnce(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewManager(NewWoxSettingStore(db), db)
+
+	titleHash := NewResultHash("plugin", "Settings", "Open settings")
+	sharedScoreHash := NewResultHash("plugin", "shared-score-key", "")
+	stableHash := NewStableResultHash("plugin", "settings")
+	for _, hash := range []ResultHash{titleHash, sharedScoreHash} {
+		if err := m.pinResultByHash(ctx, hash); err != nil {
+			t.Fatalf("failed to pin result: %v", err)
+		}
+	}
+
+	m.MigrateToStableResultHash(ctx, "plugin", "settings", "Settings", "Open settings")
+	if !m.IsPinedResultByHash(ctx, stableHash) || m.IsPinedResultByHash(ctx, titleHash) {
+		t.Fatalf("expected the favorite to move from the title hash to the stable hash")
+	}
+	if !m.IsPinedResultByHash(ctx, sharedScoreHash) {
+		t.Fatalf("expected the shared ScoreKey hash to be left alone")
+	}
+
+	// data recorded under the title hash later is not moved again
+	if err := m.pinResultByHash(ctx, titleHash); err != nil {
+		t.Fatalf("failed to pin result: %v", err)
+	}
+	m.MigrateToStableResultHash(ctx, "plugin", "settings", "Settings", "Open settings")
+	if !m.IsPinedResultByHash(ctx, titleHash) {
+		t.Fatalf("expected the migration to run only once per stable hash")
+	}
+}
@@ -0,0 +1,288 @@
+package setting
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"wox/cloudsync"
+	"wox/util"
+)
+
+const (
+	secretKeyringService = "wox.setting"
+	secretKeyringKey     = "secret-key"
+
+	// sealedSecretPrefix marks values encrypted by this file. Values without the
+	// prefix are legacy plaintext and are returned unchanged so existing
+	// settings keep working until they are saved again.
+	sealedSecretPrefix = "enc:v1:"
+)
+
+var secretKeyOnce sync.Once
+var secretKey []byte
+var secretKeyErr error
+
+// secretSettingSealers maps setting keys that contain secrets to the function
+// that rewrites their serialized value. Only database rows, the setting and its
+// oplogs, are sealed; the in-memory value and the cloud sync payload stay
+// plaintext, so callers such as provider.ApiKey readers and the cloud sync
+// crypto layer are unaffected.
+var secretSettingSealers = map[string]func(raw string, transform func(string) (string, error)) (string, error){
+	"AIProviders": transformAIProviderApiKeys,
+}
+
+// sealStoredSettingValue encrypts the secret fields of a serialized setting before it is written to the database.
+func sealStoredSettingValue(key string, raw string) (string, error) {
+	sealer, ok := secretSettingSealers[key]
+	if !ok || raw == "" {
+		return raw, nil
+	}
+	return sealer(raw, sealSecret)
+}
+
+// OpenStoredSettingValue decrypts the secret fields of a serialized setting read from the database,
+// and converts app data stored in binary form back to JSON.
+// It is exported for callers that read raw rows directly, such as the cloud sync snapshotter.
+func OpenStoredSettingValue(key string, raw string) (string, error) {
+	if isBinaryAppDataValue(raw) {
+		return binaryAppDataToJSON(key, raw)
+	}
+	sealer, ok := secretSettingSealers[key]
+	if !ok || raw == "" {
+		return raw, nil
+	}
+	return sealer(raw, func(value string) (string, error) {
+		// A key sealed on another machine (e.g. a restored backup) cannot be
+		// opened here. The ciphertext is kept as is, so saving the setting
+		// again does not erase a secret the right key could still open.
+		opened, err := openSecret(value)
+		if err != nil {
+			util.GetLogger().Warn(context.Background(), fmt.Sprintf("failed to open a secret of %s, it is kept sealed: %s", key, err.Error()))
+			return value, nil
+		}
+		return opened, nil
+	})
+}
+
+// secretSettingKeepers maps setting keys that contain secrets to the function
+// that copies the local secrets into an imported value whose secrets were
+// stripped, see ImportAll.
+var secretSettingKeepers = map[string]func(imported string, local string) (string, error){
+	"AIProviders": keepLocalAIProviderApiKeys,
+}
+
+// keepLocalSecretSettingValue returns imported with the secrets of local
+// filled in where imported has none. Both values are plaintext.
+func keepLocalSecretSettingValue(key string, imported string, local string) (string, error) {
+	keeper, ok := secretSettingKeepers[key]
+	if !ok || imported == "" || local == "" {
+		return imported, nil
+	}
+	return keeper(imported, local)
+}
+
+// keepLocalAIProviderApiKeys matches providers by Id, or by Name and Alias
+// for providers saved before ids existed.
+func keepLocalAIProviderApiKeys(imported string, local string) (string, error) {
+	var importedProviders []map[string]any
+	if err := json.Unmarshal([]byte(imported), &importedProviders); err != nil {
+		return "", fmt.Errorf("failed to decode imported ai providers: %w", err)
+	}
+	var localProviders []map[string]any
+	if err := json.Unmarshal([]byte(local), &localProviders); err != nil {
+		return "", fmt.Errorf("failed to decode local ai providers: %w", err)
+	}
+
+	providerKey := func(provider map[string]any) string {
+		if id, _ := provider["Id"].(string); id != "" {
+			return "id:" + id
+		}
+		return fmt.Sprintf("name:%v/%v", provider["Name"], provider["Alias"])
+	}
+	localApiKeys := map[string]string{}
+	for _, provider := range localProviders {
+		if apiKey, _ := provider["ApiKey"].(string); apiKey != "" {
+			localApiKeys[providerKey(provider)] = apiKey
+		}
+	}
+	for _, provider := range importedProviders {
+		if apiKey, _ := provider["ApiKey"].(string); apiKey != "" {
+			continue
+		}
+		if apiKey, ok := localApiKeys[providerKey(provider)]; ok {
+			provider["ApiKey"] = apiKey
+		}
+	}
+
+	bytes, err := json.Marshal(importedProviders)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ai providers: %w", err)
+	}
+	return string(bytes), nil
+}
+
+func transformAIProviderApiKeys(raw string, transform func(string) (string, error)) (string, error) {
+	// Decode into generic objects rather than []AIProvider so fields written by
+	// a newer build survive sealing, see preserveUnknownFields.
+	var providers []map[string]any
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return "", fmt.Errorf("failed to decode ai providers: %w", err)
+	}
+
+	for i := range providers {
+		currentApiKey, _ := providers[i]["ApiKey"].(string)
+		apiKey, err := transform(currentApiKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to process api key of ai provider %v: %w", providers[i]["Name"], err)
+		}
+		providers[i]["ApiKey"] = apiKey
+	}
+
+	bytes, err := json.Marshal(providers)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ai providers: %w", err)
+	}
+	return string(bytes), nil
+}
+
+func sealSecret(plaintext string) (string, error) {
+	if plaintext == "" || strings.HasPrefix(plaintext, sealedSecretPrefix) {
+		return plaintext, nil
+	}
+
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to read nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openSecret(value string) (string, error) {
+	if !strings.HasPrefix(value, sealedSecretPrefix) {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedSecretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("secret is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newSecretCipher() (cipher.AEAD, error) {
+	secretKeyOnce.Do(func() {
+		secretKey, secretKeyErr = loadOrCreateSecretKey(context.Background())
+	})
+	if secretKeyErr != nil {
+		return nil, secretKeyErr
+	}
+
+	block, err := aes.NewCipher(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadOrCreateSecretKey prefers the OS credential store (Keychain, Credential
+// Manager, libsecret). A key the keyring cannot store falls back to a user-only
+// file in the OS config directory, see secretKeyFilePath. It is kept out of the
+// wox data directory, which holds the backups the key encrypts and is what
+// users copy or sync as a whole. A new key is only created when neither has
+// one and the keyring reported it as not found.
+func loadOrCreateSecretKey(ctx context.Context) ([]byte, error) {
+	keyring := cloudsync.NewOSKeyringStore(secretKeyringService)
+	keyringKey := secretKeyringAccount()
+	encoded, keyringErr := keyring.Get(ctx, keyringKey)
+	if keyringErr == nil {
+		return base64.StdEncoding.DecodeString(encoded)
+	}
+
+	keyFilePath, err := secretKeyFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if content, readErr := os.ReadFile(keyFilePath); readErr == nil {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	} else if !os.IsNotExist(readErr) {
+		return nil, fmt.Errorf("failed to read secret key file: %w", readErr)
+	}
+
+	// Any other keyring error may hide an existing key, and a new key would
+	// make every secret and backup sealed with it unreadable.
+	if !errors.Is(keyringErr, cloudsync.ErrKeyNotFound) {
+		return nil, fmt.Errorf("failed to read setting secret key from os keyring: %w", keyringErr)
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	encoded = base64.StdEncoding.EncodeToString(key)
+
+	setErr := keyring.Set(ctx, keyringKey, encoded)
+	if setErr == nil {
+		return key, nil
+	}
+	util.GetLogger().Warn(ctx, fmt.Sprintf("failed to store setting secret key in os keyring, falling back to key file: %s", setErr.Error()))
+
+	if err := os.MkdirAll(filepath.Dir(keyFilePath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create secret key directory: %w", err)
+	}
+	if err := util.WriteFileAtomic(keyFilePath, []byte(encoded), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write secret key file: %w", err)
+	}
+	return key, nil
+}
+
+// secretKeyFilePath returns the fallback key file of this instance in the OS
+// config directory, named after its keyring entry so instances moved by
+// util.DataDirEnv keep separate keys there too.
+func secretKeyFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory for the secret key file: %w", err)
+	}
+	return filepath.Join(configDir, "wox", util.Md5([]byte(secretKeyringAccount()))+".key"), nil
+}
+
+// secretKeyringAccount returns the keyring entry of this instance. Instances
+// moved by util.DataDirEnv get their own key, so they cannot open each
+// other's secrets or backups.
+func secretKeyringAccount() string {
+	location := util.GetLocation()
+	if !location.IsDataDirectoryFromEnv() {
+		return secretKeyringKey
+	}
+	return secretKeyringKey + "@" + util.Md5([]byte(location.GetWoxDataDirectory()))
+}
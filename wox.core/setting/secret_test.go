@@ -0,0 +1,107 @@
+package setting
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"wox/database"
+)
+
+// failSecretKey makes every seal and open fail, like a machine without a
+// keychain and with an unreadable key file.
+func failSecretKey(t *testing.T) {
+	t.Helper()
+	secretKeyOnce = sync.Once{}
+	secretKeyOnce.Do(func() {
+		secretKey, secretKeyErr = nil, errors.New("secret key unavailable")
+	})
+	t.Cleanup(func() {
+		secretKeyOnce = sync.Once{}
+		secretKey, secretKeyErr = nil, nil
+	})
+}
+
+func TestSealFailureKeepsStoredApiKey(t *testing.T) {
+	db := newTestDB(t)
+	store := NewWoxSettingStore(db)
+	stored := `[{"Id":"p1","Name":"openai","ApiKey":"sk-stored"}]`
+	if err := db.Create(&database.WoxSetting{Key: "AIProviders", Value: stored}).Error; err != nil {
+		t.Fatalf("failed to seed providers: %v", err)
+	}
+	failSecretKey(t)
+
+	err := store.SetWithSync("AIProviders", []AIProvider{{Id: "p1", Name: "openai", ApiKey: "sk-new"}}, true)
+	if err == nil {
+		t.Fatalf("expected the write to fail when the api key cannot be sealed")
+	}
+	if got := storedRow(t, db, "AIProviders"); got != stored {
+		t.Fatalf("stored providers changed after a failed seal: %s", got)
+	}
+
+	var oplogCount int64
+	if err := db.Model(&database.Oplog{}).Count(&oplogCount).Error; err != nil {
+		t.Fatalf("failed to count oplogs: %v", err)
+	}
+	if oplogCount != 0 {
+		t.Fatalf("expected no oplog for a failed write, got %d", oplogCount)
+	}
+}
+
+func TestUnsealFailureKeepsTheSealedKeyOnRead(t *testing.T) {
+	failSecretKey(t)
+
+	sealedApiKey := sealedSecretPrefix + "AAAA"
+	opened, err := OpenStoredSettingValue("AIProviders", `[{"Id":"p1","Name":"openai","ApiKey":"`+sealedApiKey+`","Host":"https://example.com"}]`)
+	if err != nil {
+		t.Fatalf("reading providers with an unreadable key must not fail: %v", err)
+	}
+	if !strings.Contains(opened, sealedApiKey) || !strings.Contains(opened, "https://example.com") {
+		t.Fatalf("expected the sealed key to be kept as is, got %s", opened)
+	}
+}
+
+// useSecretKey makes the setting secret key a test key filled with b.
+func useSecretKey(t *testing.T, b byte) {
+	t.Helper()
+	secretKeyOnce = sync.Once{}
+	secretKeyOnce.Do(func() {
+		secretKey, secretKeyErr = bytes.Repeat([]byte{b}, 32), nil
+	})
+	t.Cleanup(func() {
+		secretKeyOnce = sync.Once{}
+		secretKey, secretKeyErr = nil, nil
+	})
+}
+
+func TestSecretSealedWithAnotherKeySurvivesOpenAndSave(t *testing.T) {
+	useSecretKey(t, 7)
+	sealed, err := sealStoredSettingValue("AIProviders", `[{"Id":"p1","Name":"openai","ApiKey":"sk-secret"}]`)
+	if err != nil {
+		t.Fatalf("failed to seal providers: %v", err)
+	}
+
+	// e.g. a backup restored on another machine
+	useSecretKey(t, 9)
+	opened, err := OpenStoredSettingValue("AIProviders", sealed)
+	if err != nil {
+		t.Fatalf("reading providers sealed with another key must not fail: %v", err)
+	}
+	if strings.Contains(opened, "sk-secret") || !strings.Contains(opened, sealedSecretPrefix) {
+		t.Fatalf("expected the api key to stay sealed, got %s", opened)
+	}
+	resealed, err := sealStoredSettingValue("AIProviders", opened)
+	if err != nil {
+		t.Fatalf("failed to save providers again: %v", err)
+	}
+
+	useSecretKey(t, 7)
+	reopened, err := OpenStoredSettingValue("AIProviders", resealed)
+	if err != nil {
+		t.Fatalf("failed to open providers: %v", err)
+	}
+	if !strings.Contains(reopened, `"ApiKey":"sk-secret"`) {
+		t.Fatalf("expected the original key to open the api key after a save, got %s", reopened)
+	}
+}
@@ -0,0 +1,67 @@
+package setting
+
+import (
+	"context"
+	"reflect"
+)
+
+// settingSnapshotter is implemented by every value type stored in WoxSetting.
+type settingSnapshotter interface {
+	snapshotValue() any
+}
+
+// GetWoxSettingSnapshot returns a deep copy of the current settings. Values in
+// the snapshot are detached from the store, so mutating a returned slice or map
+// cannot leak into the live settings and calling Set on a snapshot value fails.
+// Prefer it over GetWoxSetting for code that only reads settings.
+func (m *Manager) GetWoxSettingSnapshot(ctx context.Context) WoxSetting {
+	m.woxSettingMu.RLock()
+	defer m.woxSettingMu.RUnlock()
+
+	var snapshot WoxSetting
+	source := reflect.ValueOf(m.woxSetting).Elem()
+	target := reflect.ValueOf(&snapshot).Elem()
+	for i := 0; i < source.NumField(); i++ {
+		field := source.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		if snapshotter, ok := field.Interface().(settingSnapshotter); ok {
+			target.Field(i).Set(reflect.ValueOf(snapshotter.snapshotValue()))
+		}
+	}
+	return snapshot
+}
+
+func (v *WoxSettingValue[T]) snapshotValue() any {
+	return &WoxSettingValue[T]{SettingValue: v.SettingValue.detachedCopy()}
+}
+
+func (v *PlatformValue[T]) snapshotValue() any {
+	return &PlatformValue[T]{
+		WoxSettingValue:  &WoxSettingValue[T]{SettingValue: v.SettingValue.detachedCopy()},
+		baseKey:          v.baseKey,
+		platformDefaults: v.platformDefaults,
+	}
+}
+
+// detachedCopy returns a loaded copy of the value without a store. The value is
+// deep copied through its serialized form, which every stored setting supports.
+func (v *SettingValue[T]) detachedCopy() *SettingValue[T] {
+	value := v.Get()
+	if raw, err := SerializeValue(value); err == nil {
+		var decoded T
+		if err := deserializeValue(raw, &decoded); err == nil {
+			value = decoded
+		}
+	}
+
+	return &SettingValue[T]{
+		key:          v.key,
+		value:        value,
+		defaultValue: v.defaultValue,
+		validator:    v.validator,
+		syncable:     v.syncable,
+		isLoaded:     true,
+	}
+}
@@ -2,9 +2,11 @@ package setting
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"wox/cloudsync"
 	"wox/database"
 	"wox/util"
@@ -13,7 +15,8 @@ import (
 )
 
 // SettingStore defines the abstract interface for reading and writing settings
-// This is the base interface that both WoxSettingStore and PluginSettingStore adapters implement
+// This is the base interface that WoxSettingStore, PluginSettingStore and MemorySettingStore implement
+// Manager only depends on this interface, see NewManager
 type SettingStore interface {
 	Get(key string, target interface{}) error
 	Set(key string, value interface{}) error
@@ -30,6 +33,9 @@ type SyncableStore interface {
 
 type WoxSettingStore struct {
 	db *gorm.DB
+	// trackedWrites collects the writes made inside Transaction. They are
+	// recorded in selfWrites only once the transaction committed.
+	trackedWrites *[]trackedWrite
 }
 
 func NewWoxSettingStore(db *gorm.DB) *WoxSettingStore {
@@ -44,48 +50,177 @@ func (s *WoxSettingStore) Get(key string, target interface{}) error {
 		return err
 	}
 
-	return deserializeValue(setting.Value, target)
+	// Binary app data is decoded straight into its type, reading it as a
+	// string goes through OpenStoredSettingValue to get JSON.
+	if _, isString := target.(*string); !isString && isBinaryAppDataValue(setting.Value) {
+		return decodeBinaryAppDataValue(setting.Value, target)
+	}
+
+	strValue, err := OpenStoredSettingValue(key, setting.Value)
+	if err != nil {
+		return err
+	}
+
+	return deserializeValue(strValue, target)
 }
 
 func (s *WoxSettingStore) Set(key string, value interface{}) error {
-	strValue, err := SerializeValue(value)
+	_, err := s.set(key, value)
+	return err
+}
+
+// set is Set that also returns the length of the stored row value, which
+// app data saves use to track their size, see Manager.recordAppDataSize.
+func (s *WoxSettingStore) set(key string, value interface{}) (int, error) {
+	strValue, isBinary, err := serializeAppDataValue(key, value)
+	if !isBinary {
+		strValue, err = SerializeValue(value)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to serialize value: %w", err)
+		return 0, fmt.Errorf("failed to serialize value: %w", err)
 	}
 
-	return s.db.Save(&database.WoxSetting{Key: key, Value: strValue}).Error
+	strValue, err = sealStoredSettingValue(key, strValue)
+	if err != nil {
+		return 0, fmt.Errorf("failed to seal value: %w", err)
+	}
+
+	err = s.trackWrite(key, strValue, false, func() (string, error) {
+		if err := s.db.Save(&database.WoxSetting{Key: key, Value: strValue}).Error; err != nil {
+			return "", err
+		}
+		return touchLastModified(s.db)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(strValue), nil
 }
 
 func (s *WoxSettingStore) Delete(key string) error {
-	return s.db.Delete(&database.WoxSetting{Key: key}).Error
+	_, err := s.delete(key)
+	return err
 }
 
-func (s *WoxSettingStore) SetWithSync(key string, value interface{}, syncable bool) error {
-	if err := s.Set(key, value); err != nil {
+// delete removes the row of key and advances LastModified.
+func (s *WoxSettingStore) delete(key string) (int64, error) {
+	var rowsAffected int64
+	err := s.trackWrite(key, "", true, func() (string, error) {
+		result := s.db.Delete(&database.WoxSetting{Key: key})
+		if result.Error != nil {
+			return "", result.Error
+		}
+		rowsAffected = result.RowsAffected
+		return touchLastModified(s.db)
+	})
+	return rowsAffected, err
+}
+
+// List returns every stored row, with secrets opened and binary app data
+// converted to JSON like Get does for a *string target.
+func (s *WoxSettingStore) List() (map[string]string, error) {
+	var rows []database.WoxSetting
+	if err := s.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		value, err := OpenStoredSettingValue(row.Key, row.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open setting %s: %w", row.Key, err)
+		}
+		values[row.Key] = value
+	}
+	return values, nil
+}
+
+// Transaction runs fn with a store bound to one database transaction, so
+// bulk changes are applied completely or not at all.
+func (s *WoxSettingStore) Transaction(fn func(store SettingStore) error) error {
+	if s.trackedWrites != nil {
+		return fn(s)
+	}
+	if isInTransaction(s.db) {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			return fn(NewWoxSettingStore(tx))
+		})
+	}
+
+	var writes []trackedWrite
+	return selfWrites.trackTransaction(&writes, func() error {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			return fn(&WoxSettingStore{db: tx, trackedWrites: &writes})
+		})
+	})
+}
+
+// trackWrite runs write and records it in selfWrites. Writes made inside
+// Transaction are recorded when it commits. Writes of a store bound to a
+// transaction opened elsewhere, e.g. by a migration, are not recorded, since
+// that transaction may still roll back.
+func (s *WoxSettingStore) trackWrite(key string, value string, deleted bool, write func() (string, error)) error {
+	if s.trackedWrites != nil {
+		lastModified, err := write()
+		if err != nil {
+			return err
+		}
+		*s.trackedWrites = append(*s.trackedWrites, trackedWrite{key: key, value: value, deleted: deleted, lastModified: lastModified})
+		return nil
+	}
+	if isInTransaction(s.db) {
+		_, err := write()
 		return err
 	}
+	return selfWrites.track(key, value, deleted, write)
+}
+
+// isInTransaction reports whether db is bound to an open transaction.
+func isInTransaction(db *gorm.DB) bool {
+	committer, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok && committer != nil
+}
+
+func (s *WoxSettingStore) SetWithSync(key string, value interface{}, syncable bool) error {
+	_, err := s.setWithSync(key, value, syncable)
+	return err
+}
+
+// setWithSync is SetWithSync that also returns the stored length, see set.
+func (s *WoxSettingStore) setWithSync(key string, value interface{}, syncable bool) (int, error) {
+	size, err := s.set(key, value)
+	if err != nil {
+		return 0, err
+	}
 	if !syncable {
-		return nil
+		return size, nil
 	}
-	return s.logOplog(key, value, cloudsync.OpUpsert)
+	return size, s.logOplog(key, value, cloudsync.OpUpsert)
 }
 
 func (s *WoxSettingStore) DeleteWithSync(key string, syncable bool) error {
-	result := s.db.Delete(&database.WoxSetting{Key: key})
-	if result.Error != nil {
-		return result.Error
+	rowsAffected, err := s.delete(key)
+	if err != nil {
+		return err
 	}
-	if !syncable || result.RowsAffected == 0 {
+	if !syncable || rowsAffected == 0 {
 		return nil
 	}
 	return s.logOplog(key, nil, cloudsync.OpDelete)
 }
 
+// logOplog records a change for cloud sync. Secrets are sealed like the row
+// itself, so the oplog table never holds them in plaintext; they are opened
+// again right before upload, see settingadapter.SecretOpeningOplogStore.
 func (s *WoxSettingStore) logOplog(key string, value interface{}, op string) error {
 	strValue, err := SerializeValue(value)
 	if err != nil {
 		return fmt.Errorf("failed to serialize value for oplog: %w", err)
 	}
+	strValue, err = sealStoredSettingValue(key, strValue)
+	if err != nil {
+		return fmt.Errorf("failed to seal value for oplog: %w", err)
+	}
 
 	oplog := database.Oplog{
 		EntityType: cloudsync.EntityWoxSetting,
@@ -98,12 +233,23 @@ func (s *WoxSettingStore) logOplog(key string, value interface{}, op string) err
 	return writeCloudSyncOplog(s.db, oplog)
 }
 
-// PluginSettingStore defines the interface for plugin settings
+// PluginSettingStore defines the interface for plugin settings.
+//
+// Isolation contract: a store only ever reads or writes rows whose plugin_id is
+// the plugin id it was created with. Plugins never create stores themselves;
+// the host creates one per plugin from its metadata id, so a plugin cannot
+// reach another plugin's settings by guessing its id or key. Every query goes
+// through scoped, and a store without a plugin id refuses to run, because
+// gorm drops empty primary key fields from conditions and would otherwise
+// match the same key in every plugin.
 type PluginSettingStore struct {
 	db       *gorm.DB
 	pluginId string
 }
 
+// ErrPluginSettingStoreWithoutPluginId is returned by a PluginSettingStore created with an empty plugin id.
+var ErrPluginSettingStoreWithoutPluginId = errors.New("plugin setting store has no plugin id")
+
 func NewPluginSettingStore(db *gorm.DB, pluginId string) *PluginSettingStore {
 	return &PluginSettingStore{
 		db:       db,
@@ -111,39 +257,132 @@ func NewPluginSettingStore(db *gorm.DB, pluginId string) *PluginSettingStore {
 	}
 }
 
+// scoped returns a query limited to this store's plugin id. It is a new
+// session, so the result can be reused for several statements.
+func (s *PluginSettingStore) scoped() (*gorm.DB, error) {
+	if strings.TrimSpace(s.pluginId) == "" {
+		return nil, ErrPluginSettingStoreWithoutPluginId
+	}
+	return s.db.Where("plugin_id = ?", s.pluginId).Session(&gorm.Session{}), nil
+}
+
+// checkNamespace is a debug assertion for the isolation contract. A row of
+// another plugin can only show up through a bug in this file, so dev builds
+// panic to surface it right away while release builds refuse the row.
+func (s *PluginSettingStore) checkNamespace(setting database.PluginSetting) error {
+	if setting.PluginID == s.pluginId {
+		return nil
+	}
+	err := fmt.Errorf("plugin setting store of %s read a row of plugin %s", s.pluginId, setting.PluginID)
+	if util.IsDev() {
+		panic(err)
+	}
+	return err
+}
+
 func (s *PluginSettingStore) Get(key string, target interface{}) error {
-	var setting database.PluginSetting
-	if err := s.db.Where("plugin_id = ? AND key = ?", s.pluginId, key).First(&setting).Error; err != nil {
+	value, err := s.cachedValue(key)
+	if err != nil {
 		return err
 	}
 
-	return deserializeValue(setting.Value, target)
+	return deserializeValue(value, target)
 }
 
 func (s *PluginSettingStore) Set(key string, value interface{}) error {
+	if _, err := s.scoped(); err != nil {
+		return err
+	}
+
 	strValue, err := SerializeValue(value)
 	if err != nil {
 		return fmt.Errorf("failed to serialize plugin setting value: %w", err)
 	}
+	if readOnlyPluginSettings.set(s.pluginId, key, &strValue) {
+		return nil
+	}
 
+	defer s.invalidateCache()
 	return s.db.Save(&database.PluginSetting{PluginID: s.pluginId, Key: key, Value: strValue}).Error
 }
 
 func (s *PluginSettingStore) Delete(key string) error {
-	return s.db.Delete(&database.PluginSetting{PluginID: s.pluginId, Key: key}).Error
+	db, err := s.scoped()
+	if err != nil {
+		return err
+	}
+	if readOnlyPluginSettings.set(s.pluginId, key, nil) {
+		return nil
+	}
+	defer s.invalidateCache()
+	return db.Where("key = ?", key).Delete(&database.PluginSetting{}).Error
+}
+
+// SetJSON stores v as JSON, so plugins can keep structured data without marshalling it into a string setting themselves.
+// The value is synced like any other plugin setting.
+func (s *PluginSettingStore) SetJSON(key string, v any) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin setting %s: %w", key, err)
+	}
+	return s.SetWithSync(key, string(bytes), true)
+}
+
+// GetJSON reads a value stored by SetJSON into v. It returns gorm.ErrRecordNotFound if the key does not exist.
+func (s *PluginSettingStore) GetJSON(key string, v any) error {
+	value, err := s.cachedValue(key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return fmt.Errorf("failed to unmarshal plugin setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// Keys returns every stored setting key of the plugin.
+func (s *PluginSettingStore) Keys() ([]string, error) {
+	db, err := s.scoped()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	if err := db.Model(&database.PluginSetting{}).Pluck("key", &keys).Error; err != nil {
+		return nil, err
+	}
+	return readOnlyPluginSettings.applyToKeys(s.pluginId, keys), nil
 }
 
 func (s *PluginSettingStore) DeleteAll() error {
+	db, err := s.scoped()
+	if err != nil {
+		return err
+	}
+	if readOnlyPluginSettings.isEnabled() {
+		keys, err := s.Keys()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			readOnlyPluginSettings.set(s.pluginId, key, nil)
+		}
+		return nil
+	}
+
 	var settings []database.PluginSetting
-	if err := s.db.Where("plugin_id = ?", s.pluginId).Find(&settings).Error; err != nil {
+	if err := db.Find(&settings).Error; err != nil {
 		return err
 	}
 
-	if err := s.db.Where("plugin_id = ?", s.pluginId).Delete(&database.PluginSetting{}).Error; err != nil {
+	defer s.invalidateCache()
+	if err := db.Delete(&database.PluginSetting{}).Error; err != nil {
 		return err
 	}
 
 	for _, setting := range settings {
+		if err := s.checkNamespace(setting); err != nil {
+			return err
+		}
 		if err := s.logOplog(setting.Key, nil, cloudsync.OpDelete); err != nil {
 			return err
 		}
@@ -156,14 +395,23 @@ func (s *PluginSettingStore) SetWithSync(key string, value interface{}, syncable
 	if err := s.Set(key, value); err != nil {
 		return err
 	}
-	if !syncable {
+	// Changes kept in memory for a read-only data directory are not synced.
+	if !syncable || readOnlyPluginSettings.isEnabled() {
 		return nil
 	}
 	return s.logOplog(key, value, cloudsync.OpUpsert)
 }
 
 func (s *PluginSettingStore) DeleteWithSync(key string, syncable bool) error {
-	result := s.db.Delete(&database.PluginSetting{PluginID: s.pluginId, Key: key})
+	db, err := s.scoped()
+	if err != nil {
+		return err
+	}
+	if readOnlyPluginSettings.set(s.pluginId, key, nil) {
+		return nil
+	}
+	result := db.Where("key = ?", key).Delete(&database.PluginSetting{})
+	s.invalidateCache()
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,212 @@
+package setting
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// listIdentityFields names the field that identifies an entry of a list
+// setting. Entry types not listed here are identified by an Id field if they
+// have one.
+var listIdentityFields = map[reflect.Type]string{
+	reflect.TypeFor[QueryHotkey]():             "Hotkey",
+	reflect.TypeFor[QueryShortcut]():           "Shortcut",
+	reflect.TypeFor[IgnoredHotkeyApp]():        "Identity",
+	reflect.TypeFor[TrayQuery]():               "Query",
+	reflect.TypeFor[QueryCompletionFeedback](): "CompletionText",
+}
+
+// preserveUnknownFields keeps JSON fields that the current build does not know
+// about when a structured setting is saved. Every setting is its own row, so
+// unknown settings already survive a downgrade, but a value such as
+// AIProviders or QueryHotkeys written by a newer build may carry struct fields
+// an older build drops while decoding. Without this, saving the value once on
+// the older build would delete those fields for good.
+//
+// Fields are merged back from stored into serialized using valueType to tell
+// known from unknown fields. List entries are matched by their identity field,
+// see listIdentityFields, so entries that were added, removed or reordered
+// keep their own fields. Lists whose entries have no identity are not merged.
+func preserveUnknownFields(valueType reflect.Type, stored string, serialized string) string {
+	if stored == "" || !hasStructFields(valueType) {
+		return serialized
+	}
+
+	var storedValue any
+	var serializedValue any
+	if json.Unmarshal([]byte(stored), &storedValue) != nil || json.Unmarshal([]byte(serialized), &serializedValue) != nil {
+		return serialized
+	}
+
+	merged, changed := mergeUnknownJSONFields(valueType, storedValue, serializedValue)
+	if !changed {
+		return serialized
+	}
+	bytes, err := json.Marshal(merged)
+	if err != nil {
+		return serialized
+	}
+	return string(bytes)
+}
+
+func mergeUnknownJSONFields(t reflect.Type, stored any, serialized any) (any, bool) {
+	t = derefType(t)
+	if isOpaqueJSONType(t) {
+		return serialized, false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		storedObject, storedOk := stored.(map[string]any)
+		serializedObject, serializedOk := serialized.(map[string]any)
+		if !storedOk || !serializedOk {
+			return serialized, false
+		}
+
+		fields := knownJSONFields(t)
+		changed := false
+		for key, storedField := range storedObject {
+			fieldType, known := fields[strings.ToLower(key)]
+			if !known {
+				serializedObject[key] = storedField
+				changed = true
+				continue
+			}
+			if serializedField, ok := serializedObject[key]; ok {
+				if merged, fieldChanged := mergeUnknownJSONFields(fieldType, storedField, serializedField); fieldChanged {
+					serializedObject[key] = merged
+					changed = true
+				}
+			}
+		}
+		return serializedObject, changed
+	case reflect.Slice, reflect.Array:
+		storedList, storedOk := stored.([]any)
+		serializedList, serializedOk := serialized.([]any)
+		identityField, hasIdentity := listIdentityField(t.Elem())
+		if !storedOk || !serializedOk || !hasIdentity {
+			return serialized, false
+		}
+
+		// Entries sharing an identity are paired in order.
+		storedByIdentity := map[string][]any{}
+		for _, storedItem := range storedList {
+			if identity, ok := listItemIdentity(storedItem, identityField); ok {
+				storedByIdentity[identity] = append(storedByIdentity[identity], storedItem)
+			}
+		}
+
+		changed := false
+		for i, serializedItem := range serializedList {
+			identity, ok := listItemIdentity(serializedItem, identityField)
+			if !ok || len(storedByIdentity[identity]) == 0 {
+				continue
+			}
+			storedItem := storedByIdentity[identity][0]
+			storedByIdentity[identity] = storedByIdentity[identity][1:]
+			if merged, itemChanged := mergeUnknownJSONFields(t.Elem(), storedItem, serializedItem); itemChanged {
+				serializedList[i] = merged
+				changed = true
+			}
+		}
+		return serializedList, changed
+	default:
+		return serialized, false
+	}
+}
+
+// listIdentityField returns the JSON name of the field that identifies list
+// entries of type t, see listIdentityFields.
+func listIdentityField(t reflect.Type) (string, bool) {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+	fields := knownJSONFields(t)
+	if name, ok := listIdentityFields[t]; ok {
+		_, known := fields[strings.ToLower(name)]
+		return strings.ToLower(name), known
+	}
+	if _, ok := fields["id"]; ok {
+		return "id", true
+	}
+	return "", false
+}
+
+// listItemIdentity returns the non-empty identity of a decoded list entry.
+// Field names are compared case-insensitively like json.Unmarshal does.
+func listItemIdentity(item any, identityField string) (string, bool) {
+	object, ok := item.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	for key, value := range object {
+		if strings.ToLower(key) != identityField {
+			continue
+		}
+		identity, isString := value.(string)
+		return identity, isString && identity != ""
+	}
+	return "", false
+}
+
+// knownJSONFields returns the lower-cased JSON names of t's fields, following
+// encoding/json's handling of tags and embedded structs. Lower-casing matches
+// the case-insensitive field matching of json.Unmarshal.
+func knownJSONFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && derefType(field.Type).Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range knownJSONFields(derefType(field.Type)) {
+				fields[embeddedName] = embeddedType
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
+
+// hasStructFields reports whether values of t can contain struct fields that
+// an older build might not know.
+func hasStructFields(t reflect.Type) bool {
+	t = derefType(t)
+	if isOpaqueJSONType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Array:
+		return hasStructFields(t.Elem())
+	default:
+		return false
+	}
+}
+
+// isOpaqueJSONType reports types with their own JSON encoding, such as
+// util.HashMap, whose fields say nothing about the keys they produce.
+func isOpaqueJSONType(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
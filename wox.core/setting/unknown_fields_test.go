@@ -0,0 +1,44 @@
+package setting
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPreserveUnknownFieldsMatchesListEntriesById(t *testing.T) {
+	stored := `[{"Id":"a","Name":"openai","Future":"kept-a"},{"Id":"b","Name":"groq","Future":"kept-b"}]`
+	// The older build removed provider a and added c in front of b.
+	serialized := `[{"Id":"c","Name":"ollama"},{"Id":"b","Name":"groq"}]`
+
+	merged := preserveUnknownFields(reflect.TypeFor[[]AIProvider](), stored, serialized)
+
+	var providers []map[string]any
+	if err := json.Unmarshal([]byte(merged), &providers); err != nil {
+		t.Fatalf("failed to decode merged value %s: %v", merged, err)
+	}
+	if len(providers) != 2 {
+		t.Fatalf("expected 2 providers, got %s", merged)
+	}
+	if _, ok := providers[0]["Future"]; ok {
+		t.Fatalf("expected new provider c to get no fields of another entry, got %s", merged)
+	}
+	if providers[1]["Future"] != "kept-b" {
+		t.Fatalf("expected provider b to keep its unknown field, got %s", merged)
+	}
+}
+
+func TestPreserveUnknownFieldsUsesListIdentityFields(t *testing.T) {
+	stored := `[{"Hotkey":"alt+1","Query":"a","Future":1},{"Hotkey":"alt+2","Query":"b","Future":2}]`
+	serialized := `[{"Hotkey":"alt+2","Query":"b"},{"Hotkey":"alt+1","Query":"a"}]`
+
+	merged := preserveUnknownFields(reflect.TypeFor[[]QueryHotkey](), stored, serialized)
+
+	var hotkeys []map[string]any
+	if err := json.Unmarshal([]byte(merged), &hotkeys); err != nil {
+		t.Fatalf("failed to decode merged value %s: %v", merged, err)
+	}
+	if hotkeys[0]["Future"] != float64(2) || hotkeys[1]["Future"] != float64(1) {
+		t.Fatalf("expected reordered hotkeys to keep their own unknown fields, got %s", merged)
+	}
+}
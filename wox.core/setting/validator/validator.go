@@ -21,6 +21,8 @@ type PluginSettingValidator struct {
 
 type PluginSettingValidatorValue interface {
 	GetValidatorType() PluginSettingValidatorType
+	// Validate checks a single stored setting value and returns a descriptive error when it is rejected
+	Validate(value string) error
 }
 
 func (p *PluginSettingValidator) UnmarshalJSON(b []byte) error {
@@ -1,5 +1,11 @@
 package validator
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type PluginSettingValidatorIsNumber struct {
 	IsInteger bool
 	IsFloat   bool
@@ -8,3 +14,17 @@ type PluginSettingValidatorIsNumber struct {
 func (p *PluginSettingValidatorIsNumber) GetValidatorType() PluginSettingValidatorType {
 	return PluginSettingValidatorTypeIsNumber
 }
+
+func (p *PluginSettingValidatorIsNumber) Validate(value string) error {
+	trimmed := strings.TrimSpace(value)
+	if p.IsInteger {
+		if _, err := strconv.ParseInt(trimmed, 10, 64); err != nil {
+			return fmt.Errorf("value %q must be an integer", value)
+		}
+	} else if p.IsFloat {
+		if _, err := strconv.ParseFloat(trimmed, 64); err != nil {
+			return fmt.Errorf("value %q must be a number", value)
+		}
+	}
+	return nil
+}
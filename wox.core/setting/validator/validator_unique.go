@@ -6,3 +6,8 @@ type PluginSettingValidatorUnique struct {
 func (p *PluginSettingValidatorUnique) GetValidatorType() PluginSettingValidatorType {
 	return PluginSettingValidatorTypeUnique
 }
+
+// Validate accepts every value because uniqueness is checked across table rows by the UI, not per value.
+func (p *PluginSettingValidatorUnique) Validate(value string) error {
+	return nil
+}
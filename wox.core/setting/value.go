@@ -1,10 +1,15 @@
 package setting
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 	"wox/util"
+
+	"gorm.io/gorm"
 )
 
 // ValidatorFunc is a function type for validating setting values
@@ -21,7 +26,10 @@ type SettingValue[T any] struct {
 	validator    ValidatorFunc[T]
 	syncable     bool
 	isLoaded     bool
-	mu           sync.RWMutex
+	// mergeBase is the value last read from or written to the store, kept for
+	// app data that persistMerging merges with changes from other machines.
+	mergeBase T
+	mu        sync.RWMutex
 }
 
 // local setting value. Don't set this value directly, use get,set instead
@@ -39,6 +47,8 @@ type WoxSettingValue[T any] struct {
 // The physical storage key of a platform setting is automatically suffixed with @windows, @darwin, or @linux based on the current platform.
 type PlatformValue[T any] struct {
 	*WoxSettingValue[T]
+	baseKey          string
+	platformDefaults map[util.Platform]T
 }
 
 type PluginSettingValue[T any] struct {
@@ -46,7 +56,7 @@ type PluginSettingValue[T any] struct {
 	pluginId string
 }
 
-func NewWoxSettingValue[T any](store *WoxSettingStore, key string, defaultValue T) *WoxSettingValue[T] {
+func NewWoxSettingValue[T any](store SettingStore, key string, defaultValue T) *WoxSettingValue[T] {
 	return &WoxSettingValue[T]{
 		SettingValue: &SettingValue[T]{
 			settingStore: store,
@@ -59,7 +69,7 @@ func NewWoxSettingValue[T any](store *WoxSettingStore, key string, defaultValue
 
 // NewLocalWoxSettingValue creates a Wox setting that is persisted only on the
 // current device and is excluded from cloud sync replication.
-func NewLocalWoxSettingValue[T any](store *WoxSettingStore, key string, defaultValue T) *WoxSettingValue[T] {
+func NewLocalWoxSettingValue[T any](store SettingStore, key string, defaultValue T) *WoxSettingValue[T] {
 	return &WoxSettingValue[T]{
 		SettingValue: &SettingValue[T]{
 			settingStore: store,
@@ -70,7 +80,7 @@ func NewLocalWoxSettingValue[T any](store *WoxSettingStore, key string, defaultV
 	}
 }
 
-func NewWoxSettingValueWithValidator[T any](store *WoxSettingStore, key string, defaultValue T, validator ValidatorFunc[T]) *WoxSettingValue[T] {
+func NewWoxSettingValueWithValidator[T any](store SettingStore, key string, defaultValue T, validator ValidatorFunc[T]) *WoxSettingValue[T] {
 	return &WoxSettingValue[T]{
 		SettingValue: &SettingValue[T]{
 			settingStore: store,
@@ -82,7 +92,7 @@ func NewWoxSettingValueWithValidator[T any](store *WoxSettingStore, key string,
 	}
 }
 
-func NewPlatformValue[T any](store *WoxSettingStore, key string, winValue T, macValue T, linuxValue T) *PlatformValue[T] {
+func NewPlatformValue[T any](store SettingStore, key string, winValue T, macValue T, linuxValue T) *PlatformValue[T] {
 	currentDefaultValue := linuxValue
 	if util.IsWindows() {
 		currentDefaultValue = winValue
@@ -94,7 +104,55 @@ func NewPlatformValue[T any](store *WoxSettingStore, key string, winValue T, mac
 	// inherited Get/Set methods operate on MainHotkey@darwin-style keys directly.
 	return &PlatformValue[T]{
 		WoxSettingValue: NewWoxSettingValue(store, PlatformSettingKey(key, util.GetCurrentPlatform()), currentDefaultValue),
+		baseKey:         key,
+		platformDefaults: map[util.Platform]T{
+			util.PlatformWindows: winValue,
+			util.PlatformMacOS:   macValue,
+			util.PlatformLinux:   linuxValue,
+		},
+	}
+}
+
+// GetForPlatform returns the value stored for platform p, or p's default when
+// nothing is stored. For the current platform this is the same as Get.
+func (v *PlatformValue[T]) GetForPlatform(p util.Platform) T {
+	if string(p) == util.GetCurrentPlatform() {
+		return v.Get()
+	}
+
+	defaultValue := v.platformDefaults[p]
+	if v.settingStore == nil {
+		return defaultValue
+	}
+
+	var value T
+	if err := v.settingStore.Get(PlatformSettingKey(v.baseKey, string(p)), &value); err != nil {
+		return defaultValue
+	}
+	if v.validator != nil && !v.validator(value) {
+		return defaultValue
+	}
+	return value
+}
+
+// SetForPlatform stores value for platform p. Values for other platforms are
+// only written to the store; they take effect when Wox runs on that platform.
+func (v *PlatformValue[T]) SetForPlatform(p util.Platform, value T) error {
+	if string(p) == util.GetCurrentPlatform() {
+		return v.Set(value)
+	}
+	if !util.IsSupportedPlatform(string(p)) {
+		return fmt.Errorf("unsupported platform: %s", p)
+	}
+	if v.settingStore == nil {
+		return fmt.Errorf("no store available")
+	}
+
+	key := PlatformSettingKey(v.baseKey, string(p))
+	if syncStore, ok := v.settingStore.(SyncableStore); ok {
+		return syncStore.SetWithSync(key, value, v.syncable)
 	}
+	return v.settingStore.Set(key, value)
 }
 
 // PlatformSettingKey builds the same physical key shape used by plugin platform settings.
@@ -148,10 +206,16 @@ func (v *SettingValue[T]) Get() T {
 	// Load from unified store
 	v.value = v.defaultValue // Start with default value
 	if v.settingStore != nil {
-		if err := v.settingStore.Get(v.key, &v.value); err != nil {
-			// Log error and keep default value
+		start := time.Now()
+		err := v.settingStore.Get(v.key, &v.value)
+		if err != nil {
+			// Keep default value; a missing row is the normal case for it.
 			v.value = v.defaultValue
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = nil
+			}
 		}
+		LogSettingOperation(util.NewTraceContext(), SettingOperationLoad, v.key, start, err)
 	}
 
 	// Apply validation if provided
@@ -159,39 +223,126 @@ func (v *SettingValue[T]) Get() T {
 		v.value = v.defaultValue
 	}
 
+	v.rememberMergeBase(v.value)
 	v.isLoaded = true
 	return v.value
 }
 
-// Set updates the value of the setting and persists it to the store.
+// Set updates the value of the setting and persists it to the store. Values
+// the validator rejects are not saved, the error wraps ErrInvalidSettingValue.
 func (v *SettingValue[T]) Set(newValue T) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	var err error
-	if v.settingStore != nil {
-		if syncStore, ok := v.settingStore.(SyncableStore); ok {
-			err = syncStore.SetWithSync(v.key, newValue, v.syncable)
-		} else {
-			err = v.settingStore.Set(v.key, newValue)
-		}
-	} else {
-		return fmt.Errorf("no store available")
+	if v.validator != nil && !v.validator(newValue) {
+		return fmt.Errorf("%w: %v is not accepted for %s", ErrInvalidSettingValue, newValue, v.key)
 	}
 
+	start := time.Now()
+	err := v.writeStore(newValue)
+	LogSettingOperation(util.NewTraceContext(), SettingOperationSave, v.key, start, err)
 	if err != nil {
 		return err
 	}
 
 	v.value = newValue
+	v.rememberMergeBase(newValue)
 	v.isLoaded = true
 	return nil
 }
 
+// setInMemory updates the cached value without touching the store. The caller
+// is responsible for calling persist later, see Manager.markAppDataDirty.
+func (v *SettingValue[T]) setInMemory(newValue T) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.value = newValue
+	v.isLoaded = true
+}
+
+// persist writes the cached value to the store.
+func (v *SettingValue[T]) persist() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.isLoaded {
+		return nil
+	}
+	return v.writeStore(v.value)
+}
+
+func (v *SettingValue[T]) writeStore(value T) error {
+	_, err := v.writeStoreSized(value)
+	return err
+}
+
+// writeStoreSized is writeStore that also returns the length of the stored
+// value. Only the database store reports it, other stores return 0.
+func (v *SettingValue[T]) writeStoreSized(value T) (int, error) {
+	if v.settingStore == nil {
+		return 0, fmt.Errorf("no store available")
+	}
+	storeValue := v.withUnknownFields(value)
+	if dbStore, ok := v.settingStore.(*WoxSettingStore); ok {
+		return dbStore.setWithSync(v.key, storeValue, v.syncable)
+	}
+	if syncStore, ok := v.settingStore.(SyncableStore); ok {
+		return 0, syncStore.SetWithSync(v.key, storeValue, v.syncable)
+	}
+	return 0, v.settingStore.Set(v.key, storeValue)
+}
+
+// withUnknownFields returns the value to store, re-adding struct fields from
+// the stored value that this build does not know. See preserveUnknownFields.
+func (v *SettingValue[T]) withUnknownFields(value T) any {
+	valueType := reflect.TypeFor[T]()
+	if !hasStructFields(valueType) {
+		return value
+	}
+
+	var stored string
+	if err := v.settingStore.Get(v.key, &stored); err != nil || stored == "" {
+		return value
+	}
+	serialized, err := SerializeValue(value)
+	if err != nil {
+		return value
+	}
+	return preserveUnknownFields(valueType, stored, serialized)
+}
+
+// checkStored decodes the stored row without touching the cached value. A
+// missing row is fine because the default applies; a row that cannot be
+// decoded or that fails validation is reported, since Get silently falls back
+// to the default for both.
+func (v *SettingValue[T]) checkStored() error {
+	if v.settingStore == nil {
+		return nil
+	}
+
+	var stored T
+	if err := v.settingStore.Get(v.key, &stored); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if v.validator != nil && !v.validator(stored) {
+		return errStoredValueInvalid
+	}
+	return nil
+}
+
 func (v *SettingValue[T]) Key() string {
 	return v.key
 }
 
+// hasValidator reports whether Set checks new values before saving them.
+func (v *SettingValue[T]) hasValidator() bool {
+	return v.validator != nil
+}
+
 func (v *SettingValue[T]) IsSyncable() bool {
 	return v.syncable
 }
@@ -204,11 +355,12 @@ func (v *SettingValue[T]) SetLocal(newValue T) error {
 		return fmt.Errorf("no store available")
 	}
 
-	if err := v.settingStore.Set(v.key, newValue); err != nil {
+	if err := v.settingStore.Set(v.key, v.withUnknownFields(newValue)); err != nil {
 		return err
 	}
 
 	v.value = newValue
+	v.rememberMergeBase(newValue)
 	v.isLoaded = true
 	return nil
 }
@@ -237,3 +389,11 @@ func (v *SettingValue[T]) DeleteLocal() error {
 	v.isLoaded = true
 	return nil
 }
+
+// unload drops the cached value so the next Get reads the store again.
+func (v *SettingValue[T]) unload() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.isLoaded = false
+}
@@ -0,0 +1,68 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"wox/util/screen"
+)
+
+// minVisibleWindowSize is how much of the launcher, measured from its top-left
+// corner, must stay on a screen for the user to be able to grab it.
+const minVisibleWindowSize = 100
+
+// ClampWindowPosition returns the saved launcher position, moved back onto
+// the nearest screen when it would be off-screen, e.g. after a monitor was
+// disconnected. The unset position (-1, -1) and an empty screen list are
+// returned unchanged.
+func (m *Manager) ClampWindowPosition(ctx context.Context, screens []screen.Rect) (x, y int) {
+	woxSetting := m.currentWoxSetting()
+	x, y = woxSetting.LastWindowX.Get(), woxSetting.LastWindowY.Get()
+	if x == -1 && y == -1 {
+		return x, y
+	}
+
+	clampedX, clampedY := clampPointToScreens(x, y, screens)
+	if clampedX != x || clampedY != y {
+		logger.Info(ctx, fmt.Sprintf("saved window position %d,%d is off-screen, moved to %d,%d", x, y, clampedX, clampedY))
+	}
+	return clampedX, clampedY
+}
+
+// IsWindowPositionVisible reports whether the launcher at x,y keeps enough of
+// itself on one of screens to be grabbed. Without any usable screen the
+// position cannot be judged and counts as visible.
+func IsWindowPositionVisible(x, y int, screens []screen.Rect) bool {
+	clampedX, clampedY := clampPointToScreens(x, y, screens)
+	return clampedX == x && clampedY == y
+}
+
+func clampPointToScreens(x, y int, screens []screen.Rect) (int, int) {
+	var nearest screen.Rect
+	nearestDistance := -1
+	for _, s := range screens {
+		if s.IsEmpty() {
+			continue
+		}
+
+		visibleRight := max(s.X, s.Right()-minVisibleWindowSize)
+		visibleBottom := max(s.Y, s.Bottom()-minVisibleWindowSize)
+		cx := min(max(x, s.X), visibleRight)
+		cy := min(max(y, s.Y), visibleBottom)
+		if cx == x && cy == y {
+			return x, y
+		}
+
+		distance := (cx-x)*(cx-x) + (cy-y)*(cy-y)
+		if nearestDistance == -1 || distance < nearestDistance {
+			nearest = s
+			nearestDistance = distance
+		}
+	}
+	if nearestDistance == -1 {
+		return x, y
+	}
+
+	visibleRight := max(nearest.X, nearest.Right()-minVisibleWindowSize)
+	visibleBottom := max(nearest.Y, nearest.Bottom()-minVisibleWindowSize)
+	return min(max(x, nearest.X), visibleRight), min(max(y, nearest.Y), visibleBottom)
+}
@@ -3,20 +3,23 @@ package setting
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"wox/common"
 	"wox/i18n"
 	"wox/util"
+	"wox/util/fuzzymatch"
 	"wox/util/locale"
 )
 
 type WoxSetting struct {
 	EnableAutostart      *PlatformValue[bool]
 	MainHotkey           *PlatformValue[string]
+	MainHotkeys          *PlatformValue[[]string] // see GetMainHotkeys
 	SelectionHotkey      *PlatformValue[string]
 	IgnoredHotkeyApps    *PlatformValue[[]IgnoredHotkeyApp]
 	LogLevel             *WoxSettingValue[string]
-	UsePinYin            *WoxSettingValue[bool]
+	PinYinMatchMode      *WoxSettingValue[PinYinMatchMode]
 	SwitchInputMethodABC *WoxSettingValue[bool]
 	HideOnStart          *WoxSettingValue[bool]
 	// OnboardingFinished records whether this user data directory has already
@@ -39,6 +42,22 @@ type WoxSetting struct {
 	CustomPythonPath   *PlatformValue[string]
 	CustomNodejsPath   *PlatformValue[string]
 
+	// AutoBackupIntervalHours and AutoBackupMaxCount control how often auto
+	// backups are taken and how many backups of any type are kept on disk.
+	// EncryptBackups encrypts new backups with the key kept in the OS keychain,
+	// so it is a local setting: another device cannot open those backups.
+	AutoBackupIntervalHours *WoxSettingValue[int]
+	AutoBackupMaxCount      *WoxSettingValue[int]
+	EncryptBackups          *WoxSettingValue[bool]
+
+	// AutostartReconcileMode decides which side wins at startup when
+	// EnableAutostart and the OS autostart entry disagree.
+	AutostartReconcileMode *WoxSettingValue[AutostartReconcileMode]
+
+	// ActiveProfile is the name of the settings profile in use, see
+	// SwitchProfile. It is local because profiles live on this device only.
+	ActiveProfile *WoxSettingValue[string]
+
 	// CloudSyncServerUrl is a local-only development override. It must not be
 	// synced because each device may target a different test server.
 	CloudSyncServerUrl       *WoxSettingValue[string]
@@ -65,6 +84,16 @@ type WoxSetting struct {
 	// Glance providers still return icons for metadata and future surfaces, but
 	// the launcher can render a quieter text-only accessory when users prefer it.
 	HideGlanceIcon *WoxSettingValue[bool]
+	// QueryModeSettings overrides MaxResultCount and ShowPosition per show
+	// source (e.g. "default" for the main hotkey, "selection" for the selection
+	// hotkey), see GetQueryModeConfig.
+	QueryModeSettings *WoxSettingValue[map[string]QueryModeConfig]
+	// RankingMode decides how action history boosts results, see
+	// GetActionedResultRankingScore.
+	RankingMode *WoxSettingValue[RankingMode]
+	// QueryDebounceMs delays input queries until typing pauses for this long,
+	// so fast typists do not start a plugin query per keystroke. 0 disables it.
+	QueryDebounceMs *WoxSettingValue[int]
 
 	// Development-only debug display switches. Score and performance tails were
 	// previously hard-coded around dev-only code paths, so storing the switches
@@ -80,14 +109,36 @@ type WoxSetting struct {
 	LastWindowX *WoxSettingValue[int]
 	LastWindowY *WoxSettingValue[int]
 
-	QueryHistories           *WoxSettingValue[[]QueryHistory]
 	QueryCompletionFeedbacks *WoxSettingValue[[]QueryCompletionFeedback]
 	PinedResults             *WoxSettingValue[*util.HashMap[ResultHash, bool]]
 	ActionedResults          *WoxSettingValue[*util.HashMap[ResultHash, []ActionedResult]]
+	// FavoriteLabels holds the names users gave to favorites, see SetFavoriteLabel.
+	FavoriteLabels *WoxSettingValue[*util.HashMap[ResultHash, string]]
+	// QueryHistoryExcludePatterns are regular expressions; submitted queries
+	// matching any of them are not added to the query history.
+	QueryHistoryExcludePatterns *WoxSettingValue[[]string]
+	// AppDataFormat selects how the app data above is stored, see
+	// SetAppDataFormat. It is local since it only describes this wox.db.
+	AppDataFormat *WoxSettingValue[AppDataFormat]
+	// MaxFavoriteResults caps PinedResults, so a plugin pinning results in a
+	// loop cannot grow app data without bound.
+	MaxFavoriteResults *WoxSettingValue[int]
 
 	// Anonymous usage statistics
 	EnableAnonymousUsageStats *WoxSettingValue[bool]
 
+	// DoNotDisturb hides notification popups while keeping them in the
+	// notification history. DoNotDisturbStart and DoNotDisturbEnd schedule a
+	// daily window (HH:MM, may span midnight) in which it applies automatically.
+	DoNotDisturb      *WoxSettingValue[bool]
+	DoNotDisturbStart *WoxSettingValue[string]
+	DoNotDisturbEnd   *WoxSettingValue[string]
+
+	// NotificationSound is "default", "silent" or a sound file path. It
+	// defaults to silent because notifications have always been silent
+	// overlays, turning sound on is left to the user.
+	NotificationSound *WoxSettingValue[string]
+
 	// IgnoredDoctorChecks stores doctor check types the user has dismissed.
 	// Ignored checks are skipped in the toolbar but still visible in the
 	// doctor query with an Unignore action.
@@ -102,6 +153,18 @@ type ReleaseChannel string
 
 type PositionType string
 
+type AutostartReconcileMode string
+
+// PinYinMatchMode is defined next to the matcher that consumes it.
+type PinYinMatchMode = fuzzymatch.PinYinMatchMode
+
+const (
+	PinYinMatchModeOff      = fuzzymatch.PinYinMatchModeOff
+	PinYinMatchModeFull     = fuzzymatch.PinYinMatchModeFull
+	PinYinMatchModeInitials = fuzzymatch.PinYinMatchModeInitials
+	PinYinMatchModeBoth     = fuzzymatch.PinYinMatchModeBoth
+)
+
 const (
 	PositionTypeMouseScreen  PositionType = "mouse_screen"
 	PositionTypeActiveScreen PositionType = "active_screen"
@@ -124,6 +187,16 @@ const (
 	UiDensityComfortable UiDensity = "comfortable"
 )
 
+const (
+	// AutostartReconcileModeTrustConfig re-applies EnableAutostart to the OS, e.g.
+	// after an antivirus removed the autostart entry.
+	AutostartReconcileModeTrustConfig AutostartReconcileMode = "trust_config"
+	// AutostartReconcileModeTrustOS updates EnableAutostart to the OS state.
+	AutostartReconcileModeTrustOS AutostartReconcileMode = "trust_os"
+	// AutostartReconcileModeAsk changes neither side and tells the user.
+	AutostartReconcileModeAsk AutostartReconcileMode = "ask"
+)
+
 const (
 	ReleaseChannelStable ReleaseChannel = "stable"
 	ReleaseChannelBeta   ReleaseChannel = "beta"
@@ -160,10 +233,16 @@ func (q *QueryShortcut) PlaceholderCount() int {
 }
 
 type AIProvider struct {
+	// Id is a stable identifier assigned when the provider is saved. Models
+	// reference it so several configs of the same provider can coexist.
+	Id     string
 	Name   common.ProviderName // see ai.ProviderName
 	Alias  string              // optional, used to distinguish multiple configs for the same provider
 	ApiKey string
 	Host   string
+
+	DefaultModel   string // optional, used when a chat request does not name a model
+	TimeoutSeconds int    // optional request timeout, 0 means no timeout
 }
 
 type QueryHotkey struct {
@@ -226,7 +305,9 @@ func (g GlanceRef) IsEmpty() bool {
 type ResultHash string
 
 func NewResultHash(pluginId, title, subTitle string) ResultHash {
-	return ResultHash(util.Md5([]byte(fmt.Sprintf("%s%s%s", pluginId, title, subTitle))))
+	hash := ResultHash(util.Md5([]byte(fmt.Sprintf("%s%s%s", pluginId, title, subTitle))))
+	recordResultHashSource(hash, ResultHashSource{PluginId: pluginId, Title: title, SubTitle: subTitle})
+	return hash
 }
 
 // NormalizeUiDensity converts missing or stale stored values to normal. The
@@ -259,14 +340,84 @@ func NormalizeReleaseChannel(value string) ReleaseChannel {
 	}
 }
 
+// ValidPositionTypes lists the window positions the UI can honor, in the order
+// they are offered in the settings dropdown.
+func ValidPositionTypes() []PositionType {
+	return []PositionType{PositionTypeMouseScreen, PositionTypeActiveScreen, PositionTypeLastLocation}
+}
+
+func IsValidPositionType(value PositionType) bool {
+	return slices.Contains(ValidPositionTypes(), value)
+}
+
+func IsValidAutostartReconcileMode(value AutostartReconcileMode) bool {
+	return value == AutostartReconcileModeTrustConfig || value == AutostartReconcileModeTrustOS || value == AutostartReconcileModeAsk
+}
+
 func IsValidReleaseChannel(value ReleaseChannel) bool {
 	return value == ReleaseChannelStable || value == ReleaseChannelBeta
 }
 
+const (
+	DefaultAutoBackupIntervalHours = 24
+	MaxAutoBackupIntervalHours     = 24 * 30
+	DefaultAutoBackupMaxCount      = 5
+	MaxAutoBackupMaxCount          = 100
+)
+
+func IsValidAutoBackupIntervalHours(value int) bool {
+	return value >= 1 && value <= MaxAutoBackupIntervalHours
+}
+
+func IsValidAutoBackupMaxCount(value int) bool {
+	return value >= 1 && value <= MaxAutoBackupMaxCount
+}
+
+const (
+	MinMaxResultCount = 1
+	MaxMaxResultCount = 100
+	// MinAppWidth keeps the query box and result tails usable. The upper bound
+	// depends on the screen Wox is shown on, see IsValidAppWidth.
+	MinAppWidth = 400
+)
+
+func IsValidMaxResultCount(value int) bool {
+	return value >= MinMaxResultCount && value <= MaxMaxResultCount
+}
+
+const MaxQueryDebounceMs = 1000
+
+func IsValidQueryDebounceMs(value int) bool {
+	return value >= 0 && value <= MaxQueryDebounceMs
+}
+
+const (
+	DefaultMaxFavoriteResults = 1000
+	MaxMaxFavoriteResults     = 100000
+)
+
+func IsValidMaxFavoriteResults(value int) bool {
+	return value >= 1 && value <= MaxMaxFavoriteResults
+}
+
+// IsValidAppWidth checks the width against MinAppWidth and, when known, the
+// screen width. A screenWidth of 0 skips the upper bound.
+func IsValidAppWidth(value int, screenWidth int) bool {
+	if value < MinAppWidth {
+		return false
+	}
+	return screenWidth <= 0 || value <= screenWidth
+}
+
 // ActionedResult stores the information of an actioned result.
 type ActionedResult struct {
 	Timestamp int64
 	Query     string // Record the raw query text when the user performs action on this result
+
+	// ActionId and ActionName identify the executed action. Both are empty for
+	// actions recorded before they were tracked.
+	ActionId   string
+	ActionName string // action name as shown to the user when it was executed
 }
 
 // QueryHistory stores the information of a query history.
@@ -284,42 +435,46 @@ type QueryCompletionFeedback struct {
 	LastAcceptedTimestamp int64
 }
 
-func NewWoxSetting(store *WoxSettingStore) *WoxSetting {
-	usePinYin := false
+func NewWoxSetting(store SettingStore) *WoxSetting {
+	pinYinMatchMode := PinYinMatchModeOff
 	defaultLangCode := i18n.LangCodeEnUs
 	switchInputMethodABC := false
 	if locale.IsZhCN() {
-		usePinYin = true
+		pinYinMatchMode = PinYinMatchModeBoth
 		switchInputMethodABC = true
 		defaultLangCode = i18n.LangCodeZhCn
 	}
 
 	return &WoxSetting{
 		MainHotkey:        NewPlatformValue(store, "MainHotkey", "alt+space", "cmd+space", "ctrl+space"),
+		MainHotkeys:       NewPlatformValue(store, "MainHotkeys", []string{"alt+space"}, []string{"cmd+space"}, []string{"ctrl+space"}),
 		SelectionHotkey:   NewPlatformValue(store, "SelectionHotkey", "ctrl+alt+space", "command+option+space", "ctrl+shift+j"),
 		IgnoredHotkeyApps: NewPlatformValue(store, "IgnoredHotkeyApps", []IgnoredHotkeyApp{}, []IgnoredHotkeyApp{}, []IgnoredHotkeyApp{}),
 		LogLevel: NewWoxSettingValueWithValidator(store, "LogLevel", LogLevelInfo, func(level string) bool {
 			return strings.EqualFold(level, LogLevelInfo) || strings.EqualFold(level, LogLevelDebug)
 		}),
-		UsePinYin:            NewWoxSettingValue(store, "UsePinYin", usePinYin),
+		PinYinMatchMode:      NewWoxSettingValueWithValidator(store, "PinYinMatchMode", pinYinMatchMode, fuzzymatch.IsValidPinYinMatchMode),
 		SwitchInputMethodABC: NewWoxSettingValue(store, "SwitchInputMethodABC", switchInputMethodABC),
 		ShowTray:             NewWoxSettingValue(store, "ShowTray", true),
 		HideOnLostFocus:      NewWoxSettingValue(store, "HideOnLostFocus", false),
 		HideOnStart:          NewWoxSettingValue(store, "HideOnStart", false),
 		OnboardingFinished:   NewWoxSettingValue(store, "OnboardingFinished", false),
 		LangCode: NewWoxSettingValueWithValidator(store, "LangCode", defaultLangCode, func(code i18n.LangCode) bool {
-			return i18n.IsSupportedLangCode(string(code))
+			return i18n.IsSupported(code)
 		}),
 		LaunchMode:                         NewWoxSettingValue(store, "LaunchMode", LaunchModeContinue),
 		StartPage:                          NewWoxSettingValue(store, "StartPage", StartPageMRU),
-		ShowPosition:                       NewWoxSettingValue(store, "ShowPosition", PositionTypeMouseScreen),
+		ShowPosition:                       NewWoxSettingValueWithValidator(store, "ShowPosition", PositionTypeMouseScreen, IsValidPositionType),
 		AppWidth:                           NewWoxSettingValue(store, "AppWidth", 750),
-		MaxResultCount:                     NewWoxSettingValue(store, "MaxResultCount", 8),
+		MaxResultCount:                     NewWoxSettingValueWithValidator(store, "MaxResultCount", 8, IsValidMaxResultCount),
 		UiDensity:                          NewWoxSettingValueWithValidator(store, "UiDensity", UiDensityNormal, IsValidUiDensity),
 		ThemeId:                            NewWoxSettingValue(store, "ThemeId", DefaultThemeId),
 		AppFontFamily:                      NewPlatformValue(store, "AppFontFamily", "", "", ""),
 		EnableQueryCompletionHint:          NewWoxSettingValue(store, "EnableQueryCompletionHint", false),
 		EnableGlance:                       NewWoxSettingValue(store, "EnableGlance", false),
+		QueryModeSettings:                  NewWoxSettingValueWithValidator(store, "QueryModeSettings", map[string]QueryModeConfig{}, IsValidQueryModeSettings),
+		RankingMode:                        NewWoxSettingValueWithValidator(store, "RankingMode", RankingModeHybrid, IsValidRankingMode),
+		QueryDebounceMs:                    NewWoxSettingValueWithValidator(store, "QueryDebounceMs", 0, IsValidQueryDebounceMs),
 		PrimaryGlance:                      NewWoxSettingValue(store, "PrimaryGlance", GlanceRef{PluginId: "e3ad9f18-fbbe-4f22-8c1b-8274c751f6e6", GlanceId: "time"}),
 		HideGlanceIcon:                     NewWoxSettingValue(store, "HideGlanceIcon", false),
 		ShowScoreTail:                      NewWoxSettingValue(store, "ShowScoreTail", false),
@@ -329,13 +484,18 @@ func NewWoxSetting(store *WoxSettingStore) *WoxSetting {
 		ShowPerformanceTailBackendPrepared: NewWoxSettingValue(store, "ShowPerformanceTailBackendPrepared", true),
 		ShowPerformanceTailUiReceived:      NewWoxSettingValue(store, "ShowPerformanceTailUiReceived", true),
 		EnableAutostart:                    NewPlatformValue(store, "EnableAutostart", false, false, false),
+		AutostartReconcileMode:             NewWoxSettingValueWithValidator(store, "AutostartReconcileMode", AutostartReconcileModeTrustConfig, IsValidAutostartReconcileMode),
 		HttpProxyEnabled:                   NewPlatformValue(store, "HttpProxyEnabled", false, false, false),
 		HttpProxyUrl:                       NewPlatformValue(store, "HttpProxyUrl", "", "", ""),
 		CustomPythonPath:                   NewPlatformValue(store, "CustomPythonPath", "", "", ""),
 		CustomNodejsPath:                   NewPlatformValue(store, "CustomNodejsPath", "", "", ""),
+		ActiveProfile:                      NewLocalWoxSettingValue(store, "ActiveProfile", DefaultProfileName),
 		CloudSyncServerUrl:                 NewLocalWoxSettingValue(store, "CloudSyncServerUrl", ""),
 		CloudSyncDisabledPlugins:           NewWoxSettingValue(store, "CloudSyncDisabledPlugins", []string{}),
 		EnableAutoBackup:                   NewWoxSettingValue(store, "EnableAutoBackup", true),
+		AutoBackupIntervalHours:            NewWoxSettingValueWithValidator(store, "AutoBackupIntervalHours", DefaultAutoBackupIntervalHours, IsValidAutoBackupIntervalHours),
+		AutoBackupMaxCount:                 NewWoxSettingValueWithValidator(store, "AutoBackupMaxCount", DefaultAutoBackupMaxCount, IsValidAutoBackupMaxCount),
+		EncryptBackups:                     NewLocalWoxSettingValue(store, "EncryptBackups", false),
 		EnableAutoUpdate:                   NewWoxSettingValue(store, "EnableAutoUpdate", true),
 		ReleaseChannel:                     NewWoxSettingValueWithValidator(store, "ReleaseChannel", ReleaseChannelStable, IsValidReleaseChannel),
 		LastWindowX:                        NewWoxSettingValue(store, "LastWindowX", -1),
@@ -344,11 +504,18 @@ func NewWoxSetting(store *WoxSettingStore) *WoxSetting {
 		QueryShortcuts:                     NewWoxSettingValue(store, "QueryShortcuts", []QueryShortcut{}),
 		TrayQueries:                        NewWoxSettingValue(store, "TrayQueries", []TrayQuery{}),
 		AIProviders:                        NewWoxSettingValue(store, "AIProviders", []AIProvider{}),
-		QueryHistories:                     NewWoxSettingValue(store, "QueryHistories", []QueryHistory{}),
 		QueryCompletionFeedbacks:           NewWoxSettingValue(store, "QueryCompletionFeedback", []QueryCompletionFeedback{}),
 		PinedResults:                       NewWoxSettingValue(store, "PinedResults", util.NewHashMap[ResultHash, bool]()),
 		ActionedResults:                    NewWoxSettingValue(store, "ActionedResults", util.NewHashMap[ResultHash, []ActionedResult]()),
+		FavoriteLabels:                     NewWoxSettingValue(store, "FavoriteLabels", util.NewHashMap[ResultHash, string]()),
+		QueryHistoryExcludePatterns:        NewWoxSettingValueWithValidator(store, "QueryHistoryExcludePatterns", []string{}, IsValidQueryHistoryExcludePatterns),
+		AppDataFormat:                      NewLocalWoxSettingValue(store, "AppDataFormat", AppDataFormatJSON),
+		MaxFavoriteResults:                 NewWoxSettingValueWithValidator(store, "MaxFavoriteResults", DefaultMaxFavoriteResults, IsValidMaxFavoriteResults),
 		EnableAnonymousUsageStats:          NewWoxSettingValue(store, "EnableAnonymousUsageStats", true),
 		IgnoredDoctorChecks:                NewWoxSettingValue(store, "IgnoredDoctorChecks", []string{}),
+		DoNotDisturb:                       NewWoxSettingValue(store, "DoNotDisturb", false),
+		DoNotDisturbStart:                  NewWoxSettingValueWithValidator(store, "DoNotDisturbStart", "", IsValidDoNotDisturbTime),
+		DoNotDisturbEnd:                    NewWoxSettingValueWithValidator(store, "DoNotDisturbEnd", "", IsValidDoNotDisturbTime),
+		NotificationSound:                  NewWoxSettingValueWithValidator(store, "NotificationSound", "silent", IsValidNotificationSound),
 	}
 }
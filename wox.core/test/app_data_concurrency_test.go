@@ -0,0 +1,65 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"wox/common"
+	"wox/setting"
+)
+
+// TestAppDataConcurrentWrites hammers the app data writers from many
+// goroutines. Run it with -race to catch unsynchronized access; without the
+// race detector it still catches lost updates from interleaved read-modify-write.
+func TestAppDataConcurrentWrites(t *testing.T) {
+	suite := NewTestSuite(t)
+	ctx := suite.ctx
+	manager := setting.GetSettingManager()
+
+	const goroutines = 20
+	const iterations = 10
+	runId := fmt.Sprintf("race-%d", time.Now().UnixNano())
+	pluginId := runId + "-plugin"
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				manager.AddQueryHistory(ctx, common.PlainQuery{
+					QueryType: "input",
+					QueryText: fmt.Sprintf("%s query %d-%d", runId, g, i),
+				})
+				manager.AddActionedResult(ctx, pluginId, "title", "subtitle", runId, "open")
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	histories := manager.GetLatestQueryHistory(ctx, setting.MaxQueryHistoryCount)
+	found := map[string]bool{}
+	for _, history := range histories {
+		found[history.Query.QueryText] = true
+	}
+	for g := 0; g < goroutines; g++ {
+		for i := 0; i < iterations; i++ {
+			queryText := fmt.Sprintf("%s query %d-%d", runId, g, i)
+			if !found[queryText] {
+				t.Fatalf("query history lost concurrent update: %s", queryText)
+			}
+		}
+	}
+
+	resultHash := setting.NewResultHash(pluginId, "title", "subtitle")
+	actionedResults, ok := manager.GetWoxSetting(ctx).ActionedResults.Get().Load(resultHash)
+	if !ok {
+		t.Fatalf("actioned result was not stored")
+	}
+	// AddActionedResult keeps the latest 100 actions per result.
+	expected := min(goroutines*iterations, 100)
+	if len(actionedResults) != expected {
+		t.Fatalf("expected %d actioned results, got %d", expected, len(actionedResults))
+	}
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 	"wox/database"
@@ -62,3 +63,90 @@ func TestPluginSettingStore_DeleteAll(t *testing.T) {
 		t.Fatalf("expected pluginB settings preserved, got %d rows", countB)
 	}
 }
+
+func openPluginSettingStoreTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "plugin_setting_test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get underlying sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&database.PluginSetting{}, &database.Oplog{}); err != nil {
+		t.Fatalf("failed to migrate schema: %v", err)
+	}
+	return db
+}
+
+func TestPluginSettingStore_NamespaceIsolation(t *testing.T) {
+	db := openPluginSettingStoreTestDB(t)
+	storeA := setting.NewPluginSettingStore(db, "pluginA")
+	storeB := setting.NewPluginSettingStore(db, "pluginB")
+
+	if err := storeA.Set("shared", "a"); err != nil {
+		t.Fatalf("failed to set pluginA shared: %v", err)
+	}
+
+	var value string
+	if err := storeB.Get("shared", &value); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected pluginB not to see pluginA's key, got value %q err %v", value, err)
+	}
+
+	if err := storeB.Set("shared", "b"); err != nil {
+		t.Fatalf("failed to set pluginB shared: %v", err)
+	}
+	if err := storeA.Get("shared", &value); err != nil || value != "a" {
+		t.Fatalf("expected pluginA value to stay a, got %q err %v", value, err)
+	}
+
+	if err := storeB.Delete("shared"); err != nil {
+		t.Fatalf("failed to delete pluginB shared: %v", err)
+	}
+	if err := storeB.DeleteWithSync("shared", true); err != nil {
+		t.Fatalf("failed to delete pluginB shared with sync: %v", err)
+	}
+	if err := storeA.Get("shared", &value); err != nil || value != "a" {
+		t.Fatalf("expected pluginA value to survive pluginB deletes, got %q err %v", value, err)
+	}
+
+	keys, err := storeB.Keys()
+	if err != nil {
+		t.Fatalf("failed to list pluginB keys: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("expected pluginB to have no keys, got %v", keys)
+	}
+}
+
+func TestPluginSettingStore_RejectsEmptyPluginId(t *testing.T) {
+	db := openPluginSettingStoreTestDB(t)
+	storeA := setting.NewPluginSettingStore(db, "pluginA")
+	if err := storeA.Set("shared", "a"); err != nil {
+		t.Fatalf("failed to set pluginA shared: %v", err)
+	}
+
+	// Without the guard, gorm drops the empty plugin id from the delete
+	// condition and removes "shared" from every plugin.
+	emptyStore := setting.NewPluginSettingStore(db, "")
+	if err := emptyStore.Set("shared", "x"); !errors.Is(err, setting.ErrPluginSettingStoreWithoutPluginId) {
+		t.Fatalf("expected Set to be rejected, got %v", err)
+	}
+	if err := emptyStore.Delete("shared"); !errors.Is(err, setting.ErrPluginSettingStoreWithoutPluginId) {
+		t.Fatalf("expected Delete to be rejected, got %v", err)
+	}
+	var value string
+	if err := emptyStore.Get("shared", &value); !errors.Is(err, setting.ErrPluginSettingStoreWithoutPluginId) {
+		t.Fatalf("expected Get to be rejected, got %v", err)
+	}
+
+	if err := storeA.Get("shared", &value); err != nil || value != "a" {
+		t.Fatalf("expected pluginA value to be untouched, got %q err %v", value, err)
+	}
+}
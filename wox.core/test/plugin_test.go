@@ -631,14 +631,14 @@ func TestFilePlugin_PinyinInitialSearch(t *testing.T) {
 	ctx := suite.ctx
 
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	previousUsePinyin := woxSetting.UsePinYin.Get()
-	// File search now honors the global UsePinYin setting, so this pinyin-specific
+	previousPinYinMatchMode := woxSetting.PinYinMatchMode.Get()
+	// File search now honors the global PinYinMatchMode setting, so this pinyin-specific
 	// integration case must opt in explicitly and then restore the shared setting.
-	if err := woxSetting.UsePinYin.Set(true); err != nil {
+	if err := woxSetting.PinYinMatchMode.Set(setting.PinYinMatchModeBoth); err != nil {
 		t.Fatalf("failed to enable pinyin setting: %v", err)
 	}
 	t.Cleanup(func() {
-		_ = woxSetting.UsePinYin.Set(previousUsePinyin)
+		_ = woxSetting.PinYinMatchMode.Set(previousPinYinMatchMode)
 	})
 
 	rootPath := newStableFileSearchRoot(t, "filesearch-pinyin-root")
@@ -114,7 +114,7 @@ func (suite *ConfigBlackboxTestSuite) testLoadWoxSettingBlackbox(t *testing.T) {
 			setupDB: func(db *gorm.DB) error {
 				// Insert invalid boolean value
 				return db.Create(&database.WoxSetting{
-					Key:   "UsePinYin",
+					Key:   "ShowTray",
 					Value: "not_a_boolean",
 				}).Error
 			},
@@ -140,7 +140,7 @@ func (suite *ConfigBlackboxTestSuite) testLoadWoxSettingBlackbox(t *testing.T) {
 			setupDB: func(db *gorm.DB) error {
 				// Insert some valid settings
 				settings := []database.WoxSetting{
-					{Key: "UsePinYin", Value: "true"},
+					{Key: "PinYinMatchMode", Value: "both"},
 					{Key: "ShowTray", Value: "false"},
 					{Key: "AppWidth", Value: "1200"},
 				}
@@ -319,7 +319,7 @@ func (suite *ConfigBlackboxTestSuite) testDatabaseCorruptionScenarios(t *testing
 
 			// Test basic operations don't panic
 			_ = woxSetting.AppWidth.Get()
-			_ = woxSetting.UsePinYin.Get()
+			_ = woxSetting.PinYinMatchMode.Get()
 			_ = woxSetting.MainHotkey.Get()
 
 			t.Logf("Corruption scenario %s handled gracefully", tc.name)
@@ -399,7 +399,7 @@ func (suite *ConfigBlackboxTestSuite) validateLoadedWoxSetting(t *testing.T, wox
 	t.Logf("Loaded setting values for %s:", testName)
 	t.Logf("  AppWidth: %d", woxSetting.AppWidth.Get())
 	t.Logf("  MaxResultCount: %d", woxSetting.MaxResultCount.Get())
-	t.Logf("  UsePinYin: %t", woxSetting.UsePinYin.Get())
+	t.Logf("  PinYinMatchMode: %s", woxSetting.PinYinMatchMode.Get())
 	t.Logf("  ShowTray: %t", woxSetting.ShowTray.Get())
 	t.Logf("  LangCode: %s", woxSetting.LangCode.Get())
 	t.Logf("  ThemeId: %s", woxSetting.ThemeId.Get())
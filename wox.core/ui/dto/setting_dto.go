@@ -8,10 +8,12 @@ import (
 type WoxSettingDto struct {
 	EnableAutostart      bool
 	MainHotkey           string
+	MainHotkeys          []string
 	SelectionHotkey      string
 	IgnoredHotkeyApps    []setting.IgnoredHotkeyApp
 	LogLevel             string
 	UsePinYin            bool
+	PinYinMatchMode      setting.PinYinMatchMode
 	SwitchInputMethodABC bool
 	HideOnStart          bool
 	// OnboardingFinished is sent with the regular settings DTO so Flutter can
@@ -36,13 +38,27 @@ type WoxSettingDto struct {
 	// show the Wayland double-modifier hotkey guidance prompt.
 	IsEvdevReadAvailable bool
 	EnableAutoBackup            bool
+	AutoBackupIntervalHours     int
+	AutoBackupMaxCount          int
+	EncryptBackups              bool
 	EnableAutoUpdate            bool
+	// IsAutoUpdatePolicyLocked reports that a machine policy forces auto update
+	// off, so the UI shows the toggle as read-only.
+	IsAutoUpdatePolicyLocked    bool
 	ReleaseChannel              setting.ReleaseChannel
 	EnableAnonymousUsageStats   bool
 	CustomPythonPath            string
 	CustomNodejsPath            string
 	CloudSyncServerUrl          string
 	CloudSyncDisabledPlugins    []string
+	AutostartReconcileMode      setting.AutostartReconcileMode
+	DoNotDisturb                bool
+	DoNotDisturbStart           string
+	DoNotDisturbEnd             string
+	NotificationSound           string
+	MaxFavoriteResults          int
+	QueryHistoryExcludePatterns []string
+	AppDataFormat               setting.AppDataFormat
 
 	// UI related
 	AppWidth       int
@@ -59,6 +75,11 @@ type WoxSettingDto struct {
 	// HideGlanceIcon is kept beside the Glance selection because Flutter needs
 	// it with the rest of the UI settings to render the query-box accessory.
 	HideGlanceIcon bool
+	// QueryModeSettings holds only the overrides; unset fields follow
+	// MaxResultCount and ShowPosition.
+	QueryModeSettings map[string]setting.QueryModeConfig
+	RankingMode       setting.RankingMode
+	QueryDebounceMs   int
 
 	// Debug display switches are only shown by the dev UI, but the DTO keeps
 	// them beside other settings so backend tail rendering and Flutter toggles
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -65,8 +66,8 @@ type uiLaunchConfig struct {
 }
 
 type Manager struct {
-	mainHotkey           *hotkey.Hotkey
-	mainHotkeyKey        string
+	mainHotkeys          map[string]*hotkey.Hotkey
+	mainHotkeyKeys       []string
 	selectionHotkey      *hotkey.Hotkey
 	selectionHotkeyKey   string
 	waylandPortalHotkeys *hotkey.Group
@@ -98,7 +99,6 @@ type Manager struct {
 func GetUIManager() *Manager {
 	managerOnce.Do(func() {
 		managerInstance = &Manager{}
-		managerInstance.mainHotkey = &hotkey.Hotkey{}
 		managerInstance.selectionHotkey = &hotkey.Hotkey{}
 		managerInstance.ui = &uiImpl{
 			requestMap:      util.NewHashMap[string, chan WebsocketMsg](),
@@ -161,6 +161,9 @@ func (m *Manager) Start(ctx context.Context) error {
 		m.themes.Store(theme.ThemeId, theme)
 	}
 
+	// A deleted custom theme would otherwise leave the UI without any theme.
+	m.ResolveTheme(ctx)
+
 	if util.IsDev() {
 		var onThemeChange = func(e fsnotify.Event) {
 			var themePath = e.Name
@@ -209,6 +212,9 @@ func (m *Manager) Start(ctx context.Context) error {
 		})
 	}
 
+	// Settings edited outside Wox (e.g. wox.db synced in place) get the same side effects as UI updates
+	setting.GetSettingManager().OnSettingChanged(m.PostSettingUpdate)
+
 	util.Go(ctx, "start store manager", func() {
 		GetStoreManager().Start(util.NewTraceContext())
 	})
@@ -249,54 +255,77 @@ func (m *Manager) Stop(ctx context.Context) {
 	}
 }
 
-func (m *Manager) RegisterMainHotkey(ctx context.Context, combineKey string) error {
+// RegisterMainHotkeys binds every main hotkey and releases the ones that are
+// no longer configured. New combinations are bound before anything is
+// released, so a failed bind leaves the previous hotkeys working.
+func (m *Manager) RegisterMainHotkeys(ctx context.Context, combineKeys []string) error {
+	combineKeys = setting.NormalizeMainHotkeys(combineKeys)
 	if shouldGroupWaylandPortalHotkeys() {
 		m.globalHotkeyMu.Lock()
 		defer m.globalHotkeyMu.Unlock()
 
-		if m.mainHotkeyKey == combineKey && m.waylandPortalHotkeys != nil {
-			logger.Info(ctx, fmt.Sprintf("main hotkey already registered: %s", combineKey))
+		if isSameMainHotkeyList(m.mainHotkeyKeys, combineKeys) && m.waylandPortalHotkeys != nil {
+			logger.Info(ctx, fmt.Sprintf("main hotkeys already registered: %s", strings.Join(combineKeys, ", ")))
 			return nil
 		}
 		woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-		return m.reregisterWaylandPortalGlobalHotkeys(ctx, combineKey, woxSetting.SelectionHotkey.Get(), woxSetting.QueryHotkeys.Get())
+		return m.reregisterWaylandPortalGlobalHotkeys(ctx, combineKeys, woxSetting.SelectionHotkey.Get(), woxSetting.QueryHotkeys.Get())
 	}
 
-	if combineKey == "" {
-		// remove hotkey
-		logger.Info(ctx, "remove main hotkey")
-		if m.mainHotkey != nil {
-			m.mainHotkey.Unregister(ctx)
-			m.mainHotkey = nil
-		}
-		m.mainHotkeyKey = ""
-		return nil
-	}
-	if m.mainHotkeyKey == combineKey && m.mainHotkey != nil {
-		logger.Info(ctx, fmt.Sprintf("main hotkey already registered: %s", combineKey))
+	if isSameMainHotkeyList(m.mainHotkeyKeys, combineKeys) {
+		logger.Info(ctx, fmt.Sprintf("main hotkeys already registered: %s", strings.Join(combineKeys, ", ")))
 		return nil
 	}
-	logger.Info(ctx, fmt.Sprintf("register main hotkey: %s", combineKey))
 
-	callback := func() {
-		m.handleMainHotkeyTrigger(combineKey)
-	}
+	// Registered hotkeys are keyed by setting.HotkeyConflictKey, so a hotkey
+	// stored in another spelling is not registered a second time.
+	added := map[string]*hotkey.Hotkey{}
+	for _, combineKey := range combineKeys {
+		if _, registered := m.mainHotkeys[setting.HotkeyConflictKey(combineKey)]; registered {
+			continue
+		}
+		logger.Info(ctx, fmt.Sprintf("register main hotkey: %s", combineKey))
 
-	newHotkey := &hotkey.Hotkey{}
-	registerErr := newHotkey.Register(ctx, combineKey, callback)
-	if registerErr != nil {
-		return registerErr
+		callback := func() {
+			m.handleMainHotkeyTrigger(combineKey)
+		}
+
+		newHotkey := &hotkey.Hotkey{}
+		if registerErr := newHotkey.Register(ctx, combineKey, callback); registerErr != nil {
+			for _, addedHotkey := range added {
+				addedHotkey.Unregister(ctx)
+			}
+			return fmt.Errorf("failed to register main hotkey %s: %w", combineKey, registerErr)
+		}
+		added[setting.HotkeyConflictKey(combineKey)] = newHotkey
 	}
 
-	oldHotkey := m.mainHotkey
-	m.mainHotkey = newHotkey
-	m.mainHotkeyKey = combineKey
-	if oldHotkey != nil {
-		oldHotkey.Unregister(ctx)
+	registered := make(map[string]*hotkey.Hotkey, len(combineKeys))
+	for _, combineKey := range combineKeys {
+		compareKey := setting.HotkeyConflictKey(combineKey)
+		if newHotkey, ok := added[compareKey]; ok {
+			registered[compareKey] = newHotkey
+		} else {
+			registered[compareKey] = m.mainHotkeys[compareKey]
+		}
 	}
+	for combineKey, oldHotkey := range m.mainHotkeys {
+		if _, keep := registered[combineKey]; !keep {
+			logger.Info(ctx, fmt.Sprintf("remove main hotkey: %s", combineKey))
+			oldHotkey.Unregister(ctx)
+		}
+	}
+	m.mainHotkeys = registered
+	m.mainHotkeyKeys = combineKeys
 	return nil
 }
 
+// isSameMainHotkeyList reports whether both lists hold the same hotkeys in the
+// same order, ignoring how each hotkey is spelled.
+func isSameMainHotkeyList(left []string, right []string) bool {
+	return slices.EqualFunc(left, right, isSameHotkey)
+}
+
 func effectiveSelectionHotkeyForRuntime(selectionHotkey string) string {
 	if util.IsLinuxWaylandSession() {
 		return ""
@@ -315,7 +344,7 @@ func (m *Manager) RegisterSelectionHotkey(ctx context.Context, combineKey string
 			return nil
 		}
 		woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-		return m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.MainHotkey.Get(), combineKey, woxSetting.QueryHotkeys.Get())
+		return m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.GetMainHotkeys(), combineKey, woxSetting.QueryHotkeys.Get())
 	}
 
 	if combineKey == "" {
@@ -355,9 +384,9 @@ func (m *Manager) RegisterSelectionHotkey(ctx context.Context, combineKey string
 
 // reregisterWaylandPortalGlobalHotkeys binds all Wox shortcuts in one portal
 // session whenever the Wayland GlobalShortcuts portal is the active backend.
-func (m *Manager) reregisterWaylandPortalGlobalHotkeys(ctx context.Context, mainHotkey string, selectionHotkey string, queryHotkeys []setting.QueryHotkey) error {
+func (m *Manager) reregisterWaylandPortalGlobalHotkeys(ctx context.Context, mainHotkeys []string, selectionHotkey string, queryHotkeys []setting.QueryHotkey) error {
 	previousGroup := m.waylandPortalHotkeys
-	previousMainHotkey := m.mainHotkeyKey
+	previousMainHotkeys := slices.Clone(m.mainHotkeyKeys)
 	previousSelectionHotkey := m.selectionHotkeyKey
 	previousQueryHotkeys := cloneQueryHotkeys(m.waylandPortalQueries)
 
@@ -366,15 +395,15 @@ func (m *Manager) reregisterWaylandPortalGlobalHotkeys(ctx context.Context, main
 		m.waylandPortalHotkeys = nil
 	}
 
-	newGroup, err := hotkey.RegisterGroup(ctx, m.buildWaylandPortalGlobalHotkeySpecs(mainHotkey, selectionHotkey, queryHotkeys))
+	newGroup, err := hotkey.RegisterGroup(ctx, m.buildWaylandPortalGlobalHotkeySpecs(mainHotkeys, selectionHotkey, queryHotkeys))
 	if err != nil {
 		if previousGroup != nil {
-			restoreGroup, restoreErr := hotkey.RegisterGroup(ctx, m.buildWaylandPortalGlobalHotkeySpecs(previousMainHotkey, previousSelectionHotkey, previousQueryHotkeys))
+			restoreGroup, restoreErr := hotkey.RegisterGroup(ctx, m.buildWaylandPortalGlobalHotkeySpecs(previousMainHotkeys, previousSelectionHotkey, previousQueryHotkeys))
 			if restoreErr != nil {
 				return fmt.Errorf("failed to register Wayland portal global hotkeys: %w; failed to restore previous hotkeys: %v", err, restoreErr)
 			}
 			m.waylandPortalHotkeys = restoreGroup
-			m.mainHotkeyKey = previousMainHotkey
+			m.mainHotkeyKeys = previousMainHotkeys
 			m.selectionHotkeyKey = previousSelectionHotkey
 			m.waylandPortalQueries = previousQueryHotkeys
 		}
@@ -391,9 +420,11 @@ func (m *Manager) reregisterWaylandPortalGlobalHotkeys(ctx context.Context, main
 	// due to platform limitations (e.g. missing evdev read access on Wayland);
 	// recording them as registered would mislead subsequent re-registrations
 	// and hide the skip from the user.
-	m.mainHotkeyKey = ""
-	if strings.TrimSpace(mainHotkey) != "" && registeredKeys[strings.TrimSpace(mainHotkey)] {
-		m.mainHotkeyKey = strings.TrimSpace(mainHotkey)
+	m.mainHotkeyKeys = nil
+	for _, mainHotkey := range setting.NormalizeMainHotkeys(mainHotkeys) {
+		if registeredKeys[mainHotkey] {
+			m.mainHotkeyKeys = append(m.mainHotkeyKeys, mainHotkey)
+		}
 	}
 	effectiveSelection := effectiveSelectionHotkeyForRuntime(selectionHotkey)
 	m.selectionHotkeyKey = ""
@@ -414,12 +445,10 @@ func (m *Manager) reregisterWaylandPortalGlobalHotkeys(ctx context.Context, main
 
 // buildWaylandPortalGlobalHotkeySpecs keeps every Wox shortcut in the same portal
 // bind request so the compositor treats Wox shortcuts as one lifecycle.
-func (m *Manager) buildWaylandPortalGlobalHotkeySpecs(mainHotkey string, selectionHotkey string, queryHotkeys []setting.QueryHotkey) []hotkey.Spec {
-	specs := make([]hotkey.Spec, 0, 2+len(queryHotkeys))
+func (m *Manager) buildWaylandPortalGlobalHotkeySpecs(mainHotkeys []string, selectionHotkey string, queryHotkeys []setting.QueryHotkey) []hotkey.Spec {
+	specs := make([]hotkey.Spec, 0, len(mainHotkeys)+1+len(queryHotkeys))
 
-	mainHotkey = strings.TrimSpace(mainHotkey)
-	if mainHotkey != "" {
-		combineKey := mainHotkey
+	for _, combineKey := range setting.NormalizeMainHotkeys(mainHotkeys) {
 		specs = append(specs, hotkey.Spec{
 			CombineKey: combineKey,
 			Callback: func() {
@@ -624,7 +653,7 @@ func (m *Manager) RegisterQueryHotkey(ctx context.Context, queryHotkey setting.Q
 			return nil
 		}
 		woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-		return m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.MainHotkey.Get(), woxSetting.SelectionHotkey.Get(), woxSetting.QueryHotkeys.Get())
+		return m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.GetMainHotkeys(), woxSetting.SelectionHotkey.Get(), woxSetting.QueryHotkeys.Get())
 	}
 
 	combineKey := strings.TrimSpace(queryHotkey.Hotkey)
@@ -685,13 +714,13 @@ func (m *Manager) reregisterGlobalHotkeys(ctx context.Context) {
 	if shouldGroupWaylandPortalHotkeys() {
 		m.globalHotkeyMu.Lock()
 		defer m.globalHotkeyMu.Unlock()
-		if err := m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.MainHotkey.Get(), effectiveSelectionHotkeyForRuntime(woxSetting.SelectionHotkey.Get()), woxSetting.QueryHotkeys.Get()); err != nil {
+		if err := m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.GetMainHotkeys(), effectiveSelectionHotkeyForRuntime(woxSetting.SelectionHotkey.Get()), woxSetting.QueryHotkeys.Get()); err != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to register Wayland portal global hotkeys: %s", err.Error()))
 		}
 		return
 	}
 
-	_ = m.RegisterMainHotkey(ctx, woxSetting.MainHotkey.Get())
+	_ = m.RegisterMainHotkeys(ctx, woxSetting.GetMainHotkeys())
 	_ = m.RegisterSelectionHotkey(ctx, woxSetting.SelectionHotkey.Get())
 	if err := m.reregisterIndividualQueryHotkeys(ctx, woxSetting.QueryHotkeys.Get()); err != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to register query hotkeys after global hotkey setting update: %s", err.Error()))
@@ -739,8 +768,10 @@ func (m *Manager) findConfiguredHotkeyConflict(ctx context.Context, hotkeyStr st
 	}
 
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(woxSetting.MainHotkey.Get())) {
-		return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeMain}
+	for _, mainHotkey := range woxSetting.GetMainHotkeys() {
+		if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(mainHotkey)) {
+			return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeMain}
+		}
 	}
 	if hotkeyCompareKeysIntersect(candidateKeys, hotkeyCompareKeys(effectiveSelectionHotkeyForRuntime(woxSetting.SelectionHotkey.Get()))) {
 		return HotkeyAvailability{Available: false, ConflictType: hotkeyConflictTypeSelection}
@@ -1107,9 +1138,26 @@ func (m *Manager) scheduleUIReadyMonitor(ctx context.Context, appPath string, pi
 	})
 }
 
-func (m *Manager) GetCurrentTheme(ctx context.Context) common.Theme {
+// ResolveTheme returns the configured ThemeId if that theme is installed.
+// Otherwise, e.g. after the custom theme was deleted outside Wox, it logs a
+// warning, switches the setting back to the built-in default theme and
+// returns that one.
+func (m *Manager) ResolveTheme(ctx context.Context) string {
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if v, ok := m.themes.Load(woxSetting.ThemeId.Get()); ok {
+	themeId := woxSetting.ThemeId.Get()
+	if _, ok := m.themes.Load(themeId); ok || themeId == setting.DefaultThemeId {
+		return themeId
+	}
+
+	logger.Warn(ctx, fmt.Sprintf("configured theme %s is not installed, falling back to the default theme", themeId))
+	if err := woxSetting.ThemeId.Set(setting.DefaultThemeId); err != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to reset theme to default: %s", err.Error()))
+	}
+	return setting.DefaultThemeId
+}
+
+func (m *Manager) GetCurrentTheme(ctx context.Context) common.Theme {
+	if v, ok := m.themes.Load(m.ResolveTheme(ctx)); ok {
 		// If it's an auto appearance theme, return the actual applied theme (light or dark)
 		if v.IsAutoAppearance {
 			return m.getActualTheme(ctx, v)
@@ -1429,6 +1477,7 @@ func (m *Manager) PostOnHide(ctx context.Context) {
 		impl.isInOnboardingView = false
 		impl.isRecordingHotkey = false
 	}
+	endQueryDebounceSession(util.GetContextSessionId(ctx))
 	m.releaseHiddenCoreMemory(ctx)
 }
 
@@ -1567,9 +1616,9 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key string, value strin
 		} else {
 			m.HideTray()
 		}
-	case "MainHotkey":
-		if err := m.RegisterMainHotkey(ctx, vs); err != nil {
-			logger.Error(ctx, fmt.Sprintf("failed to update main hotkey: %s", err.Error()))
+	case "MainHotkey", "MainHotkeys":
+		if err := m.RegisterMainHotkeys(ctx, setting.GetSettingManager().GetWoxSetting(ctx).GetMainHotkeys()); err != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to update main hotkeys: %s", err.Error()))
 		}
 	case "SelectionHotkey":
 		if err := m.RegisterSelectionHotkey(ctx, vs); err != nil {
@@ -1582,7 +1631,7 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key string, value strin
 			m.globalHotkeyMu.Lock()
 			defer m.globalHotkeyMu.Unlock()
 			woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-			if err := m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.MainHotkey.Get(), woxSetting.SelectionHotkey.Get(), woxSetting.QueryHotkeys.Get()); err != nil {
+			if err := m.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.GetMainHotkeys(), woxSetting.SelectionHotkey.Get(), woxSetting.QueryHotkeys.Get()); err != nil {
 				logger.Error(ctx, fmt.Sprintf("failed to update Wayland portal query hotkeys: %s", err.Error()))
 			}
 			return
@@ -1598,11 +1647,27 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key string, value strin
 			m.refreshTrayQueryIcons(ctx)
 		}
 	case "LangCode":
-		langCode := vs
-		langErr := i18n.GetI18nManager().UpdateLang(ctx, i18n.LangCode(langCode))
-		if langErr != nil {
-			logger.Error(ctx, fmt.Sprintf("failed to update lang: %s", langErr.Error()))
+		// The settings UI already switched the language while saving; other
+		// sources such as reset or cloud sync saved it before getting here.
+		langCode := i18n.LangCode(vs)
+		if i18n.GetI18nManager().GetCurrentLangCode() != langCode {
+			langErr := i18n.GetI18nManager().UpdateLang(ctx, langCode)
+			if langErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to update lang: %s", langErr.Error()))
+			}
+		}
+	case setting.SystemLangCodeChangedKey:
+		langName := vs
+		for _, lang := range i18n.GetSupportedLanguages() {
+			if string(lang.Code) == vs {
+				langName = lang.Name
+			}
 		}
+		m.GetUI(ctx).Notify(ctx, common.NotifyMsg{
+			Icon:           common.WoxIcon.String(),
+			Text:           fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "ui_system_language_changed_notify"), langName),
+			DisplaySeconds: 8,
+		})
 	case "EnableAutostart":
 		enabled := vb
 		err := autostart.SetAutostart(ctx, enabled)
@@ -1611,7 +1676,17 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key string, value strin
 		}
 	case "EnableAutoUpdate":
 		updater.CheckForUpdatesWithCallback(ctx, nil)
+	case "HttpProxyEnabled", "HttpProxyUrl":
+		woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
+		if woxSetting.HttpProxyEnabled.Get() {
+			util.UpdateHTTPProxy(ctx, woxSetting.HttpProxyUrl.Get())
+		} else {
+			util.UpdateHTTPProxy(ctx, "")
+		}
+	case "AutoBackupIntervalHours":
+		setting.GetSettingManager().RescheduleAutoBackup(ctx)
 	case "AIProviders":
+		plugin.GetPluginManager().ClearAIProviderCache(ctx)
 		plugin.GetPluginManager().GetUI().ReloadChatResources(ctx, "models")
 	}
 }
@@ -2104,10 +2179,18 @@ func clampInt(v int, min int, max int) int {
 	return v
 }
 
+// exitShutdownTimeout bounds how long ExitApp waits for running migrations.
+const exitShutdownTimeout = 10 * time.Second
+
 func (m *Manager) ExitApp(ctx context.Context) {
 	m.exitOnce.Do(func() {
 		util.GetLogger().Info(ctx, "start quitting")
+		// Let running migrations reach a safe point before the process exits.
+		util.RequestShutdown(ctx, exitShutdownTimeout)
 		plugin.GetPluginManager().Stop(ctx)
+		if err := setting.GetSettingManager().Flush(ctx); err != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("failed to flush app data: %s", err.Error()))
+		}
 		m.Stop(ctx)
 		diagnostic.GetManager().MarkCleanExit(ctx)
 		util.GetLogger().Info(ctx, "bye~")
@@ -2402,6 +2485,12 @@ func (m *Manager) ProcessDeeplink(ctx context.Context, deeplink string) {
 // This includes creating the new directory structure and copying necessary data
 func (m *Manager) ChangeUserDataDirectory(ctx context.Context, newDirectory string) error {
 	location := util.GetLocation()
+	if location.IsPortable() {
+		return fmt.Errorf("user data directory cannot be changed in portable mode")
+	}
+	if location.IsDataDirectoryFromEnv() {
+		return fmt.Errorf("user data directory is set by the %s environment variable", util.DataDirEnv)
+	}
 	oldDirectory := location.GetUserDataDirectory()
 
 	// check if new directory is valid
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"context"
+	"time"
+	"wox/util"
+)
+
+// latestInputQueryIds keeps the newest input query id per session, so a
+// debounced query can tell whether the user kept typing while it waited.
+var latestInputQueryIds = util.NewHashMap[string, string]()
+
+// waitForQueryDebounce records queryId as the newest input query of the
+// session and waits delayMs for typing to settle. It returns false when a
+// newer query of the same session arrived meanwhile, or the request was
+// cancelled, in which case the query should be dropped.
+func waitForQueryDebounce(ctx context.Context, sessionId string, queryId string, delayMs int) bool {
+	if sessionId == "" {
+		return true
+	}
+	latestInputQueryIds.Store(sessionId, queryId)
+	if delayMs <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(time.Duration(delayMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+
+	latestQueryId, _ := latestInputQueryIds.Load(sessionId)
+	return latestQueryId == queryId
+}
+
+// endQueryDebounceSession forgets the newest input query of a session once it
+// ended, e.g. the launcher was hidden. Queries of the session that are still
+// waiting for typing to settle are dropped.
+func endQueryDebounceSession(sessionId string) {
+	if sessionId == "" {
+		return
+	}
+	latestInputQueryIds.Delete(sessionId)
+}
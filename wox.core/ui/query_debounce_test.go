@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitForQueryDebounceDropsSupersededQuery(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() { endQueryDebounceSession("debounce-session") })
+
+	first := make(chan bool)
+	go func() {
+		first <- waitForQueryDebounce(ctx, "debounce-session", "query-1", 100)
+	}()
+	// Let the first query start waiting before the user "types" again.
+	time.Sleep(20 * time.Millisecond)
+
+	if !waitForQueryDebounce(ctx, "debounce-session", "query-2", 100) {
+		t.Fatalf("expected the newest query to run")
+	}
+	if <-first {
+		t.Fatalf("expected the first query to be dropped once a newer one arrived")
+	}
+}
+
+func TestWaitForQueryDebounceDropsQueriesOfEndedSession(t *testing.T) {
+	ctx := context.Background()
+
+	pending := make(chan bool)
+	go func() {
+		pending <- waitForQueryDebounce(ctx, "ended-session", "query-1", 100)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	endQueryDebounceSession("ended-session")
+
+	if <-pending {
+		t.Fatalf("expected the pending query of an ended session to be dropped")
+	}
+	if _, found := latestInputQueryIds.Load("ended-session"); found {
+		t.Fatalf("expected the ended session to be forgotten")
+	}
+}
+
+func TestWaitForQueryDebounceStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	t.Cleanup(func() { endQueryDebounceSession("cancelled-session") })
+
+	if waitForQueryDebounce(ctx, "cancelled-session", "query-1", 1000) {
+		t.Fatalf("expected a cancelled query to be dropped")
+	}
+}
@@ -1,15 +1,18 @@
 package ui
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,7 +33,10 @@ import (
 	"wox/updater"
 	"wox/util"
 	"wox/util/font"
+	"wox/util/fuzzymatch"
+	"wox/util/hotkey"
 	"wox/util/keyboard"
+	"wox/util/notifier"
 	"wox/util/overlay"
 	"wox/util/permission"
 	"wox/util/processmemory"
@@ -64,10 +70,20 @@ var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	"/theme/uninstall": handleThemeUninstall,
 	"/theme/apply":     handleThemeApply,
 	"/theme/save":      handleThemeSave,
+	"/theme/export":    handleThemeExport,
+	"/theme/import":    handleThemeImport,
 
 	// settings
 	"/setting/wox":                      handleSettingWox,
 	"/setting/wox/update":               handleSettingWoxUpdate,
+	"/setting/wox/reset":                handleSettingWoxReset,
+	"/setting/wox/metadata":             handleSettingWoxMetadata,
+	"/setting/wox/reset_all":            handleSettingWoxResetAll,
+	"/setting/appdata/compact":          handleSettingAppDataCompact,
+	"/setting/profile/list":             handleSettingProfileList,
+	"/setting/profile/create":           handleSettingProfileCreate,
+	"/setting/profile/switch":           handleSettingProfileSwitch,
+	"/setting/favorite/label":           handleSettingFavoriteLabel,
 	"/setting/hotkey/apps":              handleHotkeyAppCandidates,
 	"/setting/window-manager/displays":  handleWindowManagerDisplays,
 	"/browser/extension/status":         handleBrowserExtensionStatus,
@@ -76,6 +92,7 @@ var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	"/setting/userdata/location":        handleUserDataLocation,
 	"/setting/userdata/location/update": handleUserDataLocationUpdate,
 	"/setting/position":                 handleSaveWindowPosition,
+	"/setting/diagnose":                 handleSettingDiagnose,
 	"/runtime/status":                   handleRuntimeStatus,
 	"/runtime/restart":                  handleRuntimeRestart,
 	"/account/status":                   handleAccountStatus,
@@ -156,6 +173,8 @@ var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	"/backup/restore":                     handleBackupRestore,
 	"/backup/all":                         handleBackupAll,
 	"/backup/folder":                      handleBackupFolder,
+	"/setting/bundle/export":              handleSettingBundleExport,
+	"/setting/bundle/import":              handleSettingBundleImport,
 	"/log/clear":                          handleLogClear,
 	"/log/open":                           handleLogOpen,
 	"/diagnostics/status":                 handleDiagnosticsStatus,
@@ -165,6 +184,7 @@ var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	"/diagnostics/export":                 handleDiagnosticsExport,
 	"/hotkey/available":                   handleHotkeyAvailable,
 	"/hotkey/availability":                handleHotkeyAvailability,
+	"/hotkey/conflicts":                   handleHotkeyConflicts,
 	"/glance":                             handleGlance,
 	"/glance/action":                      handleGlanceAction,
 	"/updater/channel/versions":           handleUpdateChannelVersions,
@@ -628,6 +648,7 @@ func handlePluginUninstall(w http.ResponseWriter, r *http.Request) {
 }
 
 func handlePluginDisable(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
 	body, _ := io.ReadAll(r.Body)
 	idResult := gjson.GetBytes(body, "id")
 	if !idResult.Exists() {
@@ -635,25 +656,15 @@ func handlePluginDisable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pluginId := idResult.String()
-
-	plugins := plugin.GetPluginManager().GetPluginInstances()
-	findPlugin, exist := lo.Find(plugins, func(item *plugin.Instance) bool {
-		if item.Metadata.Id == pluginId {
-			return true
-		}
-		return false
-	})
-	if !exist {
-		writeErrorResponse(w, "can't find plugin")
+	if err := plugin.GetPluginManager().DisablePlugin(ctx, idResult.String()); err != nil {
+		writeErrorResponse(w, err.Error())
 		return
 	}
-
-	findPlugin.Setting.Disabled.Set(true)
 	writeSuccessResponse(w, "")
 }
 
 func handlePluginEnable(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
 	body, _ := io.ReadAll(r.Body)
 	idResult := gjson.GetBytes(body, "id")
 	if !idResult.Exists() {
@@ -661,18 +672,10 @@ func handlePluginEnable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pluginId := idResult.String()
-
-	plugins := plugin.GetPluginManager().GetPluginInstances()
-	findPlugin, exist := lo.Find(plugins, func(item *plugin.Instance) bool {
-		return item.Metadata.Id == pluginId
-	})
-	if !exist {
-		writeErrorResponse(w, "can't find plugin")
+	if err := plugin.GetPluginManager().EnablePlugin(ctx, idResult.String()); err != nil {
+		writeErrorResponse(w, err.Error())
 		return
 	}
-
-	findPlugin.Setting.Disabled.Set(false)
 	writeSuccessResponse(w, "")
 }
 
@@ -892,17 +895,60 @@ func handleThemeSave(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, theme)
 }
 
+// handleThemeExport returns the shareable file content of an installed theme.
+func handleThemeExport(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	idResult := gjson.GetBytes(body, "id")
+	if !idResult.Exists() {
+		writeErrorResponse(w, "id is empty")
+		return
+	}
+
+	var content bytes.Buffer
+	if err := GetStoreManager().ExportTheme(ctx, idResult.String(), &content); err != nil {
+		writeErrorResponse(w, "can't export theme: "+err.Error())
+		return
+	}
+
+	writeSuccessResponse(w, content.String())
+}
+
+// handleThemeImport installs a theme file shared by another user and returns its new id.
+func handleThemeImport(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	contentResult := gjson.GetBytes(body, "content")
+	if !contentResult.Exists() || strings.TrimSpace(contentResult.String()) == "" {
+		writeErrorResponse(w, "content is empty")
+		return
+	}
+
+	themeId, err := GetStoreManager().ImportTheme(ctx, strings.NewReader(contentResult.String()))
+	if err != nil {
+		writeErrorResponse(w, "can't import theme: "+err.Error())
+		return
+	}
+
+	writeSuccessResponse(w, themeId)
+}
+
 func handleSettingWox(w http.ResponseWriter, r *http.Request) {
 	ctx := getTraceContext(r)
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
 
 	var settingDto dto.WoxSettingDto
 	settingDto.EnableAutostart = woxSetting.EnableAutostart.Get()
+	settingDto.AutostartReconcileMode = woxSetting.AutostartReconcileMode.Get()
 	settingDto.MainHotkey = woxSetting.MainHotkey.Get()
+	settingDto.MainHotkeys = woxSetting.GetMainHotkeys()
 	settingDto.SelectionHotkey = woxSetting.SelectionHotkey.Get()
 	settingDto.IgnoredHotkeyApps = woxSetting.IgnoredHotkeyApps.Get()
 	settingDto.LogLevel = util.NormalizeLogLevel(woxSetting.LogLevel.Get())
-	settingDto.UsePinYin = woxSetting.UsePinYin.Get()
+	settingDto.UsePinYin = woxSetting.PinYinMatchMode.Get() != setting.PinYinMatchModeOff
+	settingDto.PinYinMatchMode = woxSetting.PinYinMatchMode.Get()
 	settingDto.SwitchInputMethodABC = woxSetting.SwitchInputMethodABC.Get()
 	settingDto.HideOnStart = woxSetting.HideOnStart.Get()
 	settingDto.OnboardingFinished = woxSetting.OnboardingFinished.Get()
@@ -921,13 +967,24 @@ func handleSettingWox(w http.ResponseWriter, r *http.Request) {
 	settingDto.IsLinuxWaylandSession = util.IsLinuxWaylandSession()
 	settingDto.IsEvdevReadAvailable = keyboard.IsEvdevReadAvailable()
 	settingDto.EnableAutoBackup = woxSetting.EnableAutoBackup.Get()
-	settingDto.EnableAutoUpdate = woxSetting.EnableAutoUpdate.Get()
+	settingDto.AutoBackupIntervalHours = woxSetting.AutoBackupIntervalHours.Get()
+	settingDto.AutoBackupMaxCount = woxSetting.AutoBackupMaxCount.Get()
+	settingDto.EncryptBackups = woxSetting.EncryptBackups.Get()
+	settingDto.EnableAutoUpdate = woxSetting.IsAutoUpdateEnabled()
+	settingDto.IsAutoUpdatePolicyLocked = setting.GetAutoUpdatePolicy().Locked
 	settingDto.ReleaseChannel = woxSetting.ReleaseChannel.Get()
 	settingDto.EnableAnonymousUsageStats = woxSetting.EnableAnonymousUsageStats.Get()
 	settingDto.CustomPythonPath = woxSetting.CustomPythonPath.Get()
 	settingDto.CustomNodejsPath = woxSetting.CustomNodejsPath.Get()
 	settingDto.CloudSyncServerUrl = woxSetting.CloudSyncServerUrl.Get()
 	settingDto.CloudSyncDisabledPlugins = woxSetting.CloudSyncDisabledPlugins.Get()
+	settingDto.DoNotDisturb = woxSetting.DoNotDisturb.Get()
+	settingDto.DoNotDisturbStart = woxSetting.DoNotDisturbStart.Get()
+	settingDto.DoNotDisturbEnd = woxSetting.DoNotDisturbEnd.Get()
+	settingDto.NotificationSound = woxSetting.NotificationSound.Get()
+	settingDto.MaxFavoriteResults = woxSetting.MaxFavoriteResults.Get()
+	settingDto.QueryHistoryExcludePatterns = woxSetting.QueryHistoryExcludePatterns.Get()
+	settingDto.AppDataFormat = woxSetting.AppDataFormat.Get()
 
 	settingDto.AppWidth = woxSetting.AppWidth.Get()
 	settingDto.MaxResultCount = woxSetting.MaxResultCount.Get()
@@ -938,6 +995,9 @@ func handleSettingWox(w http.ResponseWriter, r *http.Request) {
 	settingDto.EnableGlance = woxSetting.EnableGlance.Get()
 	settingDto.PrimaryGlance = woxSetting.PrimaryGlance.Get()
 	settingDto.HideGlanceIcon = woxSetting.HideGlanceIcon.Get()
+	settingDto.QueryModeSettings = woxSetting.QueryModeSettings.Get()
+	settingDto.RankingMode = woxSetting.RankingMode.Get()
+	settingDto.QueryDebounceMs = woxSetting.QueryDebounceMs.Get()
 	settingDto.ShowScoreTail = woxSetting.ShowScoreTail.Get()
 	settingDto.ShowPerformanceTail = woxSetting.ShowPerformanceTail.Get()
 	settingDto.ShowPerformanceTailBatch = woxSetting.ShowPerformanceTailBatch.Get()
@@ -948,6 +1008,123 @@ func handleSettingWox(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, settingDto)
 }
 
+// handleSettingWoxReset resets a platform-scoped setting to its default on the
+// current platform only, leaving the values saved on other platforms untouched.
+func handleSettingWoxReset(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	keyResult := gjson.GetBytes(body, "Key")
+	if !keyResult.Exists() || keyResult.String() == "" {
+		writeErrorResponse(w, "key is empty")
+		return
+	}
+
+	key := keyResult.String()
+	oldValue, _ := setting.GetSettingManager().SerializedWoxSettingValue(key)
+	value, err := setting.GetSettingManager().ResetToDefault(ctx, key)
+	if err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	setting.GetSettingManager().RecordSettingAudit(ctx, key, oldValue, value, setting.SettingAuditSourceReset)
+
+	GetUIManager().PostSettingUpdate(ctx, key, value)
+	writeSuccessResponse(w, value)
+}
+
+// handleSettingWoxResetAll resets every Wox setting to its default, optionally
+// keeping query history and favorites.
+func handleSettingWoxResetAll(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	keepHistory := gjson.GetBytes(body, "KeepHistory").Bool()
+	if err := setting.GetSettingManager().ResetToDefaults(ctx, keepHistory); err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	writeSuccessResponse(w, "")
+}
+
+func handleSettingProfileList(w http.ResponseWriter, r *http.Request) {
+	profiles, err := setting.GetSettingManager().ListProfiles(getTraceContext(r))
+	if err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	writeSuccessResponse(w, profiles)
+}
+
+// handleSettingProfileCreate saves a copy of the current settings as a new profile.
+func handleSettingProfileCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	if err := setting.GetSettingManager().CreateProfile(ctx, gjson.GetBytes(body, "Name").String()); err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	writeSuccessResponse(w, "")
+}
+
+// handleSettingProfileSwitch replaces the current settings with a saved profile.
+func handleSettingProfileSwitch(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	if err := setting.GetSettingManager().SwitchProfile(ctx, gjson.GetBytes(body, "Name").String()); err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	writeSuccessResponse(w, "")
+}
+
+// handleSettingFavoriteLabel names a favorite, an empty Label removes the name.
+func handleSettingFavoriteLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	hash := setting.ResultHash(gjson.GetBytes(body, "Hash").String())
+	if err := setting.GetSettingManager().SetFavoriteLabelByHash(ctx, hash, gjson.GetBytes(body, "Label").String()); err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	writeSuccessResponse(w, "")
+}
+
+// handleSettingWoxMetadata returns the type and validation needs of a Wox
+// setting key, so callers can check a key before updating it.
+func handleSettingWoxMetadata(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	key := gjson.GetBytes(body, "Key").String()
+	meta, ok := setting.GetSettingManager().SettingMetadata(key)
+	if !ok {
+		writeErrorResponse(w, setting.NewUnknownSettingKeyError(key).Error())
+		return
+	}
+	writeSuccessResponse(w, meta)
+}
+
+// handleSettingAppDataCompact removes actioned results unused for MaxAgeDays,
+// defaulting to the startup threshold, and returns how many were removed.
+func handleSettingAppDataCompact(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+
+	body, _ := io.ReadAll(r.Body)
+	maxAgeDays := setting.DefaultAppDataMaxAgeDays
+	if maxAgeDaysResult := gjson.GetBytes(body, "MaxAgeDays"); maxAgeDaysResult.Exists() {
+		maxAgeDays = int(maxAgeDaysResult.Int())
+	}
+
+	removed, err := setting.GetSettingManager().CompactAppData(ctx, maxAgeDays)
+	if err != nil {
+		writeErrorResponse(w, err.Error())
+		return
+	}
+	writeSuccessResponse(w, removed)
+}
+
 func handleHotkeyAppCandidates(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, appplugin.GetHotkeyAppCandidates(getTraceContext(r)))
 }
@@ -992,6 +1169,9 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 	type keyValuePair struct {
 		Key   string
 		Value string
+		// RejectHotkeyConflict lets callers refuse a hotkey that is already used
+		// by another Wox hotkey setting instead of silently shadowing it.
+		RejectHotkeyConflict bool
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -1004,10 +1184,33 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 
 	ctx := getTraceContext(r)
 	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
+
+	// Several keys return early below, so the audit entry compares the stored
+	// value before and after the request instead of hooking every branch.
+	// The same goes for the operation log, which takes the error from the
+	// recorder that writeSettingUpdateErrorResponse fills in.
+	start := time.Now()
+	recorder := &settingUpdateRecorder{ResponseWriter: w}
+	w = recorder
+	oldValue, oldValueExist := setting.GetSettingManager().SerializedWoxSettingValue(kv.Key)
+	defer func() {
+		setting.LogSettingOperation(ctx, setting.SettingOperationUpdate, kv.Key, start, recorder.err)
+		if newValue, ok := setting.GetSettingManager().SerializedWoxSettingValue(kv.Key); ok && oldValueExist {
+			setting.GetSettingManager().RecordSettingAudit(ctx, kv.Key, oldValue, newValue, setting.SettingAuditSourceSettingsUI)
+		}
+	}()
+
+	// The keys handled below must match setting.UpdatableSettingKeys, which
+	// SettingMetadata reports to callers.
+	if _, ok := setting.GetSettingManager().SettingMetadata(kv.Key); !ok {
+		writeSettingUpdateErrorResponse(w, setting.NewUnknownSettingKeyError(kv.Key))
+		return
+	}
+
 	if kv.Key == "ReleaseChannel" {
 		updatedValue, updateErr := updateWoxSettingValue(ctx, woxSetting, kv.Key, kv.Value)
 		if updateErr != nil {
-			writeErrorResponse(w, updateErr.Error())
+			writeSettingUpdateErrorResponse(w, updateErr)
 			return
 		}
 
@@ -1031,26 +1234,84 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 	// system bind does not leave stored settings ahead of the actual OS
 	// registration. These branches return early, so the normal PostSettingUpdate
 	// path does not register the same change again.
-	if kv.Key == "MainHotkey" {
-		if vs != woxSetting.MainHotkey.Get() {
-			if err := GetUIManager().RegisterMainHotkey(ctx, vs); err != nil {
-				writeErrorResponse(w, err.Error())
+	if kv.Key == "MainHotkey" || kv.Key == "SelectionHotkey" {
+		// Validate before the availability probe so typos like "ctr+space" are
+		// reported as such instead of as a generic registration failure. An
+		// empty value is still allowed because it clears the hotkey.
+		if strings.TrimSpace(vs) != "" {
+			parsedHotkey, parseErr := hotkey.Parse(vs)
+			if parseErr != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid hotkey: %s", parseErr.Error())))
+				return
+			}
+			vs = parsedHotkey.String()
+		}
+		if kv.RejectHotkeyConflict {
+			if conflictErr := checkHotkeyUpdateConflict(ctx, kv.Key, []setting.HotkeyBinding{{Setting: kv.Key, Hotkey: vs}}); conflictErr != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, conflictErr))
 				return
 			}
 		}
-		woxSetting.MainHotkey.Set(vs)
+	}
+
+	if kv.Key == "MainHotkey" || kv.Key == "MainHotkeys" {
+		var mainHotkeys []string
+		if kv.Key == "MainHotkey" {
+			mainHotkeys = replaceFirstMainHotkey(woxSetting.GetMainHotkeys(), vs)
+		} else {
+			parsedHotkeys, parseErr := parseMainHotkeysSettingValue(vs)
+			if parseErr != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, parseErr))
+				return
+			}
+			mainHotkeys = parsedHotkeys
+			if kv.RejectHotkeyConflict {
+				var candidates []setting.HotkeyBinding
+				for _, mainHotkey := range mainHotkeys {
+					candidates = append(candidates, setting.HotkeyBinding{Setting: "MainHotkey", Hotkey: mainHotkey})
+				}
+				if conflictErr := checkHotkeyUpdateConflict(ctx, "MainHotkey", candidates); conflictErr != nil {
+					writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, conflictErr))
+					return
+				}
+			}
+		}
+
+		currentHotkeys := woxSetting.GetMainHotkeys()
+		for _, mainHotkey := range mainHotkeys {
+			// Registered hotkeys are held by Wox itself, so only new ones can be probed.
+			if slices.ContainsFunc(currentHotkeys, func(current string) bool { return isSameHotkey(current, mainHotkey) }) {
+				continue
+			}
+			if !hotkey.IsHotkeyAvailable(ctx, mainHotkey) {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("hotkey %s is not available", mainHotkey)))
+				return
+			}
+		}
+
+		if err := GetUIManager().RegisterMainHotkeys(ctx, mainHotkeys); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
+			return
+		}
+		if err := woxSetting.SetMainHotkeys(mainHotkeys); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, err))
+			return
+		}
 		writeSuccessResponse(w, "")
 		return
 	}
 
 	if kv.Key == "SelectionHotkey" {
-		if vs != woxSetting.SelectionHotkey.Get() {
+		if !isSameHotkey(vs, woxSetting.SelectionHotkey.Get()) {
 			if err := GetUIManager().RegisterSelectionHotkey(ctx, vs); err != nil {
-				writeErrorResponse(w, err.Error())
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 				return
 			}
 		}
-		woxSetting.SelectionHotkey.Set(vs)
+		if err := woxSetting.SelectionHotkey.Set(vs); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, err))
+			return
+		}
 		writeSuccessResponse(w, "")
 		return
 	}
@@ -1058,86 +1319,131 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 	if kv.Key == "QueryHotkeys" {
 		queryHotkeys, parseErr := parseQueryHotkeysSettingValue(vs)
 		if parseErr != nil {
-			writeErrorResponse(w, parseErr.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, parseErr))
 			return
 		}
+		if kv.RejectHotkeyConflict {
+			var candidates []setting.HotkeyBinding
+			for _, queryHotkey := range queryHotkeys {
+				if !queryHotkey.Disabled {
+					candidates = append(candidates, setting.HotkeyBinding{Setting: kv.Key, Name: queryHotkey.DisplayName(), Hotkey: queryHotkey.Hotkey})
+				}
+			}
+			if conflictErr := checkHotkeyUpdateConflict(ctx, kv.Key, candidates); conflictErr != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, conflictErr))
+				return
+			}
+		}
 
 		uiManager := GetUIManager()
 		var registerErr error
 		if shouldGroupWaylandPortalHotkeys() {
 			uiManager.globalHotkeyMu.Lock()
-			registerErr = uiManager.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.MainHotkey.Get(), woxSetting.SelectionHotkey.Get(), queryHotkeys)
+			registerErr = uiManager.reregisterWaylandPortalGlobalHotkeys(ctx, woxSetting.GetMainHotkeys(), woxSetting.SelectionHotkey.Get(), queryHotkeys)
 			uiManager.globalHotkeyMu.Unlock()
 		} else {
 			registerErr = uiManager.reregisterIndividualQueryHotkeys(ctx, queryHotkeys)
 		}
 		if registerErr != nil {
-			writeErrorResponse(w, registerErr.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, registerErr))
 			return
 		}
 
-		woxSetting.QueryHotkeys.Set(queryHotkeys)
+		if err := woxSetting.QueryHotkeys.Set(queryHotkeys); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, err))
+			return
+		}
 		writeSuccessResponse(w, "")
 		return
 	}
 
+	var saveErr error
 	switch kv.Key {
 	case "EnableAutostart":
-		woxSetting.EnableAutostart.Set(vb)
+		saveErr = woxSetting.EnableAutostart.Set(vb)
+	case "AutostartReconcileMode":
+		if !setting.IsValidAutostartReconcileMode(setting.AutostartReconcileMode(vs)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid autostart reconcile mode: %s", vs)))
+			return
+		}
+		saveErr = woxSetting.AutostartReconcileMode.Set(setting.AutostartReconcileMode(vs))
 	case "IgnoredHotkeyApps":
 		var ignoredApps []setting.IgnoredHotkeyApp
 		if err := json.Unmarshal([]byte(vs), &ignoredApps); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
-		woxSetting.IgnoredHotkeyApps.Set(normalizeIgnoredHotkeyApps(ignoredApps))
+		saveErr = woxSetting.IgnoredHotkeyApps.Set(normalizeIgnoredHotkeyApps(ignoredApps))
 	case "LogLevel":
 		updatedValue = util.NormalizeLogLevel(vs)
 		if err := woxSetting.LogLevel.Set(updatedValue); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, err))
 			return
 		}
 	case "UsePinYin":
-		woxSetting.UsePinYin.Set(vb)
+		// legacy switch from older settings UIs, keep a more specific mode when enabling
+		if !vb || woxSetting.PinYinMatchMode.Get() == setting.PinYinMatchModeOff {
+			saveErr = woxSetting.PinYinMatchMode.Set(fuzzymatch.PinYinMatchModeFromBool(vb))
+		}
+	case "PinYinMatchMode":
+		if !fuzzymatch.IsValidPinYinMatchMode(setting.PinYinMatchMode(vs)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, errors.New("invalid pinyin match mode: "+vs)))
+			return
+		}
+		saveErr = woxSetting.PinYinMatchMode.Set(setting.PinYinMatchMode(vs))
 	case "SwitchInputMethodABC":
-		woxSetting.SwitchInputMethodABC.Set(vb)
+		saveErr = woxSetting.SwitchInputMethodABC.Set(vb)
 	case "HideOnStart":
-		woxSetting.HideOnStart.Set(vb)
+		saveErr = woxSetting.HideOnStart.Set(vb)
 	case "OnboardingFinished":
 		// The guide writes completion through the existing settings endpoint so
 		// skip and finish share one durable state transition with no extra API.
-		woxSetting.OnboardingFinished.Set(vb)
+		saveErr = woxSetting.OnboardingFinished.Set(vb)
 	case "HideOnLostFocus":
-		woxSetting.HideOnLostFocus.Set(vb)
+		saveErr = woxSetting.HideOnLostFocus.Set(vb)
 	case "ShowTray":
-		woxSetting.ShowTray.Set(vb)
+		saveErr = woxSetting.ShowTray.Set(vb)
 	case "LangCode":
-		woxSetting.LangCode.Set(i18n.LangCode(vs))
+		// Switch the in-memory language only after the setting is saved so a
+		// failed save cannot leave Wox showing a language it will not restart with.
+		langCode := i18n.LangCode(strings.TrimSpace(vs))
+		if !i18n.IsSupported(langCode) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, i18n.UnsupportedLangError(langCode)))
+			return
+		}
+		if err := i18n.GetI18nManager().SwitchLang(ctx, langCode, func() error {
+			return woxSetting.LangCode.Set(langCode)
+		}); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, err))
+			return
+		}
 	case "QueryShortcuts":
 		var queryShortcuts []setting.QueryShortcut
 		if err := json.Unmarshal([]byte(vs), &queryShortcuts); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
-		woxSetting.QueryShortcuts.Set(queryShortcuts)
+		saveErr = woxSetting.QueryShortcuts.Set(queryShortcuts)
 	case "CloudSyncServerUrl":
 		cloudSyncServerURL := strings.TrimSpace(vs)
-		woxSetting.CloudSyncServerUrl.Set(cloudSyncServerURL)
+		if saveErr = woxSetting.CloudSyncServerUrl.Set(cloudSyncServerURL); saveErr != nil {
+			break
+		}
 		if err := applyCloudSyncServerURL(ctx, cloudSyncServerURL); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
 	case "CloudSyncDisabledPlugins":
 		var disabledPlugins []string
 		if err := json.Unmarshal([]byte(vs), &disabledPlugins); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
-		woxSetting.CloudSyncDisabledPlugins.Set(disabledPlugins)
+		saveErr = woxSetting.CloudSyncDisabledPlugins.Set(disabledPlugins)
 	case "TrayQueries":
 		var rawTrayQueries []map[string]any
 		if err := json.Unmarshal([]byte(vs), &rawTrayQueries); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
 
@@ -1184,24 +1490,74 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 
 			trayQueries = append(trayQueries, trayQuery)
 		}
-		woxSetting.TrayQueries.Set(trayQueries)
+		saveErr = woxSetting.TrayQueries.Set(trayQueries)
 	case "LaunchMode":
-		woxSetting.LaunchMode.Set(setting.LaunchMode(vs))
+		saveErr = woxSetting.LaunchMode.Set(setting.LaunchMode(vs))
 	case "StartPage":
-		woxSetting.StartPage.Set(setting.StartPage(vs))
+		saveErr = woxSetting.StartPage.Set(setting.StartPage(vs))
 	case "ShowPosition":
-		woxSetting.ShowPosition.Set(setting.PositionType(vs))
+		if !setting.IsValidPositionType(setting.PositionType(vs)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid show position: %s", vs)))
+			return
+		}
+		saveErr = woxSetting.ShowPosition.Set(setting.PositionType(vs))
 	case "AIProviders":
 		var aiProviders []setting.AIProvider
 		if err := json.Unmarshal([]byte(vs), &aiProviders); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
-		woxSetting.AIProviders.Set(aiProviders)
+		if err := setting.ValidateAIProviders(aiProviders); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
+			return
+		}
+		saveErr = woxSetting.AIProviders.Set(setting.AssignAIProviderIds(woxSetting.AIProviders.Get(), aiProviders))
 	case "EnableAutoBackup":
-		woxSetting.EnableAutoBackup.Set(vb)
+		saveErr = woxSetting.EnableAutoBackup.Set(vb)
+	case "AutoBackupIntervalHours":
+		if !setting.IsValidAutoBackupIntervalHours(int(vf)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("auto backup interval must be between 1 and %d hours", setting.MaxAutoBackupIntervalHours)))
+			return
+		}
+		saveErr = woxSetting.AutoBackupIntervalHours.Set(int(vf))
+	case "AutoBackupMaxCount":
+		if !setting.IsValidAutoBackupMaxCount(int(vf)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("auto backup max count must be between 1 and %d", setting.MaxAutoBackupMaxCount)))
+			return
+		}
+		saveErr = woxSetting.AutoBackupMaxCount.Set(int(vf))
+	case "EncryptBackups":
+		saveErr = woxSetting.EncryptBackups.Set(vb)
 	case "EnableAutoUpdate":
-		woxSetting.EnableAutoUpdate.Set(vb)
+		if err := setting.CheckAutoUpdatePolicy(); err != nil {
+			writeSettingUpdateErrorResponse(w, err)
+			return
+		}
+		saveErr = woxSetting.EnableAutoUpdate.Set(vb)
+	case "DoNotDisturb":
+		saveErr = woxSetting.DoNotDisturb.Set(vb)
+	case "DoNotDisturbStart", "DoNotDisturbEnd":
+		vs = strings.TrimSpace(vs)
+		if !setting.IsValidDoNotDisturbTime(vs) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid do not disturb time %q, expected HH:MM", vs)))
+			return
+		}
+		if kv.Key == "DoNotDisturbStart" {
+			saveErr = woxSetting.DoNotDisturbStart.Set(vs)
+		} else {
+			saveErr = woxSetting.DoNotDisturbEnd.Set(vs)
+		}
+	case "NotificationSound":
+		vs = strings.TrimSpace(vs)
+		if !setting.IsValidNotificationSound(vs) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, errors.New("notification sound must be default, silent or a sound file path")))
+			return
+		}
+		if vs != notifier.SoundDefault && vs != notifier.SoundSilent && !util.IsFileExists(util.ExpandPath(vs)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("notification sound file does not exist: %s", vs)))
+			return
+		}
+		saveErr = woxSetting.NotificationSound.Set(vs)
 	case "CustomPythonPath":
 		if strings.TrimSpace(vs) != "" {
 			// Bug fix: reject unsupported custom Python paths at save time. The
@@ -1209,32 +1565,92 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 			// tried to start, so this backend guard keeps API callers and the UI
 			// on the same minimum-version contract.
 			if _, validateErr := pluginhost.ValidatePythonExecutable(ctx, vs); validateErr != nil {
-				writeErrorResponse(w, validateErr.Error())
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, validateErr))
 				return
 			}
 		}
-		woxSetting.CustomPythonPath.Set(vs)
+		saveErr = woxSetting.CustomPythonPath.Set(vs)
 	case "CustomNodejsPath":
 		if strings.TrimSpace(vs) != "" {
 			// Feature: Node.js custom paths use the same save-time validation as
 			// Python. Checking the version here prevents non-UI API callers from
 			// persisting a Node.js executable that the host will immediately reject.
 			if _, validateErr := pluginhost.ValidateNodejsExecutable(ctx, vs); validateErr != nil {
-				writeErrorResponse(w, validateErr.Error())
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, validateErr))
 				return
 			}
 		}
-		woxSetting.CustomNodejsPath.Set(vs)
+		saveErr = woxSetting.CustomNodejsPath.Set(vs)
 
 	case "HttpProxyEnabled":
-		woxSetting.HttpProxyEnabled.Set(vb)
+		// Verify the proxy before switching every request over to it, otherwise
+		// a wrong password silently breaks updates, plugin store and AI calls.
+		if vb && strings.TrimSpace(woxSetting.HttpProxyUrl.Get()) != "" {
+			if testErr := util.TestProxy(ctx, woxSetting.HttpProxyUrl.Get()); testErr != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, testErr))
+				return
+			}
+		}
+		saveErr = woxSetting.HttpProxyEnabled.Set(vb)
 	case "HttpProxyUrl":
-		woxSetting.HttpProxyUrl.Set(vs)
+		if strings.TrimSpace(vs) != "" {
+			if _, parseErr := util.ParseProxyURL(vs); parseErr != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid proxy url: %s", parseErr.Error())))
+				return
+			}
+			if woxSetting.HttpProxyEnabled.Get() {
+				if testErr := util.TestProxy(ctx, vs); testErr != nil {
+					writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, testErr))
+					return
+				}
+			}
+		}
+		saveErr = woxSetting.HttpProxyUrl.Set(vs)
 
 	case "AppWidth":
-		woxSetting.AppWidth.Set(int(vf))
+		screenWidth := screen.GetMouseScreen().Width
+		if !setting.IsValidAppWidth(int(vf), screenWidth) {
+			if screenWidth > 0 {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("app width must be between %d and the screen width %d", setting.MinAppWidth, screenWidth)))
+			} else {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("app width must be at least %d", setting.MinAppWidth)))
+			}
+			return
+		}
+		saveErr = woxSetting.AppWidth.Set(int(vf))
+	case "QueryHistoryExcludePatterns":
+		var patterns []string
+		if err := json.Unmarshal([]byte(vs), &patterns); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
+			return
+		}
+		patterns = lo.Filter(patterns, func(pattern string, _ int) bool {
+			return strings.TrimSpace(pattern) != ""
+		})
+		if err := setting.ValidateQueryHistoryExcludePatterns(patterns); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
+			return
+		}
+		saveErr = woxSetting.QueryHistoryExcludePatterns.Set(patterns)
+	case "AppDataFormat":
+		if !setting.IsValidAppDataFormat(setting.AppDataFormat(vs)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid app data format: %s", vs)))
+			return
+		}
+		// SetAppDataFormat also converts the stored app data to the new format.
+		saveErr = setting.GetSettingManager().SetAppDataFormat(ctx, setting.AppDataFormat(vs))
+	case "MaxFavoriteResults":
+		if !setting.IsValidMaxFavoriteResults(int(vf)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("max favorite results must be between 1 and %d", setting.MaxMaxFavoriteResults)))
+			return
+		}
+		saveErr = woxSetting.MaxFavoriteResults.Set(int(vf))
 	case "MaxResultCount":
-		woxSetting.MaxResultCount.Set(int(vf))
+		if !setting.IsValidMaxResultCount(int(vf)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("max result count must be between %d and %d", setting.MinMaxResultCount, setting.MaxMaxResultCount)))
+			return
+		}
+		saveErr = woxSetting.MaxResultCount.Set(int(vf))
 	case "UiDensity":
 		// New launcher presentation setting: store only the normalized density
 		// enum. The old fixed-size behavior maps to normal, while unsupported
@@ -1243,56 +1659,86 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 		normalizedDensity := setting.NormalizeUiDensity(vs)
 		updatedValue = string(normalizedDensity)
 		if err := woxSetting.UiDensity.Set(normalizedDensity); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, err))
 			return
 		}
 	case "ThemeId":
-		woxSetting.ThemeId.Set(vs)
+		saveErr = woxSetting.ThemeId.Set(vs)
 	case "AppFontFamily":
 		vs = font.NormalizeConfiguredFontFamily(vs, font.GetSystemFontFamilies(ctx))
-		woxSetting.AppFontFamily.Set(vs)
+		saveErr = woxSetting.AppFontFamily.Set(vs)
 	case "EnableQueryCompletionHint":
-		woxSetting.EnableQueryCompletionHint.Set(vb)
+		saveErr = woxSetting.EnableQueryCompletionHint.Set(vb)
 	case "EnableGlance":
-		woxSetting.EnableGlance.Set(vb)
+		saveErr = woxSetting.EnableGlance.Set(vb)
 	case "PrimaryGlance":
 		var glance setting.GlanceRef
 		if err := json.Unmarshal([]byte(vs), &glance); err != nil {
-			writeErrorResponse(w, err.Error())
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
 			return
 		}
-		woxSetting.PrimaryGlance.Set(glance)
+		saveErr = woxSetting.PrimaryGlance.Set(glance)
 	case "HideGlanceIcon":
 		// This setting only changes the launcher presentation. Persisting it in
 		// the shared settings API keeps the behavior consistent after reloads
 		// without asking Glance providers to omit useful icon metadata.
-		woxSetting.HideGlanceIcon.Set(vb)
+		saveErr = woxSetting.HideGlanceIcon.Set(vb)
+	case "QueryModeSettings":
+		var queryModeSettings map[string]setting.QueryModeConfig
+		if err := json.Unmarshal([]byte(vs), &queryModeSettings); err != nil {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
+			return
+		}
+		for mode, config := range queryModeSettings {
+			if err := setting.ValidateQueryModeConfig(mode, config); err != nil {
+				writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, err))
+				return
+			}
+		}
+		saveErr = woxSetting.QueryModeSettings.Set(queryModeSettings)
+	case "RankingMode":
+		if !setting.IsValidRankingMode(setting.RankingMode(vs)) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, fmt.Errorf("invalid ranking mode: %s", vs)))
+			return
+		}
+		saveErr = woxSetting.RankingMode.Set(setting.RankingMode(vs))
+	case "QueryDebounceMs":
+		// Out of range values are rejected by the validator of the setting.
+		saveErr = woxSetting.QueryDebounceMs.Set(int(vf))
 	case "ShowScoreTail":
 		// New dev setting: score tails used to be compiled into a helper but
 		// effectively disabled by commented call sites. Persisting this switch
 		// lets developers opt in without editing code for each debug session.
-		woxSetting.ShowScoreTail.Set(vb)
+		saveErr = woxSetting.ShowScoreTail.Set(vb)
 	case "ShowPerformanceTail":
 		// New dev setting: performance tags were previously always appended in
 		// dev builds. Keeping the check in the backend prevents hidden UI tabs
 		// from being the only guard for noisy query-result tags.
-		woxSetting.ShowPerformanceTail.Set(vb)
+		saveErr = woxSetting.ShowPerformanceTail.Set(vb)
 	case "ShowPerformanceTailBatch":
-		woxSetting.ShowPerformanceTailBatch.Set(vb)
+		saveErr = woxSetting.ShowPerformanceTailBatch.Set(vb)
 	case "ShowPerformanceTailPluginQuery":
-		woxSetting.ShowPerformanceTailPluginQuery.Set(vb)
+		saveErr = woxSetting.ShowPerformanceTailPluginQuery.Set(vb)
 	case "ShowPerformanceTailBackendPrepared":
-		woxSetting.ShowPerformanceTailBackendPrepared.Set(vb)
+		saveErr = woxSetting.ShowPerformanceTailBackendPrepared.Set(vb)
 	case "ShowPerformanceTailUiReceived":
-		woxSetting.ShowPerformanceTailUiReceived.Set(vb)
+		saveErr = woxSetting.ShowPerformanceTailUiReceived.Set(vb)
 	case "EnableAnonymousUsageStats":
-		woxSetting.EnableAnonymousUsageStats.Set(vb)
+		saveErr = woxSetting.EnableAnonymousUsageStats.Set(vb)
 		// When disabled, delete telemetry state to stop tracking
 		if !vb {
 			telemetry.DeleteTelemetryState(ctx)
 		}
 	default:
-		writeErrorResponse(w, "unknown setting key: "+kv.Key)
+		writeSettingUpdateErrorResponse(w, setting.NewUnknownSettingKeyError(kv.Key))
+		return
+	}
+	if saveErr != nil {
+		if errors.Is(saveErr, setting.ErrInvalidSettingValue) {
+			writeSettingUpdateErrorResponse(w, setting.NewInvalidSettingValueError(kv.Key, saveErr))
+			return
+		}
+		writeSettingUpdateErrorResponse(w, setting.NewSettingSaveError(kv.Key, saveErr))
 		return
 	}
 
@@ -1301,6 +1747,75 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, "")
 }
 
+// checkHotkeyUpdateConflict replaces the stored bindings of settingKey with the
+// pending candidates and reports the first conflict that involves them.
+func checkHotkeyUpdateConflict(ctx context.Context, settingKey string, candidates []setting.HotkeyBinding) error {
+	bindings := lo.Filter(setting.GetSettingManager().HotkeyBindings(ctx), func(binding setting.HotkeyBinding, _ int) bool {
+		return binding.Setting != settingKey
+	})
+	bindings = append(bindings, candidates...)
+
+	for _, conflict := range setting.FindHotkeyConflicts(bindings) {
+		if conflict.First.Setting != settingKey && conflict.Second.Setting != settingKey {
+			continue
+		}
+		owner := conflict.First
+		if owner.Setting == settingKey && conflict.Second.Setting != settingKey {
+			owner = conflict.Second
+		}
+		if owner.Name != "" {
+			return fmt.Errorf("hotkey %s conflicts with %s (%s)", conflict.Hotkey, owner.Setting, owner.Name)
+		}
+		return fmt.Errorf("hotkey %s conflicts with %s", conflict.Hotkey, owner.Setting)
+	}
+	return nil
+}
+
+// parseMainHotkeysSettingValue decodes the JSON list of main hotkeys and
+// returns each in canonical form, without empty entries and repeats.
+func parseMainHotkeysSettingValue(value string) ([]string, error) {
+	var rawHotkeys []string
+	if err := json.Unmarshal([]byte(value), &rawHotkeys); err != nil {
+		return nil, err
+	}
+
+	var mainHotkeys []string
+	for _, rawHotkey := range rawHotkeys {
+		if strings.TrimSpace(rawHotkey) == "" {
+			continue
+		}
+		parsedHotkey, parseErr := hotkey.Parse(rawHotkey)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid hotkey %s: %s", rawHotkey, parseErr.Error())
+		}
+		mainHotkeys = append(mainHotkeys, parsedHotkey.String())
+	}
+	return setting.NormalizeMainHotkeys(mainHotkeys), nil
+}
+
+// replaceFirstMainHotkey applies a MainHotkey update from older clients, which
+// only know one main hotkey, the first entry. An empty value clears it.
+func replaceFirstMainHotkey(current []string, value string) []string {
+	mainHotkeys := slices.Clone(current)
+	if len(mainHotkeys) > 0 {
+		mainHotkeys[0] = value
+	} else {
+		mainHotkeys = []string{value}
+	}
+	return setting.NormalizeMainHotkeys(mainHotkeys)
+}
+
+// isSameHotkey compares hotkeys by canonical form so reordering or recasing a
+// stored hotkey does not trigger a needless re-registration.
+func isSameHotkey(left string, right string) bool {
+	if left == right {
+		return true
+	}
+	parsedLeft, leftErr := hotkey.Parse(left)
+	parsedRight, rightErr := hotkey.Parse(right)
+	return leftErr == nil && rightErr == nil && parsedLeft.String() == parsedRight.String()
+}
+
 // parseQueryHotkeysSettingValue normalizes query hotkey payloads before both
 // pre-registration and persistence so portal errors do not leave two views
 // of the same setting.
@@ -1359,13 +1874,43 @@ func updateWoxSettingValue(_ context.Context, woxSetting *setting.WoxSetting, ke
 	case "ReleaseChannel":
 		normalizedChannel := setting.NormalizeReleaseChannel(value)
 		if err := woxSetting.ReleaseChannel.Set(normalizedChannel); err != nil {
-			return "", err
+			return "", setting.NewSettingSaveError(key, err)
 		}
 		updater.ResetUpdateInfoForReleaseChannel(normalizedChannel)
 		return string(normalizedChannel), nil
 	default:
-		return "", fmt.Errorf("unknown setting key: %s", key)
+		return "", setting.NewUnknownSettingKeyError(key)
+	}
+}
+
+// settingUpdateRecorder keeps the error a setting update responded with, see
+// handleSettingWoxUpdate.
+type settingUpdateRecorder struct {
+	http.ResponseWriter
+	err error
+}
+
+// writeSettingUpdateErrorResponse reports a failed setting update with the
+// error code and key in Data, so the UI can react without parsing Message.
+func writeSettingUpdateErrorResponse(w http.ResponseWriter, err error) {
+	if recorder, ok := w.(*settingUpdateRecorder); ok {
+		recorder.err = err
+	}
+
+	data := map[string]string{}
+	var updateErr *setting.SettingUpdateError
+	if errors.As(err, &updateErr) {
+		data["Code"] = updateErr.Code()
+		data["Key"] = updateErr.Key
 	}
+
+	d, _ := json.Marshal(RestResponse{
+		Success: false,
+		Message: err.Error(),
+		Data:    data,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(d)
 }
 
 func handleGlance(w http.ResponseWriter, r *http.Request) {
@@ -2372,7 +2917,14 @@ func handleSettingPluginUpdate(w http.ResponseWriter, r *http.Request) {
 	if kv.Key == "Disabled" {
 		pluginInstance.Setting.Disabled.Set(kv.Value == "true")
 	} else if kv.Key == "TriggerKeywords" {
-		pluginInstance.Setting.TriggerKeywords.Set(strings.Split(kv.Value, ","))
+		if err := plugin.GetPluginManager().SetPluginTriggerKeywords(getTraceContext(r), kv.PluginId, strings.Split(kv.Value, ",")); err != nil {
+			if conflictErr, ok := plugin.AsTriggerKeywordConflictError(err); ok {
+				writeErrorResponse(w, fmt.Sprintf("trigger keyword %s is already used by %s", conflictErr.Conflict.Keyword, conflictErr.Conflict.PluginInstances[0].GetName(getTraceContext(r))))
+				return
+			}
+			writeErrorResponse(w, err.Error())
+			return
+		}
 	} else {
 		var isPlatformSpecific = false
 		for _, settingDefinition := range pluginInstance.Metadata.SettingDefinitions {
@@ -2381,6 +2933,10 @@ func handleSettingPluginUpdate(w http.ResponseWriter, r *http.Request) {
 				break
 			}
 		}
+		if validateErr := pluginInstance.Metadata.SettingDefinitions.Validate(kv.Key, kv.Value); validateErr != nil {
+			writeErrorResponse(w, validateErr.Error())
+			return
+		}
 		pluginInstance.API.SaveSetting(getTraceContext(r), kv.Key, kv.Value, isPlatformSpecific)
 	}
 
@@ -2453,6 +3009,54 @@ func handleBackupRestore(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, "")
 }
 
+func handleSettingBundleExport(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+	body, _ := io.ReadAll(r.Body)
+	pathResult := gjson.GetBytes(body, "path")
+	if !pathResult.Exists() || strings.TrimSpace(pathResult.String()) == "" {
+		writeErrorResponse(w, "path is empty")
+		return
+	}
+
+	file, createErr := os.Create(pathResult.String())
+	if createErr != nil {
+		writeErrorResponse(w, createErr.Error())
+		return
+	}
+	defer file.Close()
+
+	if exportErr := setting.GetSettingManager().ExportAll(ctx, file, gjson.GetBytes(body, "includeSecrets").Bool()); exportErr != nil {
+		writeErrorResponse(w, exportErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, pathResult.String())
+}
+
+func handleSettingBundleImport(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+	body, _ := io.ReadAll(r.Body)
+	pathResult := gjson.GetBytes(body, "path")
+	if !pathResult.Exists() || strings.TrimSpace(pathResult.String()) == "" {
+		writeErrorResponse(w, "path is empty")
+		return
+	}
+
+	file, openErr := os.Open(pathResult.String())
+	if openErr != nil {
+		writeErrorResponse(w, openErr.Error())
+		return
+	}
+	defer file.Close()
+
+	if importErr := setting.GetSettingManager().ImportAll(ctx, file); importErr != nil {
+		writeErrorResponse(w, importErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, "")
+}
+
 func handleBackupAll(w http.ResponseWriter, r *http.Request) {
 	ctx := getTraceContext(r)
 
@@ -2621,6 +3225,16 @@ func handleHotkeyAvailability(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, availability)
 }
 
+func handleHotkeyConflicts(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+	writeSuccessResponse(w, setting.GetSettingManager().CheckHotkeyConflicts(ctx))
+}
+
+func handleSettingDiagnose(w http.ResponseWriter, r *http.Request) {
+	ctx := getTraceContext(r)
+	writeSuccessResponse(w, setting.GetSettingManager().Diagnose(ctx))
+}
+
 func handleShow(w http.ResponseWriter, r *http.Request) {
 	ctx := getTraceContext(r)
 	GetUIManager().GetUI(ctx).ShowApp(ctx, common.ShowContext{
@@ -2929,8 +3543,7 @@ func startCloudSyncManagerIfSyncEnabled(ctx context.Context, service *cloudsync.
 
 func handleOnFocusLost(w http.ResponseWriter, r *http.Request) {
 	ctx := getTraceContext(r)
-	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	if woxSetting.HideOnLostFocus.Get() {
+	if setting.GetSettingManager().ShouldHideOnLostFocus(ctx) {
 		GetUIManager().GetUI(ctx).HideApp(ctx)
 	}
 	writeSuccessResponse(w, "")
@@ -2951,9 +3564,10 @@ func handleLangJson(w http.ResponseWriter, r *http.Request) {
 	}
 	langCode := langCodeResult.String()
 
-	if !i18n.IsSupportedLangCode(langCode) {
-		logger.Error(ctx, fmt.Sprintf("unsupported lang code: %s", langCode))
-		writeErrorResponse(w, fmt.Sprintf("unsupported lang code: %s", langCode))
+	if !i18n.IsSupported(i18n.LangCode(langCode)) {
+		err := i18n.UnsupportedLangError(i18n.LangCode(langCode))
+		logger.Error(ctx, err.Error())
+		writeErrorResponse(w, err.Error())
 		return
 	}
 
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"testing"
+	"wox/setting"
+)
+
+// settingUpdateHandlerKeys returns the keys handleSettingWoxUpdate handles,
+// read from the kv.Key comparisons and switch cases in router.go.
+func settingUpdateHandlerKeys(t *testing.T) map[string]bool {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "router.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse router.go: %v", err)
+	}
+
+	keys := map[string]bool{}
+	addKey := func(expr ast.Expr) {
+		if literal, ok := expr.(*ast.BasicLit); ok && literal.Kind == token.STRING {
+			if key, err := strconv.Unquote(literal.Value); err == nil {
+				keys[key] = true
+			}
+		}
+	}
+	isKeyExpr := func(expr ast.Expr) bool {
+		selector, ok := expr.(*ast.SelectorExpr)
+		return ok && selector.Sel.Name == "Key"
+	}
+
+	for _, decl := range file.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok || function.Name.Name != "handleSettingWoxUpdate" {
+			continue
+		}
+		ast.Inspect(function.Body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.BinaryExpr:
+				if node.Op == token.EQL && isKeyExpr(node.X) {
+					addKey(node.Y)
+				}
+			case *ast.SwitchStmt:
+				if node.Tag == nil || !isKeyExpr(node.Tag) {
+					return true
+				}
+				for _, stmt := range node.Body.List {
+					for _, expr := range stmt.(*ast.CaseClause).List {
+						addKey(expr)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return keys
+}
+
+func TestSettingMetadataKeysAreAcceptedByUpdateHandler(t *testing.T) {
+	handled := settingUpdateHandlerKeys(t)
+	if len(handled) == 0 {
+		t.Fatalf("found no keys in handleSettingWoxUpdate")
+	}
+
+	updatable := setting.UpdatableSettingKeys()
+	for _, key := range updatable {
+		if !handled[key] {
+			t.Errorf("setting %s has metadata but handleSettingWoxUpdate does not handle it", key)
+		}
+	}
+	for key := range handled {
+		if !slices.Contains(updatable, key) {
+			t.Errorf("handleSettingWoxUpdate handles %s but it is missing from the updatable settings", key)
+		}
+	}
+}
+
+func TestParseMainHotkeysSettingValue(t *testing.T) {
+	hotkeys, err := parseMainHotkeysSettingValue(`["alt+space", "", "Alt+Space", "ctrl+shift+k"]`)
+	if err != nil {
+		t.Fatalf("failed to parse main hotkeys: %v", err)
+	}
+	if len(hotkeys) != 2 || !isSameHotkey(hotkeys[0], "alt+space") || !isSameHotkey(hotkeys[1], "ctrl+shift+k") {
+		t.Fatalf("expected two canonical hotkeys without repeats, got %v", hotkeys)
+	}
+
+	if _, err := parseMainHotkeysSettingValue(`"alt+space"`); err == nil {
+		t.Fatalf("expected a value that is not a list to be rejected")
+	}
+	if _, err := parseMainHotkeysSettingValue(`["ctr+space"]`); err == nil {
+		t.Fatalf("expected an invalid hotkey to be rejected")
+	}
+}
+
+func TestReplaceFirstMainHotkey(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  []string
+		value    string
+		expected []string
+	}{
+		{name: "replace", current: []string{"alt+space", "ctrl+shift+k"}, value: "ctrl+space", expected: []string{"ctrl+space", "ctrl+shift+k"}},
+		{name: "first hotkey", current: nil, value: "alt+space", expected: []string{"alt+space"}},
+		{name: "clear the only hotkey", current: []string{"alt+space"}, value: "", expected: []string{}},
+		{name: "clear keeps the others", current: []string{"alt+space", "ctrl+shift+k"}, value: "", expected: []string{"ctrl+shift+k"}},
+		{name: "repeat of another hotkey", current: []string{"alt+space", "ctrl+shift+k"}, value: "Ctrl+Shift+K", expected: []string{"Ctrl+Shift+K"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := slices.Clone(tt.current)
+			if got := replaceFirstMainHotkey(current, tt.value); !slices.Equal(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			if !slices.Equal(current, tt.current) {
+				t.Fatalf("expected the current hotkeys to stay unchanged, got %v", current)
+			}
+		})
+	}
+}
+
+func TestIsSameMainHotkeyListIgnoresSpelling(t *testing.T) {
+	if !isSameMainHotkeyList([]string{"Alt+Space", "ctrl+shift+k"}, []string{"alt+space", "Ctrl+Shift+K"}) {
+		t.Fatalf("expected lists that only differ in spelling to match")
+	}
+	if isSameMainHotkeyList([]string{"alt+space", "ctrl+shift+k"}, []string{"ctrl+shift+k", "alt+space"}) {
+		t.Fatalf("expected the order of the main hotkeys to matter")
+	}
+}
@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"wox/common"
+	"wox/util"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/pretty"
+)
+
+// themeExportVersion is bumped when the export layout changes incompatibly.
+const themeExportVersion = 1
+
+// installImportedTheme installs one theme of an import, replaced in tests.
+var installImportedTheme = func(s *Store, ctx context.Context, theme common.Theme, applyTheme bool) error {
+	return s.install(ctx, theme, true, applyTheme)
+}
+
+// themeExport is the portable file written by ExportTheme. Themes are plain
+// colors and sizes, so the only referenced assets are the light and dark
+// variants of an auto appearance theme, which are bundled when they are user
+// themes. System variants exist on every install and are referenced by id.
+type themeExport struct {
+	WoxThemeExportVersion int
+	Theme                 common.Theme
+	Variants              []common.Theme `json:",omitempty"`
+}
+
+// ExportTheme writes an installed theme, with its user variants, as a file
+// that ImportTheme on another machine can install.
+func (s *Store) ExportTheme(ctx context.Context, themeId string, w io.Writer) error {
+	theme, ok := GetUIManager().themes.Load(themeId)
+	if !ok {
+		return fmt.Errorf("theme %s is not installed", themeId)
+	}
+
+	export := themeExport{
+		WoxThemeExportVersion: themeExportVersion,
+		Theme:                 exportableTheme(theme),
+	}
+	if theme.IsAutoAppearance {
+		for _, variantId := range []string{theme.DarkThemeId, theme.LightThemeId} {
+			if variantId == "" || GetUIManager().IsSystemTheme(variantId) {
+				continue
+			}
+			variant, variantOk := GetUIManager().themes.Load(variantId)
+			if !variantOk {
+				return fmt.Errorf("variant theme %s of %s is not installed", variantId, theme.ThemeName)
+			}
+			export.Variants = append(export.Variants, exportableTheme(variant))
+		}
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("failed to encode theme %s: %w", themeId, err)
+	}
+	if _, err := w.Write(pretty.Pretty(data)); err != nil {
+		return fmt.Errorf("failed to write theme %s: %w", themeId, err)
+	}
+
+	logger.Info(ctx, fmt.Sprintf("exported theme %s(%s) with %d variant(s)", theme.ThemeName, themeId, len(export.Variants)))
+	return nil
+}
+
+// ImportTheme validates and installs a theme written by ExportTheme. A plain
+// theme JSON, as served by the theme store, is accepted too. Imported themes
+// always get fresh ids so they can never overwrite a built-in or an already
+// installed theme; the new id of the main theme is returned and applied.
+func (s *Store) ImportTheme(ctx context.Context, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read theme file: %w", err)
+	}
+
+	export, err := parseThemeExport(data)
+	if err != nil {
+		return "", err
+	}
+
+	// Variants are remapped first so the main theme can point at their new ids.
+	newIds := map[string]string{}
+	for i := range export.Variants {
+		if err := validateImportedTheme(export.Variants[i]); err != nil {
+			return "", err
+		}
+		newId := uuid.NewString()
+		newIds[export.Variants[i].ThemeId] = newId
+		export.Variants[i].ThemeId = newId
+	}
+
+	theme := export.Theme
+	if err := validateImportedTheme(theme); err != nil {
+		return "", err
+	}
+	theme.ThemeId = uuid.NewString()
+	if theme.IsAutoAppearance {
+		for _, variantId := range []*string{&theme.DarkThemeId, &theme.LightThemeId} {
+			if newId, ok := newIds[*variantId]; ok {
+				*variantId = newId
+				continue
+			}
+			if !GetUIManager().IsSystemTheme(*variantId) {
+				return "", fmt.Errorf("invalid theme file: variant theme %s of %s is neither bundled nor a built-in theme", *variantId, theme.ThemeName)
+			}
+		}
+	}
+
+	// A failed import removes the variants it installed, so it leaves no
+	// half imported theme behind.
+	var installed []common.Theme
+	for _, variant := range export.Variants {
+		if err := installImportedTheme(s, ctx, variant, false); err != nil {
+			s.rollbackImportedThemes(ctx, installed)
+			return "", fmt.Errorf("failed to install variant theme %s: %w", variant.ThemeName, err)
+		}
+		installed = append(installed, variant)
+	}
+	if err := installImportedTheme(s, ctx, theme, true); err != nil {
+		s.rollbackImportedThemes(ctx, installed)
+		return "", fmt.Errorf("failed to install theme %s: %w", theme.ThemeName, err)
+	}
+
+	logger.Info(ctx, fmt.Sprintf("imported theme %s as %s with %d variant(s)", theme.ThemeName, theme.ThemeId, len(export.Variants)))
+	return theme.ThemeId, nil
+}
+
+// rollbackImportedThemes removes themes installed by a failed ImportTheme.
+// They have fresh ids, so nothing else refers to them yet.
+func (s *Store) rollbackImportedThemes(ctx context.Context, themes []common.Theme) {
+	for _, theme := range themes {
+		themePath := path.Join(util.GetLocation().GetThemeDirectory(), fmt.Sprintf("%s.json", theme.ThemeId))
+		if err := os.Remove(themePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warn(ctx, fmt.Sprintf("failed to remove imported theme %s while rolling back: %s", theme.ThemeId, err.Error()))
+		}
+		GetUIManager().RemoveTheme(ctx, theme)
+		s.logInstalledThemeDelete(ctx, theme.ThemeId)
+	}
+	if len(themes) > 0 {
+		logger.Info(ctx, fmt.Sprintf("rolled back %d imported variant theme(s)", len(themes)))
+	}
+}
+
+func parseThemeExport(data []byte) (themeExport, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return themeExport{}, fmt.Errorf("invalid theme file: not a JSON object: %w", err)
+	}
+
+	var export themeExport
+	if _, ok := raw["WoxThemeExportVersion"]; !ok {
+		// A bare theme, e.g. downloaded from the theme store.
+		if err := json.Unmarshal(data, &export.Theme); err != nil {
+			return themeExport{}, fmt.Errorf("invalid theme file: %w", err)
+		}
+		return export, nil
+	}
+
+	if err := json.Unmarshal(data, &export); err != nil {
+		return themeExport{}, fmt.Errorf("invalid theme file: %w", err)
+	}
+	if export.WoxThemeExportVersion > themeExportVersion {
+		return themeExport{}, fmt.Errorf("theme file version %d is newer than this Wox supports (%d), please upgrade Wox", export.WoxThemeExportVersion, themeExportVersion)
+	}
+	return export, nil
+}
+
+// validateImportedTheme rejects files that would install a theme the UI
+// cannot render. Platform overrides were already checked while parsing.
+func validateImportedTheme(theme common.Theme) error {
+	name := strings.TrimSpace(theme.ThemeName)
+	if name == "" {
+		return errors.New("invalid theme file: theme name is empty")
+	}
+	if theme.IsAutoAppearance {
+		if theme.DarkThemeId == "" || theme.LightThemeId == "" {
+			return fmt.Errorf("invalid theme file: auto appearance theme %s needs both a dark and a light theme", name)
+		}
+		return nil
+	}
+	if strings.TrimSpace(theme.AppBackgroundColor) == "" || strings.TrimSpace(theme.ResultItemTitleColor) == "" || strings.TrimSpace(theme.QueryBoxFontColor) == "" {
+		return fmt.Errorf("invalid theme file: theme %s has no colors", name)
+	}
+	return nil
+}
+
+// exportableTheme clears the local install state, which means nothing on the
+// machine that imports the file.
+func exportableTheme(theme common.Theme) common.Theme {
+	theme.IsSystem = false
+	theme.IsInstalled = false
+	return theme
+}
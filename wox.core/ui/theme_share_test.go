@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"wox/common"
+)
+
+func TestImportThemeRollsBackVariantsWhenInstallFails(t *testing.T) {
+	ctx := context.Background()
+	manager := GetUIManager()
+	originalInstall := installImportedTheme
+	t.Cleanup(func() { installImportedTheme = originalInstall })
+
+	var installedIds []string
+	installImportedTheme = func(s *Store, ctx context.Context, theme common.Theme, applyTheme bool) error {
+		if applyTheme {
+			return errors.New("disk full")
+		}
+		manager.themes.Store(theme.ThemeId, theme)
+		installedIds = append(installedIds, theme.ThemeId)
+		return nil
+	}
+
+	variant := func(id string, name string) common.Theme {
+		return common.Theme{ThemeId: id, ThemeName: name, AppBackgroundColor: "#000000", ResultItemTitleColor: "#ffffff", QueryBoxFontColor: "#ffffff"}
+	}
+	data, err := json.Marshal(themeExport{
+		WoxThemeExportVersion: themeExportVersion,
+		Theme:                 common.Theme{ThemeId: "auto", ThemeName: "Auto", IsAutoAppearance: true, DarkThemeId: "dark", LightThemeId: "light"},
+		Variants:              []common.Theme{variant("dark", "Dark"), variant("light", "Light")},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode theme: %v", err)
+	}
+
+	if _, err := GetStoreManager().ImportTheme(ctx, bytes.NewReader(data)); err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	if len(installedIds) != 2 {
+		t.Fatalf("expected both variants to be installed before the failure, got %v", installedIds)
+	}
+	for _, id := range installedIds {
+		if _, ok := manager.themes.Load(id); ok {
+			t.Fatalf("expected variant %s to be rolled back", id)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"wox/setting"
 	"wox/util"
 	"wox/util/notifier"
+	"wox/util/screen"
 	"wox/util/selection"
 	"wox/util/timetracking"
 
@@ -463,6 +464,10 @@ func getShowAppParams(ctx context.Context, showContext common.ShowContext) map[s
 	if windowWidth <= 0 {
 		windowWidth = woxSetting.AppWidth.Get()
 	}
+	modeConfig := setting.GetSettingManager().GetQueryModeConfig(ctx, string(showSource))
+	if maxResultCount <= 0 {
+		maxResultCount = modeConfig.MaxResultCount
+	}
 
 	// if specific position provided, use it
 	if showContext.WindowPosition != nil {
@@ -472,14 +477,16 @@ func getShowAppParams(ctx context.Context, showContext common.ShowContext) map[s
 			Y:    showContext.WindowPosition.Y,
 		}
 	} else {
-		switch woxSetting.ShowPosition.Get() {
+		switch modeConfig.ShowPosition {
 		case setting.PositionTypeActiveScreen:
 			position = NewActiveScreenPositionWithOptions(ctx, windowWidth, maxResultCount, showQueryBox, !hideToolbar)
 		case setting.PositionTypeLastLocation:
 			// Use saved window position if available, otherwise use mouse screen position as fallback
 			if woxSetting.LastWindowX.Get() != -1 && woxSetting.LastWindowY.Get() != -1 {
-				logger.Info(ctx, fmt.Sprintf("Using saved window position: x=%d, y=%d", woxSetting.LastWindowX.Get(), woxSetting.LastWindowY.Get()))
-				position = NewLastLocationPosition(woxSetting.LastWindowX.Get(), woxSetting.LastWindowY.Get())
+				// The saved position may belong to a monitor that is no longer connected.
+				x, y := setting.GetSettingManager().ClampWindowPosition(ctx, getScreenWorkAreas(ctx))
+				logger.Info(ctx, fmt.Sprintf("Using saved window position: x=%d, y=%d", x, y))
+				position = NewLastLocationPosition(x, y)
 			} else {
 				logger.Info(ctx, "No saved window position, using mouse screen position as fallback")
 				// No saved position, fallback to mouse screen position
@@ -748,6 +755,19 @@ func handleWebsocketQuery(ctx context.Context, request WebsocketMsg) {
 
 	logger.Info(ctx, fmt.Sprintf("start to handle query changed: %s, queryId: %s", changedQuery.String(), queryId))
 
+	if changedQuery.QueryType == plugin.QueryTypeInput {
+		// Clearing the query box is answered right away, but still supersedes
+		// queries that are waiting for typing to settle.
+		debounceMs := 0
+		if changedQuery.QueryText != "" {
+			debounceMs = setting.GetSettingManager().GetWoxSetting(ctx).QueryDebounceMs.Get()
+		}
+		if !waitForQueryDebounce(ctx, sessionId, queryId, debounceMs) {
+			logger.Info(ctx, fmt.Sprintf("query superseded while debouncing, skipped: %s, queryId: %s", changedQuery.String(), queryId))
+			return
+		}
+	}
+
 	if changedQuery.QueryType == plugin.QueryTypeInput && changedQuery.QueryText == "" {
 		emptyInputQuery := plugin.Query{
 			Id:        queryId,
@@ -1179,3 +1199,19 @@ func getWebsocketMsgParameterMap(ctx context.Context, msg WebsocketMsg, key stri
 
 	return values, nil
 }
+
+// getScreenWorkAreas returns the usable area of every connected display.
+func getScreenWorkAreas(ctx context.Context) []screen.Rect {
+	displays, err := screen.ListDisplays()
+	if err != nil {
+		logger.Warn(ctx, fmt.Sprintf("failed to list displays: %s", err.Error()))
+		return nil
+	}
+
+	return lo.Map(displays, func(display screen.Display, _ int) screen.Rect {
+		if display.WorkArea.IsEmpty() {
+			return display.Bounds
+		}
+		return display.WorkArea
+	})
+}
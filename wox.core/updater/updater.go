@@ -115,7 +115,7 @@ func CheckForUpdatesWithCallback(ctx context.Context, callback UpdateInfoCallbac
 	}
 	resetCurrentUpdateInfoForReleaseChannel(releaseChannel)
 
-	if woxSetting != nil && !woxSetting.EnableAutoUpdate.Get() {
+	if woxSetting != nil && !woxSetting.IsAutoUpdateEnabled() {
 		util.GetLogger().Info(ctx, "auto update is disabled, skipping")
 		currentUpdateInfo = UpdateInfo{
 			CurrentVersion: CURRENT_VERSION,
@@ -3,6 +3,7 @@ package util
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -14,6 +15,33 @@ func IsFileExecAny(mode os.FileMode) bool {
 	return mode&0111 != 0
 }
 
+var windowsEnvVarRegex = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// ExpandPath expands a leading ~ and environment variables written as $VAR,
+// ${VAR} or %VAR%, so a path saved on one machine also works on another.
+// %VAR% is expanded on every platform but left as-is when VAR is not set.
+func ExpandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return path
+	}
+
+	path = windowsEnvVarRegex.ReplaceAllStringFunc(path, func(match string) string {
+		if value, ok := os.LookupEnv(match[1 : len(match)-1]); ok {
+			return value
+		}
+		return match
+	})
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	return path
+}
+
 func IsFileExists(path string) bool {
 	stat, err := os.Stat(path)
 	return err == nil && !stat.IsDir()
@@ -116,3 +144,31 @@ func CollectExecutables(base string, binaries []string, filter func(string) bool
 
 	return results
 }
+
+// WriteFileAtomic writes data to a temp file next to path, fsyncs it and renames it over path,
+// so a crash mid-write leaves either the old content or the new content, never a truncated file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempPath, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
@@ -41,12 +41,40 @@ const (
 	optimalAlignmentStateSize    = optimalAlignmentTextLimit * optimalAlignmentPatternLimit
 )
 
+// PinYinMatchMode selects which pinyin forms can match Chinese text.
+type PinYinMatchMode string
+
+const (
+	PinYinMatchModeOff      PinYinMatchMode = "off"
+	PinYinMatchModeFull     PinYinMatchMode = "full"     // full syllables only, e.g. "nihao" for 你好
+	PinYinMatchModeInitials PinYinMatchMode = "initials" // first letters only, e.g. "nh" for 你好
+	PinYinMatchModeBoth     PinYinMatchMode = "both"
+)
+
+func IsValidPinYinMatchMode(mode PinYinMatchMode) bool {
+	return mode == PinYinMatchModeOff || mode == PinYinMatchModeFull || mode == PinYinMatchModeInitials || mode == PinYinMatchModeBoth
+}
+
+// PinYinMatchModeFromBool maps the legacy UsePinYin switch to a match mode.
+func PinYinMatchModeFromBool(usePinYin bool) PinYinMatchMode {
+	if usePinYin {
+		return PinYinMatchModeBoth
+	}
+	return PinYinMatchModeOff
+}
+
 // FuzzyMatch performs fuzzy matching between pattern and text
 // It supports:
 // - Multi-factor scoring similar to fzf
 // - Diacritics normalization (é -> e, ü -> u, etc.)
 // - Chinese pinyin matching when usePinYin is true
 func FuzzyMatch(text string, pattern string, usePinYin bool) FuzzyMatchResult {
+	return FuzzyMatchWithPinYinMode(text, pattern, PinYinMatchModeFromBool(usePinYin))
+}
+
+// FuzzyMatchWithPinYinMode is FuzzyMatch with control over which pinyin forms
+// are accepted for Chinese text.
+func FuzzyMatchWithPinYinMode(text string, pattern string, pinYinMode PinYinMatchMode) FuzzyMatchResult {
 	if pattern == "" {
 		return FuzzyMatchResult{IsMatch: true, Score: 0}
 	}
@@ -106,8 +134,8 @@ func FuzzyMatch(text string, pattern string, usePinYin bool) FuzzyMatchResult {
 	}
 
 	// Try pinyin matching for Chinese text
-	if usePinYin && hasChineseChar {
-		pinyinResult := matchPinyinStrict(text, patternRunes)
+	if pinYinMode != PinYinMatchModeOff && pinYinMode != "" && hasChineseChar {
+		pinyinResult := matchPinyinStrict(text, patternRunes, pinYinMode)
 		if pinyinResult.IsMatch {
 			return pinyinResult
 		}
@@ -581,7 +609,7 @@ type pinyinSearchState struct {
 // Only allows: all first letters (e.g., "nh" for "你好") OR all full pinyin (e.g., "nihao" for "你好")
 // Does NOT allow mixed mode (e.g., "nhao" or "nih")
 // Now uses a state-based search (limited beam) to handle polyphonic ambiguities without exponential complexity.
-func matchPinyinStrict(text string, patternRunes []rune) FuzzyMatchResult {
+func matchPinyinStrict(text string, patternRunes []rune, pinYinMode PinYinMatchMode) FuzzyMatchResult {
 	segments := getPinYin(text)
 	if len(segments) == 0 {
 		return FuzzyMatchResult{IsMatch: false, Score: 0}
@@ -596,7 +624,7 @@ func matchPinyinStrict(text string, patternRunes []rune) FuzzyMatchResult {
 	firstLetMatch := true
 	firstLetScore := int64(0)
 
-	if len(patternRunes) <= len(segments) {
+	if pinYinMode != PinYinMatchModeFull && len(patternRunes) <= len(segments) {
 		for i, r := range patternRunes {
 			seg := segments[i]
 			found := false
@@ -635,6 +663,16 @@ func matchPinyinStrict(text string, patternRunes []rune) FuzzyMatchResult {
 		ModeFullPinyin  = 2
 	)
 
+	// Starting the search in a fixed mode reuses the existing rules that keep
+	// one match from mixing first letters and full syllables.
+	initialMode := ModeAny
+	switch pinYinMode {
+	case PinYinMatchModeInitials:
+		initialMode = ModeFirstLetter
+	case PinYinMatchModeFull:
+		initialMode = ModeFullPinyin
+	}
+
 	// Active states
 	statesPtr := getSearchStateBuffer()
 	defer putSearchStateBuffer(statesPtr)
@@ -644,7 +682,7 @@ func matchPinyinStrict(text string, patternRunes []rune) FuzzyMatchResult {
 	} else {
 		states = states[:1]
 	}
-	states[0] = pinyinSearchState{0, 0, 0, 0, false, ModeAny}
+	states[0] = pinyinSearchState{0, 0, 0, 0, false, initialMode}
 	*statesPtr = states
 
 	// Pre-allocate next states buffer
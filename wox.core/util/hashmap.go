@@ -1,6 +1,8 @@
 package util
 
 import (
+	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -33,6 +35,31 @@ func (h *HashMap[K, V]) MarshalJSON() ([]byte, error) {
 	return json.Marshal(h.inner)
 }
 
+// GobEncode and GobDecode let HashMap values be stored in binary form, e.g.
+// app data saved with the binary app data format.
+func (h *HashMap[K, V]) GobEncode() ([]byte, error) {
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(h.inner); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *HashMap[K, V]) GobDecode(b []byte) error {
+	inner := make(map[K]V)
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&inner); err != nil {
+		return err
+	}
+
+	h.rw.Lock()
+	defer h.rw.Unlock()
+	h.inner = inner
+	return nil
+}
+
 func (h *HashMap[K, V]) Store(k K, v V) {
 	h.rw.Lock()
 	defer h.rw.Unlock()
@@ -161,3 +188,25 @@ func (h *HashMap[K, V]) ToMap() (m map[K]V) {
 
 	return m
 }
+
+// Clone returns a shallow copy that can be changed without affecting h.
+func (h *HashMap[K, V]) Clone() *HashMap[K, V] {
+	return &HashMap[K, V]{inner: h.ToMap()}
+}
+
+// AddMissing stores the entries of other whose keys are not in h. base is the
+// copy both h and other started from: keys in base that are missing from h
+// were removed from h since, and are not added back. Entries already in h
+// keep their value. A nil base adds every missing key.
+func (h *HashMap[K, V]) AddMissing(other *HashMap[K, V], base *HashMap[K, V]) {
+	if other == nil || other == h {
+		return
+	}
+
+	for k, v := range other.ToMap() {
+		if base != nil && base.Exist(k) {
+			continue
+		}
+		h.LoadOrStore(k, v)
+	}
+}
@@ -3,6 +3,7 @@ package hotkey
 import (
 	"fmt"
 	"strings"
+	"wox/util"
 	"wox/util/keyboard"
 
 	"github.com/samber/lo"
@@ -89,3 +90,103 @@ func IsDoubleModifierHotkeyString(combineKey string) bool {
 	spec, err := (&Hotkey{}).parseCombineKey(combineKey)
 	return err == nil && spec.isDoubleModifier()
 }
+
+// ParsedHotkey is a validated hotkey string together with its canonical form.
+// It is separate from Hotkey, which owns a live OS registration.
+type ParsedHotkey struct {
+	normalized string
+}
+
+// String returns the canonical hotkey, e.g. both "Space+Alt" and "alt+space"
+// become "alt+space". Modifier names follow the settings recorder so the UI can
+// display the stored value unchanged.
+func (p ParsedHotkey) String() string {
+	return p.normalized
+}
+
+// Parse validates a hotkey string without registering it, so settings can
+// reject malformed input with a clear message instead of surfacing a confusing
+// registration failure later.
+func Parse(combineKey string) (ParsedHotkey, error) {
+	tokens := lo.Map(strings.Split(combineKey, "+"), func(item string, index int) string {
+		return strings.ToLower(strings.TrimSpace(item))
+	})
+	if strings.TrimSpace(combineKey) == "" {
+		return ParsedHotkey{}, fmt.Errorf("hotkey is empty")
+	}
+
+	// parseCombineKey only reports the raw token error, so check tokens first to
+	// tell users whether a modifier or the main key was misspelled.
+	keyToken := ""
+	for index, token := range tokens {
+		if token == "" {
+			return ParsedHotkey{}, fmt.Errorf("empty key in hotkey '%s'", combineKey)
+		}
+		if isCapsLockToken(token) && len(tokens) > 1 {
+			continue
+		}
+		if _, _, ok := parseModifierToken(token); ok {
+			continue
+		}
+		if _, err := keyboard.ParseKey(token); err != nil {
+			if index < len(tokens)-1 {
+				return ParsedHotkey{}, fmt.Errorf("unknown modifier '%s'", token)
+			}
+			return ParsedHotkey{}, fmt.Errorf("unknown key '%s'", token)
+		}
+		keyToken = token
+	}
+
+	spec, err := (&Hotkey{}).parseCombineKey(combineKey)
+	if err != nil {
+		return ParsedHotkey{}, err
+	}
+
+	var parts []string
+	switch {
+	case spec.isDoubleModifier():
+		name := canonicalModifierToken(spec.doubleModifierKey)
+		parts = []string{name, name}
+	case spec.isCapsLockKey():
+		parts = []string{"capslock", keyToken}
+	default:
+		for _, modifier := range []struct {
+			flag keyboard.Modifier
+			key  keyboard.Key
+		}{
+			{keyboard.ModifierCtrl, keyboard.KeyCtrl},
+			{keyboard.ModifierAlt, keyboard.KeyAlt},
+			{keyboard.ModifierShift, keyboard.KeyShift},
+			{keyboard.ModifierSuper, keyboard.KeySuper},
+		} {
+			if spec.modifiers&modifier.flag != 0 {
+				parts = append(parts, canonicalModifierToken(modifier.key))
+			}
+		}
+		parts = append(parts, keyToken)
+	}
+
+	return ParsedHotkey{normalized: strings.Join(parts, "+")}, nil
+}
+
+// canonicalModifierToken mirrors the modifier names written by the Flutter hotkey recorder.
+func canonicalModifierToken(modifierKey keyboard.Key) string {
+	switch modifierKey {
+	case keyboard.KeyCtrl:
+		return "ctrl"
+	case keyboard.KeyShift:
+		return "shift"
+	case keyboard.KeyAlt:
+		if util.IsMacOS() {
+			return "option"
+		}
+		return "alt"
+	case keyboard.KeySuper:
+		if util.IsMacOS() {
+			return "cmd"
+		}
+		return "win"
+	default:
+		return ""
+	}
+}
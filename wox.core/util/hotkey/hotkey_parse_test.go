@@ -0,0 +1,53 @@
+package hotkey
+
+import (
+	"testing"
+	"wox/util"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string // canonical form on Windows and Linux
+		wantMac string // canonical form on macOS
+		wantErr string // error on Windows and Linux
+		macErr  string // error on macOS
+	}{
+		{name: "canonical", input: "alt+space", want: "alt+space", wantMac: "option+space"},
+		{name: "order and case", input: "Space+Alt", want: "alt+space", wantMac: "option+space"},
+		{name: "modifier order", input: "shift+ctrl+k", want: "ctrl+shift+k", wantMac: "ctrl+shift+k"},
+		{name: "spaces around tokens", input: " ctrl + shift + K ", want: "ctrl+shift+k", wantMac: "ctrl+shift+k"},
+		{name: "option alias", input: "option+space", wantErr: "unknown modifier 'option'", wantMac: "option+space"},
+		{name: "cmd alias", input: "Cmd+Shift+K", wantErr: "unknown modifier 'cmd'", wantMac: "shift+cmd+k"},
+		{name: "command alias", input: "command+k", wantErr: "unknown modifier 'command'", wantMac: "cmd+k"},
+		{name: "double modifier", input: "ctrl+ctrl", want: "ctrl+ctrl", wantMac: "ctrl+ctrl"},
+		{name: "misspelled modifier", input: "ctr+space", wantErr: "unknown modifier 'ctr'", macErr: "unknown modifier 'ctr'"},
+		{name: "misspelled key", input: "ctrl+spcae", wantErr: "unknown key 'spcae'", macErr: "unknown key 'spcae'"},
+		{name: "empty", input: " ", wantErr: "hotkey is empty", macErr: "hotkey is empty"},
+		{name: "empty token", input: "ctrl++space", wantErr: "empty key in hotkey 'ctrl++space'", macErr: "empty key in hotkey 'ctrl++space'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := tt.want, tt.wantErr
+			if util.IsMacOS() {
+				want, wantErr = tt.wantMac, tt.macErr
+			}
+
+			parsed, err := Parse(tt.input)
+			if wantErr != "" {
+				if err == nil || err.Error() != wantErr {
+					t.Fatalf("expected error %q, got %v (%q)", wantErr, err, parsed.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected %q, got error %v", want, err)
+			}
+			if parsed.String() != want {
+				t.Fatalf("expected %q, got %q", want, parsed.String())
+			}
+		})
+	}
+}
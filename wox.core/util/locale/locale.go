@@ -11,7 +11,8 @@ import (
 var (
 	cachedLang   string
 	cachedRegion string
-	localeOnce   sync.Once
+	localeLoaded bool
+	localeMu     sync.Mutex
 )
 
 func IsZhCN() bool {
@@ -21,12 +22,28 @@ func IsZhCN() bool {
 
 // GetLocale returns the user's language and region
 func GetLocale() (string, string) {
-	localeOnce.Do(func() {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+
+	if !localeLoaded {
 		cachedLang, cachedRegion = detectLocale()
-	})
+		localeLoaded = true
+	}
 	return cachedLang, cachedRegion
 }
 
+// Refresh detects the locale again and updates the value returned by GetLocale.
+// Detection shells out on Windows and macOS, so callers should not poll it often.
+func Refresh() (string, string) {
+	lang, region := detectLocale()
+
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	cachedLang, cachedRegion = lang, region
+	localeLoaded = true
+	return lang, region
+}
+
 func detectLocale() (string, string) {
 	osHost := runtime.GOOS
 	defaultLang := "en"
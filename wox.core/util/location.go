@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -13,6 +15,24 @@ import (
 var locationInstance *Location
 var locationOnce sync.Once
 
+const (
+	// PortableModeEnv enables portable mode when set to a true value, for
+	// launchers that cannot create the marker file next to the executable.
+	PortableModeEnv = "WOX_PORTABLE"
+	// DataDirEnv moves everything of one Wox instance into another directory,
+	// e.g. to run isolated profiles side by side. It replaces ~/.wox like
+	// portable mode does: logs, cache, backups, wox.lock and the secret key
+	// file live in it, and user data in its wox-user subdirectory. Precedence,
+	// highest first: the test overrides, DataDirEnv, portable mode, then
+	// ~/.wox with the .userdata.location shortcut file.
+	DataDirEnv = "WOX_DATA_DIR"
+	// portableMarkerFileName next to the Wox executable enables portable mode.
+	portableMarkerFileName = "portable.txt"
+	// portableDataDirectoryName is created next to the executable in portable
+	// mode and replaces ~/.wox.
+	portableDataDirectoryName = "wox-data"
+)
+
 type Location struct {
 	// wox data directory is the directory that contains all wox data, including logs, hosts, etc.
 	woxDataDirectory string
@@ -22,6 +42,11 @@ type Location struct {
 	userDataDirectory string
 
 	userDataDirectoryShortcutPath string // A file named .wox.location that contains the user data directory path
+
+	// portable is true when all data lives next to the executable, see getPortableDataDirectory.
+	portable bool
+	// dataDirectoryFromEnv is true when DataDirEnv chose the wox data directory.
+	dataDirectoryFromEnv bool
 }
 
 func GetLocation() *Location {
@@ -39,7 +64,19 @@ func (l *Location) Init() error {
 
 	woxDataDirectory := GetTestWoxDataDirectoryOverride()
 	if woxDataDirectory == "" {
-		woxDataDirectory = path.Join(dirname, ".wox")
+		if envDirectory := strings.TrimSpace(os.Getenv(DataDirEnv)); envDirectory != "" {
+			expanded, expandErr := homedir.Expand(envDirectory)
+			if expandErr != nil {
+				return fmt.Errorf("invalid %s: %w", DataDirEnv, expandErr)
+			}
+			woxDataDirectory = expanded
+			l.dataDirectoryFromEnv = true
+		} else if portableDirectory := getPortableDataDirectory(); portableDirectory != "" {
+			woxDataDirectory = portableDirectory
+			l.portable = true
+		} else {
+			woxDataDirectory = path.Join(dirname, ".wox")
+		}
 	}
 
 	// check if wox data directory exists, if not, create it
@@ -52,6 +89,12 @@ func (l *Location) Init() error {
 	userDataDirectoryOverride := GetTestUserDataDirectoryOverride()
 	if userDataDirectoryOverride != "" {
 		l.userDataDirectory = userDataDirectoryOverride
+	} else if l.portable || l.dataDirectoryFromEnv {
+		// The shortcut file stores an absolute path, which breaks as soon as a
+		// USB stick gets another drive letter, so portable mode always keeps
+		// user data inside the portable data directory. An instance moved by
+		// DataDirEnv must not share the shortcut of the default instance.
+		l.userDataDirectory = path.Join(l.woxDataDirectory, "wox-user")
 	} else {
 		if _, statErr := os.Stat(l.userDataDirectoryShortcutPath); os.IsNotExist(statErr) {
 			// shortcut file does not exist, create and write default data directory path to it
@@ -133,6 +176,39 @@ func (l *Location) Init() error {
 	return nil
 }
 
+// getPortableDataDirectory returns the executable-relative data directory when
+// portable mode is enabled by PortableModeEnv or a portable.txt marker next to
+// the executable, and an empty string otherwise.
+func getPortableDataDirectory() string {
+	executable, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, resolveErr := filepath.EvalSymlinks(executable); resolveErr == nil {
+		executable = resolved
+	}
+	executableDirectory := filepath.Dir(executable)
+
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(PortableModeEnv)))
+	if !enabled {
+		if _, statErr := os.Stat(filepath.Join(executableDirectory, portableMarkerFileName)); statErr != nil {
+			return ""
+		}
+	}
+
+	return filepath.Join(executableDirectory, portableDataDirectoryName)
+}
+
+// IsPortable reports whether Wox stores its data next to the executable.
+func (l *Location) IsPortable() bool {
+	return l.portable
+}
+
+// IsDataDirectoryFromEnv reports whether DataDirEnv chose the data directories.
+func (l *Location) IsDataDirectoryFromEnv() bool {
+	return l.dataDirectoryFromEnv
+}
+
 func (l *Location) EnsureDirectoryExist(directory string) error {
 	if _, statErr := os.Stat(directory); os.IsNotExist(statErr) {
 		mkdirErr := os.MkdirAll(directory, os.ModePerm)
@@ -180,6 +256,11 @@ func (l *Location) GetUserDataDirectory() string {
 	return l.userDataDirectory
 }
 
+// GetDatabasePath returns wox.db, which holds all settings and app data.
+func (l *Location) GetDatabasePath() string {
+	return path.Join(l.userDataDirectory, "wox.db")
+}
+
 func (l *Location) GetWoxSettingPath() string {
 	return path.Join(l.GetPluginSettingDirectory(), "wox.json")
 }
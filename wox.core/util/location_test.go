@@ -0,0 +1,101 @@
+package util
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocationDataDirEnvMovesAllInstancePaths(t *testing.T) {
+	root := t.TempDir()
+	dataDirectory := filepath.Join(root, "profile")
+	userDataDirectory := path.Join(dataDirectory, "wox-user")
+	t.Setenv(TestWoxDataDirEnv, "")
+	t.Setenv(TestUserDataDirEnv, "")
+	t.Setenv(DataDirEnv, dataDirectory)
+
+	location := &Location{}
+	if err := location.Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+
+	if !location.IsDataDirectoryFromEnv() {
+		t.Fatalf("expected data directory to come from %s", DataDirEnv)
+	}
+	expected := map[string]string{
+		"GetWoxDataDirectory":       dataDirectory,
+		"GetUserDataDirectory":      userDataDirectory,
+		"GetDatabasePath":           path.Join(userDataDirectory, "wox.db"),
+		"GetAppLockPath":            path.Join(dataDirectory, "wox.lock"),
+		"GetPluginSettingDirectory": path.Join(userDataDirectory, "settings"),
+		"GetPluginDirectory":        path.Join(userDataDirectory, "plugins"),
+		"GetThemeDirectory":         path.Join(userDataDirectory, "themes"),
+		"GetLogDirectory":           path.Join(dataDirectory, "log"),
+		"GetBackupDirectory":        path.Join(dataDirectory, "backup"),
+		"GetCacheDirectory":         path.Join(dataDirectory, "cache"),
+	}
+	actual := map[string]string{
+		"GetWoxDataDirectory":       location.GetWoxDataDirectory(),
+		"GetUserDataDirectory":      location.GetUserDataDirectory(),
+		"GetDatabasePath":           location.GetDatabasePath(),
+		"GetAppLockPath":            location.GetAppLockPath(),
+		"GetPluginSettingDirectory": location.GetPluginSettingDirectory(),
+		"GetPluginDirectory":        location.GetPluginDirectory(),
+		"GetThemeDirectory":         location.GetThemeDirectory(),
+		"GetLogDirectory":           location.GetLogDirectory(),
+		"GetBackupDirectory":        location.GetBackupDirectory(),
+		"GetCacheDirectory":         location.GetCacheDirectory(),
+	}
+	for name, want := range expected {
+		if actual[name] != want {
+			t.Errorf("%s = %q, want %q", name, actual[name], want)
+		}
+	}
+
+	// The instance must not write the shortcut of the default instance.
+	if _, err := os.Stat(location.GetUserDataDirectoryShortcutPath()); !os.IsNotExist(err) {
+		t.Errorf("expected no shortcut file in %s, got err %v", dataDirectory, err)
+	}
+}
+
+func TestLocationTestOverrideTakesPrecedenceOverDataDirEnv(t *testing.T) {
+	root := t.TempDir()
+	testWoxDirectory := filepath.Join(root, "wox")
+	testUserDirectory := filepath.Join(root, "test-user")
+	t.Setenv(TestWoxDataDirEnv, testWoxDirectory)
+	t.Setenv(TestUserDataDirEnv, testUserDirectory)
+	t.Setenv(DataDirEnv, filepath.Join(root, "profile"))
+
+	location := &Location{}
+	if err := location.Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+
+	if location.GetWoxDataDirectory() != testWoxDirectory {
+		t.Fatalf("GetWoxDataDirectory = %q, want %q", location.GetWoxDataDirectory(), testWoxDirectory)
+	}
+	if location.GetUserDataDirectory() != testUserDirectory {
+		t.Fatalf("GetUserDataDirectory = %q, want %q", location.GetUserDataDirectory(), testUserDirectory)
+	}
+	if location.IsDataDirectoryFromEnv() {
+		t.Fatalf("expected test override to win over %s", DataDirEnv)
+	}
+}
+
+func TestLocationWithoutDataDirEnvUsesShortcutFile(t *testing.T) {
+	root := t.TempDir()
+	woxDataDirectory := filepath.Join(root, "wox")
+	t.Setenv(TestWoxDataDirEnv, woxDataDirectory)
+	t.Setenv(TestUserDataDirEnv, "")
+	t.Setenv(DataDirEnv, "")
+
+	location := &Location{}
+	if err := location.Init(); err != nil {
+		t.Fatalf("failed to init location: %v", err)
+	}
+
+	if location.GetUserDataDirectory() != path.Join(woxDataDirectory, "wox-user") {
+		t.Fatalf("GetUserDataDirectory = %q, want default under %q", location.GetUserDataDirectory(), woxDataDirectory)
+	}
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LogFields formats key/value pairs as space separated key=value, so log lines
+// can be filtered by field with grep or parsed by log tools. Values with
+// spaces, quotes or '=' are quoted. A trailing key without value is dropped.
+func LogFields(keysAndValues ...any) string {
+	var builder strings.Builder
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if builder.Len() > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(fmt.Sprint(keysAndValues[i]))
+		builder.WriteByte('=')
+
+		value := fmt.Sprint(keysAndValues[i+1])
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+		builder.WriteString(value)
+	}
+	return builder.String()
+}
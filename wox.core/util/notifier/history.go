@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"sync"
+	"wox/util"
+)
+
+const maxHistoryEntries = 100
+
+// HistoryEntry is a notification that was requested, whether or not it was shown.
+type HistoryEntry struct {
+	Timestamp  int64
+	Message    string
+	Suppressed bool // true when do-not-disturb kept the notification from being shown
+}
+
+var historyMu sync.Mutex
+var history []HistoryEntry
+
+var suppressMu sync.RWMutex
+var suppressFunc func() bool
+
+// SetSuppressFunc registers the check used to hide notifications, e.g. while
+// do-not-disturb is active. The setting manager registers it at startup so this
+// package does not depend on settings.
+func SetSuppressFunc(fn func() bool) {
+	suppressMu.Lock()
+	defer suppressMu.Unlock()
+	suppressFunc = fn
+}
+
+// GetHistory returns up to limit recent notifications, newest first. A limit of
+// zero or less returns every retained entry.
+func GetHistory(limit int) []HistoryEntry {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	result := make([]HistoryEntry, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, history[i])
+	}
+	return result
+}
+
+// shouldShow records the notification and reports whether it may be displayed.
+func shouldShow(message string) bool {
+	suppressed := isSuppressed()
+	recordHistory(message, suppressed)
+	return !suppressed
+}
+
+// Suppressed reports whether do-not-disturb hides notifications right now and
+// records message in the history as suppressed when it does. Callers that
+// show notifications without Notify, e.g. as a launcher toolbar message,
+// check it first.
+func Suppressed(message string) bool {
+	if !isSuppressed() {
+		return false
+	}
+	recordHistory(message, true)
+	return true
+}
+
+func recordHistory(message string, suppressed bool) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	history = append(history, HistoryEntry{Timestamp: util.GetSystemTimestamp(), Message: message, Suppressed: suppressed})
+	if len(history) > maxHistoryEntries {
+		history = history[len(history)-maxHistoryEntries:]
+	}
+}
+
+// isSuppressed reports whether notifications are currently hidden without
+// recording anything, for updates that should not flood the history.
+func isSuppressed() bool {
+	suppressMu.RLock()
+	fn := suppressFunc
+	suppressMu.RUnlock()
+	return fn != nil && fn()
+}
@@ -4,30 +4,23 @@ import (
 	"image"
 	"wox/common"
 	"wox/util"
-	"wox/util/overlay"
 )
 
 func Notify(icon image.Image, message string) {
 	if message == "" {
 		return
 	}
+	if !shouldShow(message) {
+		return
+	}
 	if icon == nil {
 		img, _ := common.WoxIcon.ToImage()
 		icon = img
 	}
 
-	util.Go(util.NewTraceContext(), "notifier.Notify", func() {
-		overlay.Show(overlay.OverlayOptions{
-			Name:             "wox_notifier",
-			Message:          message,
-			Icon:             overlay.NewImageIcon(icon),
-			Closable:         true,
-			Anchor:           overlay.AnchorBottomCenter,
-			OffsetY:          -80,
-			AutoCloseSeconds: 5,
-			FontSize:         12,
-			IconSize:         20,
-			Movable:          true,
-		})
+	ctx := util.NewTraceContext()
+	sound := notificationSound(ctx)
+	util.Go(ctx, "notifier.Notify", func() {
+		showNotification(ctx, icon, message, sound)
 	})
 }
@@ -0,0 +1,86 @@
+package notifier
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation -framework UserNotifications
+#include <stdlib.h>
+
+int isBundledApp();
+int showUserNotification(const char *title, const char *message, int withSound);
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+	"unsafe"
+	"wox/util"
+	"wox/util/shell"
+)
+
+// Progress notifications are drawn with the overlay window, see ShowProgress.
+const progressOverlaySupported = true
+
+const (
+	notificationTitle     = "Wox"
+	notificationCallLimit = 5 * time.Second
+)
+
+// Result codes of showUserNotification, see notify_darwin.m.
+const (
+	userNotificationShown  = 0
+	userNotificationDenied = 1
+	userNotificationFailed = 2
+)
+
+// showNotification posts the notification to the macOS notification center.
+// UNUserNotificationCenter needs a bundle identifier, so dev builds that are
+// not launched from a .app bundle use AppleScript instead. The notification
+// center always shows the app icon, so icon is not used. It can only play the
+// default sound, custom sound files are played with NSSound.
+func showNotification(ctx context.Context, icon image.Image, message string, sound string) {
+	if C.isBundledApp() == 0 {
+		if err := notifyAppleScript(ctx, message); err != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to show notification with AppleScript: %s", err.Error()))
+		}
+		playSound(ctx, sound)
+		return
+	}
+
+	cTitle := C.CString(notificationTitle)
+	defer C.free(unsafe.Pointer(cTitle))
+	cMessage := C.CString(message)
+	defer C.free(unsafe.Pointer(cMessage))
+
+	withSound := C.int(0)
+	if sound == SoundDefault {
+		withSound = 1
+	} else {
+		playSound(ctx, sound)
+	}
+
+	switch C.showUserNotification(cTitle, cMessage, withSound) {
+	case userNotificationShown:
+	case userNotificationDenied:
+		util.GetLogger().Warn(ctx, "notifications are disabled for Wox in System Settings, notification is not shown")
+	default:
+		util.GetLogger().Warn(ctx, "failed to show notification with the notification center")
+	}
+}
+
+func notifyAppleScript(ctx context.Context, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(notificationTitle))
+
+	callCtx, cancel := context.WithTimeout(ctx, notificationCallLimit)
+	defer cancel()
+	return shell.BuildCommandContext(callCtx, "osascript", nil, "-e", script).Run()
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,100 @@
+#import <Foundation/Foundation.h>
+#import <AppKit/AppKit.h>
+#import <UserNotifications/UserNotifications.h>
+
+// Shows banners while Wox is the active app, the notification center hides
+// them by default for the frontmost app.
+@interface WoxNotificationDelegate : NSObject <UNUserNotificationCenterDelegate>
+@end
+
+@implementation WoxNotificationDelegate
+- (void)userNotificationCenter:(UNUserNotificationCenter *)center
+       willPresentNotification:(UNNotification *)notification
+         withCompletionHandler:(void (^)(UNNotificationPresentationOptions options))completionHandler {
+    if (@available(macOS 11.0, *)) {
+        completionHandler(UNNotificationPresentationOptionBanner | UNNotificationPresentationOptionList);
+    } else {
+        completionHandler(UNNotificationPresentationOptionAlert);
+    }
+}
+@end
+
+static WoxNotificationDelegate *notificationDelegate = nil;
+
+int isBundledApp() {
+    @autoreleasepool {
+        NSBundle *bundle = [NSBundle mainBundle];
+        return [bundle bundleIdentifier] != nil && [[bundle bundlePath] hasSuffix:@".app"];
+    }
+}
+
+// showUserNotification returns 0 when the notification was posted, 1 when the
+// user denied notifications and 2 on any other failure. With withSound the
+// notification plays the default notification sound.
+int showUserNotification(const char *title, const char *message, int withSound) {
+    __block int result = 2;
+    @autoreleasepool {
+        UNUserNotificationCenter *center = [UNUserNotificationCenter currentNotificationCenter];
+        static dispatch_once_t onceToken;
+        dispatch_once(&onceToken, ^{
+            notificationDelegate = [[WoxNotificationDelegate alloc] init];
+            center.delegate = notificationDelegate;
+        });
+
+        NSString *titleString = [NSString stringWithUTF8String:title];
+        NSString *messageString = [NSString stringWithUTF8String:message];
+        dispatch_semaphore_t done = dispatch_semaphore_create(0);
+
+        // The permission prompt is only shown once, later calls return the
+        // stored decision immediately.
+        [center requestAuthorizationWithOptions:UNAuthorizationOptionAlert | UNAuthorizationOptionSound
+                              completionHandler:^(BOOL granted, NSError *error) {
+            if (!granted) {
+                result = 1;
+                dispatch_semaphore_signal(done);
+                return;
+            }
+
+            UNMutableNotificationContent *content = [[UNMutableNotificationContent alloc] init];
+            content.title = titleString;
+            content.body = messageString;
+            if (withSound) {
+                content.sound = [UNNotificationSound defaultSound];
+            }
+            UNNotificationRequest *request = [UNNotificationRequest requestWithIdentifier:[[NSUUID UUID] UUIDString]
+                                                                                  content:content
+                                                                                  trigger:nil];
+            [content release];
+            [center addNotificationRequest:request withCompletionHandler:^(NSError *addError) {
+                result = addError == nil ? 0 : 2;
+                dispatch_semaphore_signal(done);
+            }];
+        }];
+
+        // Never block the caller on a stuck notification daemon.
+        if (dispatch_semaphore_wait(done, dispatch_time(DISPATCH_TIME_NOW, 5 * NSEC_PER_SEC)) != 0) {
+            result = 2;
+        }
+        dispatch_release(done);
+    }
+    return result;
+}
+
+// playNotificationSound plays the sound file at path, or the Glass system
+// sound for an empty path. It returns 0 when the sound could not be played.
+int playNotificationSound(const char *path) {
+    @autoreleasepool {
+        NSString *pathString = [NSString stringWithUTF8String:path];
+        NSSound *sound = nil;
+        if ([pathString length] == 0) {
+            sound = [NSSound soundNamed:@"Glass"];
+        } else {
+            sound = [[[NSSound alloc] initWithContentsOfFile:pathString byReference:YES] autorelease];
+        }
+        if (sound == nil) {
+            return 0;
+        }
+        // play returns immediately, the sound keeps playing in the background.
+        return [sound play] ? 1 : 0;
+    }
+}
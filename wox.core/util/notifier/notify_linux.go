@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os/exec"
+	"time"
+	"wox/util"
+	"wox/util/shell"
+
+	"github.com/disintegration/imaging"
+	"github.com/godbus/dbus/v5"
+)
+
+// Linux has no overlay window implementation, so progress notifications
+// fall back to a regular notification on completion, see ShowProgress.
+const progressOverlaySupported = false
+
+const (
+	notificationsBusName    = "org.freedesktop.Notifications"
+	notificationsObjectPath = dbus.ObjectPath("/org/freedesktop/Notifications")
+	notificationsMethod     = notificationsBusName + ".Notify"
+
+	notificationAppName   = "Wox"
+	notificationTimeoutMs = 5000
+	notificationIconSize  = 64
+	notificationCallLimit = 5 * time.Second
+)
+
+// notificationImage is the image-data hint of the freedesktop notification
+// spec, signature (iiibiiay).
+type notificationImage struct {
+	Width         int32
+	Height        int32
+	RowStride     int32
+	HasAlpha      bool
+	BitsPerSample int32
+	Channels      int32
+	Data          []byte
+}
+
+// showNotification sends the notification to the desktop's notification
+// daemon over D-Bus and falls back to notify-send. The daemon plays sound,
+// see soundHints. Linux has no overlay window implementation, so without a
+// daemon the notification is only logged and kept in the notification history.
+func showNotification(ctx context.Context, icon image.Image, message string, sound string) {
+	dbusErr := notifyDBus(ctx, icon, message, sound)
+	if dbusErr == nil {
+		return
+	}
+
+	notifySendErr := notifySend(ctx, message, sound)
+	if notifySendErr == nil {
+		return
+	}
+
+	util.GetLogger().Warn(ctx, fmt.Sprintf("failed to show notification, is a notification daemon running? dbus: %s, notify-send: %s", dbusErr.Error(), notifySendErr.Error()))
+}
+
+func notifyDBus(ctx context.Context, icon image.Image, message string, sound string) error {
+	// The shared session bus connection is reused by the whole process and
+	// must not be closed here.
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	hints := map[string]dbus.Variant{}
+	for name, value := range soundHints(sound) {
+		hints[name] = dbus.MakeVariant(value)
+	}
+	if icon != nil {
+		hints["image-data"] = dbus.MakeVariant(toNotificationImage(icon))
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, notificationCallLimit)
+	defer cancel()
+	call := conn.Object(notificationsBusName, notificationsObjectPath).CallWithContext(callCtx, notificationsMethod, 0,
+		notificationAppName, uint32(0), "", notificationAppName, message, []string{}, hints, int32(notificationTimeoutMs))
+	if call.Err != nil {
+		return call.Err
+	}
+	return nil
+}
+
+func notifySend(ctx context.Context, message string, sound string) error {
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return err
+	}
+
+	args := []string{"--app-name", notificationAppName, "--expire-time", fmt.Sprintf("%d", notificationTimeoutMs)}
+	for name, value := range soundHints(sound) {
+		switch typed := value.(type) {
+		case bool:
+			args = append(args, fmt.Sprintf("--hint=boolean:%s:%t", name, typed))
+		case string:
+			args = append(args, fmt.Sprintf("--hint=string:%s:%s", name, typed))
+		}
+	}
+	args = append(args, notificationAppName, message)
+
+	callCtx, cancel := context.WithTimeout(ctx, notificationCallLimit)
+	defer cancel()
+	return shell.BuildCommandContext(callCtx, "notify-send", nil, args...).Run()
+}
+
+// toNotificationImage converts the icon to the RGBA layout of the image-data
+// hint, scaled down so large icons do not bloat the D-Bus message.
+func toNotificationImage(icon image.Image) notificationImage {
+	rgba := imaging.Fit(icon, notificationIconSize, notificationIconSize, imaging.Lanczos)
+	return notificationImage{
+		Width:         int32(rgba.Rect.Dx()),
+		Height:        int32(rgba.Rect.Dy()),
+		RowStride:     int32(rgba.Stride),
+		HasAlpha:      true,
+		BitsPerSample: 8,
+		Channels:      4,
+		Data:          rgba.Pix,
+	}
+}
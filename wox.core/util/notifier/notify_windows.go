@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"context"
+	"image"
+	"wox/util/overlay"
+)
+
+// Progress notifications are drawn with the overlay window, see ShowProgress.
+const progressOverlaySupported = true
+
+// showNotification draws the notification with the native overlay window
+// and plays sound with the system sound APIs.
+func showNotification(ctx context.Context, icon image.Image, message string, sound string) {
+	playSound(ctx, sound)
+	overlay.Show(overlay.OverlayOptions{
+		Name:             "wox_notifier",
+		Message:          message,
+		Icon:             overlay.NewImageIcon(icon),
+		Closable:         true,
+		Anchor:           overlay.AnchorBottomCenter,
+		OffsetY:          -80,
+		AutoCloseSeconds: 5,
+		FontSize:         12,
+		IconSize:         20,
+		Movable:          true,
+	})
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"fmt"
+	"sync"
+	"wox/common"
+	"wox/util"
+	"wox/util/overlay"
+)
+
+const progressOverlayNamePrefix = "wox_notifier_progress_"
+
+// Overlay calls of progress notifications, replaced in tests.
+var (
+	showProgressOverlay  = overlay.Show
+	closeProgressOverlay = overlay.Close
+)
+
+// progressUpdates orders the asynchronous overlay updates of progress
+// notifications. Every update reserves a sequence number when it is requested
+// and is applied only if no newer update of the same id was requested since,
+// so a late progress update cannot reopen a completed notification as a
+// loading toast that never closes.
+var progressUpdates = struct {
+	sync.Mutex
+	nextSeq uint64
+	latest  map[string]uint64
+}{latest: map[string]uint64{}}
+
+// ShowProgress creates or updates a progress notification identified by id.
+// Overlays are keyed by name, so calling it again with the same id replaces
+// the text of the existing notification instead of stacking a new one. This
+// mirrors updating a Windows toast by tag. Progress updates are not recorded
+// in the notification history, only the final CompleteProgress message is.
+//
+// Linux has no overlay window, so progress updates are not shown there and
+// only the CompleteProgress message is sent as a regular notification.
+func ShowProgress(id string, title string, percent int) {
+	if id == "" || isSuppressed() || !progressOverlaySupported {
+		return
+	}
+	percent = max(0, min(100, percent))
+
+	message := fmt.Sprintf("%d%%", percent)
+	if title != "" {
+		message = fmt.Sprintf("%s (%d%%)", title, percent)
+	}
+
+	queueProgressUpdate(id, false, func() {
+		showProgressOverlay(progressOverlayOptions(id, message, true))
+	})
+}
+
+// CompleteProgress finalizes the progress notification identified by id with
+// message and lets it close like a regular notification, including its sound.
+func CompleteProgress(id string, message string) {
+	if id == "" {
+		return
+	}
+	if !progressOverlaySupported {
+		Notify(nil, message)
+		return
+	}
+	completeProgressOverlay(id, message)
+}
+
+// completeProgressOverlay is CompleteProgress for platforms that draw
+// progress notifications with the overlay window.
+func completeProgressOverlay(id string, message string) {
+	if !shouldShow(message) {
+		// the progress overlay may have been shown before do-not-disturb started
+		queueProgressUpdate(id, true, func() {
+			closeProgressOverlay(progressOverlayNamePrefix + id)
+		})
+		return
+	}
+
+	ctx := util.NewTraceContext()
+	sound := notificationSound(ctx)
+	queueProgressUpdate(id, true, func() {
+		showProgressOverlay(progressOverlayOptions(id, message, false))
+		playOverlaySound(ctx, sound)
+	})
+}
+
+// queueProgressUpdate reserves the next sequence number for id and applies
+// update asynchronously, see applyProgressUpdate.
+func queueProgressUpdate(id string, complete bool, update func()) {
+	seq := reserveProgressUpdate(id)
+	util.Go(util.NewTraceContext(), "notifier.progressUpdate", func() {
+		applyProgressUpdate(id, seq, complete, update)
+	})
+}
+
+func reserveProgressUpdate(id string) uint64 {
+	progressUpdates.Lock()
+	defer progressUpdates.Unlock()
+
+	progressUpdates.nextSeq++
+	progressUpdates.latest[id] = progressUpdates.nextSeq
+	return progressUpdates.nextSeq
+}
+
+// applyProgressUpdate runs update unless a newer update of id was reserved
+// after seq. Updates are applied under the lock so two updates of the same
+// notification never interleave. A completion forgets id, so stale progress
+// updates that arrive after it are dropped and the next ShowProgress with the
+// same id starts a new notification.
+func applyProgressUpdate(id string, seq uint64, complete bool, update func()) bool {
+	progressUpdates.Lock()
+	defer progressUpdates.Unlock()
+
+	if progressUpdates.latest[id] != seq {
+		return false
+	}
+	if complete {
+		delete(progressUpdates.latest, id)
+	}
+	update()
+	return true
+}
+
+func progressOverlayOptions(id string, message string, loading bool) overlay.OverlayOptions {
+	opts := overlay.OverlayOptions{
+		Name:             progressOverlayNamePrefix + id,
+		Message:          message,
+		Closable:         true,
+		Loading:          loading,
+		Anchor:           overlay.AnchorBottomCenter,
+		OffsetY:          -80,
+		FontSize:         12,
+		IconSize:         20,
+		Movable:          true,
+		PreservePosition: true,
+	}
+	if !loading {
+		opts.AutoCloseSeconds = 5
+	}
+	if icon, err := common.WoxIcon.ToImage(); err == nil {
+		opts.Icon = overlay.NewImageIcon(icon)
+	}
+	return opts
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+	"wox/util/overlay"
+)
+
+func TestProgressUpdateArrivingAfterCompletionIsDropped(t *testing.T) {
+	var applied []string
+	progress := reserveProgressUpdate("late")
+	complete := reserveProgressUpdate("late")
+
+	// the completion goroutine runs before the earlier progress goroutine
+	if !applyProgressUpdate("late", complete, true, func() { applied = append(applied, "complete") }) {
+		t.Fatal("expected the completion to be applied")
+	}
+	if applyProgressUpdate("late", progress, false, func() { applied = append(applied, "progress") }) {
+		t.Fatal("expected the stale progress update to be dropped")
+	}
+
+	if len(applied) != 1 || applied[0] != "complete" {
+		t.Fatalf("unexpected applied updates: %v", applied)
+	}
+}
+
+func TestProgressUpdatesOnlyApplyTheLatest(t *testing.T) {
+	var applied []string
+	first := reserveProgressUpdate("order")
+	second := reserveProgressUpdate("order")
+
+	if !applyProgressUpdate("order", second, false, func() { applied = append(applied, "second") }) {
+		t.Fatal("expected the latest update to be applied")
+	}
+	if applyProgressUpdate("order", first, false, func() { applied = append(applied, "first") }) {
+		t.Fatal("expected the superseded update to be dropped")
+	}
+
+	complete := reserveProgressUpdate("order")
+	if !applyProgressUpdate("order", complete, true, func() { applied = append(applied, "complete") }) {
+		t.Fatal("expected the completion to be applied")
+	}
+
+	// the id is reusable for a new notification after completion
+	next := reserveProgressUpdate("order")
+	if !applyProgressUpdate("order", next, false, func() { applied = append(applied, "next") }) {
+		t.Fatal("expected a new progress update after completion to be applied")
+	}
+
+	want := []string{"second", "complete", "next"}
+	if len(applied) != len(want) {
+		t.Fatalf("unexpected applied updates: %v", applied)
+	}
+	for i := range want {
+		if applied[i] != want[i] {
+			t.Fatalf("unexpected applied updates: %v", applied)
+		}
+	}
+}
+
+func TestProgressUpdatesOfDifferentIdsAreIndependent(t *testing.T) {
+	a := reserveProgressUpdate("independent-a")
+	b := reserveProgressUpdate("independent-b")
+
+	if !applyProgressUpdate("independent-a", a, false, func() {}) {
+		t.Fatal("expected the update of a to be applied")
+	}
+	if !applyProgressUpdate("independent-b", b, false, func() {}) {
+		t.Fatal("expected the update of b to be applied")
+	}
+}
+
+func TestCompleteProgressPlaysNotificationSound(t *testing.T) {
+	originalShow, originalPlay := showProgressOverlay, playOverlaySound
+	t.Cleanup(func() {
+		showProgressOverlay, playOverlaySound = originalShow, originalPlay
+		SetSoundFunc(nil)
+	})
+
+	var shown overlay.OverlayOptions
+	played := make(chan string, 1)
+	showProgressOverlay = func(opts overlay.OverlayOptions) { shown = opts }
+	playOverlaySound = func(ctx context.Context, sound string) { played <- sound }
+	SetSoundFunc(func() string { return SoundDefault })
+
+	completeProgressOverlay("sound", "done")
+
+	select {
+	case sound := <-played:
+		if sound != SoundDefault {
+			t.Fatalf("expected the default sound, got %q", sound)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected completing a progress notification to play a sound")
+	}
+	if shown.Message != "done" || shown.Loading {
+		t.Fatalf("expected the completed notification to be shown, got %+v", shown)
+	}
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"wox/util"
+)
+
+const (
+	// SoundDefault plays the platform's standard notification sound.
+	SoundDefault = "default"
+	// SoundSilent shows notifications without sound. Any other value is the
+	// path of a sound file.
+	SoundSilent = "silent"
+)
+
+var soundMu sync.RWMutex
+var soundFunc func() string
+
+// playOverlaySound plays the sound of notifications drawn with the overlay
+// window, which has no sound of its own. Replaced in tests.
+var playOverlaySound = playSound
+
+// SetSoundFunc registers the lookup of the notification sound, see SoundDefault
+// and SoundSilent. Like SetSuppressFunc it is set by the setting manager so the
+// sound follows setting changes without this package depending on settings.
+func SetSoundFunc(fn func() string) {
+	soundMu.Lock()
+	defer soundMu.Unlock()
+	soundFunc = fn
+}
+
+// notificationSound returns the configured sound as SoundDefault, the path of
+// an existing sound file, or "" for no sound. Without a registered sound
+// function notifications stay silent.
+func notificationSound(ctx context.Context) string {
+	soundMu.RLock()
+	fn := soundFunc
+	soundMu.RUnlock()
+	if fn == nil {
+		return ""
+	}
+
+	sound := fn()
+	if sound == "" || sound == SoundSilent {
+		return ""
+	}
+	if sound != SoundDefault && !util.IsFileExists(sound) {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("notification sound file does not exist: %s", sound))
+		return ""
+	}
+	return sound
+}
@@ -0,0 +1,35 @@
+package notifier
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+#include <stdlib.h>
+
+int playNotificationSound(const char *path);
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"unsafe"
+	"wox/util"
+)
+
+// playSound plays sound with NSSound, used for notifications the
+// notification center cannot give a sound, see showNotification.
+func playSound(ctx context.Context, sound string) {
+	if sound == "" {
+		return
+	}
+
+	// An empty path plays the default notification sound, see notify_darwin.m.
+	path := ""
+	if sound != SoundDefault {
+		path = sound
+	}
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	if C.playNotificationSound(cPath) == 0 {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("failed to play notification sound %s", sound))
+	}
+}
@@ -0,0 +1,25 @@
+package notifier
+
+import "context"
+
+// notificationSoundName is the freedesktop sound theme event used for incoming
+// messages, so the sound matches the desktop's own notifications.
+const notificationSoundName = "message-new-instant"
+
+// playSound is not needed on Linux: notifications carry their sound as a
+// D-Bus hint, see soundHints, and progress notifications complete with a
+// regular notification.
+func playSound(ctx context.Context, sound string) {}
+
+// soundHints returns the freedesktop notification hints that make the daemon
+// play sound, or suppress the daemon's own sound when sound is "".
+func soundHints(sound string) map[string]any {
+	switch sound {
+	case "":
+		return map[string]any{"suppress-sound": true}
+	case SoundDefault:
+		return map[string]any{"sound-name": notificationSoundName}
+	default:
+		return map[string]any{"sound-file": sound}
+	}
+}
@@ -0,0 +1,15 @@
+package notifier
+
+import "testing"
+
+func TestSoundHintsFollowTheNotificationSound(t *testing.T) {
+	if hints := soundHints(""); hints["suppress-sound"] != true {
+		t.Fatalf("expected no sound to suppress the daemon sound, got %v", hints)
+	}
+	if hints := soundHints(SoundDefault); hints["sound-name"] != notificationSoundName {
+		t.Fatalf("expected the default sound to use the sound theme, got %v", hints)
+	}
+	if hints := soundHints("/tmp/ding.oga"); hints["sound-file"] != "/tmp/ding.oga" {
+		t.Fatalf("expected a custom sound to use its file, got %v", hints)
+	}
+}
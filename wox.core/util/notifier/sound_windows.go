@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"unsafe"
+	"wox/util"
+)
+
+var (
+	user32          = syscall.NewLazyDLL("user32.dll")
+	procMessageBeep = user32.NewProc("MessageBeep")
+	winmm           = syscall.NewLazyDLL("winmm.dll")
+	procPlaySoundW  = winmm.NewProc("PlaySoundW")
+)
+
+const (
+	mbIconAsterisk = 0x00000040
+	sndAsync       = 0x0001
+	sndNoDefault   = 0x0002
+	sndFilename    = 0x00020000
+)
+
+// playSound plays sound with the system sound APIs. The overlay window used
+// for notifications has no sound of its own. Both calls return immediately.
+func playSound(ctx context.Context, sound string) {
+	switch sound {
+	case "":
+		return
+	case SoundDefault:
+		if ret, _, err := procMessageBeep.Call(mbIconAsterisk); ret == 0 {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to play notification sound: %s", err.Error()))
+		}
+	default:
+		path, err := syscall.UTF16PtrFromString(sound)
+		if err != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("invalid notification sound path %s: %s", sound, err.Error()))
+			return
+		}
+		// PlaySound only supports .wav files.
+		if ret, _, callErr := procPlaySoundW.Call(uintptr(unsafe.Pointer(path)), 0, sndFilename|sndAsync|sndNoDefault); ret == 0 {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("failed to play notification sound %s: %s", sound, callErr.Error()))
+		}
+	}
+}
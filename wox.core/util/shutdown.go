@@ -0,0 +1,79 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// shutdownTracker tracks work that should stop at a safe point when Wox
+// quits, such as migrations, so quitting does not kill it halfway through.
+type shutdownTracker struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+var shutdownState = newShutdownTracker()
+
+func newShutdownTracker() *shutdownTracker {
+	tracker := &shutdownTracker{}
+	tracker.ctx, tracker.cancel = context.WithCancel(context.Background())
+	return tracker
+}
+
+// WithShutdown returns a copy of ctx that is cancelled when RequestShutdown is
+// called. Long running work should check ctx.Err() between steps and call done
+// once it stopped, so RequestShutdown can wait for it.
+func WithShutdown(ctx context.Context) (context.Context, func()) {
+	return shutdownState.with(ctx)
+}
+
+// RequestShutdown cancels every context returned by WithShutdown and waits at
+// most timeout for their work to stop. It returns false when the wait timed out.
+func RequestShutdown(ctx context.Context, timeout time.Duration) bool {
+	return shutdownState.request(ctx, timeout)
+}
+
+func (t *shutdownTracker) with(ctx context.Context) (context.Context, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	child, cancel := context.WithCancel(ctx)
+	if t.ctx.Err() != nil {
+		cancel()
+		return child, func() {}
+	}
+
+	t.running.Add(1)
+	stop := context.AfterFunc(t.ctx, cancel)
+	var doneOnce sync.Once
+	return child, func() {
+		doneOnce.Do(func() {
+			stop()
+			cancel()
+			t.running.Done()
+		})
+	}
+}
+
+func (t *shutdownTracker) request(ctx context.Context, timeout time.Duration) bool {
+	t.mu.Lock()
+	t.cancel()
+	t.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		t.running.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return true
+	case <-time.After(timeout):
+		GetLogger().Warn(ctx, "timed out waiting for running work to stop before quitting")
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdownCancelsRunningWorkAndWaitsForIt(t *testing.T) {
+	tracker := newShutdownTracker()
+	workCtx, done := tracker.with(context.Background())
+
+	stopped := make(chan bool)
+	go func() {
+		stopped <- tracker.request(context.Background(), time.Minute)
+	}()
+
+	select {
+	case <-workCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected shutdown to cancel the running work")
+	}
+	select {
+	case <-stopped:
+		t.Fatal("expected shutdown to wait until the work is done")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	done()
+	if !<-stopped {
+		t.Fatal("expected shutdown to report the work stopped")
+	}
+
+	lateCtx, lateDone := tracker.with(context.Background())
+	defer lateDone()
+	if lateCtx.Err() == nil {
+		t.Fatal("expected work started after shutdown to be cancelled")
+	}
+}
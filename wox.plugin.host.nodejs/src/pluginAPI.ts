@@ -6,6 +6,7 @@ import {
   PublicAPI,
   PushAttentionRequest,
   Query,
+  QueryHistory,
   RefreshQueryParam,
   Result,
   ResultAction,
@@ -105,6 +106,14 @@ export class PluginAPI implements PublicAPI {
     await this.invokeMethod(ctx, "Notify", { message })
   }
 
+  async ShowProgress(ctx: Context, id: string, title: string, percent: number): Promise<void> {
+    await this.invokeMethod(ctx, "ShowProgress", { id, title, percent: Math.round(percent).toString() })
+  }
+
+  async CompleteProgress(ctx: Context, id: string, message: string): Promise<void> {
+    await this.invokeMethod(ctx, "CompleteProgress", { id, message })
+  }
+
   async PushAttention(ctx: Context, request: PushAttentionRequest): Promise<void> {
     await this.invokeMethod(ctx, "PushAttention", { request: JSON.stringify(request) })
   }
@@ -145,6 +154,14 @@ export class PluginAPI implements PublicAPI {
     await this.invokeMethod(ctx, "SaveSetting", { key, value, isPlatformSpecific: isPlatformSpecific.toString() })
   }
 
+  async AddQueryHistory(ctx: Context, query: ChangeQueryParam): Promise<void> {
+    await this.invokeMethod(ctx, "AddQueryHistory", { query: JSON.stringify(query) })
+  }
+
+  async GetQueryHistory(ctx: Context, limit: number): Promise<QueryHistory[]> {
+    return ((await this.invokeMethod(ctx, "GetQueryHistory", { limit: Math.floor(limit).toString() })) as QueryHistory[] | null) ?? []
+  }
+
   async OnSettingChanged(ctx: Context, callback: (ctx: Context, key: string, value: string) => void): Promise<void> {
     const callbackId = crypto.randomUUID()
     this.settingChangeCallbacks.set(callbackId, callback)
@@ -1,7 +1,7 @@
 import asyncio
 import json
 import uuid
-from typing import Any, Awaitable, Callable, Dict, Optional
+from typing import Any, Awaitable, Callable, Dict, List, Optional
 
 import websockets
 from wox_plugin import (
@@ -17,6 +17,7 @@ from wox_plugin import (
     PluginSettingDefinitionItem,
     PublicAPI,
     Query,
+    QueryHistory,
     RefreshQueryParam,
     Result,
     ResultActionType,
@@ -118,6 +119,14 @@ class PluginAPI(PublicAPI):
         """Show a notification message"""
         await self.invoke_method(ctx, "Notify", {"message": message})
 
+    async def show_progress(self, ctx: Context, progress_id: str, title: str, percent: int) -> None:
+        """Create or update a progress notification"""
+        await self.invoke_method(ctx, "ShowProgress", {"id": progress_id, "title": title, "percent": str(percent)})
+
+    async def complete_progress(self, ctx: Context, progress_id: str, message: str) -> None:
+        """Finalize a progress notification"""
+        await self.invoke_method(ctx, "CompleteProgress", {"id": progress_id, "message": message})
+
     async def push_attention(self, ctx: Context, request: PushAttentionRequest) -> None:
         """Push a persistent attention item into Wox."""
         await self.invoke_method(ctx, "PushAttention", {"request": request.to_json()})
@@ -161,6 +170,15 @@ class PluginAPI(PublicAPI):
             {"key": key, "value": value, "isPlatformSpecific": is_platform_specific},
         )
 
+    async def add_query_history(self, ctx: Context, query: ChangeQueryParam) -> None:
+        """Record a query in the query history of this plugin"""
+        await self.invoke_method(ctx, "AddQueryHistory", {"query": query.to_json()})
+
+    async def get_query_history(self, ctx: Context, limit: int) -> List[QueryHistory]:
+        """Get the query history of this plugin, newest first"""
+        response = await self.invoke_method(ctx, "GetQueryHistory", {"limit": str(limit)})
+        return [QueryHistory.from_json(json.dumps(item)) for item in response or []]
+
     async def on_setting_changed(
         self,
         ctx: Context,
@@ -551,6 +551,15 @@ export interface Result {
    */
   ScoreKey?: string
 
+  /**
+   * Language independent identity of the result.
+   *
+   * Optional. When set, favorites and action history are keyed by it instead
+   * of the visible title, so they survive translated titles and language
+   * switches.
+   */
+  StableKey?: string
+
   /**
    * Group name for organizing results.
    *
@@ -1269,6 +1278,20 @@ export interface ChangeQueryParam {
   ContextData?: MapString
 }
 
+/**
+ * A query recorded with `AddQueryHistory`.
+ */
+export interface QueryHistory {
+  /**
+   * The recorded query
+   */
+  Query: ChangeQueryParam
+  /**
+   * When the query was recorded, in milliseconds since the epoch
+   */
+  Timestamp: number
+}
+
 export interface RefreshQueryParam {
   /**
    * Controls whether to maintain the previously selected item index after refresh.
@@ -1400,6 +1423,20 @@ export interface PublicAPI {
    */
   Notify: (ctx: Context, message: string) => Promise<void>
 
+  /**
+   * Create or update a progress notification identified by id.
+   *
+   * Calling it again with the same id updates the existing notification instead of
+   * showing a new one. Ids are scoped to the current plugin. On Linux progress is not
+   * shown, only the CompleteProgress message.
+   */
+  ShowProgress: (ctx: Context, id: string, title: string, percent: number) => Promise<void>
+
+  /**
+   * Finalize a progress notification with a message
+   */
+  CompleteProgress: (ctx: Context, id: string, message: string) => Promise<void>
+
   /**
    * Push a persistent attention item into Wox.
    *
@@ -1447,6 +1484,19 @@ export interface PublicAPI {
    */
   SaveSetting: (ctx: Context, key: string, value: string, isPlatformSpecific: boolean) => Promise<void>
 
+  /**
+   * Record a query in the query history of this plugin, e.g. so a calculator can list past calculations.
+   *
+   * The history is kept apart from the global query history and stays on this device.
+   * Queries matching the query history exclude patterns are not recorded.
+   */
+  AddQueryHistory: (ctx: Context, query: ChangeQueryParam) => Promise<void>
+
+  /**
+   * Get up to limit queries recorded with AddQueryHistory, newest first
+   */
+  GetQueryHistory: (ctx: Context, limit: number) => Promise<QueryHistory[]>
+
   /**
    * Register setting changed callback
    */
@@ -75,6 +75,7 @@ Methods for interacting with Wox:
 - `Selection`: Selected text or file paths
 - `QueryEnv`: Environment context (active window, browser URL)
 - `ChangeQueryParam`: Parameters to change the query
+- `QueryHistory`: A query recorded in the query history of a plugin
 - `RefreshQueryParam`: Parameters to refresh the query
 - `CopyParams`: Parameters for clipboard operations
 - `ScreenshotOption`: Options for the screenshot workflow
@@ -241,6 +242,7 @@ from .models.query import (
     MetadataCommand,
     Query,
     QueryEnv,
+    QueryHistory,
     QueryType,
     RefreshQueryParam,
     Selection,
@@ -344,6 +346,7 @@ __all__: List[str] = [
     "assistant_message",
     # Query
     "ChangeQueryParam",
+    "QueryHistory",
     "RefreshQueryParam",
     "QueryType",
     "Selection",
@@ -14,7 +14,7 @@ from .models.attention import PushAttentionRequest
 from .models.context import Context
 from .models.log import LogLevel
 from .models.mru import MRUData
-from .models.query import ChangeQueryParam, CopyParams, MetadataCommand, Query, RefreshQueryParam
+from .models.query import ChangeQueryParam, CopyParams, MetadataCommand, Query, QueryHistory, RefreshQueryParam
 from .models.result import Result, UpdatableResult  # noqa: F401
 from .models.setting import PluginSettingDefinitionItem
 from .models.toolbar_msg import ToolbarMsg
@@ -201,6 +201,36 @@ class PublicAPI(Protocol):
         """
         ...
 
+    async def show_progress(self, ctx: Context, progress_id: str, title: str, percent: int) -> None:
+        """
+        Create or update a progress notification.
+
+        Calling it again with the same id updates the existing notification
+        instead of showing a new one. Ids are scoped to the current plugin.
+        On Linux progress is not shown, only the complete_progress message.
+
+        Args:
+            ctx: Context
+            progress_id: Id of the progress notification
+            title: Title shown before the percentage (supports i18n keys)
+            percent: Progress from 0 to 100
+
+        Example:
+            await api.show_progress(ctx, "index", "Indexing files", 40)
+        """
+        ...
+
+    async def complete_progress(self, ctx: Context, progress_id: str, message: str) -> None:
+        """
+        Finalize a progress notification with a message.
+
+        Args:
+            ctx: Context
+            progress_id: Id passed to show_progress
+            message: Final message text (supports i18n keys)
+        """
+        ...
+
     async def push_attention(self, ctx: Context, request: PushAttentionRequest) -> None:
         """
         Push a persistent attention item into Wox.
@@ -316,6 +346,36 @@ class PublicAPI(Protocol):
         """
         ...
 
+    async def add_query_history(self, ctx: Context, query: ChangeQueryParam) -> None:
+        """
+        Record a query in the query history of this plugin.
+
+        The history is kept apart from the global query history and stays on
+        this device. Queries matching the query history exclude patterns are
+        not recorded.
+
+        Args:
+            ctx: Context
+            query: Query to record
+
+        Example:
+            await api.add_query_history(ctx, ChangeQueryParam(query_type=QueryType.INPUT, query_text="1+1"))
+        """
+        ...
+
+    async def get_query_history(self, ctx: Context, limit: int) -> List[QueryHistory]:
+        """
+        Get up to limit queries recorded with add_query_history, newest first.
+
+        Args:
+            ctx: Context
+            limit: Maximum number of queries to return
+
+        Returns:
+            List[QueryHistory]: The recorded queries
+        """
+        ...
+
     async def on_setting_changed(
         self,
         ctx: Context,
@@ -702,6 +702,41 @@ class ChangeQueryParam:
         )
 
 
+@dataclass
+class QueryHistory:
+    """
+    A query recorded with `api.add_query_history()`.
+
+    Attributes:
+        query: The recorded query
+        timestamp: When the query was recorded, in milliseconds since the epoch
+    """
+
+    query: ChangeQueryParam
+    timestamp: int = 0
+
+    @classmethod
+    def from_json(cls, json_str: str) -> "QueryHistory":
+        """
+        Create from JSON string with camelCase naming.
+
+        Args:
+            json_str: JSON string with "Query" and "Timestamp"
+
+        Returns:
+            A new QueryHistory instance
+        """
+        data = json.loads(json_str)
+        query = data.get("Query") or {}
+        # Wox sends the selection as an object, ChangeQueryParam reads it as a JSON string.
+        if isinstance(query.get("QuerySelection"), dict):
+            query["QuerySelection"] = json.dumps(query["QuerySelection"])
+        return cls(
+            query=ChangeQueryParam.from_json(json.dumps(query)),
+            timestamp=data.get("Timestamp", 0),
+        )
+
+
 @dataclass
 class RefreshQueryParam:
     """
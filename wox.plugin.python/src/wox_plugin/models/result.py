@@ -743,6 +743,7 @@ class Result:
         preview: Preview content for detail view
         score: Relevance score for sorting
         score_key: Stable identity for actioned-result ranking
+        stable_key: Language independent identity for favorites and history
         group: Group name for categorization
         group_score: Group relevance score
         tails: Additional visual elements
@@ -847,6 +848,14 @@ class Result:
     keep the same usage score in global search.
     """
 
+    stable_key: str = field(default="")
+    """
+    Language independent identity of the result.
+
+    When set, favorites and action history are keyed by it instead of the
+    visible title, so they survive translated titles and language switches.
+    """
+
     group: str = field(default="")
     """
     Group name for categorizing results.
@@ -906,6 +915,7 @@ class Result:
             "SubTitle": self.sub_title,
             "Score": self.score,
             "ScoreKey": self.score_key,
+            "StableKey": self.stable_key,
             "Group": self.group,
             "GroupScore": self.group_score,
         }
@@ -955,6 +965,7 @@ class Result:
             preview=preview,
             score=data.get("Score", 0.0),
             score_key=data.get("ScoreKey", ""),
+            stable_key=data.get("StableKey", ""),
             group=data.get("Group", ""),
             group_score=data.get("GroupScore", 0.0),
             tails=tails,
@@ -283,7 +283,7 @@ class WoxAIChatController extends GetxController {
                 isCategory: false,
                 children: [],
                 onExecute: (String traceId) {
-                  aiChatData.value.model.value = AIModel(name: model.name, provider: model.provider, providerAlias: model.providerAlias);
+                  aiChatData.value.model.value = AIModel(name: model.name, provider: model.provider, providerAlias: model.providerAlias, providerId: model.providerId);
                   hideChatSelectPanel();
                 },
               ),
@@ -3003,6 +3003,13 @@ const List<_BuiltInSettingSearchDefinition> _builtInSettingSearchDefinitions = [
     searchKeywords: ['update channel', 'release channel', 'stable', 'beta', 'stable channel', 'beta channel', 'prerelease'],
   ),
   _BuiltInSettingSearchDefinition(settingKey: 'MainHotkey', navPath: 'general', titleKey: 'ui_hotkey', subtitleKey: 'ui_hotkey_tips', searchKeywords: ['shortcut', 'main hotkey']),
+  _BuiltInSettingSearchDefinition(
+    settingKey: 'MainHotkeys',
+    navPath: 'general',
+    titleKey: 'ui_additional_hotkeys',
+    subtitleKey: 'ui_additional_hotkeys_tips',
+    searchKeywords: ['shortcut', 'main hotkeys', 'additional hotkey'],
+  ),
   _BuiltInSettingSearchDefinition(
     settingKey: 'SelectionHotkey',
     navPath: 'general',
@@ -3045,6 +3052,13 @@ const List<_BuiltInSettingSearchDefinition> _builtInSettingSearchDefinitions = [
     subtitleKey: 'ui_max_result_count_tips',
     searchKeywords: ['result count'],
   ),
+  _BuiltInSettingSearchDefinition(
+    settingKey: 'QueryDebounceMs',
+    navPath: 'ui',
+    titleKey: 'ui_query_debounce',
+    subtitleKey: 'ui_query_debounce_tips',
+    searchKeywords: ['debounce', 'typing delay'],
+  ),
   _BuiltInSettingSearchDefinition(settingKey: 'EnableGlance', navPath: 'ui', titleKey: 'ui_glance_enable', subtitleKey: 'ui_glance_enable_tips', searchKeywords: ['glance']),
   _BuiltInSettingSearchDefinition(settingKey: 'HideGlanceIcon', navPath: 'ui', titleKey: 'ui_glance_hide_icon', subtitleKey: 'ui_glance_hide_icon_tips'),
   _BuiltInSettingSearchDefinition(settingKey: 'PrimaryGlance', navPath: 'ui', titleKey: 'ui_glance_primary', subtitleKey: 'ui_glance_primary_tips'),
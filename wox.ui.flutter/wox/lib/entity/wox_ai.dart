@@ -7,13 +7,15 @@ class AIModel {
   late String name;
   late String provider;
   late String providerAlias;
+  late String providerId;
 
-  AIModel({required this.name, required this.provider, required this.providerAlias});
+  AIModel({required this.name, required this.provider, required this.providerAlias, this.providerId = ""});
 
   AIModel.fromJson(Map<String, dynamic> json) {
     name = json['Name'];
     provider = json['Provider'];
     providerAlias = json['ProviderAlias'] ?? "";
+    providerId = json['ProviderId'] ?? "";
   }
 
   Map<String, dynamic> toJson() {
@@ -21,6 +23,7 @@ class AIModel {
     data['Name'] = name;
     data['Provider'] = provider;
     data['ProviderAlias'] = providerAlias;
+    data['ProviderId'] = providerId;
     return data;
   }
 
@@ -4,6 +4,8 @@ import 'package:wox/entity/wox_glance.dart';
 class WoxSetting {
   late bool enableAutostart;
   late String mainHotkey;
+  // MainHotkeys holds every hotkey that toggles Wox, mainHotkey is its first entry.
+  late List<String> mainHotkeys;
   late String selectionHotkey;
   late List<IgnoredHotkeyApp> ignoredHotkeyApps;
   late String logLevel;
@@ -28,6 +30,8 @@ class WoxSetting {
   late List<AIProvider> aiProviders;
   late int appWidth;
   late int maxResultCount;
+  // QueryDebounceMs delays input queries until typing pauses, 0 disables it.
+  late int queryDebounceMs;
   // UiDensity is stored as a small enum so Flutter derives visual metrics
   // locally while staying aligned with backend window-height estimates.
   late String uiDensity;
@@ -41,6 +45,7 @@ class WoxSetting {
   late String httpProxyUrl;
   late bool enableAutoBackup;
   late bool enableAutoUpdate;
+  late bool isAutoUpdatePolicyLocked;
   late String releaseChannel;
   late bool enableAnonymousUsageStats;
   late String customPythonPath;
@@ -57,6 +62,7 @@ class WoxSetting {
   WoxSetting({
     required this.enableAutostart,
     required this.mainHotkey,
+    this.mainHotkeys = const [],
     required this.selectionHotkey,
     required this.ignoredHotkeyApps,
     required this.logLevel,
@@ -78,6 +84,7 @@ class WoxSetting {
     required this.aiProviders,
     required this.appWidth,
     required this.maxResultCount,
+    this.queryDebounceMs = 0,
     required this.uiDensity,
     required this.themeId,
     required this.appFontFamily,
@@ -89,6 +96,7 @@ class WoxSetting {
     required this.httpProxyUrl,
     required this.enableAutoBackup,
     required this.enableAutoUpdate,
+    required this.isAutoUpdatePolicyLocked,
     required this.releaseChannel,
     required this.enableAnonymousUsageStats,
     required this.customPythonPath,
@@ -106,6 +114,7 @@ class WoxSetting {
   WoxSetting.fromJson(Map<String, dynamic> json) {
     enableAutostart = json['EnableAutostart'] ?? false;
     mainHotkey = json['MainHotkey'];
+    mainHotkeys = json['MainHotkeys'] == null ? [mainHotkey] : List<String>.from(json['MainHotkeys']);
     selectionHotkey = json['SelectionHotkey'];
     if (json['IgnoredHotkeyApps'] != null) {
       ignoredHotkeyApps = <IgnoredHotkeyApp>[];
@@ -167,6 +176,7 @@ class WoxSetting {
 
     appWidth = json['AppWidth'];
     maxResultCount = json['MaxResultCount'];
+    queryDebounceMs = json['QueryDebounceMs'] ?? 0;
     uiDensity = json['UiDensity'] ?? 'normal';
     themeId = json['ThemeId'];
     appFontFamily = json['AppFontFamily'] ?? '';
@@ -178,6 +188,7 @@ class WoxSetting {
     httpProxyUrl = json['HttpProxyUrl'] ?? '';
     enableAutoBackup = json['EnableAutoBackup'] ?? false;
     enableAutoUpdate = json['EnableAutoUpdate'] ?? true;
+    isAutoUpdatePolicyLocked = json['IsAutoUpdatePolicyLocked'] ?? false;
     releaseChannel = json['ReleaseChannel'] ?? 'stable';
     enableAnonymousUsageStats = json['EnableAnonymousUsageStats'] ?? true;
     customPythonPath = json['CustomPythonPath'] ?? '';
@@ -200,6 +211,7 @@ class WoxSetting {
     final Map<String, dynamic> data = <String, dynamic>{};
     data['EnableAutostart'] = enableAutostart;
     data['MainHotkey'] = mainHotkey;
+    data['MainHotkeys'] = mainHotkeys;
     data['SelectionHotkey'] = selectionHotkey;
     data['IgnoredHotkeyApps'] = ignoredHotkeyApps;
     data['LogLevel'] = logLevel;
@@ -221,6 +233,7 @@ class WoxSetting {
     data['AIProviders'] = aiProviders;
     data['AppWidth'] = appWidth;
     data['MaxResultCount'] = maxResultCount;
+    data['QueryDebounceMs'] = queryDebounceMs;
     data['UiDensity'] = uiDensity;
     data['ThemeId'] = themeId;
     data['AppFontFamily'] = appFontFamily;
@@ -232,6 +245,7 @@ class WoxSetting {
     data['HttpProxyUrl'] = httpProxyUrl;
     data['EnableAutoBackup'] = enableAutoBackup;
     data['EnableAutoUpdate'] = enableAutoUpdate;
+    data['IsAutoUpdatePolicyLocked'] = isAutoUpdatePolicyLocked;
     data['ReleaseChannel'] = releaseChannel;
     data['EnableAnonymousUsageStats'] = enableAnonymousUsageStats;
     data['CustomPythonPath'] = customPythonPath;
@@ -441,27 +455,36 @@ class SettingWindowContext {
 }
 
 class AIProvider {
+  late String id;
   late String name;
   late String alias;
   late String apiKey;
 
   late String host;
+  late String defaultModel;
+  late int timeoutSeconds;
 
-  AIProvider({required this.name, required this.alias, required this.apiKey, required this.host});
+  AIProvider({this.id = '', required this.name, required this.alias, required this.apiKey, required this.host, this.defaultModel = '', this.timeoutSeconds = 0});
 
   AIProvider.fromJson(Map<String, dynamic> json) {
+    id = json['Id'] ?? '';
     name = json['Name'];
     alias = json['Alias'] ?? '';
     apiKey = json['ApiKey'];
     host = json['Host'];
+    defaultModel = json['DefaultModel'] ?? '';
+    timeoutSeconds = int.tryParse('${json['TimeoutSeconds'] ?? 0}') ?? 0;
   }
 
   Map<String, dynamic> toJson() {
     final Map<String, dynamic> data = <String, dynamic>{};
+    data['Id'] = id;
     data['Name'] = name;
     data['Alias'] = alias;
     data['ApiKey'] = apiKey;
     data['Host'] = host;
+    data['DefaultModel'] = defaultModel;
+    data['TimeoutSeconds'] = timeoutSeconds;
     return data;
   }
 }
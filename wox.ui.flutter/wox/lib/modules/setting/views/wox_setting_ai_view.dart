@@ -71,6 +71,8 @@ class WoxSettingAIView extends WoxSettingBaseView {
 
                           {"Key": "Host", "Label": "i18n:ui_ai_providers_host", "Tooltip": "i18n:ui_ai_providers_host_tooltip", "Width": 160, "Type": "text"},
                           {"Key": "ApiKey", "Label": "i18n:ui_ai_providers_api_key", "Tooltip": "i18n:ui_ai_providers_api_key_tooltip", "Type": "text", "TextMaxLines": 1},
+                          {"Key": "DefaultModel", "Label": "i18n:ui_ai_providers_default_model", "Tooltip": "i18n:ui_ai_providers_default_model_tooltip", "Width": 120, "Type": "text", "TextMaxLines": 1},
+                          {"Key": "TimeoutSeconds", "Label": "i18n:ui_ai_providers_timeout", "Tooltip": "i18n:ui_ai_providers_timeout_tooltip", "Width": 80, "Type": "text", "TextMaxLines": 1},
                         ],
                         "SortColumnKey": "Name",
                       }),
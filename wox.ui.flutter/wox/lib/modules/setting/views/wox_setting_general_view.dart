@@ -208,6 +208,13 @@ class WoxSettingGeneralView extends WoxSettingBaseView {
                   },
                 ),
               ),
+              formField(
+                settingKey: "MainHotkeys",
+                label: controller.tr("ui_additional_hotkeys"),
+                tips: controller.tr("ui_additional_hotkeys_tips"),
+                controlMaxWidth: 520,
+                child: Obx(() => _buildAdditionalMainHotkeys()),
+              ),
               // On Wayland without evdev access, double-modifier hotkeys (e.g.
               // double Ctrl) and CapsLock combos cannot work. Show a guiding
               // prompt with a link to the help article.
@@ -561,6 +568,51 @@ class WoxSettingGeneralView extends WoxSettingBaseView {
     );
   }
 
+  // The first main hotkey is edited by the MainHotkey recorder, these rows edit
+  // the others. The empty recorder at the end adds another one.
+  Widget _buildAdditionalMainHotkeys() {
+    final mainHotkeys = controller.woxSetting.value.mainHotkeys;
+    final primaryHotkey = mainHotkeys.isEmpty ? controller.woxSetting.value.mainHotkey : mainHotkeys.first;
+    final additionalHotkeys = mainHotkeys.length > 1 ? mainHotkeys.sublist(1) : <String>[];
+
+    void saveAdditionalHotkeys(List<String> hotkeys) {
+      controller.updateConfig("MainHotkeys", json.encode([primaryHotkey, ...hotkeys]));
+    }
+
+    return Column(
+      crossAxisAlignment: CrossAxisAlignment.start,
+      children: [
+        for (var i = 0; i < additionalHotkeys.length; i++)
+          Padding(
+            padding: const EdgeInsets.only(bottom: 8),
+            child: Row(
+              mainAxisSize: MainAxisSize.min,
+              children: [
+                Flexible(
+                  child: WoxHotkeyRecorder(
+                    key: ValueKey('main-hotkey-$i-${additionalHotkeys[i]}'),
+                    hotkey: WoxHotkey.parseHotkeyFromString(additionalHotkeys[i]),
+                    onHotKeyRecorded: (hotkey) => saveAdditionalHotkeys([...additionalHotkeys]..[i] = hotkey),
+                  ),
+                ),
+                IconButton(
+                  tooltip: controller.tr("ui_additional_hotkeys_remove"),
+                  visualDensity: VisualDensity.compact,
+                  icon: Icon(Icons.close, color: getThemeSubTextColor(), size: 18),
+                  onPressed: () => saveAdditionalHotkeys([...additionalHotkeys]..removeAt(i)),
+                ),
+              ],
+            ),
+          ),
+        WoxHotkeyRecorder(
+          key: ValueKey('main-hotkey-new-${additionalHotkeys.length}'),
+          hotkey: null,
+          onHotKeyRecorded: (hotkey) => saveAdditionalHotkeys([...additionalHotkeys, hotkey]),
+        ),
+      ],
+    );
+  }
+
   Widget _buildWaylandEvdevHint() {
     final isLight = getThemeBackgroundColor().computeLuminance() > 0.5;
     final accentColor = isLight ? const Color(0xFFB96D18) : const Color(0xFFF3B75C);
@@ -246,6 +246,26 @@ class WoxSettingUIView extends WoxSettingBaseView {
                   );
                 }),
               ),
+              formField(
+                settingKey: "QueryDebounceMs",
+                label: controller.tr("ui_query_debounce"),
+                tips: controller.tr("ui_query_debounce_tips"),
+                child: Obx(() {
+                  const debounceOptions = [0, 50, 100, 150, 200, 300, 500];
+                  final currentValue = controller.woxSetting.value.queryDebounceMs;
+                  // Keep values set elsewhere, e.g. by sync, selectable.
+                  final values = debounceOptions.contains(currentValue) ? debounceOptions : ([...debounceOptions, currentValue]..sort());
+                  return WoxDropdownButton<int>(
+                    value: currentValue,
+                    items: values.map((ms) => WoxDropdownItem<int>(value: ms, label: ms == 0 ? controller.tr("ui_query_debounce_off") : "$ms ms")).toList(),
+                    onChanged: (v) {
+                      if (v != null) {
+                        controller.updateConfig("QueryDebounceMs", v.toString());
+                      }
+                    },
+                  );
+                }),
+              ),
             ],
           ),
           formSection(
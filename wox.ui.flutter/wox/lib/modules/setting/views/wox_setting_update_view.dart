@@ -24,13 +24,18 @@ class WoxSettingUpdateView extends WoxSettingBaseView {
               formField(
                 settingKey: "EnableAutoUpdate",
                 label: controller.tr("ui_enable_auto_update"),
-                tips: controller.tr("ui_enable_auto_update_tips"),
+                tips: controller.woxSetting.value.isAutoUpdatePolicyLocked
+                    ? controller.tr("ui_enable_auto_update_policy_locked_tips")
+                    : controller.tr("ui_enable_auto_update_tips"),
                 child: WoxSwitch(
                   value: controller.woxSetting.value.enableAutoUpdate,
-                  onChanged: (bool value) {
-                    controller.updateConfig("EnableAutoUpdate", value.toString());
-                    _refreshDoctorAfterUpdateSettingChanges();
-                  },
+                  // A machine policy forces auto update off, so the switch is read-only.
+                  onChanged: controller.woxSetting.value.isAutoUpdatePolicyLocked
+                      ? null
+                      : (bool value) {
+                          controller.updateConfig("EnableAutoUpdate", value.toString());
+                          _refreshDoctorAfterUpdateSettingChanges();
+                        },
                 ),
               ),
               formField(